@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/middleware/stdmux"
+)
+
+func main() {
+	manager := sessions.New(sessions.Config{Cookie: "mysessionid"})
+
+	r := chi.NewRouter()
+	// starts and releases the session of each request, the handlers read it by the stdmux.Get.
+	r.Use(manager.Handler)
+
+	r.Get("/login", func(w http.ResponseWriter, r *http.Request) {
+		s := stdmux.Get(r)
+		s.Set("user", "kataras")
+		w.Write([]byte("You are logged in, navigate to the /account"))
+	})
+
+	r.Get("/logout", func(w http.ResponseWriter, r *http.Request) {
+		manager.Destroy(w, r)
+		w.Write([]byte("You are logged out, the /account responds with 401 now"))
+	})
+
+	requireUser := stdmux.Require(manager, stdmux.Options{Keys: []string{"user"}})
+
+	// a single route.
+	r.With(requireUser).Get("/account", account)
+
+	// or a group of routes.
+	r.Route("/settings", func(r chi.Router) {
+		r.Use(requireUser)
+		r.Get("/", settings)
+	})
+
+	http.ListenAndServe(":8080", r)
+}
+
+func account(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	w.Write([]byte(fmt.Sprintf("Hello %s", s.GetString("user"))))
+}
+
+func settings(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	w.Write([]byte(fmt.Sprintf("The settings of %s", s.GetString("user"))))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/middleware/stdmux"
+)
+
+func main() {
+	manager := sessions.New(sessions.Config{Cookie: "mysessionid"})
+
+	r := mux.NewRouter()
+
+	r.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s := manager.Start(w, r)
+		s.Set("user", "kataras")
+		w.Write([]byte("You are logged in, navigate to the /account"))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		manager.Destroy(w, r)
+		w.Write([]byte("You are logged out, the /account responds with 401 now"))
+	}).Methods(http.MethodGet)
+
+	// the routes of the subrouter require a session with the "user",
+	// the middleware starts the session, the handlers read it by the stdmux.Get.
+	account := r.PathPrefix("/account").Subrouter()
+	account.Use(stdmux.Require(manager, stdmux.Options{Keys: []string{"user"}}))
+	account.HandleFunc("", profile).Methods(http.MethodGet)
+	account.HandleFunc("/settings", settings).Methods(http.MethodGet)
+
+	// a single route of any session with values.
+	r.Handle("/visits", stdmux.RequireSessionFunc(manager, visits)).Methods(http.MethodGet)
+
+	http.ListenAndServe(":8080", r)
+}
+
+func profile(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	w.Write([]byte(fmt.Sprintf("Hello %s", s.GetString("user"))))
+}
+
+func settings(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	w.Write([]byte(fmt.Sprintf("The settings of %s", s.GetString("user"))))
+}
+
+func visits(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	visits, _ := s.Increment("visits", 1)
+	w.Write([]byte(fmt.Sprintf("%d visits", visits)))
+}
@@ -0,0 +1,33 @@
+package main
+
+//go:generate sessionsgen schema.yaml
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+func main() {
+	manager := sessions.New(sessions.Config{})
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		sess := NewUserSession(manager.Start(w, r))
+
+		lastSeen := sess.GetLastSeen()
+		sess.SetVisits(sess.GetVisits() + 1)
+		sess.SetLastSeen(time.Now())
+
+		fmt.Fprintf(w, "visits: %d, last seen: %s", sess.GetVisits(), lastSeen)
+	})
+
+	http.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		sess := NewUserSession(manager.Start(w, r))
+		sess.SetUserID("kataras")
+		sess.SetCart(append(sess.GetCart(), "go-sessions"))
+	})
+
+	http.ListenAndServe(":8080", nil)
+}
@@ -0,0 +1,101 @@
+// Code generated by sessionsgen. DO NOT EDIT.
+
+package main
+
+import (
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+// The session keys of the UserSession.
+const (
+	KeyUserID   = "user_id"
+	KeyVisits   = "visits"
+	KeyLastSeen = "last_seen"
+	KeyCart     = "cart"
+)
+
+// UserSession is the typed accessor of the session's values.
+type UserSession struct {
+	*sessions.Session
+}
+
+// NewUserSession returns the typed accessor of the "sess".
+func NewUserSession(sess *sessions.Session) UserSession {
+	return UserSession{Session: sess}
+}
+
+// GetUserID returns the value of the "user_id" session key.
+// The id of the logged in user.
+// It returns the zero value if the key is missing or its value has a different type.
+func (s UserSession) GetUserID() string {
+	return s.GetString(KeyUserID)
+}
+
+// SetUserID sets the value of the "user_id" session key.
+// The id of the logged in user.
+func (s UserSession) SetUserID(v string) {
+	s.Set(KeyUserID, v)
+}
+
+// DeleteUserID removes the "user_id" session key, it reports whether it was removed.
+func (s UserSession) DeleteUserID() bool {
+	return s.Delete(KeyUserID)
+}
+
+// GetVisits returns the value of the "visits" session key.
+// It returns the zero value if the key is missing or its value has a different type.
+func (s UserSession) GetVisits() int {
+	v, err := s.GetInt(KeyVisits)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// SetVisits sets the value of the "visits" session key.
+func (s UserSession) SetVisits(v int) {
+	s.Set(KeyVisits, v)
+}
+
+// DeleteVisits removes the "visits" session key, it reports whether it was removed.
+func (s UserSession) DeleteVisits() bool {
+	return s.Delete(KeyVisits)
+}
+
+// GetLastSeen returns the value of the "last_seen" session key.
+// The time of the previous request.
+// It returns the zero value if the key is missing or its value has a different type.
+func (s UserSession) GetLastSeen() time.Time {
+	v, _ := s.Get(KeyLastSeen).(time.Time)
+	return v
+}
+
+// SetLastSeen sets the value of the "last_seen" session key.
+// The time of the previous request.
+func (s UserSession) SetLastSeen(v time.Time) {
+	s.Set(KeyLastSeen, v)
+}
+
+// DeleteLastSeen removes the "last_seen" session key, it reports whether it was removed.
+func (s UserSession) DeleteLastSeen() bool {
+	return s.Delete(KeyLastSeen)
+}
+
+// GetCart returns the value of the "cart" session key.
+// It returns the zero value if the key is missing or its value has a different type.
+func (s UserSession) GetCart() []string {
+	v, _ := s.Get(KeyCart).([]string)
+	return v
+}
+
+// SetCart sets the value of the "cart" session key.
+func (s UserSession) SetCart(v []string) {
+	s.Set(KeyCart, v)
+}
+
+// DeleteCart removes the "cart" session key, it reports whether it was removed.
+func (s UserSession) DeleteCart() bool {
+	return s.Delete(KeyCart)
+}
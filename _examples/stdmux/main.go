@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/middleware/stdmux"
+)
+
+func main() {
+	app := http.NewServeMux()
+	manager := sessions.New(sessions.Config{Cookie: "mysessionid"})
+
+	app.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s := manager.Start(w, r)
+		s.Set("user", "kataras")
+		w.Write([]byte("You are logged in, navigate to the /account"))
+	})
+
+	app.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		manager.Destroy(w, r)
+		w.Write([]byte("You are logged out, the /account responds with 401 now"))
+	})
+
+	// the same middleware can be used by the chi and gorilla/mux routers,
+	// see the _examples/chi and _examples/gorilla-mux.
+	requireUser := stdmux.Options{Keys: []string{"user"}}
+	app.Handle("/account", stdmux.Require(manager, requireUser)(http.HandlerFunc(account)))
+
+	http.ListenAndServe(":8080", app)
+}
+
+func account(w http.ResponseWriter, r *http.Request) {
+	s := stdmux.Get(r)
+	w.Write([]byte(fmt.Sprintf("Hello %s", s.GetString("user"))))
+}
@@ -0,0 +1,144 @@
+package sessions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DefaultAnonymousIDCookieName the anonymous id cookie's name, see `Config#AnonymousIDCookie`.
+	DefaultAnonymousIDCookieName = "anonymous_id"
+	// DefaultAnonymousIDExpires the lifetime of an anonymous id, see `Config#AnonymousIDExpires`.
+	DefaultAnonymousIDExpires = 365 * 24 * time.Hour
+)
+
+// AnonymousID returns the anonymous id of the session's client, see `Config#AnonymousIDKey`.
+// It's stable across the session id regenerations and the new sessions of the same browser,
+// so the analytics can correlate the visits without the session id.
+// It returns empty if the anonymous ids are disabled.
+func (s *Session) AnonymousID() string {
+	s.mu.RLock()
+	id := s.anonymousID
+	s.mu.RUnlock()
+	return id
+}
+
+// signAnonymousID returns the cookie value of the "id", the id and its HMAC-SHA256 signature.
+func (s *Sessions) signAnonymousID(id string) string {
+	mac := hmac.New(sha256.New, s.config.AnonymousIDKey)
+	mac.Write([]byte(s.config.AnonymousIDCookie))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyAnonymousID returns the id of the anonymous id "cookieValue",
+// it returns empty if the signature is invalid or the id is revoked.
+func (s *Sessions) verifyAnonymousID(cookieValue string) string {
+	idx := strings.LastIndexByte(cookieValue, '.')
+	if idx <= 0 {
+		return ""
+	}
+
+	id := cookieValue[:idx]
+	if !hmac.Equal([]byte(s.signAnonymousID(id)), []byte(cookieValue)) {
+		return ""
+	}
+
+	if revoked := s.config.AnonymousIDRevoked; revoked != nil && revoked(id) {
+		return ""
+	}
+
+	return id
+}
+
+// anonymousIDCookie returns a new anonymous id cookie.
+func (s *Sessions) anonymousIDCookie(id string, secure bool) *http.Cookie {
+	expires := s.provider.now().Add(s.config.AnonymousIDExpires)
+	return &http.Cookie{
+		Name:     s.config.AnonymousIDCookie,
+		Value:    s.signAnonymousID(id),
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(s.config.AnonymousIDExpires.Seconds()),
+		HttpOnly: true,
+		Secure:   s.secureCookie(secure) || isSecurePrefixed(s.config.AnonymousIDCookie),
+		SameSite: s.config.CookieSameSite,
+	}
+}
+
+// identify sets the anonymous id of the request to the "sess",
+// a new one is issued if the request has no valid anonymous id cookie.
+func (s *Sessions) identify(w http.ResponseWriter, r *http.Request, sess *Session) {
+	if len(s.config.AnonymousIDKey) == 0 {
+		return
+	}
+
+	id := s.verifyAnonymousID(GetCookie(r, s.config.AnonymousIDCookie))
+	if id == "" {
+		id = randomToken()
+		s.addCookie(w, s.anonymousIDCookie(id, r.TLS != nil))
+	}
+
+	sess.mu.Lock()
+	sess.anonymousID = id
+	sess.mu.Unlock()
+}
+
+// identifyFasthttp sets the anonymous id of the request to the "sess", see `identify`.
+func (s *Sessions) identifyFasthttp(ctx *fasthttp.RequestCtx, sess *Session) {
+	if len(s.config.AnonymousIDKey) == 0 {
+		return
+	}
+
+	id := s.verifyAnonymousID(GetCookieFasthttp(ctx, s.config.AnonymousIDCookie))
+	if id == "" {
+		id = randomToken()
+		c := s.anonymousIDCookie(id, ctx.IsTLS())
+
+		cookie := fasthttp.AcquireCookie()
+		cookie.SetKey(c.Name)
+		cookie.SetValue(c.Value)
+		cookie.SetPath(c.Path)
+		cookie.SetExpire(c.Expires)
+		cookie.SetHTTPOnly(c.HttpOnly)
+		cookie.SetSecure(c.Secure)
+		cookie.SetSameSite(sameSiteFasthttp(c.SameSite))
+		cookie.SetPartitioned(s.config.CookiePartitioned)
+		AddCookieFasthttp(ctx, cookie)
+		fasthttp.ReleaseCookie(cookie)
+	}
+
+	sess.mu.Lock()
+	sess.anonymousID = id
+	sess.mu.Unlock()
+}
+
+// RevokeAnonymousID removes the anonymous id cookie of the request, see `Sessions#RevokeAnonymousID`.
+func RevokeAnonymousID(w http.ResponseWriter, r *http.Request) {
+	Default.RevokeAnonymousID(w, r)
+}
+
+// RevokeAnonymousID removes the anonymous id cookie of the request,
+// i.e when the user opts out of the analytics, the next `Start` issues a new, unrelated, id.
+// The copies of the cookie are still valid, use the `Config#AnonymousIDRevoked`
+// to reject the revoked ids server-side.
+func (s *Sessions) RevokeAnonymousID(w http.ResponseWriter, r *http.Request) {
+	RemoveCookie(w, r, s.config.AnonymousIDCookie)
+}
+
+// RevokeAnonymousIDFasthttp removes the anonymous id cookie of the request, see `Sessions#RevokeAnonymousID`.
+func RevokeAnonymousIDFasthttp(ctx *fasthttp.RequestCtx) {
+	Default.RevokeAnonymousIDFasthttp(ctx)
+}
+
+// RevokeAnonymousIDFasthttp removes the anonymous id cookie of the request, see `Sessions#RevokeAnonymousID`.
+func (s *Sessions) RevokeAnonymousIDFasthttp(ctx *fasthttp.RequestCtx) {
+	RemoveCookieFasthttp(ctx, s.config.AnonymousIDCookie)
+}
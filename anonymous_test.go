@@ -0,0 +1,60 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnonymousID(t *testing.T) {
+	revoked := make(map[string]bool)
+	manager := New(Config{
+		AnonymousIDKey:     []byte("01234567890123456789012345678901"),
+		AnonymousIDRevoked: func(id string) bool { return revoked[id] },
+	})
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	id := sess.AnonymousID()
+	anonymous := findCookie(w, DefaultAnonymousIDCookieName)
+	if id == "" || anonymous == nil {
+		t.Fatal("expected a new anonymous id and its cookie")
+	}
+
+	// a new session of the same browser, i.e after a logout, keeps the id.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(anonymous)
+	w = httptest.NewRecorder()
+	if got := manager.Start(w, r).AnonymousID(); got != id {
+		t.Fatalf("expected the anonymous id %q but got %q", id, got)
+	}
+
+	if findCookie(w, DefaultAnonymousIDCookieName) != nil {
+		t.Fatal("expected the valid anonymous id cookie to be kept")
+	}
+
+	// a forged id is replaced.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultAnonymousIDCookieName, Value: "forged." + anonymous.Value[len(id)+1:]})
+	if got := manager.Start(httptest.NewRecorder(), r).AnonymousID(); got == id || got == "forged" {
+		t.Fatalf("expected the forged anonymous id to be replaced but got %q", got)
+	}
+
+	// a revoked id is replaced.
+	revoked[id] = true
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(anonymous)
+	w = httptest.NewRecorder()
+	if got := manager.Start(w, r).AnonymousID(); got == id {
+		t.Fatal("expected the revoked anonymous id to be replaced")
+	}
+
+	if findCookie(w, DefaultAnonymousIDCookieName) == nil {
+		t.Fatal("expected a new anonymous id cookie")
+	}
+
+	// disabled by default.
+	if got := New(Config{}).Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)).AnonymousID(); got != "" {
+		t.Fatalf("expected no anonymous id but got %q", got)
+	}
+}
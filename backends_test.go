@@ -0,0 +1,64 @@
+package sessions_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessiondb/file"
+	"github.com/kataras/go-sessions/sessiondb/memory"
+	"github.com/kataras/go-sessions/sessiondb/tiered"
+	"github.com/kataras/go-sessions/sessiondb/writebehind"
+)
+
+// The tests of this file run the concurrency suite against the session databases,
+// like the race_test.go ones they are meant to run with the race detector:
+// go test -race -run Concurrent .
+
+func testConcurrentBackend(t *testing.T, db sessions.Database) {
+	manager := sessions.New(sessions.Config{Expires: time.Hour})
+	manager.UseDatabase(db)
+	sessions.ConcurrentSessions(t, manager)
+}
+
+func newFileDatabase(t *testing.T) *file.Database {
+	dir, err := ioutil.TempDir("", "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db, err := file.New(dir, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestConcurrentSessionsMemory(t *testing.T) {
+	testConcurrentBackend(t, memory.New())
+}
+
+func TestConcurrentSessionsFile(t *testing.T) {
+	testConcurrentBackend(t, newFileDatabase(t))
+}
+
+func TestConcurrentSessionsTiered(t *testing.T) {
+	db, err := tiered.New(memory.New(), newFileDatabase(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testConcurrentBackend(t, db)
+}
+
+func TestConcurrentSessionsWriteBehind(t *testing.T) {
+	db, err := writebehind.New(memory.New(), writebehind.Options{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testConcurrentBackend(t, db)
+}
@@ -0,0 +1,122 @@
+package sessions
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// benchStore returns a store of "n" entries, as the ones of a typical session.
+func benchStore(n int) Store {
+	store := make(Store, 0, n)
+	for i := 0; i < n; i++ {
+		store.Set("key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	return store
+}
+
+func BenchmarkStoreSet(b *testing.B) {
+	b.Run("insert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var store Store
+			store.Set("name", "kataras")
+			store.Set("id", 1)
+			store.Set("admin", true)
+		}
+	})
+
+	b.Run("update", func(b *testing.B) {
+		store := benchStore(16)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			store.Set("key8", i)
+		}
+	})
+
+	b.Run("immutable", func(b *testing.B) {
+		var store Store
+		tags := []string{"fast", "simple"}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			store.SetImmutable("tags", tags)
+		}
+	})
+}
+
+func BenchmarkStoreGet(b *testing.B) {
+	store := benchStore(16)
+	store.SetImmutable("name", "kataras")
+	store.SetImmutable("id", 1)
+	store.SetImmutable("tags", []string{"fast", "simple"})
+
+	for _, key := range []string{"key8", "name", "id", "tags"} {
+		key := key
+		b.Run(key, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				store.Get(key)
+			}
+		})
+	}
+}
+
+func BenchmarkStoreSerialize(b *testing.B) {
+	store := benchStore(16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.SerializeE(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestStoreAllocs protects the allocations of the hot paths,
+// a change which exceeds a budget should be justified and the budget updated.
+func TestStoreAllocs(t *testing.T) {
+	store := benchStore(16)
+	store.SetImmutable("name", "kataras")
+	tags := []string{"fast", "simple"}
+	store.SetImmutable("tags", tags)
+	store.SetImmutable("time", time.Now())
+	store.SetImmutable("point", struct{ X, Y float64 }{1, 2})
+
+	tests := []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		{"Get", 0, func() { store.Get("key8") }},
+		{"Set update", 0, func() { store.Set("key8", "value") }},
+		{"Get immutable string", 0, func() { store.Get("name") }},
+		{"Get immutable time", 0, func() { store.Get("time") }},
+		{"Get immutable struct", 1, func() { store.Get("point") }},
+		{"Get immutable slice", 2, func() { store.Get("tags") }},
+		{"SetImmutable slice", 3, func() { store.SetImmutable("tags", tags) }},
+	}
+
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.fn); allocs > tt.budget {
+			t.Errorf("%s: expected at most %v allocations but got %v", tt.name, tt.budget, allocs)
+		}
+	}
+}
+
+func BenchmarkProviderRead(b *testing.B) {
+	manager := New(Config{})
+	for i := 0; i < 1024; i++ {
+		manager.provider.Read(strconv.Itoa(i), 0)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			manager.provider.Read(strconv.Itoa(i%1024), 0)
+			i++
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package sessions
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+// ErrBytesTooLarge is returned by the `Session#SetBytes` when the value exceeds
+// the `Config#BytesLimit` or the session's binary values exceed the `Config#SessionBytesLimit`.
+var ErrBytesTooLarge = errors.New("sessions: the binary value exceeds the byte budget")
+
+// toBytes returns the binary value of the "value", the json session databases decode a []byte as a base64 string.
+func toBytes(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		b, err := base64.StdEncoding.DecodeString(v)
+		return b, err == nil
+	default:
+		return nil, false
+	}
+}
+
+// GetBytes returns a copy of the binary value of the "key", see `Session#SetBytes`.
+// If not found, or the value is not binary, it returns nil.
+func (r *Store) GetBytes(key string) []byte {
+	b, ok := toBytes(r.Get(key))
+	if !ok || b == nil {
+		return nil
+	}
+
+	return append([]byte{}, b...)
+}
+
+// bytesLen returns the total length of the binary values of the store, except the "key"'s one.
+func (r *Store) bytesLen(except string) int {
+	n := 0
+	for _, kv := range *r {
+		if b, ok := kv.ValueRaw.([]byte); ok && kv.Key != except {
+			n += len(b)
+		}
+	}
+	return n
+}
+
+// GetBytes same as Get but returns a copy of the binary value of the "key", see `SetBytes`.
+// If not found, or the value is not binary, it returns nil.
+func (s *Session) GetBytes(key string) []byte {
+	key = s.key(key)
+	s.mu.RLock()
+	b := s.values.GetBytes(key)
+	s.mu.RUnlock()
+
+	return b
+}
+
+// SetBytes saves a copy of the binary value "b" to the "key", i.e an avatar or a token,
+// so the caller can reuse its buffer.
+// It returns the `ErrBytesTooLarge` if the value exceeds the `Config#BytesLimit`
+// or the binary values of the session would exceed the `Config#SessionBytesLimit`,
+// and the `ErrEntryImmutable` if the entry is immutable, the session is not changed.
+func (s *Session) SetBytes(key string, b []byte) error {
+	var entryLimit, sessionLimit int
+	if cfg := s.provider.config; cfg != nil {
+		entryLimit, sessionLimit = cfg.BytesLimit, cfg.SessionBytesLimit
+	}
+
+	if entryLimit > 0 && len(b) > entryLimit {
+		return ErrBytesTooLarge
+	}
+
+	value := append([]byte{}, b...)
+	return s.update(key, false, func(key string) (interface{}, error) {
+		if s.values.isImmutable(key) {
+			return nil, ErrEntryImmutable
+		}
+
+		if sessionLimit > 0 && s.values.bytesLen(key)+len(value) > sessionLimit {
+			return nil, ErrBytesTooLarge
+		}
+		return value, nil
+	})
+}
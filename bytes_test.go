@@ -0,0 +1,56 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionBytes(t *testing.T) {
+	manager := New(Config{BytesLimit: 8, SessionBytesLimit: 12})
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	avatar := []byte("avatar")
+	if err := sess.SetBytes("avatar", avatar); err != nil {
+		t.Fatal(err)
+	}
+
+	avatar[0] = 'A'
+	got := sess.GetBytes("avatar")
+	if !bytes.Equal(got, []byte("avatar")) {
+		t.Fatalf("expected the stored bytes to be a copy but got %q", got)
+	}
+	got[0] = 'A'
+	if !bytes.Equal(sess.GetBytes("avatar"), []byte("avatar")) {
+		t.Fatalf("expected the returned bytes to be a copy")
+	}
+
+	if err := sess.SetBytes("token", []byte("123456789")); err != ErrBytesTooLarge {
+		t.Fatalf("expected the entry's limit to be exceeded but got %v", err)
+	}
+	if err := sess.SetBytes("token", []byte("1234567")); err != ErrBytesTooLarge {
+		t.Fatalf("expected the session's limit to be exceeded but got %v", err)
+	}
+	if sess.Get("token") != nil {
+		t.Fatalf("expected the rejected value to not be saved")
+	}
+
+	// the replaced value doesn't count.
+	if err := sess.SetBytes("avatar", []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.SetBytes("token", []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the json session databases decode the binary values as base64 strings.
+	sess.Set("decoded", base64.StdEncoding.EncodeToString([]byte("blob")))
+	if got := sess.GetBytes("decoded"); string(got) != "blob" {
+		t.Fatalf("expected the base64 string to be decoded but got %q", got)
+	}
+	if sess.GetBytes("missing") != nil {
+		t.Fatalf("expected nil for a missing key")
+	}
+}
@@ -0,0 +1,65 @@
+package sessions
+
+import (
+	"errors"
+	"reflect"
+)
+
+// errNotSwapped is returned by the `Session#SetIfAbsent` and `Session#CompareAndSwap` computes
+// when the value is not set, nothing is saved.
+var errNotSwapped = errors.New("not swapped")
+
+// SetIfAbsent saves the "value" to the "key" only if the key is missing,
+// and reports whether it was saved, i.e to issue a one-time token once.
+func (r *Store) SetIfAbsent(key string, value interface{}) bool {
+	if r.Exists(key) {
+		return false
+	}
+
+	r.Set(key, value)
+	return true
+}
+
+// CompareAndSwap saves the "new" value to the "key" only if its current value is equal,
+// by reflect.DeepEqual, to the "old" one, and reports whether it was saved.
+// A missing key is equal to a nil "old" value. An immutable entry is not swapped.
+func (r *Store) CompareAndSwap(key string, old, new interface{}) bool {
+	entry, _ := r.GetEntry(key)
+	if entry.immutable || !reflect.DeepEqual(entry.ValueRaw, old) {
+		return false
+	}
+
+	r.Set(key, new)
+	return true
+}
+
+// SetIfAbsent saves the "value" to the "key" only if the key is missing,
+// and reports whether it was saved, see `Store.SetIfAbsent`.
+// It's atomic for the requests of the session, use the `Config#OptimisticConcurrency`
+// or the `Lock` when the session is shared between app instances.
+func (s *Session) SetIfAbsent(key string, value interface{}) bool {
+	err := s.update(key, false, func(key string) (interface{}, error) {
+		if s.values.Exists(key) {
+			return nil, errNotSwapped
+		}
+		return value, nil
+	})
+
+	return err == nil
+}
+
+// CompareAndSwap saves the "new" value to the "key" only if its current value is equal to the "old" one,
+// and reports whether it was saved, see `Store.CompareAndSwap`, i.e to consume a one-time token:
+// sess.CompareAndSwap("token", token, nil).
+// It's atomic for the requests of the session, see `SetIfAbsent`.
+func (s *Session) CompareAndSwap(key string, old, new interface{}) bool {
+	err := s.update(key, false, func(key string) (interface{}, error) {
+		entry, _ := s.values.GetEntry(key)
+		if entry.immutable || !reflect.DeepEqual(entry.ValueRaw, old) {
+			return nil, errNotSwapped
+		}
+		return new, nil
+	})
+
+	return err == nil
+}
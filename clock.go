@@ -0,0 +1,141 @@
+package sessions
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of the current time and of the expiration timers of the sessions, see `Config#Clock`.
+// The tests can use a `ManualClock` to expire the sessions without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer calls the "fn" in its own goroutine, or synchronously, when the "d" has elapsed.
+	NewTimer(d time.Duration, fn func()) Timer
+}
+
+// Timer is a timer of a `Clock`, the *time.Timer implements it.
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// ClockSetter is implemented by the session databases which expire their sessions by a clock,
+// the `UseDatabase` sets the `Config#Clock` to them, if it's not nil.
+type ClockSetter interface {
+	SetClock(clock Clock)
+}
+
+// SystemClock is the default `Clock`, it uses the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration, fn func()) Timer {
+	return time.AfterFunc(d, fn)
+}
+
+// ManualClock is a `Clock` which moves only by its `Advance` or its `Set`,
+// a frozen clock for the tests, the due timers are fired synchronously, in the order of their deadlines.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+var _ Clock = (*ManualClock)(nil)
+
+// NewManualClock returns a new `ManualClock` which starts at the "now".
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	now := c.now
+	c.mu.Unlock()
+	return now
+}
+
+// NewTimer returns a timer which calls the "fn" when the clock is advanced by the "d".
+func (c *ManualClock) NewTimer(d time.Duration, fn func()) Timer {
+	t := &manualTimer{clock: c, fn: fn}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by the "d" and fires the due timers,
+// it returns after their functions return.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	now := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(now)
+}
+
+// Set moves the clock to the "now" and fires the due timers,
+// it returns after their functions return.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	var due []*manualTimer
+	n := 0
+	for _, t := range c.timers {
+		if t.deadline.After(now) {
+			c.timers[n] = t
+			n++
+			continue
+		}
+		due = append(due, t)
+	}
+	for i := n; i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = c.timers[:n]
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].deadline.Before(due[j].deadline) })
+	for _, t := range due {
+		t.fn()
+	}
+}
+
+// remove removes the "t" from the active timers, it's called with the clock locked.
+func (c *ManualClock) remove(t *manualTimer) bool {
+	for i, active := range c.timers {
+		if active == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	fn       func()
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	active := t.clock.remove(t)
+	t.clock.mu.Unlock()
+	return active
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	active := c.remove(t)
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+	return active
+}
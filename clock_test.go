@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClockExpiration(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{Expires: time.Hour, IdleTimeout: 10 * time.Minute, Clock: clock})
+
+	var expired []string
+	manager.OnExpire(func(sid string) { expired = append(expired, sid) })
+
+	sess := manager.provider.Init("sid", time.Hour)
+	if expected := clock.Now().Add(10 * time.Minute); !sess.lifetime.Time.Equal(expected) {
+		t.Fatalf("expected the session to expire at %s but got %s", expected, sess.lifetime.Time)
+	}
+
+	// each access extends the idle timeout.
+	for i := 0; i < 3; i++ {
+		clock.Advance(9 * time.Minute)
+		manager.provider.Read("sid", time.Hour)
+	}
+	if len(expired) != 0 {
+		t.Fatalf("expected the active session to not expire but got %v", expired)
+	}
+
+	clock.Advance(11 * time.Minute)
+	if len(expired) != 1 || expired[0] != "sid" {
+		t.Fatalf("expected the idle session to expire but got %v", expired)
+	}
+	if active := manager.Stats().Active; active != 0 {
+		t.Fatalf("expected no active sessions but got %d", active)
+	}
+}
+
+func TestManualClockTimers(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+
+	var fired []int
+	first := clock.NewTimer(2*time.Second, func() { fired = append(fired, 1) })
+	clock.NewTimer(time.Second, func() { fired = append(fired, 2) })
+	stopped := clock.NewTimer(time.Second, func() { fired = append(fired, 3) })
+
+	if !stopped.Stop() {
+		t.Fatalf("expected the active timer to be stopped")
+	}
+
+	clock.Advance(3 * time.Second)
+	if len(fired) != 2 || fired[0] != 2 || fired[1] != 1 {
+		t.Fatalf("expected the due timers to fire in order but got %v", fired)
+	}
+
+	if first.Reset(time.Second) {
+		t.Fatalf("expected the fired timer to not be active")
+	}
+	clock.Advance(time.Second)
+	if len(fired) != 3 {
+		t.Fatalf("expected the reset timer to fire again but got %v", fired)
+	}
+}
@@ -0,0 +1,165 @@
+// Command sessionsgen generates typed accessors of the session's values from a declarative schema,
+// so the session keys are compile-time constants and the values are read and written with their Go types.
+//
+// Usage:
+//
+//	//go:generate sessionsgen schema.yaml
+//
+// The accessors are written to the "sessions_gen.go" next to the schema, use the -o flag to change it.
+// See the `Schema` for the schema's format.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	output := flag.String("o", "", "the output file, defaults to the sessions_gen.go next to the schema")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: sessionsgen [-o output.go] schema.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *output); err != nil {
+		fmt.Fprintf(os.Stderr, "sessionsgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, output string) error {
+	f, err := os.Open(schemaPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	schema, err := parseSchema(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemaPath, err)
+	}
+
+	src, err := generate(schema)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = filepath.Join(filepath.Dir(schemaPath), "sessions_gen.go")
+	}
+
+	return ioutil.WriteFile(output, src, 0644)
+}
+
+// getters are the typed getters of the `sessions.Session`,
+// they convert the values which are decoded by the session databases, i.e the json.Number.
+var getters = map[string]string{
+	"int":     "GetInt",
+	"int64":   "GetInt64",
+	"float32": "GetFloat32",
+	"float64": "GetFloat64",
+	"bool":    "GetBoolean",
+}
+
+var funcs = template.FuncMap{
+	"getter": func(typ string) string { return getters[typ] },
+	"zero": func(typ string) string {
+		if typ == "bool" {
+			return "false"
+		}
+		return "0"
+	},
+	"doc": func(field Field) string {
+		if field.Doc == "" {
+			return ""
+		}
+		doc := strings.TrimSuffix(field.Doc, ".") + "."
+		return "\n// " + strings.ToUpper(doc[:1]) + doc[1:]
+	},
+}
+
+var tmpl = template.Must(template.New("sessions").Funcs(funcs).Parse(`// Code generated by sessionsgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	"github.com/kataras/go-sessions"
+)
+
+// The session keys of the {{.Type}}.
+const (
+{{- range .Fields}}
+	Key{{.Name}} = "{{.Key}}"
+{{- end}}
+)
+
+// {{.Type}} is the typed accessor of the session's values.
+type {{.Type}} struct {
+	*sessions.Session
+}
+
+// New{{.Type}} returns the typed accessor of the "sess".
+func New{{.Type}}(sess *sessions.Session) {{.Type}} {
+	return {{.Type}}{Session: sess}
+}
+{{- $type := .Type}}
+{{range .Fields}}
+// Get{{.Name}} returns the value of the "{{.Key}}" session key.{{doc .}}
+// It returns the zero value if the key is missing or its value has a different type.
+func (s {{$type}}) Get{{.Name}}() {{.Type}} {
+{{- if eq .Type "string"}}
+	return s.GetString(Key{{.Name}})
+{{- else if getter .Type}}
+	v, err := s.{{getter .Type}}(Key{{.Name}})
+	if err != nil {
+		return {{zero .Type}}
+	}
+	return v
+{{- else}}
+	v, _ := s.Get(Key{{.Name}}).({{.Type}})
+	return v
+{{- end}}
+}
+
+// Set{{.Name}} sets the value of the "{{.Key}}" session key.{{doc .}}
+func (s {{$type}}) Set{{.Name}}(v {{.Type}}) {
+	s.Set(Key{{.Name}}, v)
+}
+
+// Delete{{.Name}} removes the "{{.Key}}" session key, it reports whether it was removed.
+func (s {{$type}}) Delete{{.Name}}() bool {
+	return s.Delete(Key{{.Name}})
+}
+{{end}}`))
+
+// generate returns the formatted source of the accessors of the "schema".
+func generate(schema *Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, schema); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code, check the types of the schema: %v", err)
+	}
+
+	return src, nil
+}
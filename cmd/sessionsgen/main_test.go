@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test -run Golden -update
+var update = flag.Bool("update", false, "update the golden files of the testdata")
+
+func TestGenerateGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sessionsgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "sessions_gen.go")
+	if err = run(filepath.Join("testdata", "schema.yaml"), output); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "sessions_gen.golden")
+	if *update {
+		if err = ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("the generated accessors differ from the %s, run the tests with -update if the change is expected:\n%s", golden, got)
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{"type: Session\nfields:\n  Name: string\n", "the package is missing"},
+		{"package: app\n", "the fields are missing"},
+		{"package: app\ntype: 1Session\nfields:\n  Name: string\n", `invalid type name "1Session"`},
+		{"package: app\nfields:\n  Name:\n    size: 10\n", `field Name: unknown key "size"`},
+		{"package: app\npackage: other\n", `line 2: duplicate key "package"`},
+		{"package: app\n\tfields:\n", "line 2: tabs are not allowed for indentation"},
+		{"package: app\nowner: kataras\n", `unknown schema key "owner"`},
+	}
+
+	for i, tt := range tests {
+		_, err := parseSchema(strings.NewReader(tt.schema))
+		if err == nil || err.Error() != tt.err {
+			t.Fatalf("[%d] expected the error %q but got %v", i, tt.err, err)
+		}
+	}
+}
+
+func TestGenerateInvalidType(t *testing.T) {
+	schema := &Schema{Package: "app", Type: "Session", Fields: []Field{{Name: "Name", Key: "name", Type: "map[string"}}}
+	if _, err := generate(schema); err == nil || !strings.HasPrefix(err.Error(), "invalid generated code") {
+		t.Fatalf("expected an invalid generated code error but got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Schema is the declarative schema of the session's values.
+//
+// Example schema.yaml:
+//
+//	package: app
+//	type: UserSession
+//	imports:
+//	  - time
+//	fields:
+//	  UserID:
+//	    key: user_id
+//	    type: string
+//	    doc: the id of the logged in user
+//	  LastSeen:
+//	    type: time.Time
+type Schema struct {
+	// Package is the package name of the generated file.
+	Package string
+	// Type is the name of the generated session wrapper, defaults to "Session".
+	Type string
+	// Imports are the import paths of the fields' types.
+	Imports []string
+	// Fields are the session's values, in the order of the schema.
+	Fields []Field
+}
+
+// Field is a session value of the schema.
+type Field struct {
+	// Name is the Go name of the accessors, i.e "UserID" for GetUserID and SetUserID.
+	Name string
+	// Key is the session key, defaults to the snake case of the name.
+	Key string
+	// Type is the Go type of the value, defaults to "string".
+	Type string
+	// Doc is the documentation of the accessors.
+	Doc string
+}
+
+// node is a mapping or a scalar of the yaml subset which the schemas are written in.
+type node struct {
+	value string
+	keys  []string
+	items map[string]*node
+	list  []string
+}
+
+type line struct {
+	indent int
+	text   string
+	number int
+}
+
+// parseSchema parses the schema of the "r", written in a yaml subset:
+// nested mappings, lists of scalars and scalars, quoted or not, and "#" comments.
+func parseSchema(r io.Reader) (*Schema, error) {
+	var lines []line
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", number)
+		}
+
+		lines = append(lines, line{indent: len(raw) - len(text), text: text, number: number})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	root := &node{items: make(map[string]*node)}
+	if rest, err := parseMapping(root, lines, 0); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("line %d: unexpected indentation", rest[0].number)
+	}
+
+	return newSchema(root)
+}
+
+// parseMapping parses the lines of the "indent" to the "n" and returns the rest of the lines.
+func parseMapping(n *node, lines []line, indent int) ([]line, error) {
+	for len(lines) > 0 {
+		l := lines[0]
+		if l.indent < indent {
+			return lines, nil
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.number)
+		}
+
+		if strings.HasPrefix(l.text, "- ") {
+			return nil, fmt.Errorf("line %d: unexpected list item", l.number)
+		}
+
+		idx := strings.IndexByte(l.text, ':')
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected a \"key: value\" pair", l.number)
+		}
+
+		key := strings.TrimSpace(l.text[:idx])
+		if _, exists := n.items[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.number, key)
+		}
+
+		child := &node{value: unquote(l.text[idx+1:]), items: make(map[string]*node)}
+		n.keys = append(n.keys, key)
+		n.items[key] = child
+		lines = lines[1:]
+
+		if child.value != "" || len(lines) == 0 || lines[0].indent <= indent {
+			continue
+		}
+
+		if nested := lines[0]; strings.HasPrefix(nested.text, "- ") {
+			for len(lines) > 0 && lines[0].indent == nested.indent && strings.HasPrefix(lines[0].text, "- ") {
+				child.list = append(child.list, unquote(lines[0].text[2:]))
+				lines = lines[1:]
+			}
+			continue
+		}
+
+		var err error
+		if lines, err = parseMapping(child, lines, lines[0].indent); err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+// unquote trims the spaces, the trailing comment and the quotes of a scalar.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	if idx := strings.Index(s, " #"); idx >= 0 {
+		s = strings.TrimSpace(s[:idx])
+	}
+	return s
+}
+
+func newSchema(root *node) (*Schema, error) {
+	schema := &Schema{Type: "Session"}
+
+	for _, key := range root.keys {
+		n := root.items[key]
+		switch key {
+		case "package":
+			schema.Package = n.value
+		case "type":
+			if n.value != "" {
+				schema.Type = n.value
+			}
+		case "imports":
+			schema.Imports = n.list
+		case "fields":
+			for _, name := range n.keys {
+				field, err := newField(name, n.items[name])
+				if err != nil {
+					return nil, err
+				}
+				schema.Fields = append(schema.Fields, field)
+			}
+		default:
+			return nil, fmt.Errorf("unknown schema key %q", key)
+		}
+	}
+
+	if schema.Package == "" {
+		return nil, fmt.Errorf("the package is missing")
+	}
+
+	if !isIdentifier(schema.Type) {
+		return nil, fmt.Errorf("invalid type name %q", schema.Type)
+	}
+
+	if len(schema.Fields) == 0 {
+		return nil, fmt.Errorf("the fields are missing")
+	}
+
+	return schema, nil
+}
+
+func newField(name string, n *node) (Field, error) {
+	if !isIdentifier(name) {
+		return Field{}, fmt.Errorf("invalid field name %q", name)
+	}
+
+	field := Field{Name: name, Key: snakeCase(name), Type: "string"}
+	if n.value != "" {
+		// the short form, "Name: type".
+		field.Type = n.value
+		return field, nil
+	}
+
+	for _, key := range n.keys {
+		value := n.items[key].value
+		switch key {
+		case "key":
+			field.Key = value
+		case "type":
+			field.Type = value
+		case "doc":
+			field.Doc = value
+		default:
+			return Field{}, fmt.Errorf("field %s: unknown key %q", name, key)
+		}
+	}
+
+	if field.Key == "" || field.Type == "" {
+		return Field{}, fmt.Errorf("field %s: empty key or type", name)
+	}
+
+	return field, nil
+}
+
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// snakeCase returns the snake case of the "name", i.e "user_id" of the "UserID".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
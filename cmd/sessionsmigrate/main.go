@@ -0,0 +1,171 @@
+// Command sessionsmigrate copies the stored sessions from one session database to another,
+// i.e to switch from a boltdb to a redis without logging the users out, see the `sessions.Migrate`.
+//
+// Usage:
+//
+//	sessionsmigrate -from boltdb:./sessions.db -to redis://:password@127.0.0.1:6379/0?prefix=myapp
+//
+// The databases are written as kind:location, the kinds are the file, boltdb, badger, leveldb and redis.
+// The bucket of a boltdb defaults to "sessions", use the boltdb:path#bucket to change it.
+// Stop the app instances which write to the source before the migration, or re-run it with the -skip-existing.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessiondb/badger"
+	"github.com/kataras/go-sessions/sessiondb/boltdb"
+	"github.com/kataras/go-sessions/sessiondb/file"
+	"github.com/kataras/go-sessions/sessiondb/leveldb"
+	"github.com/kataras/go-sessions/sessiondb/redis"
+	"github.com/kataras/go-sessions/sessiondb/redis/service"
+)
+
+// defaultBucket is the bucket of the boltdb databases without a #bucket.
+const defaultBucket = "sessions"
+
+func main() {
+	from := flag.String("from", "", "the source database, i.e boltdb:./sessions.db")
+	to := flag.String("to", "", "the target database, i.e redis://127.0.0.1:6379")
+	skipExisting := flag.Bool("skip-existing", false, "keep the sessions which exist on the target")
+	deleteMigrated := flag.Bool("delete", false, "delete the migrated sessions from the source")
+	timeout := flag.Duration("timeout", 0, "stop the migration after the timeout, 0 means no timeout")
+	quiet := flag.Bool("q", false, "don't print the progress")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: sessionsmigrate -from kind:location -to kind:location [flags]")
+		fmt.Fprintln(os.Stderr, "kinds: file:dir, boltdb:path[#bucket], badger:dir, leveldb:dir, redis://[:password@]addr[/database][?prefix=prefix]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *from == "" || *to == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, *timeout)
+		defer cancelTimeout()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	opts := sessions.MigrateOptions{SkipExisting: *skipExisting, Delete: *deleteMigrated}
+	if !*quiet {
+		opts.Progress = printProgress()
+	}
+
+	if err := run(ctx, *from, *to, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "sessionsmigrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, fromLocation, toLocation string, opts sessions.MigrateOptions) error {
+	from, err := open(fromLocation)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fromLocation, err)
+	}
+	defer sessions.CloseDatabase(context.Background(), from)
+
+	to, err := open(toLocation)
+	if err != nil {
+		return fmt.Errorf("%s: %v", toLocation, err)
+	}
+
+	progress, err := sessions.Migrate(ctx, from, to, opts)
+	// the target is closed before the report, its asynchronous writes are done.
+	if closeErr := sessions.CloseDatabase(context.Background(), to); closeErr != nil && err == nil {
+		err = closeErr
+	}
+
+	fmt.Fprintf(os.Stderr, "\rmigrated %d sessions, skipped %d, failed %d\n", progress.Migrated, progress.Skipped, progress.Failed)
+	if err == nil && progress.Failed > 0 {
+		err = fmt.Errorf("%d sessions failed to be written to the target, they are kept on the source", progress.Failed)
+	}
+	return err
+}
+
+// printProgress returns a `sessions.MigrateOptions#Progress` which prints the progress, at most every 100ms.
+func printProgress() func(p sessions.MigrateProgress) {
+	var last time.Time
+	return func(p sessions.MigrateProgress) {
+		if now := time.Now(); now.Sub(last) >= 100*time.Millisecond {
+			last = now
+			fmt.Fprintf(os.Stderr, "\rmigrated %d sessions, skipped %d, failed %d", p.Migrated, p.Skipped, p.Failed)
+		}
+	}
+}
+
+// open opens the session database of the "location", see the command's documentation.
+func open(location string) (sessions.Database, error) {
+	if strings.HasPrefix(location, "redis://") {
+		return openRedis(location)
+	}
+
+	kind, path := location, ""
+	if i := strings.IndexByte(location, ':'); i > 0 {
+		kind, path = location[:i], location[i+1:]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("missing the location of the %s database", kind)
+	}
+
+	switch kind {
+	case "file":
+		return file.New(path, 0)
+	case "boltdb":
+		bucket := defaultBucket
+		if i := strings.LastIndexByte(path, '#'); i > 0 {
+			path, bucket = path[:i], path[i+1:]
+		}
+		return boltdb.New(path, 0, bucket)
+	case "badger":
+		return badger.New(path)
+	case "leveldb":
+		return leveldb.New(path)
+	default:
+		return nil, fmt.Errorf("unknown database kind %q", kind)
+	}
+}
+
+func openRedis(location string) (sessions.Database, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := service.DefaultConfig()
+	if u.Host != "" {
+		cfg.Addr = u.Host
+	}
+	if password, ok := u.User.Password(); ok {
+		cfg.Password = password
+	}
+	cfg.Database = strings.Trim(u.Path, "/")
+	cfg.Prefix = u.Query().Get("prefix")
+
+	db := redis.New(cfg)
+	if err := db.Ping(context.Background()); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,126 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// idFormatSamples is the number of session ids which are generated
+// to detect the format of an id generator.
+const idFormatSamples = 32
+
+// The character classes of a session id format.
+const (
+	idDigit = 1 << iota
+	idLower
+	idUpper
+	idDash
+	idUnderscore
+	idOther
+)
+
+// idFormat returns the length, -1 if it's variable, and the character classes of the ids of the "generate".
+func idFormat(generate func(context.Context) string) (length int, classes int) {
+	for i := 0; i < idFormatSamples; i++ {
+		id := generate(context.Background())
+		if i == 0 {
+			length = len(id)
+		} else if length != len(id) {
+			length = -1
+		}
+
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+				classes |= idDigit
+			case c >= 'a' && c <= 'z':
+				classes |= idLower
+			case c >= 'A' && c <= 'Z':
+				classes |= idUpper
+			case c == '-':
+				classes |= idDash
+			case c == '_':
+				classes |= idUnderscore
+			default:
+				classes |= idOther
+			}
+		}
+	}
+
+	return
+}
+
+// checkCookieCodec encodes a session id with the "from" configuration
+// and decodes it with the "to" one.
+func checkCookieCodec(from, to Config) error {
+	sid := from.IDGenerator(context.Background())
+	cookieValue, err := from.Encode(from.Cookie, sid)
+	if err != nil {
+		return fmt.Errorf("sessions: incompatible cookie codec: unable to encode: %v", err)
+	}
+
+	var decoded *string
+	if err = to.Decode(to.Cookie, cookieValue, &decoded); err != nil {
+		if err == ErrInvalidSignature || err == ErrDecryption {
+			return fmt.Errorf("sessions: incompatible cookie keys: %v", err)
+		}
+		return fmt.Errorf("sessions: incompatible cookie codec: %v", err)
+	}
+
+	if decoded == nil || *decoded != sid {
+		return errors.New("sessions: incompatible cookie codec: the decoded session id does not match")
+	}
+
+	return nil
+}
+
+// CompatibleWith reports whether the "cfg" of another service can share
+// the sessions of the `Default` manager, see `Sessions#CompatibleWith`.
+func CompatibleWith(cfg Config) error {
+	return Default.CompatibleWith(cfg)
+}
+
+// CompatibleWith reports whether the "cfg" of another service, i.e a sibling app
+// which mounts a manager to the same session databases, can share the sessions of this manager.
+// It should be called at startup, it returns a non-nil error which describes the first mismatch of:
+// the cookie name, the `Config#Shared` mode, the cookie's `Config#Encode` and `Config#Decode`,
+// including their keys, and the format of the session ids.
+//
+// The rest of the invariants can't be checked from the configuration, the services should:
+// register the same session databases with the same `Transcoder` (and keys, if it's an `EncryptionTranscoder`),
+// register the same custom types with the `RegisterTypes`
+// and agree on the session keys and the types of their values.
+func (s *Sessions) CompatibleWith(cfg Config) error {
+	own, other := s.config, cfg.Validate()
+
+	if own.Cookie != other.Cookie {
+		return fmt.Errorf("sessions: incompatible cookie name: %q and %q", own.Cookie, other.Cookie)
+	}
+
+	if own.Shared != other.Shared {
+		return errors.New("sessions: incompatible shared mode: both services should set the Shared field")
+	}
+
+	if (own.Encode == nil) != (other.Encode == nil) || (own.Decode == nil) != (other.Decode == nil) {
+		return errors.New("sessions: incompatible cookie codec: only one of the services encodes the cookie")
+	}
+
+	if own.Encode != nil && own.Decode != nil {
+		if err := checkCookieCodec(own, other); err != nil {
+			return err
+		}
+
+		if err := checkCookieCodec(other, own); err != nil {
+			return err
+		}
+	}
+
+	ownLength, ownClasses := idFormat(own.IDGenerator)
+	otherLength, otherClasses := idFormat(other.IDGenerator)
+	if ownLength != otherLength || ownClasses != otherClasses {
+		return errors.New("sessions: incompatible session id format: the id generators produce different ids")
+	}
+
+	return nil
+}
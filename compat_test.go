@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompatibleWith(t *testing.T) {
+	hashKey := []byte("01234567890123456789012345678901")
+	signer, _ := NewCookieSigner([][]byte{hashKey}, nil)
+	manager := New(Config{Cookie: "shared", Encode: signer.Encode, Decode: signer.Decode, Shared: true})
+
+	sibling, _ := NewCookieSigner([][]byte{hashKey}, nil)
+	if err := manager.CompatibleWith(Config{Cookie: "shared", Encode: sibling.Encode, Decode: sibling.Decode, Shared: true}); err != nil {
+		t.Fatalf("expected the configurations to be compatible but got: %v", err)
+	}
+
+	other, _ := NewCookieSigner([][]byte{[]byte("another hash key of the sibling app")}, nil)
+	tests := []struct {
+		cfg      Config
+		mismatch string
+	}{
+		{Config{Cookie: "other", Encode: sibling.Encode, Decode: sibling.Decode, Shared: true}, "cookie name"},
+		{Config{Cookie: "shared", Encode: sibling.Encode, Decode: sibling.Decode}, "shared mode"},
+		{Config{Cookie: "shared", Shared: true}, "cookie codec"},
+		{Config{Cookie: "shared", Encode: other.Encode, Decode: other.Decode, Shared: true}, "cookie keys"},
+		{Config{Cookie: "shared", Encode: sibling.Encode, Decode: sibling.Decode, Shared: true, IDGenerator: UUIDGenerator}, "session id format"},
+	}
+
+	for i, tt := range tests {
+		err := manager.CompatibleWith(tt.cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.mismatch) {
+			t.Fatalf("[%d] expected an incompatible %s error but got: %v", i, tt.mismatch, err)
+		}
+	}
+}
+
+func TestShared(t *testing.T) {
+	db := newConcurrentDatabase()
+
+	first := New(Config{Expires: time.Hour, Shared: true})
+	first.UseDatabase(db)
+	second := New(Config{Expires: time.Hour, Shared: true})
+	second.UseDatabase(db)
+
+	first.provider.Read("sid", time.Hour).Set("name", "go-sessions")
+	sess := second.provider.Read("sid", time.Hour)
+	if expected, got := "go-sessions", sess.GetString("name"); got != expected {
+		t.Fatalf("expected the value of the first service to be loaded but got %q", got)
+	}
+
+	first.provider.Read("sid", time.Hour).Set("name", "changed")
+	if expected, got := "changed", second.provider.Read("sid", time.Hour).GetString("name"); got != expected {
+		t.Fatalf("expected the change of the first service to be reloaded but got %q", got)
+	}
+
+	first.DestroyByID("sid")
+	if got := second.provider.Read("sid", time.Hour).GetString("name"); got != "" {
+		t.Fatalf("expected the session destroyed by the first service to be empty but got %q", got)
+	}
+}
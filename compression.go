@@ -0,0 +1,171 @@
+package sessions
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// Compressor is the interface which should be implemented
+// by the compression algorithms of the `CompressionTranscoder`.
+type Compressor interface {
+	// ID is the unique identifier of the algorithm,
+	// it's written to the header of the compressed data
+	// so they can be decompressed even if the configured compressor changes.
+	ID() byte
+	// Compress returns the compressed "b".
+	Compress(b []byte) ([]byte, error)
+	// Decompress returns the decompressed "b".
+	Decompress(b []byte) ([]byte, error)
+}
+
+// compressionMagic is the header of the compressed data, followed by the compressor's ID.
+var compressionMagic = []byte("gsz")
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[byte]Compressor{}
+)
+
+func init() {
+	RegisterCompressor(GzipCompressor{})
+}
+
+// RegisterCompressor registers a compressor so data compressed by it can be decompressed,
+// the built-in and the "compression/..." subpackages' compressors are registered automatically.
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	compressors[c.ID()] = c
+	compressorsMu.Unlock()
+}
+
+// CompressionTranscoder is a transcoder which compresses the output
+// of another transcoder, useful when sessions carry large payloads.
+//
+// Data which were written without compression, i.e before its usage,
+// are still decoded correctly.
+//
+// Usage:
+// sessions.DefaultTranscoder = sessions.NewCompressionTranscoder(sessions.GobTranscoder{}, sessions.GzipCompressor{})
+// Or per database, i.e:
+// redis.New(...).Transcoder(sessions.NewCompressionTranscoder(...))
+type CompressionTranscoder struct {
+	// Transcoder is the underline transcoder.
+	Transcoder Transcoder
+	// Compressor is the compression algorithm.
+	Compressor Compressor
+	// MinSize is the minimum size of the underline transcoder's output
+	// which is compressed, smaller data are kept as they are.
+	//
+	// Defaults to 0, all data are compressed.
+	MinSize int
+}
+
+var _ Transcoder = (*CompressionTranscoder)(nil)
+
+// NewCompressionTranscoder returns a new transcoder which compresses
+// the output of the "t" transcoder with the "c" compressor.
+// If "t" is nil then the `GobTranscoder` is used instead.
+func NewCompressionTranscoder(t Transcoder, c Compressor) *CompressionTranscoder {
+	if t == nil {
+		t = GobTranscoder{}
+	}
+
+	RegisterCompressor(c)
+	return &CompressionTranscoder{Transcoder: t, Compressor: c}
+}
+
+// Marshal returns the compressed output of the underline transcoder.
+func (t *CompressionTranscoder) Marshal(value interface{}) ([]byte, error) {
+	b, err := t.Transcoder.Marshal(value)
+	if err != nil || len(b) < t.MinSize {
+		return b, err
+	}
+
+	compressed, err := t.Compressor.Compress(b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(compressionMagic)+1+len(compressed))
+	out = append(out, compressionMagic...)
+	out = append(out, t.Compressor.ID())
+	return append(out, compressed...), nil
+}
+
+// Unmarshal decompresses the "b", if it's compressed, and passes it to the underline transcoder.
+func (t *CompressionTranscoder) Unmarshal(b []byte, outPtr interface{}) error {
+	if n := len(compressionMagic); len(b) > n && bytes.Equal(b[:n], compressionMagic) {
+		compressorsMu.RLock()
+		c, ok := compressors[b[n]]
+		compressorsMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("compression: unknown compressor with id %d", b[n])
+		}
+
+		decompressed, err := c.Decompress(b[n+1:])
+		if err != nil {
+			return err
+		}
+		b = decompressed
+	}
+
+	return t.Transcoder.Unmarshal(b, outPtr)
+}
+
+// FormatVersioned reports whether the underline transcoder is a `FormatVersioner`,
+// the header of the `FormatVersion` is written before the compressed data.
+func (t *CompressionTranscoder) FormatVersioned() bool {
+	return formatVersioned(t.Transcoder)
+}
+
+// GzipCompressor is the built-in gzip compressor.
+type GzipCompressor struct {
+	// Level is the gzip compression level,
+	// defaults to 0 which is the gzip.DefaultCompression.
+	Level int
+}
+
+var _ Compressor = GzipCompressor{}
+
+// ID returns the gzip compressor's identifier, 1.
+func (GzipCompressor) ID() byte {
+	return 1
+}
+
+// Compress returns the gzip-compressed "b".
+func (c GzipCompressor) Compress(b []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(b); err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the decompressed, gzip-compressed, "b".
+func (GzipCompressor) Decompress(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
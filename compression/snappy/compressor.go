@@ -0,0 +1,34 @@
+package snappy
+
+import (
+	"github.com/golang/snappy"
+	"github.com/kataras/go-sessions"
+)
+
+func init() {
+	sessions.RegisterCompressor(Compressor{})
+}
+
+// Compressor is the snappy session compressor,
+// it's faster but it compresses less than gzip.
+//
+// Usage:
+// sessions.DefaultTranscoder = sessions.NewCompressionTranscoder(sessions.GobTranscoder{}, snappy.Compressor{})
+type Compressor struct{}
+
+var _ sessions.Compressor = Compressor{}
+
+// ID returns the snappy compressor's identifier, 2.
+func (Compressor) ID() byte {
+	return 2
+}
+
+// Compress returns the snappy-compressed "b".
+func (Compressor) Compress(b []byte) ([]byte, error) {
+	return snappy.Encode(nil, b), nil
+}
+
+// Decompress returns the decompressed, snappy-compressed, "b".
+func (Compressor) Decompress(b []byte) ([]byte, error) {
+	return snappy.Decode(nil, b)
+}
@@ -0,0 +1,40 @@
+package zstd
+
+import (
+	"github.com/kataras/go-sessions"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	sessions.RegisterCompressor(Compressor{})
+}
+
+// the encoder and decoder are safe for concurrent use of their EncodeAll and DecodeAll methods.
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// Compressor is the zstd session compressor,
+// it compresses better than gzip and snappy, at a similar speed to gzip.
+//
+// Usage:
+// sessions.DefaultTranscoder = sessions.NewCompressionTranscoder(sessions.GobTranscoder{}, zstd.Compressor{})
+type Compressor struct{}
+
+var _ sessions.Compressor = Compressor{}
+
+// ID returns the zstd compressor's identifier, 3.
+func (Compressor) ID() byte {
+	return 3
+}
+
+// Compress returns the zstd-compressed "b".
+func (Compressor) Compress(b []byte) ([]byte, error) {
+	return encoder.EncodeAll(b, nil), nil
+}
+
+// Decompress returns the decompressed, zstd-compressed, "b".
+func (Compressor) Decompress(b []byte) ([]byte, error) {
+	return decoder.DecodeAll(b, nil)
+}
@@ -1,9 +1,11 @@
 package sessions
 
 import (
+	"context"
+	"net/http"
 	"time"
 
-	"github.com/satori/go.uuid"
+	"github.com/valyala/fasthttp"
 )
 
 const (
@@ -16,6 +18,9 @@ type (
 	Config struct {
 		// Cookie string, the session's client cookie name, for example: "mysessionid"
 		//
+		// The `HostCookiePrefix` and the `SecureCookiePrefix` names are supported,
+		// the cookie attributes which are required by the prefix are set, see `CheckCookiePrefix`.
+		//
 		// Defaults to "gosessionid"
 		Cookie string
 
@@ -29,6 +34,60 @@ type (
 		// Defaults to false
 		CookieSecureTLS bool
 
+		// Header if not empty the session id is carried by this request and response header too,
+		// i.e "X-Session-ID" or "Authorization", for the mobile clients and the APIs where the cookies are impractical.
+		// The header of the request has priority over the cookie, the new session ids are sent by the response's header.
+		// The "Authorization" header carries the session id with the "Bearer" scheme.
+		// The session ids of the header are encoded by the `Encode` too.
+		//
+		// Defaults to empty, the session id is carried by the cookie only
+		Header string
+		// DisableCookie set it to true in order to carry the session id by the `Header` only,
+		// the session cookie is not read or sent.
+		//
+		// Defaults to false
+		DisableCookie bool
+
+		// CookieSecure set to true in order to send the session cookie with the Secure attribute
+		// regardless of the request, i.e behind a TLS-terminating proxy, see `CookieSecureTLS` too.
+		// The cookies of the `CookieSameSite` None are always secure.
+		//
+		// Defaults to false
+		CookieSecure bool
+
+		// CookieSameSite the SameSite attribute of the session cookie, i.e the http.SameSiteLaxMode,
+		// the http.SameSiteNoneMode is required by the cross-site requests, i.e of an embedded iframe,
+		// and it's sent with the Secure attribute.
+		//
+		// Defaults to 0, the attribute is not sent
+		CookieSameSite http.SameSite
+
+		// CookiePartitioned set to true in order to send the cookies with the Partitioned attribute (CHIPS),
+		// so the browsers which block the third-party cookies keep them, partitioned by the top-level site,
+		// when the app is embedded to another site, i.e in an iframe.
+		// The partitioned cookies are sent with the Secure attribute,
+		// the `CookieSameSite` should be the http.SameSiteNoneMode.
+		//
+		// Defaults to false
+		CookiePartitioned bool
+
+		// CookiePath the Path attribute of the session cookie.
+		//
+		// Defaults to "/"
+		CookiePath string
+
+		// CookieDomain if not empty it's the Domain attribute of the session cookie,
+		// instead of the one which is resolved from the request's host, see `DisableSubdomainPersistence`.
+		//
+		// Defaults to empty
+		CookieDomain string
+
+		// DisableHTTPOnly set it to true in order to send the session cookie without the HttpOnly attribute,
+		// so it can be read by the client-side scripts, it's not recommended.
+		//
+		// Defaults to false
+		DisableHTTPOnly bool
+
 		// Encode the cookie value if not nil.
 		// Should accept as first argument the cookie name (config.Name)
 		//         as second argument the server's generated session id.
@@ -38,6 +97,8 @@ type (
 		// and remember: if you use AES it only supports key sizes of 16, 24 or 32 bytes.
 		// You either need to provide exactly that amount or you derive the key from what you type in.
 		//
+		// See the built-in `CookieSigner#Encode` too.
+		//
 		// Defaults to nil
 		Encode func(cookieName string, value interface{}) (string, error)
 		// Decode the cookie value if not nil.
@@ -49,9 +110,18 @@ type (
 		// and remember: if you use AES it only supports key sizes of 16, 24 or 32 bytes.
 		// You either need to provide exactly that amount or you derive the key from what you type in.
 		//
+		// See the built-in `CookieSigner#Decode` too.
+		//
 		// Defaults to nil
 		Decode func(cookieName string, cookieValue string, v interface{}) error
 
+		// ClearSiteData are the directives of the "Clear-Site-Data" header, i.e "cookies" and "storage",
+		// which is sent alongside the expired cookie on `Destroy`, so the browsers purge
+		// the client-side state of the session too, useful for logouts on shared computers.
+		//
+		// Defaults to nil, the header is not sent
+		ClearSiteData []string
+
 		// Expires the duration of which the cookie must expires (created_time.Add(Expires)).
 		// If you want to delete the cookie when the browser closes, set it to -1.
 		//
@@ -62,15 +132,263 @@ type (
 		// Defaults to infinitive/unlimited life duration(0)
 		Expires time.Duration
 
+		// IdleTimeout is the duration of inactivity after which a session expires,
+		// each `Start` of the session extends its lifetime by this duration, up to
+		// its absolute expiration, the `Expires`, which is configured independently.
+		//
+		// Defaults to 0, no idle timeout
+		IdleTimeout time.Duration
+
+		// MaxAbsoluteLifetime is the maximum lifetime of a session since its creation, regardless of its activity,
+		// the creation time is stored to the session databases and a loaded session which exceeded it is replaced by a new one.
+		//
+		// Defaults to 0, unlimited
+		MaxAbsoluteLifetime time.Duration
+
+		// MaxExtensions is the maximum number of times the expiration of a session
+		// can be extended by the `UpdateExpiration`, `ShiftExpiration` and the sliding of the `IdleTimeout`,
+		// the number of the extensions is stored to the session databases.
+		//
+		// Defaults to 0, unlimited
+		MaxExtensions int
+
+		// MaxExtendedLifetime is the maximum lifetime, since its creation, that a session can be extended to
+		// by the `UpdateExpiration`, `ShiftExpiration` and the sliding of the `IdleTimeout`, i.e 24 hours regardless of the user's activity,
+		// the last extension is shortened to fit.
+		//
+		// Defaults to 0, unlimited
+		MaxExtendedLifetime time.Duration
+
 		// SessionIDGenerator should returns a random session id.
-		// By default we will use a uuid impl package to generate
-		// that, but developers can change that with simple assignment.
+		// It's used only when the `IDGenerator` is nil.
+		//
+		// Prefer the `IDGenerator` instead.
 		SessionIDGenerator func() string
 
+		// IDGenerator should returns a unique, unpredictable, session id,
+		// it accepts the request's context, on fasthttp it's the *fasthttp.RequestCtx.
+		// The built-in `RandomIDGenerator`, `UUIDGenerator`, `ULIDGenerator` and `KSUIDGenerator`
+		// can be used to meet the id format, entropy and sortability requirements.
+		//
+		// Defaults to the `RandomIDGenerator`, 256 random bits of the crypto/rand, base64-url encoded
+		IDGenerator func(ctx context.Context) string
+
 		// DisableSubdomainPersistence set it to true in order dissallow your subdomains to have access to the session cookie
 		//
 		// Defaults to false
 		DisableSubdomainPersistence bool
+
+		// Shared set it to true when the session databases are shared with other services,
+		// i.e sibling apps which mount a manager with the same cookie, keys and backend,
+		// the session is reloaded from the databases on each `Start`
+		// so the changes of the other services are visible, see `Sessions#CompatibleWith`.
+		//
+		// Defaults to false, the session is loaded from the databases once and kept in memory
+		Shared bool
+
+		// OptimisticConcurrency set it to true in order to write the sessions to the databases
+		// which implement the `VersionedDatabase` by a compare-and-swap of their version,
+		// so a session which was modified by another app instance since it was loaded is not overridden,
+		// instead of the last write wins. See `MergeConflict` and `Session#Conflict`.
+		// The concurrent requests of a session on the same app instance should be serialized, see `SingleWriter`.
+		//
+		// Defaults to false
+		OptimisticConcurrency bool
+
+		// MergeConflict if not nil is called on a conflict of the `OptimisticConcurrency`
+		// with the "local" session, which was rejected, and the "stored" one.
+		// It returns the merged values, which are written instead, or false to drop the local changes,
+		// the session's values are replaced by the stored ones and its `Session#Conflict` reports the conflict.
+		//
+		// Defaults to nil, the local changes are dropped
+		MergeConflict func(sid string, local, stored RemoteStore) (Store, bool)
+
+		// SingleWriter set it to true in order to serialize all requests of the same session id,
+		// `Start` will block until the previous request of that session calls `Session.Release`.
+		// Use it when correctness of read-modify-write session flows is more important than parallelism.
+		//
+		// Note: every handler that starts a session MUST call `Release` when done with it,
+		// otherwise the next requests of the same client will wait until their context is done
+		// or the `SingleWriterTimeout` passes, their sessions are read-only then.
+		//
+		// Defaults to false
+		SingleWriter bool
+
+		// SingleWriterTimeout is the maximum time which the `Start` waits for the session to be released,
+		// see `SingleWriter`, the session of a request which couldn't hold it is read-only and the `ErrSessionBusy` is logged.
+		// The requests of the net/http wait until their context is done too, i.e the client disconnected.
+		//
+		// Defaults to 0, no timeout, the fasthttp requests wait forever
+		SingleWriterTimeout time.Duration
+
+		// DistributedSingleWriter set it to true in order to serialize the requests of the same session id
+		// across the app instances, the `Start` acquires the distributed lock of the session too, see `Session#Lock`,
+		// and the `Session#Release` releases it. The `LockTTL` and the `SingleWriterTimeout` apply.
+		//
+		// It enables the `SingleWriter`.
+		//
+		// Defaults to false
+		DistributedSingleWriter bool
+
+		// LockTTL is the lifetime of the distributed session locks, see `Session#Lock`,
+		// a lock which is not released by its app instance, i.e it crashed, expires after it.
+		//
+		// Defaults to the `DefaultLockTTL`, 30 seconds
+		LockTTL time.Duration
+
+		// PrivilegeKeys are the session keys which change the privileges of the user, i.e "user" or "role",
+		// when one of them is set or deleted, the session id is regenerated and the client's cookie is updated,
+		// see `Session#RegenerateID`.
+		// The new session id is sent to the client of the request which changed the key, until its `Session#Release`,
+		// use the `SingleWriter` so the concurrent requests of the same client don't send the old one.
+		//
+		// Defaults to nil
+		PrivilegeKeys []string
+
+		// KeyNormalizer if not nil it normalizes the keys of the session's values on each
+		// `Session#Set`, `Session#Get`, `Session#Delete` and the rest of the key-based methods,
+		// so the keys which are built from user input or headers match regardless of their spaces or case,
+		// see the `TrimKey`, `LowerKey` and `NormalizeKeys`.
+		// The keys are stored to the session databases normalized,
+		// the sessions which were stored before it was set are not normalized.
+		//
+		// Defaults to nil
+		KeyNormalizer func(key string) string
+
+		// Partitions are the configurations of the session's partitions by their names,
+		// i.e a short `PartitionConfig#Expires` of the "admin" partition, see `Session#Partition`.
+		//
+		// Defaults to nil, the partitions expire with the session and use the `DefaultTranscoder`
+		Partitions map[string]PartitionConfig
+
+		// AutoRegisterTypes set it to true in order to register the type of each value
+		// to the gob encoding on its first `Set`, so custom struct values
+		// can be saved to the session databases without a manual `RegisterTypes` call.
+		//
+		// Defaults to false
+		AutoRegisterTypes bool
+
+		// RiskScorer if not nil it's called by the `Start` with the signals of the session's request,
+		// i.e its age, the change of the client's address and the requests velocity,
+		// the returned score can be read by the `Session#RiskScore` to act on it.
+		//
+		// Defaults to nil
+		RiskScorer RiskScorer
+
+		// RememberMe if not nil it enables the remember-me logins, they are issued by the `Sessions#Remember`
+		// and restored by the `Start` to the sessions which are lost, i.e after a browser restart.
+		//
+		// Defaults to nil
+		RememberMe RememberMeStore
+		// RememberMeCookie the remember-me cookie's name.
+		//
+		// Defaults to "remember_me"
+		RememberMeCookie string
+		// RememberMeExpires the lifetime of a remember-me login, it's not extended by its use.
+		//
+		// Defaults to 30 days
+		RememberMeExpires time.Duration
+
+		// AnonymousIDKey if not empty it enables the anonymous ids, the `Start` issues to each browser
+		// a long-lived id, signed with HMAC-SHA256 by this key, and sends it as a separate cookie,
+		// the id is kept across the session id regenerations and the new sessions,
+		// so the analytics can correlate the visits, see `Session#AnonymousID`.
+		// The key should be at least 32 bytes.
+		//
+		// Defaults to nil
+		AnonymousIDKey []byte
+		// AnonymousIDCookie the anonymous id cookie's name.
+		//
+		// Defaults to "anonymous_id"
+		AnonymousIDCookie string
+		// AnonymousIDExpires the lifetime of an anonymous id.
+		//
+		// Defaults to 365 days
+		AnonymousIDExpires time.Duration
+		// AnonymousIDRevoked if not nil it reports whether an anonymous id is revoked,
+		// a revoked id is replaced by a new one, see `Sessions#RevokeAnonymousID`.
+		//
+		// Defaults to nil
+		AnonymousIDRevoked func(id string) bool
+
+		// Hydrator if not nil it's called by the `Start` when a new session is created,
+		// including the session of an unknown or expired session id of the request's cookie,
+		// the returned values are saved to the session before the handler runs,
+		// they are subject to the `MaxSessionSize` and the `ReadOnly` of the request.
+		// It's useful to populate the initial claims of an authenticated upstream identity,
+		// i.e from the SSO header of an auth proxy.
+		// It should return nil if the request has no identity.
+		//
+		// Defaults to nil
+		Hydrator func(r *http.Request) Store
+		// HydratorFasthttp same as `Hydrator` but it's called by the `StartFasthttp`.
+		//
+		// Defaults to nil
+		HydratorFasthttp func(ctx *fasthttp.RequestCtx) Store
+		// HydratorContext same as `Hydrator` but it's called by the `StartContext`,
+		// i.e with the context of a gRPC call.
+		//
+		// Defaults to nil
+		HydratorContext func(ctx context.Context) Store
+
+		// ReadOnly if not nil it reports whether the request's session is read-only, i.e the GET routes of an API,
+		// its writes are rejected and the session databases are not synced, see `Session#SetReadOnly`.
+		//
+		// Defaults to nil
+		ReadOnly func(r *http.Request) bool
+		// ReadOnlyFasthttp same as `ReadOnly` but it's called by the `StartFasthttp`.
+		//
+		// Defaults to nil
+		ReadOnlyFasthttp func(ctx *fasthttp.RequestCtx) bool
+		// ReadOnlyContext same as `ReadOnly` but it's called by the `StartContext`.
+		//
+		// Defaults to nil
+		ReadOnlyContext func(ctx context.Context) bool
+
+		// BytesLimit if positive it's the maximum length of a value of the `Session#SetBytes`,
+		// a larger value is rejected with the `ErrBytesTooLarge`.
+		//
+		// Defaults to 0, no limit
+		BytesLimit int
+		// SessionBytesLimit if positive it's the maximum total length of the binary values of a session,
+		// the `Session#SetBytes` which exceeds it is rejected with the `ErrBytesTooLarge`.
+		//
+		// Defaults to 0, no limit
+		SessionBytesLimit int
+
+		// MaxSessionSize if positive it's the maximum length of the serialized values of a session,
+		// protects the session databases and the cookies from unbounded growth.
+		// A write which exceeds it is rejected with the `ErrSessionTooLarge`, see `TrimPolicy`.
+		// Each write serializes the session's values when it's set.
+		//
+		// Defaults to 0, no limit
+		MaxSessionSize int
+		// TrimPolicy if not nil it removes entries, i.e the `TrimOldest`,
+		// to make room for a write which exceeds the `MaxSessionSize` instead of rejecting it.
+		//
+		// Defaults to nil
+		TrimPolicy TrimPolicy
+
+		// Clock if not nil it's the source of the current time and of the expiration timers of the sessions,
+		// i.e a `ManualClock` so the tests can expire the sessions without sleeping.
+		// It's set to the session databases which implement the `ClockSetter` too.
+		// The session cookie's expiration time is still based on the system's clock, it's read by the browsers.
+		//
+		// Defaults to nil, the `SystemClock`
+		Clock Clock
+
+		// Shards is the number of the locks which the in-memory sessions are sharded across,
+		// so the concurrent requests of different sessions don't wait for a single lock.
+		//
+		// Defaults to 0, four times the GOMAXPROCS
+		Shards int
+
+		// Logger if not nil it logs the invalid session cookies, the accesses of missing or expired sessions
+		// and the errors of the session databases and the invalidator,
+		// it's set to the session databases which implement the `LoggerSetter` too, see `Logger`.
+		//
+		// Defaults to nil, nothing is logged
+		Logger Logger
 	}
 )
 
@@ -81,10 +399,46 @@ func (c Config) Validate() Config {
 		c.Cookie = DefaultCookieName
 	}
 
+	if c.CookiePath == "" {
+		c.CookiePath = "/"
+	}
+
+	c.validateCookiePrefix()
+
+	if c.IDGenerator == nil {
+		if generate := c.SessionIDGenerator; generate != nil {
+			c.IDGenerator = func(context.Context) string {
+				return generate()
+			}
+		} else {
+			c.IDGenerator = RandomIDGenerator
+		}
+	}
+
+	if c.RememberMeCookie == "" {
+		c.RememberMeCookie = DefaultRememberMeCookieName
+	}
+
+	if c.RememberMeExpires <= 0 {
+		c.RememberMeExpires = DefaultRememberMeExpires
+	}
+
+	if c.AnonymousIDCookie == "" {
+		c.AnonymousIDCookie = DefaultAnonymousIDCookieName
+	}
+
+	if c.AnonymousIDExpires <= 0 {
+		c.AnonymousIDExpires = DefaultAnonymousIDExpires
+	}
+
+	if c.DistributedSingleWriter {
+		c.SingleWriter = true
+	}
+
 	if c.SessionIDGenerator == nil {
+		generate := c.IDGenerator
 		c.SessionIDGenerator = func() string {
-			id := uuid.NewV4()
-			return id.String()
+			return generate(context.Background())
 		}
 	}
 
@@ -81,6 +81,102 @@ func RemoveCookieFasthttp(ctx *fasthttp.RequestCtx, name string) {
 	ctx.Request.Header.DelCookie(name)
 }
 
+// secureCookie reports whether the cookies should be sent with the Secure attribute,
+// "tls" is true if the request is served over TLS.
+// The SameSite=None and the partitioned cookies are always secure, the browsers reject them otherwise.
+func (s *Sessions) secureCookie(tls bool) bool {
+	return s.config.CookieSecure || (tls && s.config.CookieSecureTLS) ||
+		s.config.CookieSameSite == http.SameSiteNoneMode || s.config.CookiePartitioned
+}
+
+// addCookie adds the "cookie" with the Partitioned attribute if the `Config#CookiePartitioned` is true.
+func (s *Sessions) addCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	if !s.config.CookiePartitioned {
+		AddCookie(w, cookie)
+		return
+	}
+
+	// the attribute is appended, the http.Cookie can't write it on the older go versions.
+	if v := cookie.String(); v != "" {
+		w.Header().Add("Set-Cookie", v+"; Partitioned")
+	}
+}
+
+// sameSiteFasthttp returns the fasthttp mode of the "mode".
+func sameSiteFasthttp(mode http.SameSite) fasthttp.CookieSameSite {
+	switch mode {
+	case http.SameSiteDefaultMode:
+		return fasthttp.CookieSameSiteDefaultMode
+	case http.SameSiteLaxMode:
+		return fasthttp.CookieSameSiteLaxMode
+	case http.SameSiteStrictMode:
+		return fasthttp.CookieSameSiteStrictMode
+	case http.SameSiteNoneMode:
+		return fasthttp.CookieSameSiteNoneMode
+	default:
+		return fasthttp.CookieSameSiteDisabled
+	}
+}
+
+// removeCookie deletes the session cookie, with the `Config#CookiePath` and `Config#CookieDomain`
+// so the browsers match the cookie which was sent by the `Start`.
+func (s *Sessions) removeCookie(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(s.config.Cookie); err != nil {
+		return
+	}
+
+	s.addCookie(w, &http.Cookie{
+		Name:     s.config.Cookie,
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		Expires:  CookieExpireDelete,
+		MaxAge:   -1,
+		HttpOnly: !s.config.DisableHTTPOnly,
+		Secure:   s.secureCookie(r.TLS != nil),
+		SameSite: s.config.CookieSameSite,
+	})
+}
+
+// removeCookieFasthttp deletes the session cookie, see `removeCookie`.
+func (s *Sessions) removeCookieFasthttp(ctx *fasthttp.RequestCtx) {
+	name := s.config.Cookie
+	ctx.Response.Header.DelCookie(name)
+
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey(name)
+	cookie.SetValue("")
+	cookie.SetPath(s.config.CookiePath)
+	if domain := s.config.CookieDomain; domain != "" {
+		cookie.SetDomain(domain)
+	}
+	cookie.SetHTTPOnly(!s.config.DisableHTTPOnly)
+	cookie.SetSecure(s.secureCookie(ctx.IsTLS()))
+	cookie.SetSameSite(sameSiteFasthttp(s.config.CookieSameSite))
+	cookie.SetPartitioned(s.config.CookiePartitioned)
+	cookie.SetExpire(time.Now().Add(-time.Minute))
+	AddCookieFasthttp(ctx, cookie)
+	fasthttp.ReleaseCookie(cookie)
+	// delete request's cookie also, which is temporary available
+	ctx.Request.Header.DelCookie(name)
+}
+
+const clearSiteDataHeaderKey = "Clear-Site-Data"
+
+// clearSiteData returns the "Clear-Site-Data" header value of the "directives",
+// each directive is quoted, i.e "cookies", "storage".
+func clearSiteData(directives []string) string {
+	if len(directives) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, 0, len(directives))
+	for _, directive := range directives {
+		quoted = append(quoted, `"`+strings.Trim(directive, `"`)+`"`)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
 // IsValidCookieDomain returns true if the receiver is a valid domain to set
 // valid means that is recognised as 'domain' by the browser, so it(the cookie) can be shared with subdomains also
 func IsValidCookieDomain(domain string) bool {
@@ -0,0 +1,137 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDestroyClearSiteData(t *testing.T) {
+	manager := New(Config{ClearSiteData: []string{"cookies", `"storage"`}})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	manager.Start(w, r)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	w = httptest.NewRecorder()
+	manager.Destroy(w, r)
+
+	if expected, got := `"cookies", "storage"`, w.Header().Get("Clear-Site-Data"); got != expected {
+		t.Fatalf("expected the Clear-Site-Data header to be %s but got %s", expected, got)
+	}
+
+	w = httptest.NewRecorder()
+	New(Config{}).Destroy(w, r)
+	if got := w.Header().Get("Clear-Site-Data"); got != "" {
+		t.Fatalf("expected no Clear-Site-Data header by default but got %s", got)
+	}
+}
+
+func TestCookieAttributes(t *testing.T) {
+	manager := New(Config{
+		Cookie:          "sid",
+		CookiePath:      "/app",
+		CookieDomain:    "example.com",
+		CookieSameSite:  http.SameSiteNoneMode,
+		DisableHTTPOnly: true,
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/app", nil)
+	w := httptest.NewRecorder()
+	manager.Start(w, r)
+
+	c := findCookie(w, "sid")
+	if c == nil {
+		t.Fatalf("expected the session cookie")
+	}
+
+	if c.Path != "/app" || c.Domain != "example.com" {
+		t.Fatalf("expected the path /app and the domain example.com but got %s and %s", c.Path, c.Domain)
+	}
+
+	if c.SameSite != http.SameSiteNoneMode || !c.Secure {
+		t.Fatalf("expected a secure SameSite=None cookie but got %v and secure: %v", c.SameSite, c.Secure)
+	}
+
+	if c.HttpOnly {
+		t.Fatalf("expected the HttpOnly attribute to be disabled")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/app", nil)
+	r.AddCookie(c)
+	w = httptest.NewRecorder()
+	manager.Destroy(w, r)
+
+	removed := findCookie(w, "sid")
+	if removed == nil || removed.MaxAge >= 0 {
+		t.Fatalf("expected the session cookie to be removed but got %v", removed)
+	}
+
+	if removed.Path != "/app" || removed.Domain != "example.com" {
+		t.Fatalf("expected the removal cookie to match the path and the domain but got %s and %s", removed.Path, removed.Domain)
+	}
+
+	w = httptest.NewRecorder()
+	New(Config{Cookie: "sid"}).Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if c = findCookie(w, "sid"); c.Path != "/" || !c.HttpOnly || c.Secure {
+		t.Fatalf("expected the defaults to be the path / and an HttpOnly, not secure, cookie but got %s, %v, %v", c.Path, c.HttpOnly, c.Secure)
+	}
+}
+
+func TestCookiePrefix(t *testing.T) {
+	c := Config{Cookie: HostCookiePrefix + "sid", CookiePath: "/app", CookieDomain: "example.com"}
+	if err := c.CheckCookiePrefix(); err == nil {
+		t.Fatalf("expected an error for a %s cookie with a domain", HostCookiePrefix)
+	}
+
+	if err := (Config{Cookie: SecureCookiePrefix + "sid"}).CheckCookiePrefix(); err == nil {
+		t.Fatalf("expected an error for a not secure %s cookie", SecureCookiePrefix)
+	}
+
+	if err := (Config{Cookie: SecureCookiePrefix + "sid", CookieSecure: true}).CheckCookiePrefix(); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := New(c)
+	r := httptest.NewRequest(http.MethodGet, "http://sub.example.com/", nil)
+	w := httptest.NewRecorder()
+	manager.Start(w, r)
+
+	cookie := findCookie(w, HostCookiePrefix+"sid")
+	if cookie == nil {
+		t.Fatalf("expected the session cookie")
+	}
+
+	if !cookie.Secure || cookie.Path != "/" || cookie.Domain != "" {
+		t.Fatalf("expected a secure cookie with the path / and no domain but got %v, %s and %s", cookie.Secure, cookie.Path, cookie.Domain)
+	}
+
+	w = httptest.NewRecorder()
+	New(Config{Cookie: SecureCookiePrefix + "sid"}).Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if cookie = findCookie(w, SecureCookiePrefix+"sid"); cookie == nil || !cookie.Secure {
+		t.Fatalf("expected a secure %s cookie", SecureCookiePrefix)
+	}
+}
+
+func TestCookiePartitioned(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(Config{Cookie: "sid", CookiePartitioned: true}).Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	c := findCookie(w, "sid")
+	if c == nil || !c.Secure {
+		t.Fatalf("expected a secure session cookie but got %v", c)
+	}
+
+	if header := w.Header().Get("Set-Cookie"); !strings.HasSuffix(header, "; Partitioned") {
+		t.Fatalf("expected the Partitioned attribute but got %s", header)
+	}
+
+	w = httptest.NewRecorder()
+	New(Config{Cookie: "sid"}).Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if header := w.Header().Get("Set-Cookie"); strings.Contains(header, "Partitioned") {
+		t.Fatalf("expected a not partitioned session cookie by default but got %s", header)
+	}
+}
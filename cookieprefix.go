@@ -0,0 +1,59 @@
+package sessions
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HostCookiePrefix is the cookie name prefix which the browsers accept only for the cookies
+	// which are secure, their path is "/" and they have no domain, so they are bound to the host
+	// which set them and they can't be overridden by a subdomain or an insecure origin.
+	HostCookiePrefix = "__Host-"
+	// SecureCookiePrefix is the cookie name prefix which the browsers accept only for the secure cookies.
+	SecureCookiePrefix = "__Secure-"
+)
+
+// isSecurePrefixed reports whether the cookie "name" has the `HostCookiePrefix` or the `SecureCookiePrefix`.
+func isSecurePrefixed(name string) bool {
+	return strings.HasPrefix(name, HostCookiePrefix) || strings.HasPrefix(name, SecureCookiePrefix)
+}
+
+// CheckCookiePrefix reports whether the cookie attributes of the configuration conflict with the prefix
+// of the `Cookie` name, i.e a "__Host-" cookie with a `CookieDomain`, the browsers silently reject such a cookie.
+// The `Validate` sets the required attributes of a prefixed cookie, overriding the conflicting ones,
+// call it before the `New` to fail instead.
+func (c Config) CheckCookiePrefix() error {
+	if c.DisableCookie || !isSecurePrefixed(c.Cookie) {
+		return nil
+	}
+
+	if strings.HasPrefix(c.Cookie, HostCookiePrefix) {
+		if c.CookieDomain != "" {
+			return fmt.Errorf("sessions: the cookie %s can't have a domain but got %s", c.Cookie, c.CookieDomain)
+		}
+		if c.CookiePath != "" && c.CookiePath != "/" {
+			return fmt.Errorf("sessions: the cookie %s requires the path / but got %s", c.Cookie, c.CookiePath)
+		}
+	}
+
+	if !c.CookieSecure && !c.CookieSecureTLS && c.CookieSameSite != http.SameSiteNoneMode {
+		return fmt.Errorf("sessions: the cookie %s requires the CookieSecure or the CookieSecureTLS", c.Cookie)
+	}
+
+	return nil
+}
+
+// validateCookiePrefix sets the attributes which are required by the prefix of the `Cookie` name.
+func (c *Config) validateCookiePrefix() {
+	if strings.HasPrefix(c.Cookie, HostCookiePrefix) {
+		c.CookiePath = "/"
+		c.CookieDomain = ""
+		c.DisableSubdomainPersistence = true
+	}
+
+	if isSecurePrefixed(c.Cookie) {
+		c.CookieSecure = true
+	}
+}
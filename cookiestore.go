@@ -0,0 +1,222 @@
+package sessions
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MaxCookieSize is the maximum size of the session cookie's name and value
+// which the `CookieStore` writes, browsers drop larger cookies.
+const MaxCookieSize = 4096
+
+// ErrCookieTooLarge returned by the `CookieStore#Save` when the encoded session
+// exceeds the `MaxCookieSize`, the session should carry less data or a server-side database should be used instead.
+var ErrCookieTooLarge = errors.New("cookie store: the encoded session exceeds the cookie size limit")
+
+// CookieStore is the stateless sessions manager, the whole
+// session's store lives inside the client's cookie, serialized, signed and optionally encrypted,
+// so nothing is stored to the server, useful for deployments that can't run a session database.
+//
+// Unlike the `Sessions`, the changes of a session are not saved automatically,
+// the `Save` should be called before the response is written.
+//
+// Usage:
+// store, err := sessions.NewCookieStore(sessions.Config{Expires: 24 * time.Hour}, [][]byte{hashKey}, [][]byte{blockKey})
+// sess := store.Start(w, r)
+// sess.Set("name", "go-sessions")
+// err = store.Save(w, r, sess)
+type CookieStore struct {
+	sessions   *Sessions
+	signer     *CookieSigner
+	transcoder Transcoder
+}
+
+// NewCookieStore returns a new cookie store, the session cookies are signed with the first of the "hashKeys"
+// and, if "blockKeys" are not empty, encrypted with the first of the "blockKeys", see `NewCookieSigner`.
+// The "cfg"'s `Encode` and `Decode` are not used.
+func NewCookieStore(cfg Config, hashKeys [][]byte, blockKeys [][]byte) (*CookieStore, error) {
+	signer, err := NewCookieSigner(hashKeys, blockKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Encode = nil
+	cfg.Decode = nil
+
+	return &CookieStore{
+		sessions: New(cfg),
+		signer:   signer,
+	}, nil
+}
+
+// Transcoder sets the transcoder which is used to serialize
+// the sessions to the cookies, i.e a `CompressionTranscoder` to fit larger sessions to the cookie.
+// Defaults to nil, the `DefaultTranscoder` is used instead.
+func (c *CookieStore) Transcoder(t Transcoder) *CookieStore {
+	c.transcoder = t
+	return c
+}
+
+// load returns the session of the "cookieValue",
+// or a new one if it's missing, invalid or expired.
+func (c *CookieStore) load(ctx context.Context, cookieValue string) *Session {
+	sess := &Session{sessionState: &sessionState{
+		provider: c.sessions.provider,
+		flashes:  make(map[string]*flashMessage),
+		writer:   make(chan struct{}, 1),
+	}, requestState: new(requestState)}
+
+	if cookieValue != "" {
+		var payload string
+		if err := c.signer.Decode(c.sessions.config.Cookie, cookieValue, &payload); err == nil {
+			if sid, store, err := decodeCookiePayload(c.transcoder, payload); err == nil {
+				deadline := c.sessions.provider.absoluteDeadline(store.CreatedAt)
+				store.Lifetime.clock = c.sessions.provider.clock()
+				if !store.Lifetime.HasExpired() && (deadline.IsZero() || deadline.After(c.sessions.provider.now())) {
+					sess.sid = sid
+					sess.values = store.Values
+					sess.lifetime = LifeTime{Time: store.Lifetime.Time, clock: store.Lifetime.clock}
+					sess.createdAt = store.CreatedAt
+					sess.extensions = store.Extensions
+					return sess
+				}
+			}
+		}
+	}
+
+	sess.sid = c.sessions.config.IDGenerator(ctx)
+	sess.isNew = true
+	sess.createdAt = c.sessions.provider.now()
+	sess.lifetime.clock = c.sessions.provider.clock()
+	if expires := c.sessions.config.Expires; expires > 0 {
+		sess.lifetime.Time = sess.createdAt.Add(expires)
+	}
+
+	return sess
+}
+
+// errInvalidCookiePayload is returned by the `decodeCookiePayload` when the session id is truncated.
+var errInvalidCookiePayload = errors.New("cookie store: invalid session payload")
+
+// decodeCookiePayload returns the session id and the store of the verified cookie's "payload",
+// the length-prefixed session id followed by the serialized remote store, see `encode`.
+func decodeCookiePayload(t Transcoder, payload string) (string, RemoteStore, error) {
+	n, size := binary.Uvarint([]byte(payload))
+	if size <= 0 || uint64(len(payload)-size) < n {
+		return "", RemoteStore{}, errInvalidCookiePayload
+	}
+
+	sid := payload[size : size+int(n)]
+	store, err := DecodeRemoteStoreWith(t, []byte(payload[size+int(n):]))
+	return sid, store, err
+}
+
+// encode returns the signed cookie value of the "sess" and its expiration duration.
+func (c *CookieStore) encode(sess *Session) (string, time.Duration, error) {
+	sess.mu.RLock()
+	store := RemoteStore{Values: sess.values, Lifetime: LifeTime{Time: sess.lifetime.Time}, CreatedAt: sess.createdAt, Extensions: sess.extensions}
+	data, err := store.SerializeWith(c.transcoder)
+	sess.mu.RUnlock()
+	if err != nil {
+		return "", 0, err
+	}
+
+	payload := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(sess.sid)+len(data))
+	payload = payload[:binary.PutUvarint(payload, uint64(len(sess.sid)))]
+	payload = append(payload, sess.sid...)
+	payload = append(payload, data...)
+
+	cookieName := c.sessions.config.Cookie
+	value, err := c.signer.Encode(cookieName, string(payload))
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(cookieName)+len(value) > MaxCookieSize {
+		return "", 0, ErrCookieTooLarge
+	}
+
+	expires := c.sessions.config.Expires
+	if !store.Lifetime.IsZero() {
+		expires = store.Lifetime.Sub(c.sessions.provider.now())
+	}
+
+	return value, expires, nil
+}
+
+// unmodified reports whether the "sess" is loaded from the request's cookie and it's not modified since,
+// so the cookie is kept as it's, see `Session#Dirty`.
+func (c *CookieStore) unmodified(sess *Session) bool {
+	sess.mu.RLock()
+	unmodified := !sess.isNew && !sess.dirty
+	sess.mu.RUnlock()
+	return unmodified
+}
+
+// Start returns the session which is stored to the request's cookie,
+// if it's missing, invalid or expired then a new session is returned.
+func (c *CookieStore) Start(w http.ResponseWriter, r *http.Request) *Session {
+	return c.load(r.Context(), GetCookie(r, c.sessions.config.Cookie))
+}
+
+// Save writes the "sess" to the response's cookie,
+// it returns an `ErrCookieTooLarge` if it doesn't fit to the cookie.
+// The cookie is kept if the session is loaded from it and it's not modified, see `Session#Dirty`.
+func (c *CookieStore) Save(w http.ResponseWriter, r *http.Request, sess *Session) error {
+	if c.unmodified(sess) {
+		return nil
+	}
+
+	value, expires, err := c.encode(sess)
+	if err != nil {
+		return err
+	}
+
+	c.sessions.updateCookie(w, r, value, expires)
+	return nil
+}
+
+// Destroy removes the session cookie,
+// the `Config#ClearSiteData` header is sent too.
+func (c *CookieStore) Destroy(w http.ResponseWriter, r *http.Request) {
+	RemoveCookie(w, r, c.sessions.config.Cookie)
+	if value := clearSiteData(c.sessions.config.ClearSiteData); value != "" {
+		w.Header().Set(clearSiteDataHeaderKey, value)
+	}
+}
+
+// StartFasthttp returns the session which is stored to the request's cookie,
+// if it's missing, invalid or expired then a new session is returned.
+func (c *CookieStore) StartFasthttp(ctx *fasthttp.RequestCtx) *Session {
+	return c.load(ctx, GetCookieFasthttp(ctx, c.sessions.config.Cookie))
+}
+
+// SaveFasthttp writes the "sess" to the response's cookie,
+// it returns an `ErrCookieTooLarge` if it doesn't fit to the cookie.
+func (c *CookieStore) SaveFasthttp(ctx *fasthttp.RequestCtx, sess *Session) error {
+	if c.unmodified(sess) {
+		return nil
+	}
+
+	value, expires, err := c.encode(sess)
+	if err != nil {
+		return err
+	}
+
+	c.sessions.updateCookieFasthttp(ctx, value, expires)
+	return nil
+}
+
+// DestroyFasthttp removes the session cookie,
+// the `Config#ClearSiteData` header is sent too.
+func (c *CookieStore) DestroyFasthttp(ctx *fasthttp.RequestCtx) {
+	RemoveCookieFasthttp(ctx, c.sessions.config.Cookie)
+	if value := clearSiteData(c.sessions.config.ClearSiteData); value != "" {
+		ctx.Response.Header.Set(clearSiteDataHeaderKey, value)
+	}
+}
@@ -0,0 +1,55 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCookieStore(t *testing.T) {
+	store, err := NewCookieStore(Config{}, [][]byte{[]byte(strings.Repeat("h", 32))}, [][]byte{[]byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	sess := store.Start(w, r)
+	if !sess.IsNew() {
+		t.Fatalf("expected a new session")
+	}
+	sess.Set("name", "go-sessions")
+
+	if err = store.Save(w, r, sess); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected the session cookie to be written but got %v", cookies)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	got := store.Start(httptest.NewRecorder(), r)
+	if got.IsNew() || got.ID() != sess.ID() {
+		t.Fatalf("expected the session %s to be loaded from the cookie but got %s", sess.ID(), got.ID())
+	}
+
+	if expected, v := "go-sessions", got.GetString("name"); v != expected {
+		t.Fatalf("expected %s but got %s", expected, v)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: "x" + cookies[0].Value})
+	if forged := store.Start(httptest.NewRecorder(), r); !forged.IsNew() {
+		t.Fatalf("expected a new session for a tampered cookie")
+	}
+
+	got.Set("payload", strings.Repeat("go-sessions", MaxCookieSize/10))
+	if err = store.Save(httptest.NewRecorder(), r, got); err != ErrCookieTooLarge {
+		t.Fatalf("expected %v but got %v", ErrCookieTooLarge, err)
+	}
+}
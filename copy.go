@@ -0,0 +1,173 @@
+package sessions
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ImmutableCopyDepth is the depth of the copies of the immutable values, on the `Store.SetImmutable`
+// and on each `Entry.Value`, the slices, maps and pointers below it are shared with the stored value.
+// A depth of 1 copies the value itself, i.e the slice, but not its elements.
+//
+// Defaults to 0, the immutable values are copied recursively,
+// a negative value disables the copies for performance, the immutable values are protected from the `Set` only.
+var ImmutableCopyDepth = 0
+
+// copyKey is a copied reference of a `deepCopy`, the length separates the sub-slices of the same array.
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// deepCopy returns a deep copy of the "v", its slices, maps and pointers are copied recursively,
+// up to the `ImmutableCopyDepth`, so the caller's references can't change the copy.
+// The shared references of the "v", and the self-references, are copied once.
+// The functions and the channels are kept as they are.
+func deepCopy(v interface{}) interface{} {
+	if v == nil || ImmutableCopyDepth < 0 || !needsCopy(v) {
+		return v
+	}
+
+	return deepCopyValue(reflect.ValueOf(v), make(map[copyKey]reflect.Value), copyDepth()).Interface()
+}
+
+// needsCopy reports whether a copy of the "v" differs from the "v" itself, its type has references,
+// the common scalars are checked first, without reflection.
+func needsCopy(v interface{}) bool {
+	switch v.(type) {
+	case string, int, int64, bool, float64, time.Time:
+		return false
+	default:
+		return hasReferences(reflect.TypeOf(v))
+	}
+}
+
+// referenceTypes caches the results of the `hasReferences`, per type.
+var referenceTypes sync.Map // reflect.Type:bool
+
+// hasReferences reports whether the values of the "t" have slices, maps, pointers or interfaces
+// which a `deepCopy` copies, the unexported fields of the structs are not copied so they don't count.
+func hasReferences(t reflect.Type) bool {
+	if has, ok := referenceTypes.Load(t); ok {
+		return has.(bool)
+	}
+
+	var has bool
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		has = true
+	case reflect.Array:
+		has = hasReferences(t.Elem())
+	case reflect.Struct:
+		for i, n := 0, t.NumField(); i < n && !has; i++ {
+			if f := t.Field(i); f.PkgPath == "" {
+				has = hasReferences(f.Type)
+			}
+		}
+	}
+
+	referenceTypes.Store(t, has)
+	return has
+}
+
+// copyDepth returns the remaining depth of a copy, -1 means unlimited.
+func copyDepth() int {
+	if ImmutableCopyDepth == 0 {
+		return -1
+	}
+	return ImmutableCopyDepth
+}
+
+func deepCopyValue(v reflect.Value, copies map[copyKey]reflect.Value, depth int) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if depth == 0 {
+			return v
+		}
+	}
+
+	// the depth of the elements of a reference.
+	next := depth
+	if depth > 0 {
+		next--
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		k := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := copies[k]; ok {
+			return c
+		}
+
+		c := reflect.New(v.Type().Elem())
+		copies[k] = c
+		c.Elem().Set(deepCopyValue(v.Elem(), copies, next))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		k := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := copies[k]; ok {
+			return c
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[k] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopyValue(iter.Key(), copies, next), deepCopyValue(iter.Value(), copies, next))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		k := copyKey{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}
+		if c, ok := copies[k]; ok {
+			return c
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[k] = c
+		for i, n := 0, v.Len(); i < n; i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), copies, next))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i, n := 0, v.Len(); i < n; i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), copies, depth))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem(), copies, depth))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v) // the unexported fields are copied as they are.
+		t := v.Type()
+		for i, n := 0, v.NumField(); i < n; i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			c.Field(i).Set(deepCopyValue(v.Field(i), copies, depth))
+		}
+		return c
+	default:
+		return v
+	}
+}
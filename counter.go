@@ -0,0 +1,138 @@
+package sessions
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Incrementer is implemented by the session databases which increment the counters atomically,
+// i.e the redis one, so the concurrent increments of the app instances are not lost, see `Session#Increment`.
+type Incrementer interface {
+	// Increment adds the "delta" to the "key" counter of the session "sid" and returns its new value,
+	// the "current" is the session's value, it's used when the database has no counter of the "key" yet.
+	Increment(sid, key string, current, delta int64) (int64, error)
+}
+
+// toInt64 converts the integers, the numeric strings and the json.Number, *big.Int and *big.Rat values to int64,
+// it reports false if the "v" is not one of them.
+func toInt64(v interface{}) (int64, bool, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, true, nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, true, err
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return 0, true, ErrNumberOverflow
+		}
+		return int64(u), true, nil
+	}
+
+	return numberToInt64(v)
+}
+
+// addInt64 returns the "n" + "delta", the `ErrNumberOverflow` if it overflows.
+func addInt64(n, delta int64) (int64, error) {
+	sum := n + delta
+	if (delta > 0 && sum < n) || (delta < 0 && sum > n) {
+		return 0, ErrNumberOverflow
+	}
+	return sum, nil
+}
+
+// counter returns the int64 value of the "key", 0 if it's missing.
+func (r *Store) counter(key string) (int64, error) {
+	v := r.Get(key)
+	if v == nil {
+		return 0, nil
+	}
+
+	n, ok, err := toInt64(v)
+	if !ok {
+		return 0, fmt.Errorf(errIntParseFormat, "int64", key, v)
+	}
+	return n, err
+}
+
+// Increment adds the "delta" to the numeric value of the "key", a missing value is 0,
+// and saves and returns the new value as int64, i.e for the counters of the login attempts.
+// It returns an error if the value is not a number, the `ErrNumberOverflow` if the result overflows
+// and the `ErrEntryImmutable` if the entry is immutable.
+func (r *Store) Increment(key string, delta int64) (int64, error) {
+	if r.isImmutable(key) {
+		return 0, ErrEntryImmutable
+	}
+
+	n, err := r.counter(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err = addInt64(n, delta); err != nil {
+		return 0, err
+	}
+
+	r.Set(key, n)
+	return n, nil
+}
+
+// Decrement subtracts the "delta" from the numeric value of the "key", see `Increment`.
+func (r *Store) Decrement(key string, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNumberOverflow
+	}
+	return r.Increment(key, -delta)
+}
+
+// Increment adds the "delta" to the numeric value of the "key", a missing value is 0,
+// and saves and returns the new value as int64, i.e for the counters of the login attempts and the rate limits.
+// The increment is atomic, the concurrent increments of the session's requests are not lost,
+// and if a session database implements the `Incrementer`, i.e the redis one,
+// it's pushed down to it, so the increments of the other app instances are not lost either.
+// Use the `Increment` consistently for the counters, a `Set` of the key is not seen by the database's counter.
+//
+// It returns an error if the value is not a number, the `ErrNumberOverflow` if the result overflows
+// and the `ErrEntryImmutable` if the entry is immutable.
+func (s *Session) Increment(key string, delta int64) (int64, error) {
+	var n int64
+	err := s.update(key, false, func(key string) (interface{}, error) {
+		if s.values.isImmutable(key) {
+			return nil, ErrEntryImmutable
+		}
+
+		current, err := s.values.counter(key)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, db := range s.provider.databases {
+			if incrementer, ok := db.(Incrementer); ok {
+				n, err = incrementer.Increment(s.sid, key, current, delta)
+				return n, err
+			}
+		}
+
+		n, err = addInt64(current, delta)
+		return n, err
+	})
+
+	return n, err
+}
+
+// Decrement subtracts the "delta" from the numeric value of the "key", see `Increment`.
+func (s *Session) Decrement(key string, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrNumberOverflow
+	}
+	return s.Increment(key, -delta)
+}
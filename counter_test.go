@@ -0,0 +1,86 @@
+package sessions
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+// incrementerDatabase is a session database with shared counters, as the ones of another app instance.
+type incrementerDatabase struct {
+	*concurrentDatabase
+	counters map[string]int64
+}
+
+func (db *incrementerDatabase) Increment(sid, key string, current, delta int64) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	n, ok := db.counters[sid+key]
+	if !ok {
+		n = current
+	}
+	n += delta
+	db.counters[sid+key] = n
+	return n, nil
+}
+
+func TestStoreIncrement(t *testing.T) {
+	var store Store
+	if n, err := store.Increment("attempts", 2); err != nil || n != 2 {
+		t.Fatalf("expected 2 but got %d, %v", n, err)
+	}
+
+	store.Set("int", 5)
+	if n, err := store.Decrement("int", 1); err != nil || n != 4 {
+		t.Fatalf("expected 4 but got %d, %v", n, err)
+	}
+
+	store.Set("max", int64(math.MaxInt64))
+	if _, err := store.Increment("max", 1); err != ErrNumberOverflow {
+		t.Fatalf("expected %v but got %v", ErrNumberOverflow, err)
+	}
+
+	store.Set("name", "kataras")
+	if _, err := store.Increment("name", 1); err == nil {
+		t.Fatalf("expected an error on a non-numeric value")
+	}
+
+	store.SetImmutable("frozen", 1)
+	if _, err := store.Increment("frozen", 1); err != ErrEntryImmutable {
+		t.Fatalf("expected %v but got %v", ErrEntryImmutable, err)
+	}
+}
+
+func TestSessionIncrement(t *testing.T) {
+	manager := New(Config{})
+	sess := manager.provider.Init("sid", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sess.Increment("hits", 1)
+		}()
+	}
+	wg.Wait()
+
+	if n, _ := sess.GetInt64("hits"); n != 50 {
+		t.Fatalf("expected the concurrent increments to be kept but got %d", n)
+	}
+
+	db := &incrementerDatabase{concurrentDatabase: newConcurrentDatabase(), counters: make(map[string]int64)}
+	manager.UseDatabase(db)
+	db.counters["sidhits"] = 100 // incremented by another app instance.
+
+	if n, err := sess.Increment("hits", 1); err != nil || n != 101 {
+		t.Fatalf("expected the database's counter to be used but got %d, %v", n, err)
+	}
+	if n, _ := sess.GetInt64("hits"); n != 101 {
+		t.Fatalf("expected the session's value to be updated but got %d", n)
+	}
+	if n, _ := sess.Decrement("attempts", 1); n != -1 || db.counters["sidattempts"] != -1 {
+		t.Fatalf("expected a missing counter to be initialized by the session's value but got %d", n)
+	}
+}
@@ -0,0 +1,131 @@
+package sessions
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// CSRFTokenKey is the session key which the CSRF token is stored to, see `Session#CSRFToken`.
+	CSRFTokenKey = "csrf_token"
+	// CSRFHeaderKey is the request header which carries the CSRF token, i.e of the AJAX requests.
+	CSRFHeaderKey = "X-CSRF-Token"
+	// CSRFFormKey is the form field which carries the CSRF token, i.e of the html forms.
+	CSRFFormKey = "csrf_token"
+)
+
+// ErrInvalidCSRFToken returned by the `VerifyCSRF` when the request's CSRF token
+// is missing or it doesn't match the token of its session.
+var ErrInvalidCSRFToken = errors.New("sessions: the CSRF token is missing or invalid")
+
+// CSRFToken returns the CSRF token of the session, it's generated on its first call
+// and stored to the session's `CSRFTokenKey`, so it's saved to the session databases too.
+// The token should be rendered to the html forms as the `CSRFFormKey` field
+// or sent by the AJAX requests as the `CSRFHeaderKey` header, see `Sessions#VerifyCSRF`.
+//
+// The token is written as any other entry, an empty token is returned if it can't be,
+// i.e the session is read-only, see `SetReadOnly`, or it exceeds the `Config#MaxSessionSize`.
+func (s *Session) CSRFToken() string {
+	key := s.key(CSRFTokenKey)
+
+	s.mu.RLock()
+	token, _ := s.values.Get(key).(string)
+	s.mu.RUnlock()
+	if token != "" {
+		return token
+	}
+
+	err := s.update(CSRFTokenKey, false, func(key string) (interface{}, error) {
+		// generated under the lock, so the concurrent requests of the session receive the same token.
+		if current, ok := s.values.Get(key).(string); ok && current != "" {
+			token = current
+		} else {
+			token = randomToken()
+		}
+		return token, nil
+	})
+	if err != nil {
+		s.provider.logger().Warnf("sessions: the CSRF token of the session %s is not saved: %v", s.ID(), err)
+		return ""
+	}
+
+	return token
+}
+
+// isSafeMethod reports whether the http method doesn't change the state of the server,
+// the requests of the safe methods are not verified.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// verifyCSRF compares the request's "token" with the token of the session of the "cookieValue".
+func (s *Sessions) verifyCSRF(cookieValue, token string) error {
+	sid := s.decodeCookieValue(cookieValue)
+	if sid == "" || token == "" {
+		return ErrInvalidCSRFToken
+	}
+
+	// the session is not created for an unknown session id, i.e of a forged cookie.
+	sess, ok := s.provider.Lookup(sid, s.config.Expires)
+	if !ok {
+		return ErrInvalidCSRFToken
+	}
+
+	expected := sess.GetString(CSRFTokenKey)
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(token)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+
+	return nil
+}
+
+// VerifyCSRF verifies the CSRF token of the request, see `Sessions#VerifyCSRF`.
+func VerifyCSRF(r *http.Request) error {
+	return Default.VerifyCSRF(r)
+}
+
+// VerifyCSRF verifies the CSRF token of the request, the `CSRFHeaderKey` header
+// or, if it's missing, the `CSRFFormKey` form field, against the `Session#CSRFToken` of its session.
+// The requests of the safe methods, GET, HEAD, OPTIONS and TRACE, are not verified.
+// It returns the `ErrInvalidCSRFToken` if the token is missing or it doesn't match.
+//
+// See the "middleware/csrf" subpackage too.
+func (s *Sessions) VerifyCSRF(r *http.Request) error {
+	if isSafeMethod(r.Method) {
+		return nil
+	}
+
+	token := r.Header.Get(CSRFHeaderKey)
+	if token == "" {
+		token = r.PostFormValue(CSRFFormKey)
+	}
+
+	return s.verifyCSRF(s.requestValue(r), token)
+}
+
+// VerifyCSRFFasthttp verifies the CSRF token of the request, see `Sessions#VerifyCSRF`.
+func VerifyCSRFFasthttp(ctx *fasthttp.RequestCtx) error {
+	return Default.VerifyCSRFFasthttp(ctx)
+}
+
+// VerifyCSRFFasthttp verifies the CSRF token of the request, see `Sessions#VerifyCSRF`.
+func (s *Sessions) VerifyCSRFFasthttp(ctx *fasthttp.RequestCtx) error {
+	if isSafeMethod(string(ctx.Method())) {
+		return nil
+	}
+
+	token := string(ctx.Request.Header.Peek(CSRFHeaderKey))
+	if token == "" {
+		token = string(ctx.PostArgs().Peek(CSRFFormKey))
+	}
+
+	return s.verifyCSRF(s.requestValueFasthttp(ctx), token)
+}
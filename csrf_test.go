@@ -0,0 +1,103 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	manager := New(Config{})
+
+	r := httptest.NewRequest(http.MethodGet, "/form", nil)
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	cookie := w.Result().Cookies()[0]
+
+	token := sess.CSRFToken()
+	if token == "" || sess.CSRFToken() != token {
+		t.Fatalf("expected the same token on each call but got %q and %q", token, sess.CSRFToken())
+	}
+
+	if err := manager.VerifyCSRF(r); err != nil {
+		t.Fatalf("expected the safe methods to be skipped but got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/form", nil)
+	r.AddCookie(cookie)
+	if err := manager.VerifyCSRF(r); err != ErrInvalidCSRFToken {
+		t.Fatalf("expected a missing token to be rejected but got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/form", nil)
+	r.AddCookie(cookie)
+	r.Header.Set(CSRFHeaderKey, token+"x")
+	if err := manager.VerifyCSRF(r); err != ErrInvalidCSRFToken {
+		t.Fatalf("expected an invalid token to be rejected but got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/form", nil)
+	r.Header.Set(CSRFHeaderKey, token)
+	if err := manager.VerifyCSRF(r); err != ErrInvalidCSRFToken {
+		t.Fatalf("expected a request without a session to be rejected but got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/form", nil)
+	r.AddCookie(cookie)
+	r.Header.Set(CSRFHeaderKey, token)
+	if err := manager.VerifyCSRF(r); err != nil {
+		t.Fatalf("expected the header's token to be accepted but got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(url.Values{CSRFFormKey: {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(cookie)
+	if err := manager.VerifyCSRF(r); err != nil {
+		t.Fatalf("expected the form's token to be accepted but got: %v", err)
+	}
+}
+
+func TestCSRFUnknownSession(t *testing.T) {
+	manager := New(Config{})
+
+	for i := 0; i < 5; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/form", nil)
+		r.AddCookie(&http.Cookie{Name: manager.config.Cookie, Value: "forged" + strconv.Itoa(i)})
+		r.Header.Set(CSRFHeaderKey, "token")
+		if err := manager.VerifyCSRF(r); err != ErrInvalidCSRFToken {
+			t.Fatalf("expected the token of an unknown session to be rejected but got: %v", err)
+		}
+	}
+
+	if n := manager.provider.sessions.len(); n != 0 {
+		t.Fatalf("expected no sessions to be created by the forged cookies but got %d", n)
+	}
+}
+
+func TestCSRFTokenWrite(t *testing.T) {
+	manager := New(Config{})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/form", nil))
+	sess.SetReadOnly()
+	if token := sess.CSRFToken(); token != "" {
+		t.Fatalf("expected no token to be written to a read-only session but got %q", token)
+	}
+
+	sess.readOnly = false
+	token := sess.CSRFToken()
+	stored := db.Load(sess.ID())
+	if got := stored.Values.GetString(CSRFTokenKey); token == "" || got != token {
+		t.Fatalf("expected the token %q to be synced to the database but got %q", token, got)
+	}
+
+	// the token is a write of the request.
+	sess.Rollback()
+	if sess.Exists(CSRFTokenKey) {
+		t.Fatalf("expected the token to be removed by the rollback")
+	}
+}
@@ -1,10 +1,10 @@
 package sessions
 
 import (
-	"bytes"
 	"encoding/gob"
-	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 func init() {
@@ -46,6 +46,17 @@ type Database interface {
 	Sync(p SyncPayload)
 }
 
+// Scanner is an optional interface of a `Database` which can iterate over its stored sessions,
+// it's used by the `Sessions#Visit` and `Sessions#Count` to list the sessions
+// which are not loaded to the memory, i.e the sessions of a previous app run.
+type Scanner interface {
+	// Scan calls the "visitor" for each stored, non-expired, session,
+	// the visitor can return false to stop the iteration.
+	// The visitor should be called outside of the database's locks and transactions,
+	// the `Sessions#Visit` visitors may write to it, i.e destroy the visited sessions.
+	Scan(visitor func(sid string, store RemoteStore) bool)
+}
+
 // Action reports the specific action that the memory store
 // sends to the database.
 type Action uint32
@@ -87,13 +98,19 @@ type SyncPayload struct {
 	// the database has access to the whole session's data
 	// every time.
 	Store RemoteStore
+
+	// session is the synced session, see `VersionedDatabase`.
+	session *Session
 }
 
 var spPool = sync.Pool{New: func() interface{} { return SyncPayload{} }}
 
 func acquireSyncPayload(session *Session, action Action) SyncPayload {
 	p := spPool.Get().(SyncPayload)
+
+	session.mu.RLock()
 	p.SessionID = session.sid
+	p.session = session
 
 	// clone the life time, except the timer.
 	// lifetime := LifeTime{
@@ -103,10 +120,16 @@ func acquireSyncPayload(session *Session, action Action) SyncPayload {
 
 	// lifetime := acquireLifetime(session.lifetime.OriginalDuration, nil)
 
+	// the values are copied, the databases may read them
+	// while the session is modified by other requests.
 	p.Store = RemoteStore{
-		Values:   session.values,
-		Lifetime: session.lifetime,
+		Values:     append(Store(nil), session.values...),
+		Lifetime:   session.lifetime,
+		CreatedAt:  session.createdAt,
+		Version:    atomic.AddUint64(&session.version, 1),
+		Extensions: session.extensions,
 	}
+	session.mu.RUnlock()
 
 	p.Action = action
 	return p
@@ -115,6 +138,7 @@ func acquireSyncPayload(session *Session, action Action) SyncPayload {
 func releaseSyncPayload(p SyncPayload) {
 	p.Value.Key = ""
 	p.Value.ValueRaw = nil
+	p.session = nil
 
 	// releaseLifetime(p.Store.Lifetime)
 	spPool.Put(p)
@@ -122,6 +146,10 @@ func releaseSyncPayload(p SyncPayload) {
 
 func syncDatabases(databases []Database, payload SyncPayload) {
 	for i, n := 0, len(databases); i < n; i++ {
+		if db, ok := databases[i].(VersionedDatabase); ok && payload.versioned() {
+			payload.session.syncVersion(db, payload)
+			continue
+		}
 		databases[i].Sync(payload)
 	}
 	releaseSyncPayload(payload)
@@ -143,27 +171,80 @@ type RemoteStore struct {
 	// on clear it will be zero
 	// on destroy it will be zero
 	Lifetime LifeTime
+	// CreatedAt is the creation time of the session,
+	// it's used to enforce the `Config#MaxAbsoluteLifetime` when the session is loaded.
+	CreatedAt time.Time
+	// Version is the version of the session, it's incremented on each sync,
+	// see `VersionedDatabase` and `Config#OptimisticConcurrency`.
+	Version uint64
+	// Extensions is the number of the expiration extensions of the session,
+	// it's used to enforce the `Config#MaxExtensions` when the session is loaded.
+	Extensions int
 }
 
-// Serialize returns the byte representation of this RemoteStore.
+// Serialize returns the byte representation of this RemoteStore,
+// based on the `DefaultTranscoder`, prefixed by the `FormatVersion` if it's a `FormatVersioner`.
 func (s RemoteStore) Serialize() ([]byte, error) {
-	w := new(bytes.Buffer)
-	err := encode(s, w)
-	return w.Bytes(), err
+	values, err := encodeHooks(s.Values)
+	if err != nil {
+		return nil, err
+	}
+	s.Values = values
+
+	return marshalRemoteStore(DefaultTranscoder, s)
 }
 
-// encode accepts a store and writes
-// as series of bytes to the "w" writer.
-func encode(s RemoteStore, w io.Writer) error {
-	enc := gob.NewEncoder(w)
-	err := enc.Encode(s)
-	return err
+// marshalRemoteStore encodes the "s" by the "t", prefixed by the `FormatVersion` if it's a `FormatVersioner`.
+func marshalRemoteStore(t Transcoder, s RemoteStore) ([]byte, error) {
+	b, err := t.Marshal(s)
+	if err != nil || !formatVersioned(t) {
+		return b, err
+	}
+	return withFormatVersion(b), nil
+}
+
+// SerializeWith same as `Serialize` but it uses the "t" transcoder instead of the `DefaultTranscoder`,
+// if "t" is nil then it's the same as `Serialize`.
+// It's used by session databases which are configured with a custom transcoder.
+func (s RemoteStore) SerializeWith(t Transcoder) ([]byte, error) {
+	if t == nil {
+		return s.Serialize()
+	}
+
+	values, err := encodeHooks(s.Values)
+	if err != nil {
+		return nil, err
+	}
+	s.Values = values
+
+	return marshalRemoteStore(t, s)
 }
 
 // DecodeRemoteStore accepts a series of bytes and returns
-// the store.
+// the store, based on the `DefaultTranscoder`.
 func DecodeRemoteStore(b []byte) (store RemoteStore, err error) {
-	dec := gob.NewDecoder(bytes.NewBuffer(b))
-	err = dec.Decode(&store)
+	return DecodeRemoteStoreWith(DefaultTranscoder, b)
+}
+
+// DecodeRemoteStoreWith same as `DecodeRemoteStore` but it uses the "t" transcoder instead,
+// if "t" is nil then it's the same as `DecodeRemoteStore`.
+// The stores of an older `FormatVersion` of a `FormatVersioner` "t" are decoded by its migration, if any, see `RegisterMigration`.
+func DecodeRemoteStoreWith(t Transcoder, b []byte) (store RemoteStore, err error) {
+	if t == nil {
+		t = DefaultTranscoder
+	}
+
+	if formatVersioned(t) {
+		if migrated, ok, err := migrateFormat(b); ok {
+			return migrated, err
+		}
+		_, b = formatVersionOf(b)
+	}
+
+	if err = t.Unmarshal(b, &store); err != nil {
+		return
+	}
+
+	err = decodeHooks(store.Values)
 	return
 }
@@ -0,0 +1,145 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DebugOptions are the options of the `DebugHandler`.
+type DebugOptions struct {
+	// Redact if not nil it's called for each value of the inspected session and its result is shown instead,
+	// i.e to hide the tokens and the personal data, see `RedactValues`.
+	//
+	// Defaults to nil, the values are shown as they are
+	Redact func(key string, value interface{}) interface{}
+	// ReadOnly if true then the sessions can't be deleted through the handler.
+	//
+	// Defaults to false
+	ReadOnly bool
+}
+
+// RedactValues can be used as the `DebugOptions#Redact` to show the keys of the sessions without their values.
+func RedactValues(key string, value interface{}) interface{} {
+	return "[redacted]"
+}
+
+// DebugSession is the JSON view of a session of the `DebugHandler`.
+type DebugSession struct {
+	ID string `json:"id"`
+	// ExpiresAt is zero if the session doesn't expire, TTL is its remaining lifetime in seconds.
+	ExpiresAt time.Time `json:"expiresAt"`
+	TTL       int64     `json:"ttl"`
+	// Keys is the number of the session's values.
+	Keys int `json:"keys"`
+	// Values are the session's values, they are shown on a single session's inspection only.
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+// DebugHandler returns an admin http handler which inspects the sessions of the "manager",
+// it's opt-in and it should be served behind the authentication of the app's administrators,
+// the session ids and values give access to the users' sessions.
+//
+// Routes, relative to the path the handler is mounted at:
+// GET / lists the active sessions, see `Sessions#Visit`.
+// GET /{sid} shows the session of the "sid" and its values, see `DebugOptions#Redact`.
+// DELETE /{sid} destroys the session of the "sid", see `Sessions#DestroyByID` and `DebugOptions#ReadOnly`.
+//
+// Usage:
+// mux.Handle("/debug/sessions/", http.StripPrefix("/debug/sessions", requireAdmin(sessions.DebugHandler(manager))))
+func DebugHandler(manager *Sessions, opts ...DebugOptions) http.Handler {
+	var o DebugOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sid := strings.Trim(r.URL.Path, "/")
+
+		switch {
+		case sid == "" && r.Method == http.MethodGet:
+			var list []DebugSession
+			manager.Visit(func(_ string, sess *Session) bool {
+				list = append(list, debugSession(sess, nil))
+				return true
+			})
+
+			sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+			if list == nil {
+				list = []DebugSession{}
+			}
+			writeDebugJSON(w, http.StatusOK, list)
+		case sid == "":
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		case r.Method == http.MethodGet:
+			sess, found := manager.provider.Find(sid)
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+
+			redact := o.Redact
+			if redact == nil {
+				redact = func(_ string, value interface{}) interface{} { return value }
+			}
+			writeDebugJSON(w, http.StatusOK, debugSession(sess, redact))
+		case r.Method == http.MethodDelete && !o.ReadOnly:
+			if _, found := manager.provider.Find(sid); !found {
+				http.NotFound(w, r)
+				return
+			}
+
+			manager.DestroyByID(sid)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			allow := http.MethodGet
+			if !o.ReadOnly {
+				allow += ", " + http.MethodDelete
+			}
+			w.Header().Set("Allow", allow)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// debugSession returns the view of the "sess", its values are included if "redact" is not nil.
+func debugSession(sess *Session, redact func(key string, value interface{}) interface{}) DebugSession {
+	sess.mu.RLock()
+	view := DebugSession{ID: sess.sid, ExpiresAt: sess.lifetime.Time, Keys: len(sess.values)}
+	values := sess.values
+	if redact != nil {
+		values = append(Store(nil), sess.values...)
+	}
+	sess.mu.RUnlock()
+
+	if redact != nil {
+		view.Values = make(map[string]interface{}, len(values))
+		for _, kv := range values {
+			value := redact(kv.Key, kv.Value())
+			if _, err := json.Marshal(value); err != nil {
+				// i.e a func or a channel, show its representation instead.
+				value = fmt.Sprintf("%v", value)
+			}
+			view.Values[kv.Key] = value
+		}
+	}
+
+	if !view.ExpiresAt.IsZero() {
+		if ttl := view.ExpiresAt.Sub(sess.provider.now()); ttl > 0 {
+			view.TTL = int64(ttl / time.Second)
+		}
+	}
+
+	return view
+}
+
+func writeDebugJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,101 @@
+package sessions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	sess := manager.provider.Init("sid", manager.config.Expires)
+	sess.Set("token", "secret")
+
+	h := DebugHandler(manager, DebugOptions{Redact: RedactValues})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var list []DebugSession
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "sid" || list[0].Keys != 1 || list[0].Values != nil {
+		t.Fatalf("expected the session to be listed without its values but got %+v", list)
+	}
+	if list[0].TTL <= 0 || list[0].TTL > int64(time.Hour/time.Second) {
+		t.Fatalf("expected the remaining lifetime of the session but got %d", list[0].TTL)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sid", nil))
+	var view DebugSession
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if got := view.Values["token"]; got != "[redacted]" {
+		t.Fatalf("expected the value to be redacted but got %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d but got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	DebugHandler(manager, DebugOptions{ReadOnly: true}).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/sid", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected %d on a read-only handler but got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/sid", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d but got %d", http.StatusNoContent, w.Code)
+	}
+	if manager.Count() != 0 {
+		t.Fatalf("expected the session to be destroyed")
+	}
+}
+
+func TestDebugHandlerStored(t *testing.T) {
+	db := &countingScanner{concurrentDatabase: newConcurrentDatabase()}
+	var values Store
+	values.Set("user", "kataras")
+	db.Sync(SyncPayload{SessionID: "stored", Action: ActionInsert, Store: RemoteStore{Values: values}})
+	db.Sync(SyncPayload{SessionID: "other", Action: ActionInsert, Store: RemoteStore{Values: values}})
+
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(db)
+	h := DebugHandler(manager, DebugOptions{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stored", nil))
+	var view DebugSession
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.ID != "stored" || view.Values["user"] != "kataras" {
+		t.Fatalf("expected the stored session but got %+v", view)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/stored", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d but got %d", http.StatusNoContent, w.Code)
+	}
+	if _, ok := db.stores["stored"]; ok {
+		t.Fatalf("expected the stored session to be destroyed")
+	}
+
+	// the session is loaded directly, the database is not scanned
+	// and the session is not loaded to the memory.
+	if db.scanned != 0 {
+		t.Fatalf("expected no scan of the database but %d sessions were scanned", db.scanned)
+	}
+	if _, found := manager.provider.sessions.get("stored"); found {
+		t.Fatalf("expected the stored session not to be loaded to the memory")
+	}
+}
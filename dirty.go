@@ -0,0 +1,54 @@
+package sessions
+
+import (
+	"reflect"
+)
+
+// Dirty reports whether the session's values were modified since the `Start` of the request,
+// the session databases are synced on each modification and only then,
+// the writes which don't change anything, i.e a `Set` of the same string or a `Delete` of a missing key, are skipped.
+// The `CookieStore#Save` uses it to skip the encoding of an unmodified session.
+func (s *Session) Dirty() bool {
+	s.mu.RLock()
+	dirty := s.dirty
+	s.mu.RUnlock()
+	return dirty
+}
+
+// unchanged reports whether a save of the "value" to the "key" doesn't change the store,
+// the entry exists and its value is the same scalar, i.e a string or a number,
+// or the entry is immutable and the save is not, so it's ignored, see `Save`.
+// The references, i.e pointers and slices, are always reported as changed,
+// their pointed values may be modified in place.
+func (r *Store) unchanged(key string, value interface{}, immutable bool) bool {
+	for _, kv := range *r {
+		if kv.Key != key {
+			continue
+		}
+
+		if kv.immutable && !immutable {
+			return true
+		}
+
+		return kv.immutable == immutable && isScalar(value) && kv.ValueRaw == value
+	}
+
+	return false
+}
+
+// isScalar reports whether the "v" is a boolean, a number or a string.
+func isScalar(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,107 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingDatabase struct {
+	*concurrentDatabase
+	syncs int32
+}
+
+func (db *countingDatabase) Sync(p SyncPayload) {
+	atomic.AddInt32(&db.syncs, 1)
+	db.concurrentDatabase.Sync(p)
+}
+
+func TestDirty(t *testing.T) {
+	manager := New(Config{})
+	db := &countingDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if sess.Dirty() {
+		t.Fatalf("expected a new session to not be dirty")
+	}
+
+	sess.Set("name", "kataras")
+	sess.SetImmutable("role", "admin")
+	syncs := atomic.LoadInt32(&db.syncs)
+
+	sess.Set("name", "kataras")
+	sess.Set("role", "user")
+	sess.Delete("missing")
+	if got := atomic.LoadInt32(&db.syncs); got != syncs {
+		t.Fatalf("expected the unchanged writes to not be synced but got %d syncs", got-syncs)
+	}
+
+	// the references may be modified in place, they are always synced.
+	list := []string{"a"}
+	sess.Set("list", list)
+	sess.Set("list", list)
+	if got := atomic.LoadInt32(&db.syncs); got != syncs+2 {
+		t.Fatalf("expected the references to be synced but got %d syncs", got-syncs)
+	}
+
+	if !sess.Dirty() {
+		t.Fatalf("expected the modified session to be dirty")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	next := manager.Start(httptest.NewRecorder(), r)
+	if next.Dirty() {
+		t.Fatalf("expected the next request to start clean")
+	}
+	if !sess.Dirty() {
+		t.Fatalf("expected the start of the next request to not reset the dirty state of the previous one")
+	}
+
+	next.Set("name", "makis")
+	if !next.Dirty() {
+		t.Fatalf("expected the modified session of the next request to be dirty")
+	}
+}
+
+func TestCookieStoreUnmodified(t *testing.T) {
+	store, err := NewCookieStore(Config{}, [][]byte{[]byte(strings.Repeat("h", 32))}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	sess := store.Start(w, r)
+	sess.Set("name", "kataras")
+	if err = store.Save(w, r, sess); err != nil {
+		t.Fatal(err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	w = httptest.NewRecorder()
+	sess = store.Start(w, r)
+	if sess.GetString("name") != "kataras" {
+		t.Fatalf("expected the session to be loaded from the cookie")
+	}
+	if err = store.Save(w, r, sess); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatalf("expected the cookie of the unmodified session to be kept")
+	}
+
+	sess.Set("name", "other")
+	w = httptest.NewRecorder()
+	if err = store.Save(w, r, sess); err != nil || len(w.Result().Cookies()) != 1 {
+		t.Fatalf("expected the modified session to be written")
+	}
+}
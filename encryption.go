@@ -0,0 +1,143 @@
+package sessions
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrEncryptionKeyMissing returned by the `NewEncryptionTranscoder` when no key was given.
+	ErrEncryptionKeyMissing = errors.New("encryption: at least one key is required")
+	// ErrDecryption returned by the `EncryptionTranscoder` when the data
+	// cannot be decrypted by any of its keys or when they are not encrypted at all.
+	ErrDecryption = errors.New("encryption: unable to decrypt the session data")
+)
+
+// encryptionMagic is the header of the encrypted data, followed by the nonce.
+var encryptionMagic = []byte("gse")
+
+// EncryptionTranscoder is a transcoder which encrypts the output
+// of another transcoder with AES-GCM, so the session contents which are stored
+// to a shared infrastructure, i.e redis, cannot be read by its operators.
+//
+// Keys can be rotated, the first key encrypts the data and all keys
+// are tried to decrypt them, so a new key can be put first
+// while the previous ones are still accepted until the old sessions expire.
+//
+// Usage:
+// t, err := sessions.NewEncryptionTranscoder(sessions.GobTranscoder{}, newKey, oldKey)
+// sessions.DefaultTranscoder = t
+type EncryptionTranscoder struct {
+	// Transcoder is the underline transcoder,
+	// it can be a `CompressionTranscoder` as well, compression should happen before encryption.
+	Transcoder Transcoder
+	// AllowUnencrypted set it to true in order to decode
+	// the data which were written before the encryption was enabled.
+	//
+	// Defaults to false, unencrypted data are rejected with an `ErrDecryption`.
+	AllowUnencrypted bool
+
+	aeads []cipher.AEAD
+}
+
+var _ Transcoder = (*EncryptionTranscoder)(nil)
+
+// NewEncryptionTranscoder returns a new transcoder which encrypts
+// the output of the "t" transcoder with the first of the "keys"
+// and decrypts with any of them.
+// Each key should be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+// If "t" is nil then the `GobTranscoder` is used instead.
+func NewEncryptionTranscoder(t Transcoder, keys ...[]byte) (*EncryptionTranscoder, error) {
+	if len(keys) == 0 {
+		return nil, ErrEncryptionKeyMissing
+	}
+
+	if t == nil {
+		t = GobTranscoder{}
+	}
+
+	aeads, err := newAEADs(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptionTranscoder{Transcoder: t, aeads: aeads}, nil
+}
+
+// newAEADs returns the AES-GCM ciphers of the "keys".
+func newAEADs(keys [][]byte) ([]cipher.AEAD, error) {
+	aeads := make([]cipher.AEAD, 0, len(keys))
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		aeads = append(aeads, aead)
+	}
+
+	return aeads, nil
+}
+
+// Marshal returns the encrypted output of the underline transcoder.
+func (t *EncryptionTranscoder) Marshal(value interface{}) ([]byte, error) {
+	b, err := t.Transcoder.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	aead := t.aeads[0]
+	nonceSize := aead.NonceSize()
+
+	out := make([]byte, len(encryptionMagic)+nonceSize, len(encryptionMagic)+nonceSize+len(b)+aead.Overhead())
+	copy(out, encryptionMagic)
+	nonce := out[len(encryptionMagic):]
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, nonce, b, encryptionMagic), nil
+}
+
+// Unmarshal decrypts the "b" and passes it to the underline transcoder.
+func (t *EncryptionTranscoder) Unmarshal(b []byte, outPtr interface{}) error {
+	n := len(encryptionMagic)
+	if len(b) < n || !bytes.Equal(b[:n], encryptionMagic) {
+		if t.AllowUnencrypted {
+			return t.Transcoder.Unmarshal(b, outPtr)
+		}
+		return ErrDecryption
+	}
+
+	b = b[n:]
+	for _, aead := range t.aeads {
+		nonceSize := aead.NonceSize()
+		if len(b) < nonceSize {
+			continue
+		}
+
+		decrypted, err := aead.Open(nil, b[:nonceSize], b[nonceSize:], encryptionMagic)
+		if err != nil {
+			continue
+		}
+
+		return t.Transcoder.Unmarshal(decrypted, outPtr)
+	}
+
+	return ErrDecryption
+}
+
+// FormatVersioned reports whether the underline transcoder is a `FormatVersioner`,
+// the header of the `FormatVersion` is written before the encrypted data.
+func (t *EncryptionTranscoder) FormatVersioned() bool {
+	return formatVersioned(t.Transcoder)
+}
@@ -0,0 +1,229 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExtensionBudget(t *testing.T) {
+	manager := New(Config{Expires: time.Minute, MaxExtensions: 2, MaxExtendedLifetime: 90 * time.Second})
+	sess := manager.provider.Init("sid", time.Minute)
+	defer manager.DestroyByID("sid")
+
+	expires, ok := manager.provider.UpdateExpiration("sid", time.Minute)
+	if !ok || expires != time.Minute {
+		t.Fatalf("expected the session to be extended by a minute but got %s", expires)
+	}
+
+	expires, ok = manager.provider.UpdateExpiration("sid", 2*time.Minute)
+	if !ok || expires > 90*time.Second || expires < 89*time.Second {
+		t.Fatalf("expected the extension to be shortened to the max extended lifetime but got %s", expires)
+	}
+
+	if until := sess.lifetime.Sub(time.Now()); until > 90*time.Second {
+		t.Fatalf("expected the session's lifetime to be shifted but it expires in %s", until)
+	}
+
+	if _, ok = manager.provider.UpdateExpiration("sid", time.Minute); ok {
+		t.Fatalf("expected the session to not be extended more than the max extensions")
+	}
+}
+
+func TestExtensionBudgetStored(t *testing.T) {
+	cfg := Config{Expires: time.Minute, MaxExtensions: 2}
+	db := newConcurrentDatabase()
+	manager := New(cfg)
+	manager.UseDatabase(db)
+
+	manager.provider.Init("sid", time.Minute).Set("name", "kataras")
+	for i := 0; i < 2; i++ {
+		if _, ok := manager.provider.UpdateExpiration("sid", time.Minute); !ok {
+			t.Fatalf("expected the extension %d to be allowed", i+1)
+		}
+	}
+
+	if n := db.Load("sid").Extensions; n != 2 {
+		t.Fatalf("expected the extensions to be stored but got %d", n)
+	}
+
+	// the app is restarted, the budget is not reset.
+	restarted := New(cfg)
+	restarted.UseDatabase(db)
+	restarted.provider.Read("sid", time.Minute)
+	if _, ok := restarted.provider.UpdateExpiration("sid", time.Minute); ok {
+		t.Fatalf("expected the stored extensions to count after a restart")
+	}
+}
+
+func TestIdleTimeoutExtensionBudget(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{Expires: time.Hour, IdleTimeout: time.Minute, MaxExtendedLifetime: 2 * time.Minute, Clock: clock})
+	manager.provider.Init("sid", time.Hour).Set("name", "kataras")
+
+	// each request slides the idle timeout, up to the max extended lifetime.
+	for i := 0; i < 3; i++ {
+		clock.Advance(35 * time.Second)
+		manager.provider.Read("sid", time.Hour)
+	}
+
+	sess, ok := manager.provider.Lookup("sid", time.Hour)
+	if !ok {
+		t.Fatalf("expected the session to be alive within the max extended lifetime")
+	}
+	if until := sess.lifetime.Sub(clock.Now()); until != 15*time.Second {
+		t.Fatalf("expected the last slide to be shortened to the max extended lifetime but it expires in %s", until)
+	}
+
+	clock.Advance(15 * time.Second)
+	if _, ok = manager.provider.Lookup("sid", time.Hour); ok {
+		t.Fatalf("expected the session to expire at the max extended lifetime regardless of its activity")
+	}
+
+	manager = New(Config{Expires: time.Hour, IdleTimeout: time.Minute, MaxExtensions: 1, Clock: clock})
+	manager.provider.Init("other", time.Hour).Set("name", "makis")
+	clock.Advance(30 * time.Second)
+	manager.provider.Read("other", time.Hour) // the only extension.
+	clock.Advance(30 * time.Second)
+	manager.provider.Read("other", time.Hour)
+	clock.Advance(31 * time.Second)
+	if _, ok = manager.provider.Lookup("other", time.Hour); ok {
+		t.Fatalf("expected the idle timeout to not slide more than the max extensions")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	manager := New(Config{Expires: time.Hour, IdleTimeout: time.Minute})
+	sess := manager.provider.Init("sid", time.Hour)
+	defer manager.DestroyByID("sid")
+
+	if until := sess.lifetime.Sub(time.Now()); until > time.Minute {
+		t.Fatalf("expected the session to expire after the idle timeout but it expires in %s", until)
+	}
+
+	sess.createdAt = time.Now().Add(-time.Hour + 30*time.Second)
+	manager.provider.Read("sid", time.Hour)
+	if until := sess.lifetime.Sub(time.Now()); until > 30*time.Second {
+		t.Fatalf("expected the touch to be limited by the absolute expiration but it expires in %s", until)
+	}
+}
+
+type testDatabase struct {
+	store   RemoteStore
+	actions []Action
+}
+
+func (db *testDatabase) Load(sid string) RemoteStore { return db.store }
+func (db *testDatabase) Sync(p SyncPayload)          { db.actions = append(db.actions, p.Action) }
+
+func TestMaxAbsoluteLifetime(t *testing.T) {
+	manager := New(Config{Expires: time.Hour, MaxAbsoluteLifetime: 2 * time.Hour})
+	db := &testDatabase{store: RemoteStore{
+		Values:    Store{{Key: "name", ValueRaw: "go-sessions"}},
+		Lifetime:  LifeTime{Time: time.Now().Add(time.Hour)},
+		CreatedAt: time.Now().Add(-90 * time.Minute),
+	}}
+	manager.UseDatabase(db)
+
+	sess := manager.provider.Init("sid", time.Hour)
+	if sess.GetString("name") != "go-sessions" {
+		t.Fatalf("expected the session to be loaded from the database")
+	}
+	if until := sess.lifetime.Sub(time.Now()); until > 30*time.Minute {
+		t.Fatalf("expected the session to expire at its absolute deadline but it expires in %s", until)
+	}
+	if _, ok := manager.provider.UpdateExpiration("sid", time.Hour); !ok {
+		t.Fatalf("expected the session to be extended up to its absolute deadline")
+	}
+	if until := sess.lifetime.Sub(time.Now()); until > 30*time.Minute {
+		t.Fatalf("expected the extension to be limited by the absolute deadline but it expires in %s", until)
+	}
+	manager.DestroyByID("sid")
+
+	db.store.CreatedAt = time.Now().Add(-3 * time.Hour)
+	db.actions = nil
+	sess = manager.provider.Init("sid", time.Hour)
+	defer manager.DestroyByID("sid")
+	if sess.GetString("name") != "" {
+		t.Fatalf("expected the session which exceeded its absolute lifetime to be started over")
+	}
+	if time.Since(sess.createdAt) > time.Minute {
+		t.Fatalf("expected a new creation time but got %s", sess.createdAt)
+	}
+	if len(db.actions) == 0 || db.actions[0] != ActionDestroy {
+		t.Fatalf("expected the stale session to be destroyed from the database but got %v", db.actions)
+	}
+}
+
+func TestSessionContext(t *testing.T) {
+	manager := New(Config{Expires: 50 * time.Millisecond})
+
+	expired := manager.provider.Init("expired", 50*time.Millisecond).Context()
+	select {
+	case <-expired.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the context to be cancelled when the session expires")
+	}
+
+	sess := manager.provider.Init("destroyed", time.Minute)
+	ctx := sess.Context()
+	sess.RegenerateID()
+	if ctx.Err() != nil {
+		t.Fatal("expected the context to be kept on the regeneration of the session id")
+	}
+
+	manager.DestroyByID(sess.ID())
+	if ctx.Err() == nil {
+		t.Fatal("expected the context to be cancelled when the session is destroyed")
+	}
+
+	if sess.Context().Err() == nil {
+		t.Fatal("expected the context of an ended session to be cancelled")
+	}
+}
+
+func TestIdleTimeoutShared(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cfg := Config{Expires: 2 * time.Hour, IdleTimeout: time.Hour, Shared: true, Clock: clock}
+	db := newConcurrentDatabase()
+
+	manager := New(cfg)
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "go-sessions")
+	sid := sess.ID()
+	cookie := w.Result().Cookies()[0]
+
+	start := func(manager *Sessions) *Session {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(cookie)
+		return manager.Start(httptest.NewRecorder(), r)
+	}
+
+	clock.Advance(30 * time.Minute)
+	start(manager)
+
+	expected := clock.Now().Add(time.Hour)
+	if got := sess.lifetime.Time; !got.Equal(expected) {
+		t.Fatalf("expected the idle timeout to slide to %s but it expires at %s", expected, got)
+	}
+	if got := db.Load(sid).Lifetime.Time; !got.Equal(expected) {
+		t.Fatalf("expected the slid lifetime %s to be synced to the database but got %s", expected, got)
+	}
+
+	// the previous deadline passes.
+	clock.Advance(45 * time.Minute)
+	if _, ok := manager.provider.sessions.get(sid); !ok {
+		t.Fatalf("expected the active session to not be expired at its previous deadline")
+	}
+
+	// restarted, or another instance.
+	other := New(cfg)
+	other.UseDatabase(db)
+	if got := start(other); got.ID() != sid || got.GetString("name") != "go-sessions" {
+		t.Fatalf("expected the active session to be loaded by another instance but got %q", got.GetString("name"))
+	}
+}
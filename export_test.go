@@ -0,0 +1,5 @@
+package sessions
+
+// ConcurrentSessions runs the concurrency suite of the race_test.go against the "manager",
+// the backends_test.go runs it against the session databases of the sessiondb.
+var ConcurrentSessions = testConcurrentSessions
@@ -0,0 +1,107 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// FormatVersion is the version of the serialization format of the stored sessions,
+// it's written to the header of each serialized `RemoteStore` of a `FormatVersioner` transcoder,
+// i.e the `DefaultTranscoder`, see `RemoteStore.Serialize`.
+// Increase it when the stored format changes, i.e the keys and the types of the values,
+// and register a migration of the previous versions, see `RegisterMigration`.
+// The sessions which were stored before the format versions have the version 0.
+//
+// Defaults to 1.
+var FormatVersion uint32 = 1
+
+// FormatVersioner is an optional interface of a `Transcoder` which prefixes its encodings
+// by the header of the `FormatVersion`, so the stored sessions can be migrated, see `RegisterMigration`.
+// The `GobTranscoder` implements it, the encodings of the rest of the transcoders are stored as they are,
+// so they can be read by non-Go services.
+type FormatVersioner interface {
+	// FormatVersioned reports whether the header of the `FormatVersion` is written.
+	FormatVersioned() bool
+}
+
+// formatVersioned reports whether the encodings of the "t" have the header of the `FormatVersion`.
+func formatVersioned(t Transcoder) bool {
+	v, ok := t.(FormatVersioner)
+	return ok && v.FormatVersioned()
+}
+
+// formatMagic is the header of the serialized remote stores, followed by their format version.
+var formatMagic = []byte("gsv")
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = map[uint32]func(old []byte) (RemoteStore, error){}
+)
+
+// RegisterMigration registers the migration of the sessions which were stored with the "fromVersion" format,
+// the "migrate" receives their stored data, without the header, and returns them in the current format,
+// i.e decoded by the `DecodeRemoteStore` with their keys renamed.
+// The returned store is loaded as it is, its lifetime, creation time and version should be kept,
+// it's written back with the current `FormatVersion` on its next sync.
+//
+// The stored sessions of a version without a migration are decoded by the transcoder, as they are.
+// The migrations apply to the `FormatVersioner` transcoders only.
+// Migrations should be registered before the session manager's first usage.
+//
+// Usage:
+// sessions.RegisterMigration(1, func(old []byte) (sessions.RemoteStore, error) { return renameKeys(sessions.DecodeRemoteStore(old)) })
+func RegisterMigration(fromVersion uint32, migrate func(old []byte) (RemoteStore, error)) {
+	migrationsMu.Lock()
+	migrations[fromVersion] = migrate
+	migrationsMu.Unlock()
+}
+
+// withFormatVersion returns the "b" prefixed by the header of the current `FormatVersion`.
+func withFormatVersion(b []byte) []byte {
+	out := make([]byte, len(formatMagic)+binary.MaxVarintLen32+len(b))
+	n := copy(out, formatMagic)
+	n += binary.PutUvarint(out[n:], uint64(FormatVersion))
+	n += copy(out[n:], b)
+	return out[:n]
+}
+
+// formatVersionOf returns the format version of the "b" and its data without the header,
+// the data without a header have the version 0.
+func formatVersionOf(b []byte) (uint32, []byte) {
+	n := len(formatMagic)
+	if len(b) <= n || !bytes.Equal(b[:n], formatMagic) {
+		return 0, b
+	}
+
+	version, size := binary.Uvarint(b[n:])
+	if size <= 0 || version > 1<<32-1 {
+		return 0, b
+	}
+
+	return uint32(version), b[n+size:]
+}
+
+// migrateFormat decodes the "b" by the migration of its format version,
+// it reports false if the "b" has the current version or its version has no migration.
+func migrateFormat(b []byte) (RemoteStore, bool, error) {
+	version, data := formatVersionOf(b)
+	if version == FormatVersion {
+		return RemoteStore{}, false, nil
+	}
+
+	migrationsMu.RLock()
+	migrateFn, ok := migrations[version]
+	migrationsMu.RUnlock()
+	if !ok {
+		return RemoteStore{}, false, nil
+	}
+
+	store, err := migrateFn(data)
+	if err != nil {
+		return RemoteStore{}, true, fmt.Errorf("sessions: migration of the format version %d: %v", version, err)
+	}
+
+	return store, true, nil
+}
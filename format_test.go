@@ -0,0 +1,123 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func removeMigration(fromVersion uint32) {
+	migrationsMu.Lock()
+	delete(migrations, fromVersion)
+	migrationsMu.Unlock()
+}
+
+func TestFormatVersion(t *testing.T) {
+	store := RemoteStore{Values: Store{{Key: "name", ValueRaw: "kataras"}}}
+	b, err := store.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version, _ := formatVersionOf(b); !bytes.HasPrefix(b, formatMagic) || version != FormatVersion {
+		t.Fatalf("expected the header of the format version %d but got %q", FormatVersion, b)
+	}
+
+	decoded, err := DecodeRemoteStore(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the decoded value but got %q", got)
+	}
+
+	// the sessions which were stored before the format versions.
+	legacy, err := DefaultTranscoder.Marshal(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err = DecodeRemoteStore(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the legacy session to be decoded as it is but got %q", got)
+	}
+
+	// the transcoders which are not a FormatVersioner are stored as they are, i.e for the non-Go services.
+	b, err = store.SerializeWith(JSONTranscoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(b) {
+		t.Fatalf("expected the json encoding without a header but got %q", b)
+	}
+	if decoded, err = DecodeRemoteStoreWith(JSONTranscoder{}, b); err != nil || decoded.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the json encoding to be decoded but got %v: %v", decoded.Values, err)
+	}
+}
+
+func TestRegisterMigration(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour).Round(0)
+	old := RemoteStore{
+		Values:    Store{{Key: "username", ValueRaw: "kataras"}},
+		Lifetime:  LifeTime{Time: createdAt.Add(2 * time.Hour)},
+		CreatedAt: createdAt,
+		Version:   7,
+	}
+	b, err := old.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the format with the "username" key is replaced by the format with the "name" key.
+	defer func(version uint32) { FormatVersion = version }(FormatVersion)
+	FormatVersion = 2
+
+	defer removeMigration(1)
+	RegisterMigration(1, func(old []byte) (RemoteStore, error) {
+		store, err := DecodeRemoteStore(old)
+		if err != nil {
+			return store, err
+		}
+
+		var values Store
+		store.Values.Visit(func(key string, value interface{}) {
+			if key == "username" {
+				key = "name"
+			}
+			values.Set(key, value)
+		})
+		store.Values = values
+		return store, nil
+	})
+
+	migrated, err := DecodeRemoteStore(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := migrated.Values.GetString("name"); got != "kataras" || migrated.Values.Exists("username") {
+		t.Fatalf("expected the migrated values but got %v", migrated.Values)
+	}
+	if !migrated.CreatedAt.Equal(old.CreatedAt) || !migrated.Lifetime.Time.Equal(old.Lifetime.Time) || migrated.Version != old.Version {
+		t.Fatalf("expected the migrated store to keep its lifetime, creation time and version but got %#v", migrated)
+	}
+
+	// the current version is not migrated.
+	current, err := migrated.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := DecodeRemoteStore(current); err != nil || decoded.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the current version to be decoded by the transcoder but got %v: %v", decoded.Values, err)
+	}
+
+	RegisterMigration(1, func([]byte) (RemoteStore, error) { return RemoteStore{}, errors.New("corrupted") })
+	if _, err = DecodeRemoteStore(b); err == nil || !strings.Contains(err.Error(), "corrupted") {
+		t.Fatalf("expected the migration's error but got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build go1.18
+// +build go1.18
+
+package sessions
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// fuzzStore returns a store with the value kinds which the decoders should handle.
+func fuzzStore() Store {
+	var store Store
+	store.Set("name", "kataras")
+	store.Set("number", 42)
+	store.Set("list", []interface{}{"a", 1.5, true})
+	store.Set("time", time.Unix(0, 0).UTC())
+	store.SetImmutable("tags", []string{"fast", "simple"})
+	return store
+}
+
+// exerciseStore reads the decoded "store" as the handlers and the databases do,
+// the hostile payloads should not panic any of them.
+func exerciseStore(store Store) {
+	for _, kv := range store {
+		kv.Value()
+		store.GetEntry(kv.Key)
+		store.GetSlice(kv.Key)
+		store.GetBytes(kv.Key)
+		store.GetInt64(kv.Key)
+		store.GetBool(kv.Key)
+	}
+	store.SerializeE()
+}
+
+func FuzzGobDeserialize(f *testing.F) {
+	b, err := GobSerialize(fuzzStore())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		store, err := GobDeserialize(b)
+		if err != nil {
+			return
+		}
+		exerciseStore(store)
+	})
+}
+
+func FuzzJSONTranscoder(f *testing.F) {
+	b, err := RemoteStore{Values: fuzzStore(), Version: 1}.SerializeWith(JSONTranscoder{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte(`{"Values":[{"Key":"hook","ValueRaw":{"Hook":"missing","Data":"AA=="}}]}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		store, err := DecodeRemoteStoreWith(JSONTranscoder{}, b)
+		if err != nil {
+			return
+		}
+		store.Lifetime.HasExpired()
+		exerciseStore(store.Values)
+	})
+}
+
+func FuzzCookieValue(f *testing.F) {
+	signer, err := NewCookieSigner([][]byte{[]byte("0123456789abcdef0123456789abcdef")}, [][]byte{[]byte("0123456789abcdef")})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	value, err := signer.Encode(DefaultCookieName, "sid")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(value)
+	f.Add(".")
+	f.Add("a.b.c")
+
+	manager := New(Config{Decode: signer.Decode})
+	f.Fuzz(func(t *testing.T, cookieValue string) {
+		manager.decodeCookieValue(cookieValue)
+	})
+}
+
+func FuzzCookiePayload(f *testing.F) {
+	data, err := RemoteStore{Values: fuzzStore()}.Serialize()
+	if err != nil {
+		f.Fatal(err)
+	}
+	payload := make([]byte, binary.MaxVarintLen64)
+	payload = append(payload[:binary.PutUvarint(payload, 3)], "sid"...)
+	f.Add(string(append(payload, data...)))
+	f.Add(string([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}))
+
+	f.Fuzz(func(t *testing.T, payload string) {
+		_, store, err := decodeCookiePayload(nil, payload)
+		if err != nil {
+			return
+		}
+		exerciseStore(store.Values)
+	})
+}
@@ -0,0 +1,237 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// sessionContextKey is the request context's key of the session, see `FromContext`.
+type sessionContextKey struct{}
+
+// sessionUserValueKey is the fasthttp user value's key of the session,
+// fasthttp accepts only string keys.
+const sessionUserValueKey = "github.com/kataras/go-sessions.session"
+
+// NewContext returns a copy of the "ctx" which carries the "sess",
+// so the session flows through the service layers without the *http.Request, see `FromContext`.
+// A *fasthttp.RequestCtx is returned as it's, the session is stored to its user values.
+func NewContext(ctx context.Context, sess *Session) context.Context {
+	if fctx, ok := ctx.(*fasthttp.RequestCtx); ok {
+		fctx.SetUserValue(sessionUserValueKey, sess)
+		return fctx
+	}
+
+	return context.WithValue(ctx, sessionContextKey{}, sess)
+}
+
+// FromContext returns the session which is stored to the "ctx" by the `NewContext`,
+// i.e by the `Handler` to the request's context or by the `HandlerFasthttp` to the *fasthttp.RequestCtx.
+// It returns nil if the context has no session.
+func FromContext(ctx context.Context) *Session {
+	if fctx, ok := ctx.(*fasthttp.RequestCtx); ok {
+		sess, _ := fctx.UserValue(sessionUserValueKey).(*Session)
+		return sess
+	}
+
+	sess, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return sess
+}
+
+// Handler starts the session of each request of the `Default` manager, see `Sessions#Handler`.
+func Handler(next http.Handler) http.Handler {
+	return Default.Handler(next)
+}
+
+// Handler returns a net/http middleware which starts the session of each request
+// and injects it into the request's context, the handlers read it by the `FromContext`.
+// The session is released when the "next" handler returns, see `Config#SingleWriter`,
+// so the handlers don't have to call the `Start` and the `Session#Release` themselves.
+//
+// The changes of the session are synced to the databases as they happen.
+//
+// Usage:
+// http.ListenAndServe(":8080", manager.Handler(mux))
+// sess := sessions.FromContext(r.Context())
+func (s *Sessions) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := s.Start(w, r)
+		defer sess.Release()
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), sess)))
+	})
+}
+
+// HandlerFasthttp starts the session of each request of the `Default` manager, see `Sessions#HandlerFasthttp`.
+func HandlerFasthttp(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return Default.HandlerFasthttp(next)
+}
+
+// HandlerFasthttp returns a fasthttp middleware which starts the session of each request
+// and stores it to the request's user values, the handlers read it by the `FromContext(ctx)`.
+// The session is released when the "next" handler returns, see `Sessions#Handler`.
+func (s *Sessions) HandlerFasthttp(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		sess := s.StartFasthttp(ctx)
+		defer sess.Release()
+
+		NewContext(ctx, sess)
+		next(ctx)
+	}
+}
+
+// StartContext starts the session of the "value" of the `Default` manager, see `Sessions#StartContext`.
+func StartContext(ctx context.Context, value string, send func(value string)) *Session {
+	return Default.StartContext(ctx, value, send)
+}
+
+// StartContext starts the session of the "value", the encoded session id as it's sent to the clients,
+// for the transports without cookies, i.e the gRPC metadata, see the "middleware/grpc" subpackage.
+// If the "value" is empty or invalid then a new session is created.
+// The "send" is called with the encoded session id when the client should store a new one,
+// i.e of a new session or a regenerated id, see `Config#PrivilegeKeys`.
+//
+// It's the `Start` without the cookies, the session is held by the `Config#SingleWriter`,
+// the `Config#HydratorContext` and the `Config#ReadOnlyContext` are called with the "ctx"
+// and the `Config#RiskScorer` is fed with the client of the `NewClientContext`.
+// The anonymous id and the remember-me login are cookies, they are not used.
+//
+// The session should be released by the `Session#Release`, as the sessions of the `Start`.
+func (s *Sessions) StartContext(ctx context.Context, value string, send func(value string)) *Session {
+	return s.start(ctx, value, &contextRequester{s: s, ctx: ctx, sendValue: send})
+}
+
+// start starts the session of the "value", the encoded session id of the request,
+// it's the `Start`, the `StartFasthttp` and the `StartContext`, they differ only by the "req".
+func (s *Sessions) start(ctx context.Context, value string, req requester) *Session {
+	var (
+		sess    *Session
+		hydrate bool
+	)
+
+	if sid := s.decodeCookieValue(value); sid != "" {
+		sess = s.provider.Read(sid, s.config.Expires)
+		// the session of an unknown or expired id is a new session too.
+		hydrate = sess.empty()
+		if req.restore(sess, true) {
+			req.send(sess.ID(), s.config.Expires)
+		}
+	} else { // the request has no session id, let's generate a session and send its id.
+		sid = s.config.IDGenerator(ctx)
+		sess = s.provider.Init(sid, s.config.Expires)
+		sess.isNew = sess.values.Len() == 0
+		hydrate = sess.isNew
+		req.restore(sess, false)
+		req.send(sid, s.config.Expires)
+	}
+
+	holdCtx := ctx
+	if _, ok := ctx.(*fasthttp.RequestCtx); ok {
+		// its Done is the server's shutdown and it can't be used outside of a server.
+		holdCtx = context.Background()
+	}
+
+	sess = s.hold(holdCtx, sess, req)
+	ip, userAgent := req.client()
+	s.assess(sess, ip, userAgent)
+	req.identify(sess)
+	if req.readOnly() {
+		sess.SetReadOnly()
+	}
+	if hydrate {
+		if values, ok := req.hydrate(); ok {
+			s.hydrate(sess, values)
+		}
+	}
+	sess.beginJournal()
+	return sess
+}
+
+// clientContextKey is the context's key of the client of the `NewClientContext`.
+type clientContextKey struct{}
+
+type clientInfo struct {
+	ip        string
+	userAgent string
+}
+
+// NewClientContext returns a copy of the "ctx" which carries the "ip" and the "userAgent" of the client,
+// the `StartContext` passes them to the `Config#RiskScorer`, i.e the peer's address of a gRPC call.
+func NewClientContext(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, clientInfo{ip: ip, userAgent: userAgent})
+}
+
+// RegenerateIDContext regenerates the id of the session of the "ctx" of the `Default` manager, see `Sessions#RegenerateIDContext`.
+func RegenerateIDContext(ctx context.Context) *Session {
+	return Default.RegenerateIDContext(ctx)
+}
+
+// RegenerateIDContext moves the session of the "ctx", see `NewContext`, to a new session id
+// and sends it to the client of its request, as the `RegenerateID` does,
+// i.e after a login of a service layer.
+// Returns nil if the "ctx" has no session.
+func (s *Sessions) RegenerateIDContext(ctx context.Context) *Session {
+	sess := FromContext(ctx)
+	if sess == nil {
+		return nil
+	}
+
+	sid := sess.regenerateID(ctx)
+	sess.mu.RLock()
+	req := sess.requester
+	sess.mu.RUnlock()
+	if req != nil {
+		req.send(sid, s.config.Expires)
+	}
+	return sess
+}
+
+// UpdateExpirationContext updates the expiration of the session of the "ctx" of the `Default` manager,
+// see `Sessions#UpdateExpirationContext`.
+func UpdateExpirationContext(ctx context.Context, expires time.Duration) bool {
+	return Default.UpdateExpirationContext(ctx, expires)
+}
+
+// UpdateExpirationContext changes the expiration of the session of the "ctx", see `NewContext`,
+// to the "expires" from now, as the `UpdateExpiration` does, the client's cookie is updated too.
+// Returns false if the "ctx" has no session or the session is not alive.
+func (s *Sessions) UpdateExpirationContext(ctx context.Context, expires time.Duration) bool {
+	sess := FromContext(ctx)
+	if sess == nil {
+		return false
+	}
+
+	expires, ok := s.provider.UpdateExpiration(sess.ID(), expires)
+	if !ok {
+		return false
+	}
+
+	sess.mu.RLock()
+	req := sess.requester
+	sess.mu.RUnlock()
+	if req != nil {
+		req.send(sess.ID(), expires)
+	}
+	return true
+}
+
+// DestroyContext destroys the session of the "ctx" of the `Default` manager, see `Sessions#DestroyContext`.
+func DestroyContext(ctx context.Context) bool {
+	return Default.DestroyContext(ctx)
+}
+
+// DestroyContext removes the session of the "ctx", see `NewContext`,
+// from the server-side memory (and database if registered), i.e a logout of a service layer.
+// The client's session cookie is reset on its next request.
+// Returns false if the "ctx" has no session.
+func (s *Sessions) DestroyContext(ctx context.Context) bool {
+	sess := FromContext(ctx)
+	if sess == nil {
+		return false
+	}
+
+	s.provider.Destroy(sess.ID())
+	return true
+}
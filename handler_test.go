@@ -0,0 +1,169 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	manager := New(Config{SingleWriter: true})
+
+	handler := manager.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := FromContext(r.Context())
+		if sess == nil {
+			t.Fatal("expected the session to be injected into the request's context")
+		}
+
+		visits, _ := sess.Get("visits").(int)
+		sess.Set("visits", visits+1)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookie := w.Result().Cookies()[0]
+
+	// the session is released, the next request doesn't block.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if visits, _ := manager.provider.Read(cookie.Value, 0).GetInt("visits"); visits != 2 {
+		t.Fatalf("expected 2 visits but got %d", visits)
+	}
+
+	if FromContext(context.Background()) != nil {
+		t.Fatal("expected nil for a context without a session")
+	}
+}
+
+func TestNewContext(t *testing.T) {
+	manager := New(Config{})
+	sess := manager.provider.Init("sid", 0)
+
+	ctx := NewContext(context.Background(), sess)
+	if FromContext(ctx) != sess {
+		t.Fatal("expected the session of the context")
+	}
+
+	if !manager.DestroyContext(ctx) || manager.Count() != 0 {
+		t.Fatal("expected the session of the context to be destroyed")
+	}
+
+	if manager.DestroyContext(context.Background()) {
+		t.Fatal("expected false for a context without a session")
+	}
+}
+
+func TestStartContext(t *testing.T) {
+	manager := New(Config{})
+
+	var sent string
+	sess := manager.StartContext(context.Background(), "", func(value string) { sent = value })
+	if sent == "" || sent != sess.ID() {
+		t.Fatalf("expected the new session id to be sent but got %q", sent)
+	}
+	sess.Set("name", "kataras")
+	sess.Release()
+
+	sent = ""
+	sess = manager.StartContext(context.Background(), sess.ID(), func(value string) { sent = value })
+	defer sess.Release()
+	if sent != "" {
+		t.Fatalf("expected the session id to be kept but got %q", sent)
+	}
+
+	if got := sess.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session's value %q but got %q", "kataras", got)
+	}
+}
+
+func TestStartContextConfig(t *testing.T) {
+	type ctxKey struct{}
+
+	var signals RiskSignals
+	manager := New(Config{
+		SingleWriter: true,
+		HydratorContext: func(ctx context.Context) Store {
+			var values Store
+			values.Set("user", ctx.Value(ctxKey{}))
+			return values
+		},
+		ReadOnlyContext: func(ctx context.Context) bool {
+			return ctx.Value(ctxKey{}) == "readonly"
+		},
+		RiskScorer: RiskScorerFunc(func(s RiskSignals) float64 {
+			signals = s
+			return 0
+		}),
+	})
+
+	ctx := NewClientContext(context.WithValue(context.Background(), ctxKey{}, "kataras"), "10.0.0.1", "grpc-go")
+	sess := manager.StartContext(ctx, "", nil)
+	if got := sess.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the hydrated value %q but got %q", "kataras", got)
+	}
+	if signals.IP != "10.0.0.1" || !signals.IsNew {
+		t.Fatalf("expected the client's signals to be assessed but got %#v", signals)
+	}
+	sid := sess.ID()
+	sess.Release()
+
+	sess = manager.StartContext(context.WithValue(context.Background(), ctxKey{}, "readonly"), sid, nil)
+	defer sess.Release()
+	if !sess.ReadOnly() {
+		t.Fatal("expected the session to be read-only by the ReadOnlyContext")
+	}
+	if got := sess.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the existing session not to be hydrated again but got %q", got)
+	}
+}
+
+func TestRegenerateIDContext(t *testing.T) {
+	manager := New(Config{})
+
+	var sent string
+	sess := manager.StartContext(context.Background(), "", func(value string) { sent = value })
+	defer sess.Release()
+	sess.Set("name", "kataras")
+	oldSid := sess.ID()
+
+	ctx := NewContext(context.Background(), sess)
+	if got := manager.RegenerateIDContext(ctx); got != sess {
+		t.Fatal("expected the session of the context")
+	}
+	if sess.ID() == oldSid || sent != sess.ID() {
+		t.Fatalf("expected the new session id %q to be sent but got %q", sess.ID(), sent)
+	}
+	if got := sess.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the values to be moved to the new id but got %q", got)
+	}
+
+	if manager.RegenerateIDContext(context.Background()) != nil {
+		t.Fatal("expected nil for a context without a session")
+	}
+}
+
+func TestUpdateExpirationContext(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+
+	handler := manager.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !manager.UpdateExpirationContext(r.Context(), 2*time.Hour) {
+			t.Fatal("expected the expiration of the context's session to be updated")
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	// the last cookie is the updated one.
+	cookies := w.Result().Cookies()
+	if updated := cookies[len(cookies)-1]; updated.MaxAge <= int(time.Hour.Seconds()) {
+		t.Fatalf("expected the cookie of the new expiration but got %v", updated)
+	}
+
+	if manager.UpdateExpirationContext(context.Background(), time.Hour) {
+		t.Fatal("expected false for a context without a session")
+	}
+}
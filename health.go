@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Pinger is an optional interface of a `Database` which reports whether its backend is reachable,
+// i.e a redis server, see `Sessions#Healthy`.
+type Pinger interface {
+	// Ping returns a non-nil error if the backend is unreachable,
+	// it should return the "ctx"'s error if its deadline passes first.
+	Ping(ctx context.Context) error
+}
+
+// PingDatabase pings the "db" if it implements the `Pinger`, it returns nil otherwise.
+// It's useful for the session databases which wrap other databases, i.e a tiered one.
+func PingDatabase(ctx context.Context, db Database) error {
+	if pinger, ok := db.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Healthy pings the registered session databases of the `Default` manager, see `Sessions#Healthy`.
+func Healthy(ctx context.Context) error {
+	return Default.Healthy(ctx)
+}
+
+// Healthy pings the registered session databases which implement the `Pinger`, concurrently,
+// and returns their errors, nil if all of them are reachable, i.e for the readiness probe of the app.
+// It returns the "ctx"'s error if its deadline passes before all databases reply.
+//
+// Usage:
+// if err := manager.Healthy(ctx); err != nil { http.Error(w, err.Error(), http.StatusServiceUnavailable) }
+func (s *Sessions) Healthy(ctx context.Context) error {
+	s.provider.mu.Lock()
+	databases := s.provider.databases
+	s.provider.mu.Unlock()
+
+	errCh := make(chan error, len(databases))
+	for _, db := range databases {
+		go func(db Database) {
+			if err := PingDatabase(ctx, db); err != nil {
+				errCh <- fmt.Errorf("%T: %v", db, err)
+				return
+			}
+			errCh <- nil
+		}(db)
+	}
+
+	var errMsgs []string
+	for range databases {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				errMsgs = append(errMsgs, err.Error())
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("sessions: unhealthy databases: %s", strings.Join(errMsgs, "; "))
+	}
+	return nil
+}
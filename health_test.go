@@ -0,0 +1,50 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pingDatabase struct {
+	*concurrentDatabase
+	err   error
+	delay time.Duration
+}
+
+func (db *pingDatabase) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(db.delay):
+		return db.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	manager := New(Config{})
+	if err := manager.Healthy(context.Background()); err != nil {
+		t.Fatalf("expected a manager without databases to be healthy but got %v", err)
+	}
+
+	manager.UseDatabase(newConcurrentDatabase()) // not a Pinger.
+	manager.UseDatabase(&pingDatabase{concurrentDatabase: newConcurrentDatabase()})
+	if err := manager.Healthy(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	manager.UseDatabase(&pingDatabase{concurrentDatabase: newConcurrentDatabase(), err: errors.New("connection refused")})
+	if err := manager.Healthy(context.Background()); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected the ping's error but got %v", err)
+	}
+
+	slow := New(Config{})
+	slow.UseDatabase(&pingDatabase{concurrentDatabase: newConcurrentDatabase(), delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := slow.Healthy(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to pass but got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package sessions
+
+import (
+	"sync"
+)
+
+// lifecycleHooks are the session lifecycle callbacks of a manager,
+// see `Sessions#OnCreate`, `Sessions#OnDestroy`, `Sessions#OnExpire` and `Sessions#OnUpdate`.
+type lifecycleHooks struct {
+	mu      sync.RWMutex
+	create  []func(sid string)
+	destroy []func(sid string)
+	expire  []func(sid string)
+	update  []func(sid string, action Action, key string)
+	// rememberMeTheft callbacks accept the user id instead.
+	rememberMeTheft []func(userID string)
+	evict           []func(sid string, reason EvictionReason, snapshot Store)
+}
+
+// EvictionReason is the reason which a session was dropped by the manager, see `Sessions#OnEvict`.
+type EvictionReason uint8
+
+const (
+	// EvictionExpired the lifetime of the session has passed.
+	EvictionExpired EvictionReason = iota + 1
+	// EvictionDestroyed the session was destroyed, i.e by the `Destroy`, `DestroyByID` or `DestroyAll`.
+	EvictionDestroyed
+	// EvictionRevoked the session was revoked with the rest sessions of its user or login,
+	// i.e by the `DestroyByUser` or `DestroyByLogin`.
+	EvictionRevoked
+	// EvictionCapacity the session was evicted by a session database to keep it within its capacity,
+	// i.e the least recently used session of the memory database's maximum sessions or bytes,
+	// see `EvictionNotifierSetter`.
+	EvictionCapacity
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionExpired:
+		return "expired"
+	case EvictionDestroyed:
+		return "destroyed"
+	case EvictionRevoked:
+		return "revoked"
+	case EvictionCapacity:
+		return "capacity"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionNotifierSetter is implemented by the session databases which evict sessions by themselves,
+// i.e to keep them within their capacity, the `UseDatabase` sets the notifier of the manager's `OnEvict` callbacks to them.
+type EvictionNotifierSetter interface {
+	SetEvictionNotifier(notify func(sid string, reason EvictionReason, snapshot Store))
+}
+
+// eviction is a dropped session, it's fired to the `OnEvict` callbacks.
+type eviction struct {
+	sid      string
+	reason   EvictionReason
+	snapshot Store
+}
+
+func (h *lifecycleHooks) onCreate(cb func(sid string)) {
+	h.mu.Lock()
+	h.create = append(h.create, cb)
+	h.mu.Unlock()
+}
+
+func (h *lifecycleHooks) onDestroy(cb func(sid string)) {
+	h.mu.Lock()
+	h.destroy = append(h.destroy, cb)
+	h.mu.Unlock()
+}
+
+func (h *lifecycleHooks) onExpire(cb func(sid string)) {
+	h.mu.Lock()
+	h.expire = append(h.expire, cb)
+	h.mu.Unlock()
+}
+
+func (h *lifecycleHooks) onUpdate(cb func(sid string, action Action, key string)) {
+	h.mu.Lock()
+	h.update = append(h.update, cb)
+	h.mu.Unlock()
+}
+
+func (h *lifecycleHooks) onRememberMeTheft(cb func(userID string)) {
+	h.mu.Lock()
+	h.rememberMeTheft = append(h.rememberMeTheft, cb)
+	h.mu.Unlock()
+}
+
+func (h *lifecycleHooks) onEvict(cb func(sid string, reason EvictionReason, snapshot Store)) {
+	h.mu.Lock()
+	h.evict = append(h.evict, cb)
+	h.mu.Unlock()
+}
+
+// hasEvict reports whether evict callbacks are registered, the snapshots are copied only then.
+func (h *lifecycleHooks) hasEvict() bool {
+	h.mu.RLock()
+	has := len(h.evict) > 0
+	h.mu.RUnlock()
+	return has
+}
+
+func (h *lifecycleHooks) fire(callbacks *[]func(sid string), sids ...string) {
+	h.mu.RLock()
+	cbs := *callbacks
+	h.mu.RUnlock()
+
+	for _, sid := range sids {
+		for _, cb := range cbs {
+			cb(sid)
+		}
+	}
+}
+
+func (h *lifecycleHooks) fireCreate(sids ...string)  { h.fire(&h.create, sids...) }
+func (h *lifecycleHooks) fireDestroy(sids ...string) { h.fire(&h.destroy, sids...) }
+func (h *lifecycleHooks) fireExpire(sids ...string)  { h.fire(&h.expire, sids...) }
+
+func (h *lifecycleHooks) fireRememberMeTheft(userID string) { h.fire(&h.rememberMeTheft, userID) }
+
+func (h *lifecycleHooks) fireEvict(evictions ...eviction) {
+	h.mu.RLock()
+	cbs := h.evict
+	h.mu.RUnlock()
+
+	for _, ev := range evictions {
+		for _, cb := range cbs {
+			cb(ev.sid, ev.reason, ev.snapshot)
+		}
+	}
+}
+
+func (h *lifecycleHooks) fireUpdate(sid string, action Action, key string) {
+	h.mu.RLock()
+	cbs := h.update
+	h.mu.RUnlock()
+
+	for _, cb := range cbs {
+		cb(sid, action, key)
+	}
+}
+
+// OnCreate registers a callback which is fired when a new session is created,
+// the sessions which are restored from a database are not reported.
+// It's fired for the new session id of a `RegenerateID` too.
+//
+// The callbacks run synchronously, after the manager's locks are released,
+// long running tasks should be executed in a goroutine.
+func OnCreate(cb func(sid string)) {
+	Default.OnCreate(cb)
+}
+
+// OnCreate registers a callback which is fired when a new session is created,
+// the sessions which are restored from a database are not reported.
+// It's fired for the new session id of a `RegenerateID` too.
+//
+// The callbacks run synchronously, after the manager's locks are released,
+// long running tasks should be executed in a goroutine.
+func (s *Sessions) OnCreate(cb func(sid string)) {
+	s.provider.hooks.onCreate(cb)
+}
+
+// OnDestroy registers a callback which is fired when a session is destroyed,
+// i.e by the `Destroy`, `DestroyByID`, `DestroyAll` or `DestroyByLogin`.
+// It's fired for the old session id of a `RegenerateID` too.
+//
+// The callbacks run synchronously, after the manager's locks are released.
+func OnDestroy(cb func(sid string)) {
+	Default.OnDestroy(cb)
+}
+
+// OnDestroy registers a callback which is fired when a session is destroyed,
+// i.e by the `Destroy`, `DestroyByID`, `DestroyAll` or `DestroyByLogin`.
+// It's fired for the old session id of a `RegenerateID` too.
+//
+// The callbacks run synchronously, after the manager's locks are released.
+func (s *Sessions) OnDestroy(cb func(sid string)) {
+	s.provider.hooks.onDestroy(cb)
+}
+
+// OnExpire registers a callback which is fired when a session is removed
+// because its lifetime has passed, useful to clean up the session's related resources.
+//
+// The callbacks run synchronously, from the expiration timer's goroutine.
+func OnExpire(cb func(sid string)) {
+	Default.OnExpire(cb)
+}
+
+// OnExpire registers a callback which is fired when a session is removed
+// because its lifetime has passed, useful to clean up the session's related resources.
+//
+// The callbacks run synchronously, from the expiration timer's goroutine.
+func (s *Sessions) OnExpire(cb func(sid string)) {
+	s.provider.hooks.onExpire(cb)
+}
+
+// OnUpdate registers a callback which is fired when an entry of a session is
+// set (`ActionCreate`, `ActionInsert` or `ActionUpdate`), removed (`ActionDelete`)
+// or when all entries are removed (`ActionClear`, the "key" is empty).
+//
+// The callbacks run synchronously, after the session's lock is released.
+func OnUpdate(cb func(sid string, action Action, key string)) {
+	Default.OnUpdate(cb)
+}
+
+// OnUpdate registers a callback which is fired when an entry of a session is
+// set (`ActionCreate`, `ActionInsert` or `ActionUpdate`), removed (`ActionDelete`)
+// or when all entries are removed (`ActionClear`, the "key" is empty).
+//
+// The callbacks run synchronously, after the session's lock is released.
+func (s *Sessions) OnUpdate(cb func(sid string, action Action, key string)) {
+	s.provider.hooks.onUpdate(cb)
+}
+
+// OnRememberMeTheft registers a callback which is fired when a stolen remember-me cookie is detected,
+// the sessions and the remember-me logins of the user are already revoked,
+// it's useful to notify the user or to require a password change, see `Sessions#Remember`.
+//
+// The callbacks run synchronously, from the request which carried the reused token.
+func OnRememberMeTheft(cb func(userID string)) {
+	Default.OnRememberMeTheft(cb)
+}
+
+// OnRememberMeTheft registers a callback which is fired when a stolen remember-me cookie is detected,
+// the sessions and the remember-me logins of the user are already revoked,
+// it's useful to notify the user or to require a password change, see `Sessions#Remember`.
+//
+// The callbacks run synchronously, from the request which carried the reused token.
+func (s *Sessions) OnRememberMeTheft(cb func(userID string)) {
+	s.provider.hooks.onRememberMeTheft(cb)
+}
+
+// OnEvict registers a callback which is fired whenever a session is dropped by the manager,
+// or evicted by a session database to keep its capacity, see `EvictionNotifierSetter`,
+// with the reason and the final snapshot of its values, i.e to persist the last-known cart
+// of an expired session or to audit why a user was logged out, see `EvictionReason`.
+// The snapshot is a copy of the session's entries, it's not changed afterwards.
+//
+// The callbacks run synchronously, after the manager's locks are released.
+func OnEvict(cb func(sid string, reason EvictionReason, snapshot Store)) {
+	Default.OnEvict(cb)
+}
+
+// OnEvict registers a callback which is fired whenever a session is dropped by the manager,
+// or evicted by a session database to keep its capacity, see `EvictionNotifierSetter`,
+// with the reason and the final snapshot of its values, i.e to persist the last-known cart
+// of an expired session or to audit why a user was logged out, see `EvictionReason`.
+// The snapshot is a copy of the session's entries, it's not changed afterwards.
+//
+// The callbacks run synchronously, after the manager's locks are released.
+func (s *Sessions) OnEvict(cb func(sid string, reason EvictionReason, snapshot Store)) {
+	s.provider.hooks.onEvict(cb)
+}
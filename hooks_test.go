@@ -0,0 +1,98 @@
+package sessions
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	manager := New(Config{})
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) func(sid string) {
+		return func(sid string) {
+			mu.Lock()
+			events = append(events, event+":"+sid)
+			mu.Unlock()
+		}
+	}
+
+	manager.OnCreate(record("create"))
+	manager.OnDestroy(record("destroy"))
+	expired := make(chan string, 1)
+	manager.OnExpire(func(sid string) { expired <- sid })
+	manager.OnUpdate(func(sid string, action Action, key string) {
+		record("update")(sid + ":" + key)
+	})
+
+	sess := manager.provider.Init("sid", time.Hour)
+	sess.Set("name", "go-sessions")
+	sess.Delete("name")
+	sess.Delete("missing")
+	manager.DestroyByID("sid")
+
+	expected := []string{"create:sid", "update:sid:name", "update:sid:name", "destroy:sid"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v but got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected events %v but got %v", expected, events)
+		}
+	}
+
+	manager.provider.Init("short", 10*time.Millisecond)
+	select {
+	case sid := <-expired:
+		if sid != "short" {
+			t.Fatalf("expected the expired session id to be short but got %s", sid)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the session to expire")
+	}
+}
+
+func TestOnEvict(t *testing.T) {
+	manager := New(Config{})
+
+	type evicted struct {
+		sid    string
+		reason EvictionReason
+		cart   string
+	}
+	evictions := make(chan evicted, 3)
+	manager.OnEvict(func(sid string, reason EvictionReason, snapshot Store) {
+		evictions <- evicted{sid, reason, snapshot.GetString("cart")}
+	})
+
+	sess := manager.provider.Init("destroyed", time.Hour)
+	sess.Set("cart", "book")
+	manager.DestroyByID("destroyed")
+
+	sess = manager.provider.Init("revoked", time.Hour)
+	sess.BindUser("user1")
+	manager.DestroyByUser("user1")
+
+	sess = manager.provider.Init("expired", 10*time.Millisecond)
+	sess.Set("cart", "pen")
+
+	expected := []evicted{
+		{"destroyed", EvictionDestroyed, "book"},
+		{"revoked", EvictionRevoked, ""},
+		{"expired", EvictionExpired, "pen"},
+	}
+	for _, e := range expected {
+		select {
+		case got := <-evictions:
+			if got != e {
+				t.Fatalf("expected the eviction %v but got %v", e, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the eviction of %s", e.sid)
+		}
+	}
+}
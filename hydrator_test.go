@@ -0,0 +1,107 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// hydrator returns the identity of the auth proxy's header.
+func hydrator(r *http.Request) Store {
+	user := r.Header.Get("X-Forwarded-User")
+	if user == "" {
+		return nil
+	}
+
+	var values Store
+	values.Set("user", user)
+	return values
+}
+
+func TestHydrator(t *testing.T) {
+	manager := New(Config{Hydrator: hydrator})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-User", "kataras")
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	if got := sess.GetString("user"); got != "kataras" || !sess.IsNew() {
+		t.Fatalf("expected the new session to be hydrated but got %q", got)
+	}
+	sess.Release()
+
+	db.mu.Lock()
+	stored := db.stores[sess.ID()]
+	db.mu.Unlock()
+	if got := stored.Values.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the hydrated values to be synced to the database but got %q", got)
+	}
+
+	// the next request of the client carries the session, it's not hydrated again.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-User", "makis")
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	if got := manager.Start(httptest.NewRecorder(), r).GetString("user"); got != "kataras" {
+		t.Fatalf("expected the existing session to not be hydrated but got %q", got)
+	}
+}
+
+func TestHydratorUnknownSession(t *testing.T) {
+	manager := New(Config{Hydrator: hydrator})
+
+	// the cookie of an expired session or of a previous app run.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-User", "kataras")
+	r.AddCookie(&http.Cookie{Name: manager.Config().Cookie, Value: "unknown"})
+
+	sess := manager.Start(httptest.NewRecorder(), r)
+	if got := sess.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the session of the unknown id to be hydrated but got %q", got)
+	}
+}
+
+func TestHydratorReadOnly(t *testing.T) {
+	manager := New(Config{Hydrator: hydrator, ReadOnly: func(r *http.Request) bool { return r.Method == http.MethodGet }})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-User", "kataras")
+	if sess := manager.Start(httptest.NewRecorder(), r); sess.Exists("user") {
+		t.Fatalf("expected the read-only session to not be hydrated")
+	}
+}
+
+func TestHydratorMaxSessionSize(t *testing.T) {
+	manager := New(Config{MaxSessionSize: 128, Hydrator: func(r *http.Request) Store {
+		var values Store
+		values.Set("user", "kataras")
+		values.Set("claims", strings.Repeat("c", 256))
+		return values
+	}})
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if sess.GetString("user") != "kataras" || sess.Exists("claims") {
+		t.Fatalf("expected the values to be hydrated up to the size limit but got %v", sess.GetAll())
+	}
+}
+
+func TestHydratorFasthttp(t *testing.T) {
+	manager := New(Config{HydratorFasthttp: func(ctx *fasthttp.RequestCtx) Store {
+		var values Store
+		values.Set("user", string(ctx.Request.Header.Peek("X-Forwarded-User")))
+		return values
+	}})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.Set("X-Forwarded-User", "kataras")
+	ctx.Request.Header.SetCookie(manager.Config().Cookie, "unknown")
+	if got := manager.StartFasthttp(&ctx).GetString("user"); got != "kataras" {
+		t.Fatalf("expected the session of the unknown id to be hydrated but got %q", got)
+	}
+}
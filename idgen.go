@@ -0,0 +1,97 @@
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+// The built-in session id generators, see `Config#IDGenerator`.
+var (
+	_ func(context.Context) string = RandomIDGenerator
+	_ func(context.Context) string = UUIDGenerator
+	_ func(context.Context) string = ULIDGenerator
+	_ func(context.Context) string = KSUIDGenerator
+)
+
+// randomBytes fills "b" with random bytes of the crypto/rand.
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		// the system's secure random source is not available, continuing would produce guessable ids.
+		panic("sessions: unable to read random bytes: " + err.Error())
+	}
+}
+
+// RandomIDGenerator returns a random session id of 256 bits, base64-url encoded,
+// it's the default `Config#IDGenerator`.
+func RandomIDGenerator(ctx context.Context) string {
+	b := make([]byte, 32)
+	randomBytes(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// UUIDGenerator returns a random (version 4) UUID as the session id.
+func UUIDGenerator(ctx context.Context) string {
+	return uuid.NewV4().String()
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator returns a ULID as the session id,
+// a 48 bits millisecond timestamp followed by 80 random bits, Crockford's base32 encoded,
+// the ids are sorted lexicographically by their creation time.
+func ULIDGenerator(ctx context.Context) string {
+	var id [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	randomBytes(id[6:])
+
+	// 128 bits are encoded to 26 characters of 5 bits, the first one carries the 3 most significant bits.
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out)
+}
+
+const (
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// ksuidEpoch is the KSUID's custom epoch, in unix seconds.
+	ksuidEpoch = 1400000000
+	ksuidLen   = 27
+)
+
+// KSUIDGenerator returns a KSUID as the session id,
+// a 32 bits second timestamp followed by 128 random bits, base62 encoded,
+// the ids are sorted lexicographically by their creation time.
+func KSUIDGenerator(ctx context.Context) string {
+	var id [20]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(time.Now().Unix()-ksuidEpoch))
+	randomBytes(id[4:])
+
+	n := new(big.Int).SetBytes(id[:])
+	base, mod := big.NewInt(62), new(big.Int)
+
+	out := make([]byte, 0, ksuidLen)
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		out = append(out, base62Alphabet[mod.Int64()])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return strings.Repeat("0", ksuidLen-len(out)) + string(out)
+}
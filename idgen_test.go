@@ -0,0 +1,49 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIDGenerators(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate func(context.Context) string
+		length   int
+	}{
+		{"random", RandomIDGenerator, 43},
+		{"uuid", UUIDGenerator, 36},
+		{"ulid", ULIDGenerator, 26},
+		{"ksuid", KSUIDGenerator, 27},
+	}
+
+	for _, tt := range tests {
+		a, b := tt.generate(context.Background()), tt.generate(context.Background())
+		if len(a) != tt.length {
+			t.Fatalf("%s: expected an id of %d characters but got %q", tt.name, tt.length, a)
+		}
+
+		if a == b {
+			t.Fatalf("%s: expected unique ids but got %q twice", tt.name, a)
+		}
+	}
+
+	first := ULIDGenerator(context.Background())
+	time.Sleep(2 * time.Millisecond)
+	if second := ULIDGenerator(context.Background()); second <= first {
+		t.Fatalf("expected ULIDs to be sorted by their creation time but got %q after %q", second, first)
+	}
+}
+
+func TestConfigIDGenerator(t *testing.T) {
+	c := Config{SessionIDGenerator: func() string { return "sid" }}.Validate()
+	if sid := c.IDGenerator(context.Background()); sid != "sid" {
+		t.Fatalf("expected the SessionIDGenerator to be used but got %q", sid)
+	}
+
+	c = Config{}.Validate()
+	if sid := c.SessionIDGenerator(); len(sid) != 43 {
+		t.Fatalf("expected the SessionIDGenerator to use the default IDGenerator but got %q", sid)
+	}
+}
@@ -0,0 +1,71 @@
+package sessions
+
+// sessionIndex maps the claims of the sessions, i.e the identity provider's subject,
+// to their session ids, so the sessions of a user can be found without visiting all of them.
+// It's protected by the provider's mutex.
+type sessionIndex struct {
+	sids   map[string]map[string]struct{} // claim -> session ids.
+	claims map[string][]string            // session id -> claims, used to remove the session's claims.
+}
+
+func newSessionIndex() *sessionIndex {
+	return &sessionIndex{
+		sids:   make(map[string]map[string]struct{}),
+		claims: make(map[string][]string),
+	}
+}
+
+// claimKey returns the index key of the claim "value" of a "kind", i.e "subject".
+func claimKey(kind, value string) string {
+	return kind + ":" + value
+}
+
+func (idx *sessionIndex) add(claim, sid string) {
+	sids, ok := idx.sids[claim]
+	if !ok {
+		sids = make(map[string]struct{})
+		idx.sids[claim] = sids
+	}
+
+	if _, exists := sids[sid]; exists {
+		return
+	}
+
+	sids[sid] = struct{}{}
+	idx.claims[sid] = append(idx.claims[sid], claim)
+}
+
+func (idx *sessionIndex) get(claim string) []string {
+	sids := idx.sids[claim]
+	if len(sids) == 0 {
+		return nil
+	}
+
+	list := make([]string, 0, len(sids))
+	for sid := range sids {
+		list = append(list, sid)
+	}
+	return list
+}
+
+func (idx *sessionIndex) remove(sid string) {
+	for _, claim := range idx.claims[sid] {
+		if sids, ok := idx.sids[claim]; ok {
+			delete(sids, sid)
+			if len(sids) == 0 {
+				delete(idx.sids, claim)
+			}
+		}
+	}
+
+	delete(idx.claims, sid)
+}
+
+// rename moves the claims of the "oldSid" to the "newSid".
+func (idx *sessionIndex) rename(oldSid, newSid string) {
+	claims := idx.claims[oldSid]
+	idx.remove(oldSid)
+	for _, claim := range claims {
+		idx.add(claim, newSid)
+	}
+}
@@ -0,0 +1,54 @@
+package sessions
+
+import (
+	"testing"
+)
+
+func TestDestroyByLogin(t *testing.T) {
+	manager := New(Config{})
+
+	first := manager.provider.Init("first", 0)
+	first.BindLogin("user", "idp-1")
+	second := manager.provider.Init("second", 0)
+	second.BindLogin("user", "idp-2")
+	manager.provider.Init("other", 0).BindLogin("other", "idp-3")
+
+	if n := manager.DestroyByLogin("user", "idp-1"); n != 1 {
+		t.Fatalf("expected the session of the identity provider's session id to be destroyed but %d were destroyed", n)
+	}
+
+	if n := manager.DestroyByLogin("user", ""); n != 1 {
+		t.Fatalf("expected the remaining session of the subject to be destroyed but %d were destroyed", n)
+	}
+
+	if _, found := manager.provider.sessions.get("other"); !found {
+		t.Fatalf("expected the session of a different subject to be kept")
+	}
+
+	if len(manager.provider.index.sids) != 2 {
+		t.Fatalf("expected the index to contain only the claims of the kept session but got %v", manager.provider.index.sids)
+	}
+}
+
+func TestDestroyByUser(t *testing.T) {
+	manager := New(Config{})
+
+	first := manager.provider.Init("first", 0)
+	first.BindUser("kataras")
+	second := manager.provider.Init("second", 0)
+	second.BindUser("kataras")
+	second.RegenerateID()
+	manager.provider.Init("other", 0).BindUser("other")
+
+	if n := manager.DestroyByUser("kataras"); n != 2 {
+		t.Fatalf("expected both sessions of the user to be destroyed but %d were destroyed", n)
+	}
+
+	if n := manager.DestroyByUser("kataras"); n != 0 {
+		t.Fatalf("expected no sessions to be left for the user but %d were destroyed", n)
+	}
+
+	if _, found := manager.provider.sessions.get("other"); !found {
+		t.Fatalf("expected the session of a different user to be kept")
+	}
+}
@@ -0,0 +1,142 @@
+package sessions
+
+import (
+	"fmt"
+)
+
+// ProblemKind is the kind of an integrity problem of a `Store`, see `Store.Validate`.
+type ProblemKind uint8
+
+const (
+	// ProblemDuplicateKey is an entry whose key is used by a previous entry too,
+	// it's unreachable, the `Store.Get` returns the first one.
+	ProblemDuplicateKey ProblemKind = iota + 1
+	// ProblemNilImmutable is an immutable entry with a nil value, its `Entry.Value` panics.
+	ProblemNilImmutable
+	// ProblemUnserializable is an entry whose value can't be serialized by the `DefaultTranscoder`,
+	// i.e its type is not registered to gob or it's self-referential.
+	ProblemUnserializable
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case ProblemDuplicateKey:
+		return "duplicate key"
+	case ProblemNilImmutable:
+		return "nil immutable value"
+	case ProblemUnserializable:
+		return "unserializable value"
+	default:
+		return "unknown problem"
+	}
+}
+
+// Problem is an integrity problem of an entry of a `Store`.
+type Problem struct {
+	Kind ProblemKind
+	// Index is the index of the entry in the store.
+	Index int
+	Key   string
+	// Err is the serialization error of the `ProblemUnserializable`.
+	Err error
+}
+
+func (p Problem) String() string {
+	if p.Err != nil {
+		return fmt.Sprintf("%s of key %s at %d: %v", p.Kind, p.Key, p.Index, p.Err)
+	}
+	return fmt.Sprintf("%s of key %s at %d", p.Kind, p.Key, p.Index)
+}
+
+// RepairPolicy is the way the `Store.Repair` repairs the problems of a store.
+type RepairPolicy uint8
+
+const (
+	// RepairDrop drops the entries which have a problem,
+	// the first entry of a duplicate key is kept.
+	RepairDrop RepairPolicy = iota
+	// RepairFix fixes the entries which can be fixed, the nil immutable values are made mutable,
+	// and drops the rest, the duplicate keys and the unserializable values.
+	RepairFix
+)
+
+// checkEntry returns the serialization error of the "entry".
+func checkEntry(entry Entry) error {
+	if err := checkRecursive(entry.Key, entry.ValueRaw); err != nil {
+		return err
+	}
+
+	values, err := encodeHooks(Store{entry})
+	if err != nil {
+		return err
+	}
+
+	_, err = DefaultTranscoder.Marshal(values)
+	return err
+}
+
+// Validate returns the integrity problems of the store, i.e after a migration
+// or the decoding of a foreign-format payload, the duplicate keys,
+// the nil immutable values and the values which can't be serialized by the `DefaultTranscoder`.
+// It returns nil if the store is valid, see `Repair`.
+func (r Store) Validate() []Problem {
+	var problems []Problem
+	seen := make(map[string]struct{}, len(r))
+
+	for i, entry := range r {
+		if _, dup := seen[entry.Key]; dup {
+			problems = append(problems, Problem{Kind: ProblemDuplicateKey, Index: i, Key: entry.Key})
+			continue
+		}
+		seen[entry.Key] = struct{}{}
+
+		if entry.immutable && entry.ValueRaw == nil {
+			problems = append(problems, Problem{Kind: ProblemNilImmutable, Index: i, Key: entry.Key})
+			continue
+		}
+
+		if err := checkEntry(entry); err != nil {
+			problems = append(problems, Problem{Kind: ProblemUnserializable, Index: i, Key: entry.Key, Err: err})
+		}
+	}
+
+	return problems
+}
+
+// Repair repairs the integrity problems of the store, see `Validate`, by the "policy",
+// the order of the rest of the entries is kept.
+// It returns the repaired problems, their indexes refer to the store before the repair.
+func (r *Store) Repair(policy RepairPolicy) []Problem {
+	problems := r.Validate()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	args := *r
+	drop := make(map[int]struct{}, len(problems))
+	for _, p := range problems {
+		if p.Kind == ProblemNilImmutable && policy == RepairFix {
+			args[p.Index].immutable = false
+			continue
+		}
+		drop[p.Index] = struct{}{}
+	}
+
+	if len(drop) > 0 {
+		n := 0
+		for i := range args {
+			if _, ok := drop[i]; ok {
+				continue
+			}
+			args[n] = args[i]
+			n++
+		}
+
+		for i := n; i < len(args); i++ {
+			args[i] = Entry{} // release the dropped values.
+		}
+		*r = args[:n]
+	}
+
+	return problems
+}
@@ -0,0 +1,78 @@
+package sessions
+
+// Invalidator broadcasts the session invalidations between the app instances,
+// i.e the `Database#Invalidator` of the redis session database, see `Sessions#UseInvalidator`.
+type Invalidator interface {
+	// Publish broadcasts the invalidation of the session "sid", the "origin" is the id of the publisher.
+	Publish(origin, sid string) error
+	// Subscribe calls the "onInvalidate" for each broadcasted invalidation
+	// and returns a function which unsubscribes.
+	Subscribe(onInvalidate func(origin, sid string)) (func() error, error)
+}
+
+// UseInvalidator broadcasts the destroyed and regenerated sessions, see `Sessions#UseInvalidator`.
+func UseInvalidator(inv Invalidator) error {
+	return Default.UseInvalidator(inv)
+}
+
+// UseInvalidator broadcasts the session ids of the destroyed sessions and the old ids of the regenerated sessions
+// to the other app instances, and drops the sessions which are broadcasted by them from the memory,
+// so a "log out everywhere" takes effect on all instances immediately, instead of
+// when their in-memory copy of the session expires.
+// The dropped sessions are not synced to the databases, the publisher did, and the hooks are not fired.
+//
+// It's unsubscribed on the `Close`.
+func (s *Sessions) UseInvalidator(inv Invalidator) error {
+	return s.provider.useInvalidator(inv)
+}
+
+func (p *provider) useInvalidator(inv Invalidator) error {
+	origin := randomToken()
+	unsubscribe, err := inv.Subscribe(func(from, sid string) {
+		if from != origin {
+			p.drop(sid)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.invalidator, p.origin = inv, origin
+	p.unsubscribe = append(p.unsubscribe, unsubscribe)
+	p.mu.Unlock()
+	return nil
+}
+
+// broadcast publishes the invalidation of the "sids", if an invalidator is used.
+func (p *provider) broadcast(sids ...string) {
+	p.mu.Lock()
+	inv, origin := p.invalidator, p.origin
+	p.mu.Unlock()
+
+	if inv == nil {
+		return
+	}
+
+	for _, sid := range sids {
+		if err := inv.Publish(origin, sid); err != nil {
+			p.logger().Errorf("sessions: invalidation of session %s: %v", sid, err)
+		}
+	}
+}
+
+// drop removes the session of the "sid" from the memory only, it's invalidated by another app instance.
+func (p *provider) drop(sid string) {
+	p.mu.Lock()
+	sess, found := p.sessions.get(sid)
+	if found {
+		p.sessions.delete(sid)
+		p.index.remove(sid)
+	}
+	p.mu.Unlock()
+
+	if found {
+		sess.lifetime.stop()
+		sess.end()
+	}
+}
@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// testInvalidator broadcasts the invalidations in memory.
+type testInvalidator struct {
+	mu   sync.Mutex
+	subs []func(origin, sid string)
+}
+
+func (inv *testInvalidator) Publish(origin, sid string) error {
+	inv.mu.Lock()
+	subs := inv.subs
+	inv.mu.Unlock()
+
+	for _, sub := range subs {
+		sub(origin, sid)
+	}
+	return nil
+}
+
+func (inv *testInvalidator) Subscribe(onInvalidate func(origin, sid string)) (func() error, error) {
+	inv.mu.Lock()
+	inv.subs = append(inv.subs, onInvalidate)
+	inv.mu.Unlock()
+	return func() error { return nil }, nil
+}
+
+func TestUseInvalidator(t *testing.T) {
+	db, inv := newConcurrentDatabase(), &testInvalidator{}
+
+	node1, node2 := New(Config{}), New(Config{})
+	for _, node := range []*Sessions{node1, node2} {
+		node.UseDatabase(db)
+		if err := node.UseInvalidator(inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	sess := node1.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("user", "kataras")
+	cookie := findCookie(w, DefaultCookieName)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	if got := node2.Start(httptest.NewRecorder(), r).GetString("user"); got != "kataras" {
+		t.Fatalf("expected the session to be loaded by the other node but got %s", got)
+	}
+
+	node1.Destroy(httptest.NewRecorder(), r)
+
+	node2.provider.mu.Lock()
+	_, found := node2.provider.sessions.get(sess.ID())
+	node2.provider.mu.Unlock()
+	if found {
+		t.Fatalf("expected the destroyed session to be dropped by the other node")
+	}
+
+	if got := node2.Start(httptest.NewRecorder(), r).GetString("user"); got != "" {
+		t.Fatalf("expected the destroyed session to be empty on the other node but got %s", got)
+	}
+}
@@ -0,0 +1,138 @@
+package sessions
+
+// journalOp is the kind of a session mutation which is recorded to the journal.
+type journalOp uint8
+
+const (
+	// journalReplace restores the previous entry of a key.
+	journalReplace journalOp = iota
+	// journalInsert removes the entry of a key which was missing.
+	journalInsert
+	// journalRemove re-inserts the removed entry of a key.
+	journalRemove
+	// journalClear re-inserts all the removed entries.
+	journalClear
+)
+
+// journalEntry is the undo record of a session mutation.
+// The entries are restored by their keys, the index is a hint of their position,
+// so the writes of the other requests of the session are kept.
+type journalEntry struct {
+	op      journalOp
+	key     string
+	index   int
+	entry   Entry
+	entries Store
+}
+
+// beginJournal starts a new undo journal of the session's mutations, it's called by the `Start`.
+func (s *Session) beginJournal() {
+	s.mu.Lock()
+	s.journaling = true
+	s.journal = s.journal[:0]
+	s.mu.Unlock()
+}
+
+// indexOf returns the index of the "key"'s entry, -1 if it's missing.
+func (s *Session) indexOf(key string) int {
+	for i := range s.values {
+		if s.values[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordSet records the undo of a set of the "key", it should be called under the lock, before the mutation.
+func (s *Session) recordSet(key string) {
+	if !s.journaling {
+		return
+	}
+
+	if i := s.indexOf(key); i >= 0 {
+		s.journal = append(s.journal, journalEntry{op: journalReplace, key: key, index: i, entry: s.values[i]})
+		return
+	}
+
+	s.journal = append(s.journal, journalEntry{op: journalInsert, key: key})
+}
+
+// recordRemove records the undo of the removal of the "key", it should be called under the lock, before the mutation.
+func (s *Session) recordRemove(key string) {
+	if !s.journaling {
+		return
+	}
+
+	if i := s.indexOf(key); i >= 0 {
+		s.journal = append(s.journal, journalEntry{op: journalRemove, key: key, index: i, entry: s.values[i]})
+	}
+}
+
+// recordClear records the undo of the removal of all entries, it should be called under the lock, before the mutation.
+func (s *Session) recordClear() {
+	if !s.journaling || len(s.values) == 0 {
+		return
+	}
+
+	// copied, the store reuses its memory after a reset.
+	s.journal = append(s.journal, journalEntry{op: journalClear, entries: append(Store(nil), s.values...)})
+}
+
+// restoreEntry puts the "entry" back to the store, in place of the entry of its key,
+// or at the "index", if it's still in range, when the key is missing.
+func (s *Session) restoreEntry(index int, entry Entry) {
+	if i := s.indexOf(entry.Key); i >= 0 {
+		s.values[i] = entry
+		return
+	}
+
+	if index < 0 || index > len(s.values) {
+		index = len(s.values)
+	}
+
+	s.values = append(s.values, Entry{})
+	copy(s.values[index+1:], s.values[index:])
+	s.values[index] = entry
+}
+
+// Rollback restores the session's values to their state at the `Start` of the request,
+// the mutations of the request (`Set`, `Delete`, `Clear`, `Append` and `Unfreeze`) are undone in reverse order
+// and the session databases are synced once.
+// It's useful to a middleware which retries an idempotent handler or rejects its response.
+// It can be called many times, the journal starts over after each rollback.
+//
+// The journal belongs to the request, the keys which were written by the concurrent requests
+// of the same session are kept, unless this request wrote them too.
+// The values which are modified in place, i.e the fields of a stored pointer, can't be restored.
+func (s *Session) Rollback() {
+	s.mu.Lock()
+	if len(s.journal) == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	for i := len(s.journal) - 1; i >= 0; i-- {
+		j := s.journal[i]
+		switch j.op {
+		case journalReplace, journalRemove:
+			s.restoreEntry(j.index, j.entry)
+		case journalInsert:
+			s.values.Remove(j.key)
+		case journalClear:
+			for index, entry := range j.entries {
+				s.restoreEntry(index, entry)
+			}
+		}
+	}
+	s.journal = s.journal[:0]
+	s.dirty = true
+	s.modified = true
+
+	action := ActionUpdate
+	if len(s.values) == 0 {
+		action = ActionClear
+	}
+	s.mu.Unlock()
+
+	syncDatabases(s.provider.databases, acquireSyncPayload(s, action))
+}
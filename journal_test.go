@@ -0,0 +1,114 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	manager := New(Config{})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "go-sessions")
+	sess.SetImmutable("role", "user")
+	sess.Set("cart", 1)
+	cookie := w.Result().Cookies()[0]
+
+	before := sess.GetAll()
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.AddCookie(cookie)
+	sess = manager.Start(httptest.NewRecorder(), r)
+
+	sess.Set("cart", 2)
+	sess.Delete("name")
+	sess.Set("new", true)
+	sess.Append("viewed", "a")
+	sess.Unfreeze("role")
+	sess.Set("role", "admin")
+	sess.Clear()
+	sess.Set("after", "clear")
+
+	sess.Rollback()
+
+	if after := sess.GetAll(); !reflect.DeepEqual(before, after) {
+		t.Fatalf("expected the values to be restored to %v but got %v", before, after)
+	}
+
+	sess.Set("role", "admin")
+	if v := sess.GetString("role"); v != "user" {
+		t.Fatalf("expected the immutability to be restored but got role %q", v)
+	}
+
+	stored := db.Load(sess.ID())
+	if v := stored.Values.GetString("name"); v != "go-sessions" {
+		t.Fatalf("expected the restored values to be synced to the database but got name %q", v)
+	}
+
+	// the journal starts over after a rollback.
+	sess.Set("cart", 3)
+	sess.Rollback()
+	if v, _ := sess.GetInt("cart"); v != 1 {
+		t.Fatalf("expected the cart to be restored to 1 but got %d", v)
+	}
+}
+
+func TestRollbackConcurrentRequests(t *testing.T) {
+	manager := New(Config{})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "go-sessions")
+	sess.Set("cart", 1)
+	cookie := w.Result().Cookies()[0]
+
+	start := func() *Session {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.AddCookie(cookie)
+		return manager.Start(httptest.NewRecorder(), r)
+	}
+
+	// the two requests of the same session are served at the same time.
+	first, second := start(), start()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		first.Set("cart", 2)
+		first.Set("coupon", "SALE")
+		first.Delete("name")
+	}()
+	go func() {
+		defer wg.Done()
+		second.Set("theme", "dark")
+		second.Set("lang", "el")
+	}()
+	wg.Wait()
+
+	first.Rollback()
+
+	expected := map[string]interface{}{"name": "go-sessions", "cart": 1, "theme": "dark", "lang": "el"}
+	if got := first.GetAll(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected only the writes of the first request to be undone, %v but got %v", expected, got)
+	}
+
+	// the journal of the second request is kept.
+	second.Rollback()
+	expected = map[string]interface{}{"name": "go-sessions", "cart": 1}
+	if got := second.GetAll(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected the writes of the second request to be undone, %v but got %v", expected, got)
+	}
+
+	if stored := db.Load(sess.ID()); stored.Values.Len() != 2 {
+		t.Fatalf("expected the restored values to be synced to the database but got %v", stored.Values)
+	}
+}
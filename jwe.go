@@ -0,0 +1,251 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	// ErrInvalidJWE returned by the `DecodeJWE` when the token is malformed,
+	// it's not encrypted with a supported algorithm or it can't be decrypted by any of the keys.
+	ErrInvalidJWE = errors.New("jwe: invalid token")
+	// ErrJWEExpired returned by the `DecodeJWE` when the token's "exp" claim has passed.
+	ErrJWEExpired = errors.New("jwe: token expired")
+)
+
+// jweHeader is the protected header of the exported sessions,
+// the key is shared between the trust domains ("dir") and the content is encrypted with AES-GCM.
+type jweHeader struct {
+	Algorithm  string `json:"alg"`
+	Encryption string `json:"enc"`
+	Type       string `json:"typ,omitempty"`
+}
+
+// jweEncryption returns the "enc" header value of the AES-GCM key.
+func jweEncryption(key []byte) (string, error) {
+	switch len(key) {
+	case 16:
+		return "A128GCM", nil
+	case 24:
+		return "A192GCM", nil
+	case 32:
+		return "A256GCM", nil
+	default:
+		return "", errors.New("jwe: the key should be 16, 24 or 32 bytes")
+	}
+}
+
+// JWEClaims are the claims of an exported session, see `Session#ExportJWE`.
+type JWEClaims struct {
+	// ID is the unique id of the token ("jti"), the importing side can keep the used ids to reject the replays.
+	ID string `json:"jti"`
+	// IssuedAt and Expiry are the unix times ("iat" and "exp") of the token's issue and expiration.
+	IssuedAt int64 `json:"iat"`
+	Expiry   int64 `json:"exp"`
+	// Values are the session's values.
+	Values map[string]interface{} `json:"values"`
+}
+
+var jweEncoding = base64.RawURLEncoding
+
+// ExportJWE returns a snapshot of the session's values as a JWE (RFC 7516) token in compact serialization,
+// encrypted by the shared "key" ("alg": "dir") with AES-GCM ("enc": "A128GCM", "A192GCM" or "A256GCM" based on the key's size),
+// which expires after the "expires" duration.
+// The token can be handed to another trust domain, i.e to a native app through a deep link,
+// and decrypted by any standard JOSE library or imported to another manager by the `Sessions#ImportJWE`.
+//
+// The session id and the flash messages are not exported,
+// the values should be encoded to JSON, numbers are imported as json.Number.
+func (s *Session) ExportJWE(key []byte, expires time.Duration) (string, error) {
+	enc, err := jweEncryption(key)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.provider.now()
+	payload, err := json.Marshal(JWEClaims{
+		ID:       randomToken(),
+		IssuedAt: now.Unix(),
+		Expiry:   now.Add(expires).Unix(),
+		Values:   s.GetAll(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(jweHeader{Algorithm: "dir", Encryption: enc, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	aeads, err := newAEADs([][]byte{key})
+	if err != nil {
+		return "", err
+	}
+	aead := aeads[0]
+
+	iv := make([]byte, aead.NonceSize())
+	randomBytes(iv)
+
+	protected := jweEncoding.EncodeToString(header)
+	sealed := aead.Seal(nil, iv, payload, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+
+	// the encrypted key is empty for the direct encryption.
+	return strings.Join([]string{
+		protected,
+		"",
+		jweEncoding.EncodeToString(iv),
+		jweEncoding.EncodeToString(ciphertext),
+		jweEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecodeJWE decrypts the "token", exported by the `Session#ExportJWE`, with any of the "keys"
+// and returns its claims, the first key which decrypts it is used, so the keys can be rotated.
+// It returns the `ErrInvalidJWE` if the token can't be decrypted and the `ErrJWEExpired` if it's expired.
+func DecodeJWE(token string, keys ...[]byte) (JWEClaims, error) {
+	return decodeJWE(token, time.Now(), keys)
+}
+
+// decodeJWE same as `DecodeJWE` but the expiry is checked against the "now", i.e of the manager's `Config#Clock`.
+func decodeJWE(token string, now time.Time, keys [][]byte) (JWEClaims, error) {
+	var claims JWEClaims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return claims, ErrInvalidJWE
+	}
+
+	headerData, err := jweEncoding.DecodeString(parts[0])
+	if err != nil {
+		return claims, ErrInvalidJWE
+	}
+
+	var header jweHeader
+	if err = json.Unmarshal(headerData, &header); err != nil || header.Algorithm != "dir" {
+		return claims, ErrInvalidJWE
+	}
+
+	iv, err := jweEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrInvalidJWE
+	}
+
+	ciphertext, err := jweEncoding.DecodeString(parts[3])
+	if err != nil {
+		return claims, ErrInvalidJWE
+	}
+
+	tag, err := jweEncoding.DecodeString(parts[4])
+	if err != nil {
+		return claims, ErrInvalidJWE
+	}
+
+	sealed := append(ciphertext, tag...)
+
+	var payload []byte
+	for _, key := range keys {
+		if enc, err := jweEncryption(key); err != nil || enc != header.Encryption {
+			continue
+		}
+
+		aeads, err := newAEADs([][]byte{key})
+		if err != nil {
+			continue
+		}
+
+		if payload, err = openJWE(aeads[0], iv, sealed, parts[0]); err == nil {
+			break
+		}
+	}
+
+	if payload == nil {
+		return claims, ErrInvalidJWE
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err = dec.Decode(&claims); err != nil {
+		return claims, ErrInvalidJWE
+	}
+
+	if claims.Expiry > 0 && now.Unix() >= claims.Expiry {
+		return claims, ErrJWEExpired
+	}
+
+	return claims, nil
+}
+
+func openJWE(aead cipher.AEAD, iv, sealed []byte, protected string) ([]byte, error) {
+	if len(iv) != aead.NonceSize() {
+		return nil, ErrInvalidJWE
+	}
+
+	return aead.Open(nil, iv, sealed, []byte(protected))
+}
+
+// importJWE decodes the "token" and returns a new session of its values.
+func (s *Sessions) importJWE(sid, token string, keys [][]byte) (*Session, error) {
+	claims, err := decodeJWE(token, s.provider.now(), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(Store, 0, len(claims.Values))
+	for key, value := range claims.Values {
+		values.Set(key, value)
+	}
+
+	sess := s.provider.Init(sid, s.config.Expires)
+	if err = sess.hydrate(values); err != nil {
+		s.provider.Destroy(sid)
+		return nil, err
+	}
+	return sess, nil
+}
+
+// ImportJWE imports the session of the "token", see `Sessions#ImportJWE`.
+func ImportJWE(w http.ResponseWriter, r *http.Request, token string, keys ...[]byte) (*Session, error) {
+	return Default.ImportJWE(w, r, token, keys...)
+}
+
+// ImportJWE decrypts the "token", exported by the `Session#ExportJWE`, with any of the "keys",
+// and starts a new session, with a new session id, of its values for the client.
+// It returns the `ErrInvalidJWE` or the `ErrJWEExpired` if the token can't be imported.
+func (s *Sessions) ImportJWE(w http.ResponseWriter, r *http.Request, token string, keys ...[]byte) (*Session, error) {
+	sid := s.config.IDGenerator(r.Context())
+	sess, err := s.importJWE(sid, token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	s.updateCookie(w, r, sid, s.config.Expires)
+	return s.hold(r.Context(), sess, &httpRequester{s: s, w: w, r: r}), nil
+}
+
+// ImportJWEFasthttp imports the session of the "token", see `Sessions#ImportJWE`.
+func ImportJWEFasthttp(ctx *fasthttp.RequestCtx, token string, keys ...[]byte) (*Session, error) {
+	return Default.ImportJWEFasthttp(ctx, token, keys...)
+}
+
+// ImportJWEFasthttp imports the session of the "token", see `Sessions#ImportJWE`.
+func (s *Sessions) ImportJWEFasthttp(ctx *fasthttp.RequestCtx, token string, keys ...[]byte) (*Session, error) {
+	sid := s.config.IDGenerator(ctx)
+	sess, err := s.importJWE(sid, token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	s.updateCookieFasthttp(ctx, sid, s.config.Expires)
+	return s.hold(context.Background(), sess, &fasthttpRequester{s: s, ctx: ctx}), nil
+}
@@ -0,0 +1,85 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWE(t *testing.T) {
+	key := []byte("01234567890123456789012345678901")
+
+	web := New(Config{})
+	sess := web.provider.Init("web-sid", time.Minute)
+	sess.Set("user", "go-sessions")
+	sess.Set("cart", 3)
+
+	token, err := sess.ExportJWE(key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parts := strings.Split(token, "."); len(parts) != 5 || parts[1] != "" {
+		t.Fatalf("expected a compact serialization of a direct encryption but got %q", token)
+	}
+
+	if strings.Contains(token, "go-sessions") {
+		t.Fatal("expected the values to be encrypted")
+	}
+
+	app := New(Config{})
+	w := httptest.NewRecorder()
+	imported, err := app.ImportJWE(w, httptest.NewRequest(http.MethodGet, "/handoff", nil), token, []byte("0123456789012345"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imported.ID() == "web-sid" || w.Result().Cookies()[0].Value != imported.ID() {
+		t.Fatalf("expected a new session id to be sent to the client but got %q", imported.ID())
+	}
+
+	if v := imported.GetString("user"); v != "go-sessions" {
+		t.Fatalf("expected the user value to be imported but got %q", v)
+	}
+
+	if v, err := imported.GetInt("cart"); err != nil || v != 3 {
+		t.Fatalf("expected the cart value to be imported but got %v (%v)", v, err)
+	}
+
+	if _, err = DecodeJWE(token, []byte("10234567890123456789012345678901")); err != ErrInvalidJWE {
+		t.Fatalf("expected an invalid token error for the wrong key but got: %v", err)
+	}
+
+	if _, err = DecodeJWE(token[:len(token)-2]+"AA", key); err != ErrInvalidJWE {
+		t.Fatalf("expected an invalid token error for a tampered token but got: %v", err)
+	}
+
+	expired, err := sess.ExportJWE(key, -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = DecodeJWE(expired, key); err != ErrJWEExpired {
+		t.Fatalf("expected an expired token error but got: %v", err)
+	}
+}
+
+func TestJWEClock(t *testing.T) {
+	key := []byte("01234567890123456789012345678901")
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{Clock: clock})
+
+	sess := manager.provider.Init("web-sid", time.Hour)
+	sess.Set("user", "go-sessions")
+	token, err := sess.ExportJWE(key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err = manager.ImportJWE(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/handoff", nil), token, key); err != ErrJWEExpired {
+		t.Fatalf("expected the token to expire by the manager's clock but got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package sessions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrJWTKeyMissing returned by the `NewJWTSigner` when no key was given.
+	ErrJWTKeyMissing = errors.New("jwt: at least one key is required")
+	// ErrInvalidJWT returned by the `DecodeJWT` when the token is malformed,
+	// it's not signed with HS256 or it's not signed by any of the keys.
+	ErrInvalidJWT = errors.New("jwt: invalid token")
+	// ErrJWTExpired returned by the `DecodeJWT` when the token's "exp" claim has passed.
+	ErrJWTExpired = errors.New("jwt: token expired")
+)
+
+// jwtHeader is the header of the session id tokens, they are signed with HMAC-SHA256.
+var jwtHeader = jweEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// JWTClaims are the claims of a session id token, see `JWTSigner`.
+type JWTClaims struct {
+	// SessionID is the session id ("sid").
+	SessionID string `json:"sid"`
+	// Audience is the name of the session cookie which the token was issued for ("aud").
+	Audience string `json:"aud,omitempty"`
+	// IssuedAt and Expiry are the unix times ("iat" and "exp") of the token's issue and expiration,
+	// the Expiry is zero if the token doesn't expire.
+	IssuedAt int64 `json:"iat"`
+	Expiry   int64 `json:"exp,omitempty"`
+}
+
+// JWTSigner wraps the session ids to JWTs (RFC 7519) signed with HS256,
+// with the issued-at and the expiry claims, so an edge service, i.e a CDN worker or an API gateway,
+// can validate the session's expiry without a backend lookup, see `DecodeJWT`.
+// The session's data are still kept server-side.
+//
+// Keys can be rotated, the first key is used to sign and all of them are tried to verify.
+//
+// Usage:
+// signer, err := sessions.NewJWTSigner(2*time.Hour, newKey, oldKey)
+// sessions.New(sessions.Config{Expires: 2 * time.Hour, Encode: signer.Encode, Decode: signer.Decode})
+type JWTSigner struct {
+	keys    [][]byte
+	expires time.Duration
+	clock   Clock
+}
+
+// NewJWTSigner returns a new JWT signer, the "keys" should be at least 32 bytes.
+// The "expires" is the lifetime of the tokens, it should match the `Config#Expires`,
+// the token is re-issued when the session's expiration is updated, see `Sessions#ShiftExpiration`.
+// Zero means that the tokens don't expire.
+func NewJWTSigner(expires time.Duration, keys ...[]byte) (*JWTSigner, error) {
+	if len(keys) == 0 {
+		return nil, ErrJWTKeyMissing
+	}
+
+	return &JWTSigner{keys: keys, expires: expires, clock: SystemClock}, nil
+}
+
+// SetClock sets the clock of the tokens' issued-at and expiry, it should be the manager's `Config#Clock`.
+// Defaults to the `SystemClock`.
+func (j *JWTSigner) SetClock(clock Clock) {
+	j.clock = clock
+}
+
+func signJWT(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Encode returns the signed token of the session id "value" of the "cookieName" cookie.
+// It can be used as the `Config#Encode`.
+func (j *JWTSigner) Encode(cookieName string, value interface{}) (string, error) {
+	sid, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("jwt: expected a string value but got %T", value)
+	}
+
+	now := j.clock.Now()
+	claims := JWTClaims{SessionID: sid, Audience: cookieName, IssuedAt: now.Unix()}
+	if j.expires > 0 {
+		claims.Expiry = now.Add(j.expires).Unix()
+	}
+
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	payload := jwtHeader + "." + jweEncoding.EncodeToString(b)
+	return payload + "." + jweEncoding.EncodeToString(signJWT(j.keys[0], payload)), nil
+}
+
+// Decode verifies the token "cookieValue" of the "cookieName" cookie and sets the session id to the "v",
+// which should be a *string or a **string, the expired tokens are rejected.
+// It can be used as the `Config#Decode`.
+func (j *JWTSigner) Decode(cookieName string, cookieValue string, v interface{}) error {
+	claims, err := decodeJWT(cookieValue, j.clock.Now(), j.keys)
+	if err != nil {
+		return err
+	}
+
+	if claims.Audience != cookieName {
+		return ErrInvalidJWT
+	}
+
+	switch ptr := v.(type) {
+	case *string:
+		*ptr = claims.SessionID
+	case **string:
+		*ptr = &claims.SessionID
+	default:
+		return fmt.Errorf("jwt: expected a *string but got %T", v)
+	}
+
+	return nil
+}
+
+// DecodeJWT verifies the session id "token", issued by a `JWTSigner` of one of the "keys", and returns its claims.
+// It returns the `ErrInvalidJWT` if the token is malformed or its signature is invalid
+// and the `ErrJWTExpired` if it's expired.
+func DecodeJWT(token string, keys ...[]byte) (JWTClaims, error) {
+	return decodeJWT(token, time.Now(), keys)
+}
+
+// decodeJWT same as `DecodeJWT` but the expiry is checked against the "now", see `JWTSigner#SetClock`.
+func decodeJWT(token string, now time.Time, keys [][]byte) (JWTClaims, error) {
+	var claims JWTClaims
+
+	dot := strings.LastIndexByte(token, '.')
+	if dot <= 0 || !strings.HasPrefix(token, jwtHeader+".") {
+		return claims, ErrInvalidJWT
+	}
+
+	payload := token[:dot]
+	signature, err := jweEncoding.DecodeString(token[dot+1:])
+	if err != nil {
+		return claims, ErrInvalidJWT
+	}
+
+	valid := false
+	for _, key := range keys {
+		if hmac.Equal(signature, signJWT(key, payload)) {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return claims, ErrInvalidJWT
+	}
+
+	b, err := jweEncoding.DecodeString(payload[len(jwtHeader)+1:])
+	if err != nil {
+		return claims, ErrInvalidJWT
+	}
+
+	if err = json.Unmarshal(b, &claims); err != nil || claims.SessionID == "" {
+		return JWTClaims{}, ErrInvalidJWT
+	}
+
+	if claims.Expiry > 0 && now.Unix() >= claims.Expiry {
+		return claims, ErrJWTExpired
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,86 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJWTSigner(t *testing.T) {
+	oldKey, newKey := []byte("01234567890123456789012345678901"), []byte("abcdefghijklmnopqrstuvwxyz012345")
+
+	if _, err := NewJWTSigner(time.Hour); err != ErrJWTKeyMissing {
+		t.Fatalf("expected the ErrJWTKeyMissing but got %v", err)
+	}
+
+	signer, _ := NewJWTSigner(time.Hour, oldKey)
+	manager := New(Config{Expires: time.Hour, Encode: signer.Encode, Decode: signer.Decode})
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "kataras")
+	cookie := findCookie(w, DefaultCookieName)
+
+	// the edge validates the token without the backend.
+	claims, err := DecodeJWT(cookie.Value, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.SessionID != sess.ID() || claims.Audience != DefaultCookieName || claims.Expiry-claims.IssuedAt != 3600 {
+		t.Fatalf("unexpected claims %#v", claims)
+	}
+
+	// the keys are rotated.
+	rotated, _ := NewJWTSigner(time.Hour, newKey, oldKey)
+	manager = New(Config{Expires: time.Hour, Encode: rotated.Encode, Decode: rotated.Decode})
+	manager.provider = sess.provider
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	if got := manager.Start(httptest.NewRecorder(), r).GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session of the old key's token but got the name %q", got)
+	}
+
+	if _, err = DecodeJWT(cookie.Value, newKey); err != ErrInvalidJWT {
+		t.Fatalf("expected the ErrInvalidJWT of an unknown key but got %v", err)
+	}
+
+	// the token of another cookie is rejected.
+	other, _ := signer.Encode("other", sess.ID())
+	var sid string
+	if err = signer.Decode(DefaultCookieName, other, &sid); err != ErrInvalidJWT {
+		t.Fatalf("expected the ErrInvalidJWT of another cookie's token but got %v", err)
+	}
+
+	// the "exp" is in seconds, it's passed as soon as it's issued.
+	expired, _ := NewJWTSigner(time.Nanosecond, oldKey)
+	token, _ := expired.Encode(DefaultCookieName, sess.ID())
+	if _, err = DecodeJWT(token, oldKey); err != ErrJWTExpired {
+		t.Fatalf("expected the ErrJWTExpired but got %v", err)
+	}
+}
+
+func TestJWTSignerClock(t *testing.T) {
+	key := []byte("01234567890123456789012345678901")
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	signer, _ := NewJWTSigner(time.Hour, key)
+	signer.SetClock(clock)
+
+	token, err := signer.Encode(DefaultCookieName, "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sid string
+	if err = signer.Decode(DefaultCookieName, token, &sid); err != nil || sid != "sid" {
+		t.Fatalf("expected the token to be valid by the clock but got %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if err = signer.Decode(DefaultCookieName, token, &sid); err != ErrJWTExpired {
+		t.Fatalf("expected the token to expire by the clock but got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package sessions
+
+import (
+	"strings"
+)
+
+// The built-in key normalizers, see `Config#KeyNormalizer`.
+var (
+	_ func(string) string = TrimKey
+	_ func(string) string = LowerKey
+)
+
+// TrimKey removes the leading and trailing white spaces of the key.
+func TrimKey(key string) string {
+	return strings.TrimSpace(key)
+}
+
+// LowerKey returns the lower case of the key, so keys which differ only in case are the same.
+func LowerKey(key string) string {
+	return strings.ToLower(key)
+}
+
+// NormalizeKeys returns a key normalizer which calls the "normalizers" in order,
+// i.e sessions.NormalizeKeys(sessions.TrimKey, sessions.LowerKey, norm.NFC.String)
+// of the golang.org/x/text/unicode/norm package, see `Config#KeyNormalizer`.
+func NormalizeKeys(normalizers ...func(key string) string) func(key string) string {
+	return func(key string) string {
+		for _, normalize := range normalizers {
+			key = normalize(key)
+		}
+		return key
+	}
+}
+
+// key returns the normalized "key", see `Config#KeyNormalizer`.
+func (s *Session) key(key string) string {
+	if cfg := s.provider.config; cfg != nil && cfg.KeyNormalizer != nil {
+		return cfg.KeyNormalizer(key)
+	}
+	return key
+}
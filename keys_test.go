@@ -0,0 +1,51 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyNormalizer(t *testing.T) {
+	manager := New(Config{KeyNormalizer: NormalizeKeys(TrimKey, LowerKey)})
+	sess := manager.provider.Init("sid", 0)
+	defer manager.DestroyByID("sid")
+
+	sess.Set(" User-Agent ", "go")
+	if v := sess.GetString("user-agent"); v != "go" {
+		t.Fatalf("expected the normalized key to be found but got %q", v)
+	}
+
+	if v := sess.GetString("USER-AGENT"); v != "go" {
+		t.Fatalf("expected the key to be normalized on get but got %q", v)
+	}
+
+	sess.Append("Viewed", "a")
+	sess.Append("viewed ", "b")
+	if v := sess.GetSlice("VIEWED"); len(v) != 2 {
+		t.Fatalf("expected a single list of the normalized key but got %v", v)
+	}
+
+	if all := sess.GetAll(); len(all) != 2 || all["user-agent"] != "go" {
+		t.Fatalf("expected the keys to be stored normalized but got %v", all)
+	}
+
+	if !sess.Delete("User-Agent") || sess.Get("user-agent") != nil {
+		t.Fatal("expected the key to be normalized on delete")
+	}
+}
+
+func TestVisitWithNormalizedPrefix(t *testing.T) {
+	manager := New(Config{KeyNormalizer: LowerKey})
+	sess := manager.provider.Init("sid", time.Hour)
+	sess.Set("User.Name", "kataras")
+	sess.Set("Other", "makis")
+
+	var visited []string
+	sess.VisitWith(VisitOptions{Prefix: "USER."}, func(key string, _ interface{}) bool {
+		visited = append(visited, key)
+		return true
+	})
+	if len(visited) != 1 || visited[0] != "user.name" {
+		t.Fatalf("expected the prefix to be normalized as the keys but got %v", visited)
+	}
+}
@@ -12,7 +12,16 @@ type LifeTime struct {
 	// Because of gob encoding it doesn't encodes/decodes the other fields if time.Time is embedded
 	// (this should be a bug(go1.9-rc1) or not. We don't care atm)
 	time.Time
-	timer *time.Timer
+	timer Timer
+	// clock is the manager's clock, nil means the `SystemClock`, see `Config#Clock`.
+	clock Clock
+}
+
+func (lt *LifeTime) getClock() Clock {
+	if lt.clock == nil {
+		return SystemClock
+	}
+	return lt.clock
 }
 
 // Begin will begin the life based on the time.Now().Add(d).
@@ -22,8 +31,9 @@ func (lt *LifeTime) Begin(d time.Duration, onExpire func()) {
 		return
 	}
 
-	lt.Time = time.Now().Add(d)
-	lt.timer = time.AfterFunc(d, onExpire)
+	clock := lt.getClock()
+	lt.Time = clock.Now().Add(d)
+	lt.timer = clock.NewTimer(d, onExpire)
 }
 
 // Revive will continue the life based on the stored Time.
@@ -33,16 +43,18 @@ func (lt *LifeTime) Revive(onExpire func()) {
 		return
 	}
 
-	now := time.Now()
+	clock := lt.getClock()
+	now := clock.Now()
 	if lt.Time.After(now) {
 		d := lt.Time.Sub(now)
-		lt.timer = time.AfterFunc(d, onExpire)
+		lt.timer = clock.NewTimer(d, onExpire)
 	}
 }
 
 // Shift resets the lifetime based on "d".
 func (lt *LifeTime) Shift(d time.Duration) {
 	if d > 0 && lt.timer != nil {
+		lt.Time = lt.getClock().Now().Add(d)
 		lt.timer.Reset(d)
 	}
 }
@@ -61,5 +73,13 @@ func (lt *LifeTime) HasExpired() bool {
 		return false
 	}
 
-	return lt.Time.Before(time.Now())
+	return lt.Time.Before(lt.getClock().Now())
+}
+
+// stop stops the expiration timer without modifying the lifetime,
+// it's used when the manager is closed.
+func (lt *LifeTime) stop() {
+	if lt.timer != nil {
+		lt.timer.Stop()
+	}
 }
@@ -0,0 +1,130 @@
+package sessions
+
+import (
+	"errors"
+	"reflect"
+)
+
+// errItemMissing is returned by the `removeFrom` when the item is not in the list, nothing is saved.
+var errItemMissing = errors.New("item missing")
+
+// listLen returns the length of the "v" as a list, see `toSlice`.
+func listLen(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+
+	if list, ok := v.([]interface{}); ok {
+		return len(list)
+	}
+
+	rv := reflect.ValueOf(v)
+	if kind := rv.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		return 1
+	}
+	return rv.Len()
+}
+
+// removeFrom returns a new list of the "v" without the elements which are equal to the "item",
+// the `errItemMissing` if there are none.
+func removeFrom(v interface{}, item interface{}) ([]interface{}, error) {
+	list := toSlice(v)
+	removed := make([]interface{}, 0, len(list))
+	for _, elem := range list {
+		if !reflect.DeepEqual(elem, item) {
+			removed = append(removed, elem)
+		}
+	}
+
+	if len(removed) == len(list) {
+		return nil, errItemMissing
+	}
+	return removed, nil
+}
+
+// AppendTo appends the "items" to the list of the "key", see `Append`,
+// and returns the length of the list or the `ErrEntryImmutable` if the entry is immutable.
+func (r *Store) AppendTo(key string, items ...interface{}) (int, error) {
+	if r.isImmutable(key) {
+		return 0, ErrEntryImmutable
+	}
+
+	entry, _ := r.Append(key, items...)
+	return listLen(entry.ValueRaw), nil
+}
+
+// RemoveFrom removes the elements which are equal, by reflect.DeepEqual, to the "item"
+// from the list of the "key", i.e a product of a shopping cart, and reports whether any was removed.
+// The list is replaced, the lists which the `GetSlice` returned are not modified.
+// An immutable entry is not modified.
+func (r *Store) RemoveFrom(key string, item interface{}) bool {
+	if r.isImmutable(key) {
+		return false
+	}
+
+	list, err := removeFrom(r.Get(key), item)
+	if err != nil {
+		return false
+	}
+
+	r.Set(key, list)
+	return true
+}
+
+// ListLen returns the length of the list of the "key", 0 if it's missing, see `GetSlice`.
+func (r *Store) ListLen(key string) int {
+	return listLen(r.Get(key))
+}
+
+// AppendTo appends the "items" to the list of the "key", see `Store.AppendTo`,
+// and returns the length of the list or the `ErrEntryImmutable` if the entry is immutable,
+// the `ErrReadOnly` if the session is read-only. The `Append` is the same without the results.
+func (s *Session) AppendTo(key string, items ...interface{}) (int, error) {
+	for _, item := range items {
+		if err := checkRecursive(key, item); err != nil {
+			return 0, err
+		}
+	}
+	s.registerTypes(items...)
+
+	var n int
+	err := s.update(key, false, func(key string) (interface{}, error) {
+		entry, exists := s.values.GetEntry(key)
+		if entry.immutable {
+			return nil, ErrEntryImmutable
+		}
+
+		list := append([]interface{}(nil), items...)
+		if exists {
+			list = appendList(entry.ValueRaw, items)
+		}
+
+		n = len(list)
+		return list, nil
+	})
+
+	return n, err
+}
+
+// RemoveFrom removes the elements which are equal to the "item" from the list of the "key",
+// and reports whether any was removed, see `Store.RemoveFrom`.
+func (s *Session) RemoveFrom(key string, item interface{}) bool {
+	err := s.update(key, false, func(key string) (interface{}, error) {
+		if s.values.isImmutable(key) {
+			return nil, ErrEntryImmutable
+		}
+		return removeFrom(s.values.Get(key), item)
+	})
+
+	return err == nil
+}
+
+// ListLen returns the length of the list of the "key", 0 if it's missing, see `GetSlice`.
+func (s *Session) ListLen(key string) int {
+	key = s.key(key)
+	s.mu.RLock()
+	n := s.values.ListLen(key)
+	s.mu.RUnlock()
+
+	return n
+}
@@ -0,0 +1,164 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultLockTTL is the default lifetime of a distributed session lock, see `Config#LockTTL`.
+	DefaultLockTTL = 30 * time.Second
+	// lockRetryInterval is the interval of the attempts to acquire a distributed lock which is held by another app instance.
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+var (
+	// ErrLockNotHeld returned by the `Session#Unlock` when the session is not locked by the `Session#Lock`.
+	ErrLockNotHeld = errors.New("sessions: the session lock is not held")
+	// ErrSessionBusy is logged when a request can't hold its session in the `Config#SingleWriterTimeout`,
+	// the session of the request is read-only, see `Config#SingleWriter`.
+	ErrSessionBusy = errors.New("sessions: the session is held by another request")
+)
+
+// Locker is an optional interface of a `Database` which can lock a session across the app instances,
+// i.e the redis database, see `Session#Lock`.
+type Locker interface {
+	// TryLock acquires the lock of the session "sid" for the "ttl", the "token" identifies the owner of the lock.
+	// It reports whether the lock was acquired, false if it's held by another owner.
+	TryLock(sid, token string, ttl time.Duration) (bool, error)
+	// Unlock releases the lock of the session "sid" if it's held by the "token".
+	Unlock(sid, token string) error
+}
+
+// Lock locks the session across the app instances, so the handlers which read-modify-write
+// the session's values, i.e a checkout flow, are serialized, it blocks until the lock is acquired
+// or the "ctx" is done. The session is locked by the registered databases which implement the `Locker`,
+// its values are reloaded from the databases after the lock is acquired, so the changes of the
+// previous holder are visible.
+//
+// The lock expires after the `Config#LockTTL`, so the lock of a crashed app instance is released eventually.
+// The requests of the session on this app instance are serialized too, unless the `Config#SingleWriter`
+// is used, the request holds the session already.
+//
+// Call the `Unlock` when done, it's not released by the `Release`,
+// unless it's acquired by the `Start` of the `Config#DistributedSingleWriter`.
+func (s *Session) Lock(ctx context.Context) error {
+	cfg := s.provider.config
+	var locker chan struct{}
+	if cfg == nil || !cfg.SingleWriter {
+		s.mu.Lock()
+		if s.locker == nil {
+			s.locker = make(chan struct{}, 1)
+		}
+		locker = s.locker
+		s.mu.Unlock()
+
+		select {
+		case locker <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	ttl := DefaultLockTTL
+	if cfg != nil && cfg.LockTTL > 0 {
+		ttl = cfg.LockTTL
+	}
+
+	token := randomToken()
+	var locked []Locker
+	for _, db := range s.provider.databases {
+		l, ok := db.(Locker)
+		if !ok {
+			continue
+		}
+
+		if err := tryLock(ctx, l, s.ID(), token, ttl); err != nil {
+			for _, l := range locked {
+				l.Unlock(s.ID(), token)
+			}
+			if locker != nil {
+				<-locker
+			}
+			return err
+		}
+		locked = append(locked, l)
+	}
+
+	s.mu.Lock()
+	s.lockToken = token
+	s.lockedBy = locked
+	s.mu.Unlock()
+
+	if len(locked) > 0 {
+		s.reload()
+	}
+
+	return nil
+}
+
+// tryLock acquires the lock of the "l", it retries until the "ctx" is done.
+func tryLock(ctx context.Context, l Locker, sid, token string, ttl time.Duration) error {
+	for {
+		ok, err := l.TryLock(sid, token, ttl)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return nil
+		}
+
+		t := time.NewTimer(lockRetryInterval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reload loads the values and the version of the session from the databases.
+func (s *Session) reload() {
+	stored := s.provider.loadSessionFromDB(s.ID())
+
+	s.mu.Lock()
+	s.values = stored.Values
+	// the undo records refer to the replaced values.
+	s.journal = s.journal[:0]
+	atomic.StoreUint64(&s.version, stored.Version)
+	s.mu.Unlock()
+}
+
+// Unlock releases the lock which was acquired by the `Lock`,
+// it returns the `ErrLockNotHeld` if the session is not locked.
+func (s *Session) Unlock() error {
+	s.mu.Lock()
+	token, locked := s.lockToken, s.lockedBy
+	s.lockToken, s.lockedBy = "", nil
+	locker := s.locker
+	s.mu.Unlock()
+
+	if token == "" {
+		return ErrLockNotHeld
+	}
+
+	var firstErr error
+	for _, l := range locked {
+		if err := l.Unlock(s.ID(), token); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if locker != nil {
+		select {
+		case <-locker:
+		default:
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,83 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockerDatabase is a shared session database which can lock the sessions.
+type lockerDatabase struct {
+	*concurrentDatabase
+	locksMu sync.Mutex
+	locks   map[string]string
+}
+
+func (db *lockerDatabase) TryLock(sid, token string, ttl time.Duration) (bool, error) {
+	db.locksMu.Lock()
+	defer db.locksMu.Unlock()
+	if _, held := db.locks[sid]; held {
+		return false, nil
+	}
+	db.locks[sid] = token
+	return true, nil
+}
+
+func (db *lockerDatabase) Unlock(sid, token string) error {
+	db.locksMu.Lock()
+	if db.locks[sid] == token {
+		delete(db.locks, sid)
+	}
+	db.locksMu.Unlock()
+	return nil
+}
+
+func TestSessionLock(t *testing.T) {
+	db := &lockerDatabase{concurrentDatabase: newConcurrentDatabase(), locks: make(map[string]string)}
+
+	node1, node2 := New(Config{}), New(Config{})
+	node1.UseDatabase(db)
+	node2.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess1 := node1.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess1.Set("items", 1)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	sess2 := node2.Start(httptest.NewRecorder(), r)
+
+	if err := sess1.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := sess2.Lock(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the lock to be held by the other app instance but got %v", err)
+	}
+
+	sess1.Set("items", 2)
+	if err := sess1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sess2.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 2, sess2.Get("items"); got != expected {
+		t.Fatalf("expected the session to be reloaded after the lock, %d but got %v", expected, got)
+	}
+
+	if err := sess2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sess2.Unlock(); err != ErrLockNotHeld {
+		t.Fatalf("expected %v but got %v", ErrLockNotHeld, err)
+	}
+}
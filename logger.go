@@ -0,0 +1,30 @@
+package sessions
+
+// Logger logs the events of the manager and the session databases, see `Config#Logger`.
+// The *golog.Logger implements it.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// LoggerSetter is implemented by the session databases which log their errors,
+// the `UseDatabase` sets the `Config#Logger` to them, if it's not nil.
+type LoggerSetter interface {
+	SetLogger(logger Logger)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
+
+// logger returns the `Config#Logger`, a logger which discards the events if it's nil.
+func (p *provider) logger() Logger {
+	if p.config != nil && p.config.Logger != nil {
+		return p.config.Logger
+	}
+
+	return nopLogger{}
+}
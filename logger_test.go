@@ -0,0 +1,78 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testLogger struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *testLogger) logf(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	l.events = append(l.events, level+" "+fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.logf("debug", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.logf("warn", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.logf("error", format, args...) }
+
+func (l *testLogger) has(prefix string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, event := range l.events {
+		if strings.HasPrefix(event, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type loggingDatabase struct {
+	*concurrentDatabase
+	logger Logger
+}
+
+func (db *loggingDatabase) SetLogger(logger Logger) {
+	db.logger = logger
+}
+
+func TestLogger(t *testing.T) {
+	logger := new(testLogger)
+	manager := New(Config{
+		Logger: logger,
+		Decode: func(cookieName, cookieValue string, v interface{}) error {
+			return errors.New("invalid cookie")
+		},
+	})
+
+	db := &loggingDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+	if db.logger != logger {
+		t.Fatalf("expected the logger to be set to the database")
+	}
+
+	if sid := manager.decodeCookieValue("forged"); sid != "" {
+		t.Fatalf("expected the invalid cookie to be rejected but got %q", sid)
+	}
+	if !logger.has("warn sessions: decode of the " + DefaultCookieName + " cookie: invalid cookie") {
+		t.Fatalf("expected the decode failure to be logged but got %v", logger.events)
+	}
+
+	manager.provider.Read("missing", manager.config.Expires)
+	if !logger.has("debug sessions: session missing is missing or expired") {
+		t.Fatalf("expected the access of a missing session to be logged but got %v", logger.events)
+	}
+
+	// without a logger nothing is logged.
+	silent := New(Config{Decode: manager.config.Decode})
+	if sid := silent.decodeCookieValue("forged"); sid != "" {
+		t.Fatalf("expected the invalid cookie to be rejected but got %q", sid)
+	}
+}
@@ -0,0 +1,190 @@
+// Package logout provides the http handlers of the identity provider-initiated logout,
+// OpenID Connect front-channel and back-channel logout and SAML single logout,
+// which destroy the local sessions bound to the login by the `Session#BindLogin`.
+package logout
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/kataras/go-sessions"
+)
+
+var (
+	// ErrClaimsMissing returned when the logout token or request has neither a subject nor a session id.
+	ErrClaimsMissing = errors.New("logout: subject and session id are missing")
+	// ErrRequestTooLarge returned when the inflated SAML LogoutRequest exceeds the `MaxRequestSize`.
+	ErrRequestTooLarge = errors.New("logout: the SAML request is too large")
+)
+
+// MaxRequestSize is the maximum size of an inflated SAML LogoutRequest of the HTTP-Redirect binding,
+// so a small deflated request can't exhaust the memory.
+const MaxRequestSize = 1 << 20
+
+// Token contains the claims of a verified OpenID Connect logout token.
+type Token struct {
+	// Subject is the "sub" claim.
+	Subject string
+	// SessionID is the "sid" claim, the identity provider's session id.
+	SessionID string
+}
+
+// TokenVerifier should verify the raw logout token, its signature, issuer, audience,
+// "events" claim and that it has no "nonce", based on the OpenID Connect Back-Channel Logout spec,
+// and return its claims.
+type TokenVerifier func(rawToken string) (Token, error)
+
+// BackChannel returns the OpenID Connect back-channel logout handler,
+// the identity provider posts a "logout_token" to it and
+// the sessions bound to its "sid" or, if missing, to its "sub" claim are destroyed.
+//
+// Usage:
+// http.Handle("/logout/backchannel", logout.BackChannel(manager, verify))
+func BackChannel(manager *sessions.Sessions, verify TokenVerifier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, err := verify(r.PostFormValue("logout_token"))
+		if err == nil && token.Subject == "" && token.SessionID == "" {
+			err = ErrClaimsMissing
+		}
+
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_request"}`))
+			return
+		}
+
+		manager.DestroyByLogin(token.Subject, token.SessionID)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// FrontChannel returns the OpenID Connect front-channel logout handler,
+// the identity provider renders it inside an iframe with the "iss" and "sid" query parameters.
+// The sessions bound to the "sid" are destroyed, if it's missing then the requester's session is destroyed.
+//
+// If "issuer" is not empty then requests with a different "iss" parameter are rejected.
+func FrontChannel(manager *sessions.Sessions, issuer string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache, no-store")
+
+		query := r.URL.Query()
+		if issuer != "" && query.Get("iss") != issuer {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if sid := query.Get("sid"); sid != "" {
+			manager.DestroyByLogin("", sid)
+		} else {
+			manager.Destroy(w, r)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Request is a SAML LogoutRequest.
+type Request struct {
+	// ID is the request's ID, it should be used as the "InResponseTo" of the LogoutResponse.
+	ID string `xml:"ID,attr"`
+	// Issuer is the identity provider's entity ID.
+	Issuer string `xml:"Issuer"`
+	// NameID is the subject of the logout.
+	NameID string `xml:"NameID"`
+	// SessionIndexes are the identity provider's session ids of the logout.
+	SessionIndexes []string `xml:"SessionIndex"`
+	// Raw is the decoded XML of the request.
+	Raw []byte `xml:"-"`
+}
+
+// RequestVerifier should verify the signature and the issuer of the SAML LogoutRequest.
+type RequestVerifier func(r *http.Request, req Request) error
+
+// SAML returns the SAML single logout handler, it accepts
+// LogoutRequests of the HTTP-Redirect and HTTP-POST bindings and destroys the sessions
+// bound to their SessionIndex or, if missing, to their NameID.
+//
+// The "respond" is called after the sessions are destroyed to send
+// the LogoutResponse to the identity provider, if nil then the handler responds with 200 OK.
+func SAML(manager *sessions.Sessions, verify RequestVerifier, respond func(w http.ResponseWriter, r *http.Request, req Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decodeRequest(r)
+		if err == nil {
+			err = verify(r, req)
+		}
+
+		if err != nil {
+			// the reason is not leaked to the requester.
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		if len(req.SessionIndexes) > 0 {
+			for _, sessionIndex := range req.SessionIndexes {
+				manager.DestroyByLogin("", sessionIndex)
+			}
+		} else {
+			manager.DestroyByLogin(req.NameID, "")
+		}
+
+		if respond != nil {
+			respond(w, r, req)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// decodeRequest decodes the "SAMLRequest", which is deflated on the HTTP-Redirect binding.
+func decodeRequest(r *http.Request) (req Request, err error) {
+	var encoded string
+	deflated := r.Method == http.MethodGet
+	if deflated {
+		encoded = r.URL.Query().Get("SAMLRequest")
+	} else {
+		encoded = r.PostFormValue("SAMLRequest")
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return
+	}
+
+	if deflated {
+		if b, err = ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(b)), MaxRequestSize+1)); err != nil {
+			return
+		}
+
+		if len(b) > MaxRequestSize {
+			err = ErrRequestTooLarge
+			return
+		}
+	}
+
+	if err = xml.Unmarshal(b, &req); err != nil {
+		return
+	}
+
+	if req.NameID == "" && len(req.SessionIndexes) == 0 {
+		err = ErrClaimsMissing
+		return
+	}
+
+	req.Raw = b
+	return
+}
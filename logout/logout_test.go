@@ -0,0 +1,190 @@
+package logout
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+// login starts a session bound to the login of the "subject" and the "idpSessionID" and returns its id.
+func login(t *testing.T, manager *sessions.Sessions, subject, idpSessionID string) string {
+	t.Helper()
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("user", subject)
+	sess.BindLogin(subject, idpSessionID)
+	sess.Release()
+	return sess.ID()
+}
+
+// exists reports whether the session of the "sid" is alive.
+func exists(manager *sessions.Sessions, sid string) bool {
+	found := false
+	manager.Visit(func(visited string, _ *sessions.Session) bool {
+		found = visited == sid
+		return !found
+	})
+	return found
+}
+
+func TestBackChannel(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	sid := login(t, manager, "kataras", "idp-sid")
+	other := login(t, manager, "makis", "other-idp-sid")
+
+	errInvalid := errors.New("invalid signature")
+	handler := BackChannel(manager, func(rawToken string) (Token, error) {
+		switch rawToken {
+		case "valid":
+			return Token{SessionID: "idp-sid"}, nil
+		case "empty":
+			return Token{}, nil
+		default:
+			return Token{}, errInvalid
+		}
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected the 405 of a GET request but got %d", w.Code)
+	}
+
+	for _, token := range []string{"invalid", "empty"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, post(url.Values{"logout_token": {token}}))
+		if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"invalid_request"}` {
+			t.Fatalf("expected the 400 of the %s token but got %d: %s", token, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, post(url.Values{"logout_token": {"valid"}}))
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected the 200 of a valid token but got %d", w.Code)
+	}
+	if exists(manager, sid) || !exists(manager, other) {
+		t.Fatalf("expected only the session of the token's sid to be destroyed")
+	}
+}
+
+func TestFrontChannel(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	sid := login(t, manager, "kataras", "idp-sid")
+	handler := FrontChannel(manager, "https://idp")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?iss=https://other&sid=idp-sid", nil))
+	if w.Code != http.StatusBadRequest || !exists(manager, sid) {
+		t.Fatalf("expected the request of another issuer to be rejected but got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?iss=https://idp&sid=idp-sid", nil))
+	if w.Code != http.StatusOK || exists(manager, sid) {
+		t.Fatalf("expected the session of the sid to be destroyed but got %d", w.Code)
+	}
+
+	// without the sid the requester's session is destroyed.
+	start := httptest.NewRecorder()
+	manager.Start(start, httptest.NewRequest(http.MethodGet, "/", nil)).Set("user", "makis")
+	r := sessionstest.NextRequest(start, http.MethodGet, "/?iss=https://idp", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if n := manager.Count(); w.Code != http.StatusOK || n != 0 {
+		t.Fatalf("expected the requester's session to be destroyed but got %d sessions", n)
+	}
+}
+
+const samlRequest = `<samlp:LogoutRequest xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="req-1">` +
+	`<saml:Issuer>https://idp</saml:Issuer><saml:NameID>kataras</saml:NameID>%s</samlp:LogoutRequest>`
+
+func deflate(t *testing.T, b []byte) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(b)
+	fw.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func post(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestSAML(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	bySessionIndex := login(t, manager, "makis", "idp-sid")
+	byNameID := login(t, manager, "kataras", "")
+
+	var responded Request
+	handler := SAML(manager, func(r *http.Request, req Request) error {
+		if req.Issuer != "https://idp" {
+			return errors.New("unknown issuer https://other")
+		}
+		return nil
+	}, func(w http.ResponseWriter, r *http.Request, req Request) {
+		responded = req
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// the HTTP-Redirect binding.
+	raw := strings.Replace(samlRequest, "%s", "<samlp:SessionIndex>idp-sid</samlp:SessionIndex>", 1)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?SAMLRequest="+url.QueryEscape(deflate(t, []byte(raw))), nil))
+	if w.Code != http.StatusNoContent || responded.ID != "req-1" || string(responded.Raw) != raw {
+		t.Fatalf("expected the LogoutResponse of the request but got %d of %#v", w.Code, responded)
+	}
+	if exists(manager, bySessionIndex) || !exists(manager, byNameID) {
+		t.Fatalf("expected only the session of the SessionIndex to be destroyed")
+	}
+
+	// the HTTP-POST binding, the NameID is used without a SessionIndex.
+	raw = strings.Replace(samlRequest, "%s", "", 1)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, post(url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString([]byte(raw))}}))
+	if w.Code != http.StatusNoContent || exists(manager, byNameID) {
+		t.Fatalf("expected the session of the NameID to be destroyed but got %d", w.Code)
+	}
+
+	// the reason of a rejected request is not sent to the requester.
+	raw = strings.Replace(strings.Replace(samlRequest, "https://idp", "https://other", 1), "%s", "", 1)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, post(url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString([]byte(raw))}}))
+	if w.Code != http.StatusBadRequest || strings.Contains(w.Body.String(), "https://other") {
+		t.Fatalf("expected a generic 400 of the rejected request but got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSAMLRequestTooLarge(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	handler := SAML(manager, func(*http.Request, Request) error { return nil }, nil)
+
+	// a deflate bomb, a few kilobytes which inflate beyond the limit.
+	raw := strings.Replace(samlRequest, "%s", "<!--"+strings.Repeat("a", MaxRequestSize)+"-->", 1)
+	r := httptest.NewRequest(http.MethodGet, "/?SAMLRequest="+url.QueryEscape(deflate(t, []byte(raw))), nil)
+	if _, err := decodeRequest(r); err != ErrRequestTooLarge {
+		t.Fatalf("expected the ErrRequestTooLarge but got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected the 400 of the large request but got %d", w.Code)
+	}
+}
@@ -15,6 +15,7 @@ func init() {
 	gob.Register(Store{})
 	gob.Register(Entry{})
 	gob.Register(time.Time{})
+	gob.Register([]interface{}{})
 }
 
 // GobEncode accepts a store and writes
@@ -28,9 +29,26 @@ func GobEncode(store Store, w io.Writer) error {
 // GobSerialize same as GobEncode but it returns
 // the bytes using a temp buffer.
 func GobSerialize(store Store) ([]byte, error) {
-	w := new(bytes.Buffer)
+	w := acquireBuffer()
 	err := GobEncode(store, w)
-	return w.Bytes(), err
+	b := append([]byte(nil), w.Bytes()...)
+	releaseBuffer(w)
+	return b, err
+}
+
+// GobDecode accepts a "r" reader which contains a gob-encoded store,
+// i.e written by the `GobEncode`, and returns the decoded store.
+func GobDecode(r io.Reader) (Store, error) {
+	var store Store
+	dec := gob.NewDecoder(r)
+	err := dec.Decode(&store)
+	return store, err
+}
+
+// GobDeserialize same as GobDecode but it accepts
+// the bytes, i.e returned by the `GobSerialize`.
+func GobDeserialize(b []byte) (Store, error) {
+	return GobDecode(bytes.NewReader(b))
 }
 
 type (
@@ -45,29 +63,36 @@ type (
 	Store []Entry
 )
 
+// ErrEntryImmutable returned by the `SaveE` and the `SetE` when a mutable value is set
+// to the key of an immutable entry, the entry is not changed, see `Unfreeze`.
+var ErrEntryImmutable = errors.New("sessions: the entry is immutable")
+
+// Immutable reports whether the entry is immutable, see `Store.SetImmutable`.
+func (e Entry) Immutable() bool {
+	return e.immutable
+}
+
 // Value returns the value of the entry,
-// respects the immutable.
+// respects the immutable, the immutable values are deep copied, see `ImmutableCopyDepth`.
+// The immutable values without references, i.e the strings, the numbers and the time.Time values,
+// are returned without a copy and without allocations.
 func (e Entry) Value() interface{} {
 	if e.immutable {
+		switch e.ValueRaw.(type) {
+		case nil, string, int, int64, bool, float64, time.Time:
+			// the common scalars, they are copied by the interface conversion itself.
+			return e.ValueRaw
+		}
+
 		// take its value, no pointer even if setted with a rreference.
 		vv := reflect.Indirect(reflect.ValueOf(e.ValueRaw))
-
-		// return copy of that slice
-		if vv.Type().Kind() == reflect.Slice {
-			newSlice := reflect.MakeSlice(vv.Type(), vv.Len(), vv.Cap())
-			reflect.Copy(newSlice, vv)
-			return newSlice.Interface()
-		}
-		// return a copy of that map
-		if vv.Type().Kind() == reflect.Map {
-			newMap := reflect.MakeMap(vv.Type())
-			for _, k := range vv.MapKeys() {
-				newMap.SetMapIndex(k, vv.MapIndex(k))
-			}
-			return newMap.Interface()
+		if ImmutableCopyDepth < 0 || !hasReferences(vv.Type()) {
+			return vv.Interface()
 		}
+
+		// return a deep copy of that slice, map or struct, see `ImmutableCopyDepth`.
 		// if was *value it will return value{}.
-		return vv.Interface()
+		return deepCopyValue(vv, make(map[copyKey]reflect.Value), copyDepth()).Interface()
 	}
 	return e.ValueRaw
 }
@@ -79,6 +104,11 @@ func (e Entry) Value() interface{} {
 // Returns the entry and true if it was just inserted, meaning that
 // it will return the entry and a false boolean if the entry exists and it has been updated.
 func (r *Store) Save(key string, value interface{}, immutable bool) (Entry, bool) {
+	if immutable {
+		// copy on write, the references which the caller kept can't change the immutable value.
+		value = deepCopy(value)
+	}
+
 	args := *r
 	n := len(args)
 
@@ -124,6 +154,38 @@ func (r *Store) Save(key string, value interface{}, immutable bool) (Entry, bool
 	return kv, true
 }
 
+// SaveE same as `Save` but it returns the `ErrEntryImmutable` if the "key"'s entry is immutable
+// and "immutable" is false, instead of ignoring the value silently.
+// Self-referential values are rejected with an `ErrRecursiveValue` error.
+func (r *Store) SaveE(key string, value interface{}, immutable bool) (Entry, bool, error) {
+	if !immutable && r.isImmutable(key) {
+		return Entry{}, false, ErrEntryImmutable
+	}
+
+	if err := checkRecursive(key, value); err != nil {
+		return Entry{}, false, err
+	}
+
+	entry, inserted := r.Save(key, value, immutable)
+	return entry, inserted, nil
+}
+
+// isImmutable reports whether the entry of the "key" exists and it's immutable.
+func (r *Store) isImmutable(key string) bool {
+	for _, kv := range *r {
+		if kv.Key == key {
+			return kv.immutable
+		}
+	}
+	return false
+}
+
+// SetE same as `Set` but it returns the `ErrEntryImmutable` if the "key"'s entry is immutable,
+// so the caller can detect that the value was not changed, or the `ErrRecursiveValue`.
+func (r *Store) SetE(key string, value interface{}) (Entry, bool, error) {
+	return r.SaveE(key, value, false)
+}
+
 // Set saves a value to the key-value storage.
 // Returns the entry and true if it was just inserted, meaning that
 // it will return the entry and a false boolean if the entry exists and it has been updated.
@@ -135,6 +197,7 @@ func (r *Store) Set(key string, value interface{}) (Entry, bool) {
 
 // SetImmutable saves a value to the key-value storage.
 // Unlike `Set`, the output value cannot be changed by the caller later on (when .Get OR .Set)
+// and the "value" is deep copied, so the references which the caller kept can't change it either.
 //
 // An Immutable entry should be only changed with a `SetImmutable`, simple `Set` will not work
 // if the entry was immutable, for your own safety.
@@ -148,6 +211,110 @@ func (r *Store) SetImmutable(key string, value interface{}) (Entry, bool) {
 	return r.Save(key, value, true)
 }
 
+// Unfreeze makes the immutable entry of the "key" mutable again,
+// so it can be changed by a simple `Set` or be removed and replaced intentionally.
+// Each unfreeze is reported to the `OnUnfreeze`, the `Session#Unfreeze` logs it to the manager's `Config#Logger` instead.
+//
+// Returns true if the entry exists and it was immutable.
+func (r *Store) Unfreeze(key string) bool {
+	args := *r
+	for i, n := 0, len(args); i < n; i++ {
+		kv := &args[i]
+		if kv.Key == key {
+			if !kv.immutable {
+				return false
+			}
+
+			kv.immutable = false
+			if OnUnfreeze != nil {
+				OnUnfreeze(key, kv.ValueRaw)
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// Append appends the "values" to the list of the "key", the entry is created if it's missing,
+// a value which is not a list is converted to a list which starts with it.
+// The entry gets a new list, the copies of the store, i.e the snapshots of the session and the trials of its size limit,
+// keep their lists as they were.
+//
+// An immutable entry can't be appended, see `SetImmutable`.
+//
+// Returns the entry and true if it was just inserted.
+func (r *Store) Append(key string, values ...interface{}) (Entry, bool) {
+	args := *r
+	for i, n := 0, len(args); i < n; i++ {
+		kv := &args[i]
+		if kv.Key != key {
+			continue
+		}
+
+		if kv.immutable {
+			return *kv, false
+		}
+
+		kv.ValueRaw = appendList(kv.ValueRaw, values)
+		return *kv, false
+	}
+
+	return r.Save(key, append([]interface{}(nil), values...), false)
+}
+
+// appendList returns a new list of the "v" followed by the "values", see `Store.Append`.
+func appendList(v interface{}, values []interface{}) []interface{} {
+	list, ok := v.([]interface{})
+	if !ok {
+		list = toSlice(v)
+	}
+	// never append to the spare capacity of the list, it may be shared by a copy of the store.
+	return append(list[:len(list):len(list)], values...)
+}
+
+// toSlice converts the "v" to a []interface{},
+// the elements of a slice or an array are copied, any other value becomes the first element.
+func toSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+
+	rv := reflect.ValueOf(v)
+	if kind := rv.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		return []interface{}{v}
+	}
+
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list
+}
+
+// GetSlice returns the entry's value as a list, based on its key, see `Append`.
+// A slice of any type is converted to a []interface{}, any other value is returned as a list of one element.
+// If not found returns nil.
+//
+// The list shares its elements with the entry, it's capped so an append to it doesn't modify the entry.
+func (r *Store) GetSlice(key string) []interface{} {
+	list := toSlice(r.Get(key))
+	return list[:len(list):len(list)]
+}
+
+// OnUnfreeze is called by the `Store.Unfreeze` when an immutable entry becomes mutable,
+// set it to a function which writes an audit log of the change.
+//
+// Defaults to nil.
+//
+// Deprecated: the `Session#Unfreeze` logs the change to the manager's `Config#Logger`,
+// this hook is shared by all managers and it's not called for the sessions.
+var OnUnfreeze func(key string, value interface{})
+
 // GetDefault returns the entry's value based on its key.
 // If not found returns "def".
 func (r *Store) GetDefault(key string, def interface{}) interface{} {
@@ -169,6 +336,35 @@ func (r *Store) Get(key string) interface{} {
 	return r.GetDefault(key, nil)
 }
 
+// GetEntry returns the entry of the "key" and true, or false if not found,
+// unlike the `Get` it separates a missing key from a key with a nil value.
+// The value of an immutable entry is a copy of the stored one, see `ImmutableCopyDepth`.
+func (r *Store) GetEntry(key string) (Entry, bool) {
+	args := *r
+	for i, n := 0, len(args); i < n; i++ {
+		if kv := args[i]; kv.Key == key {
+			if kv.immutable {
+				kv.ValueRaw = deepCopy(kv.ValueRaw)
+			}
+			return kv, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// Exists reports whether the "key" exists, even if its value is nil.
+func (r *Store) Exists(key string) bool {
+	args := *r
+	for i, n := 0, len(args); i < n; i++ {
+		if args[i].Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Visit accepts a visitor which will be filled
 // by the key-value objects.
 func (r *Store) Visit(visitor func(key string, value interface{})) {
@@ -179,6 +375,53 @@ func (r *Store) Visit(visitor func(key string, value interface{})) {
 	}
 }
 
+// VisitOptions are the filter and the pagination options of the `Store.VisitWith`
+// and of the `Sessions#VisitWith`.
+type VisitOptions struct {
+	// Prefix if not empty then only the entries whose key starts with it are visited.
+	Prefix string
+	// Predicate if not nil then only the entries which it returns true for are visited.
+	Predicate func(key string, value interface{}) bool
+	// Offset is the number of the matched entries to skip.
+	Offset int
+	// Limit is the maximum number of the entries to visit,
+	// 0 means no limit.
+	Limit int
+}
+
+// VisitWith same as `Visit` but only the entries which pass the "opts" filter and are inside
+// the "opts" offset and limit are visited, the visitor can return false to stop the iteration,
+// so huge stores can be paginated without copying their entries.
+func (r *Store) VisitWith(opts VisitOptions, visitor func(key string, value interface{}) bool) {
+	args := *r
+	skipped, visited := 0, 0
+	for i, n := 0, len(args); i < n; i++ {
+		if opts.Limit > 0 && visited >= opts.Limit {
+			return
+		}
+
+		kv := args[i]
+		if opts.Prefix != "" && !strings.HasPrefix(kv.Key, opts.Prefix) {
+			continue
+		}
+
+		value := kv.Value()
+		if opts.Predicate != nil && !opts.Predicate(kv.Key, value) {
+			continue
+		}
+
+		if skipped < opts.Offset {
+			skipped++
+			continue
+		}
+
+		visited++
+		if !visitor(kv.Key, value) {
+			return
+		}
+	}
+}
+
 // GetStringDefault returns the entry's value as string, based on its key.
 // If not found returns "def".
 func (r *Store) GetStringDefault(key string, def string) string {
@@ -222,6 +465,11 @@ func (r *Store) GetIntDefault(key string, def int) (int, error) {
 			return def, nil
 		}
 		return strconv.Atoi(vstring)
+	} else if vint, nok, err := numberToInt(v); nok {
+		if err != nil {
+			return def, err
+		}
+		return vint, nil
 	}
 
 	return def, nil
@@ -247,6 +495,11 @@ func (r *Store) GetInt64Default(key string, def int64) (int64, error) {
 			return def, nil
 		}
 		return strconv.ParseInt(vstring, 10, 64)
+	} else if vint64, nok, err := numberToInt64(v); nok {
+		if err != nil {
+			return def, err
+		}
+		return vint64, nil
 	}
 
 	return def, nil
@@ -272,6 +525,11 @@ func (r *Store) GetFloat64Default(key string, def float64) (float64, error) {
 			return def, nil
 		}
 		return strconv.ParseFloat(vstring, 64)
+	} else if vfloat64, nok, err := numberToFloat64(v); nok {
+		if err != nil {
+			return def, err
+		}
+		return vfloat64, nil
 	}
 
 	return def, nil
@@ -353,8 +611,40 @@ func (r *Store) Len() int {
 	return len(args)
 }
 
-// Serialize returns the byte representation of the current Store.
+// Serialize returns the byte representation of the current Store,
+// based on the `DefaultTranscoder`.
+//
+// Serialization errors, i.e a value's type is not registered to gob, are reported
+// to the `OnSerializeError` and nil is returned.
+//
+// Deprecated: use the `SerializeE`, it returns the error to the caller.
 func (r Store) Serialize() []byte { // note: no pointer here, ignore linters if shows up.
-	b, _ := GobSerialize(r)
+	b, err := r.SerializeE()
+	if err != nil {
+		if OnSerializeError != nil {
+			OnSerializeError(err)
+		}
+		return nil
+	}
 	return b
 }
+
+// OnSerializeError is called by the `Store.Serialize`
+// when the store cannot be serialized, i.e a value's type is not registered to gob,
+// so data loss can be detected.
+// Set it to a function which logs or panics with the error.
+//
+// Defaults to nil, the error is ignored and `Serialize` returns nil bytes.
+//
+// Deprecated: use the `Store.SerializeE` and handle its error, this hook is shared by all managers.
+var OnSerializeError func(err error)
+
+// SerializeE same as `Serialize` but it returns the serialization error, if any.
+func (r Store) SerializeE() ([]byte, error) {
+	values, err := encodeHooks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DefaultTranscoder.Marshal(values)
+}
@@ -0,0 +1,541 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	var store Store
+	store.Set("name", "go-sessions")
+	store.Set("days", 1)
+	store.Set("tags", []string{"fast", "simple"})
+
+	b, err := GobSerialize(store)
+	if err != nil {
+		t.Fatalf("while serializing the store: %v", err)
+	}
+
+	got, err := GobDeserialize(b)
+	if err != nil {
+		t.Fatalf("while deserializing the store: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, store) {
+		t.Fatalf("expected %#v but got %#v", store, got)
+	}
+
+	w := new(bytes.Buffer)
+	if err = GobEncode(store, w); err != nil {
+		t.Fatalf("while encoding the store: %v", err)
+	}
+
+	if got, err = GobDecode(w); err != nil {
+		t.Fatalf("while decoding the store: %v", err)
+	}
+
+	if expected, v := "go-sessions", got.GetString("name"); v != expected {
+		t.Fatalf("expected %s but got %s", expected, v)
+	}
+}
+
+func TestGobDeserializeInvalid(t *testing.T) {
+	if _, err := GobDeserialize([]byte("not a gob store")); err == nil {
+		t.Fatalf("expected an error on invalid input")
+	}
+}
+
+func TestStoreNumbers(t *testing.T) {
+	var store Store
+	store.Set("json", json.Number("42"))
+	store.Set("big", big.NewInt(7))
+	store.Set("rat", big.NewRat(5, 2))
+	store.Set("huge", new(big.Int).Lsh(big.NewInt(1), 100))
+
+	if v, err := store.GetInt("json"); err != nil || v != 42 {
+		t.Fatalf("expected 42 but got %d (%v)", v, err)
+	}
+
+	if v, err := store.GetInt64("big"); err != nil || v != 7 {
+		t.Fatalf("expected 7 but got %d (%v)", v, err)
+	}
+
+	if v, err := store.GetFloat64("rat"); err != nil || v != 2.5 {
+		t.Fatalf("expected 2.5 but got %f (%v)", v, err)
+	}
+
+	if _, err := store.GetInt("rat"); err == nil {
+		t.Fatalf("expected an error for a non-integer rational")
+	}
+
+	if _, err := store.GetInt64("huge"); err != ErrNumberOverflow {
+		t.Fatalf("expected ErrNumberOverflow but got %v", err)
+	}
+}
+
+func TestStoreSerializeError(t *testing.T) {
+	type unregistered struct{ Name string }
+
+	var store Store
+	store.Set("value", unregistered{Name: "go-sessions"})
+
+	if _, err := store.SerializeE(); err == nil {
+		t.Fatalf("expected an error for an unregistered gob type")
+	}
+
+	var reported error
+	OnSerializeError = func(err error) { reported = err }
+	defer func() { OnSerializeError = nil }()
+
+	if b := store.Serialize(); b != nil || reported == nil {
+		t.Fatalf("expected nil bytes and a reported error but got %v and %v", b, reported)
+	}
+}
+
+func TestStoreSetRecursive(t *testing.T) {
+	m := map[string]interface{}{"name": "go-sessions"}
+	m["self"] = m
+
+	shared := []int{1, 2}
+
+	var store Store
+	if _, _, err := store.SetE("shared", map[string][]int{"a": shared, "b": shared}); err != nil {
+		t.Fatalf("shared references should be allowed but got: %v", err)
+	}
+
+	if _, _, err := store.SetE("recursive", m); err == nil || !strings.HasPrefix(err.Error(), ErrRecursiveValue.Error()) {
+		t.Fatalf("expected a recursive value error but got: %v", err)
+	}
+	if store.Exists("recursive") {
+		t.Fatalf("expected the recursive value to be rejected")
+	}
+}
+
+func TestStoreUnfreeze(t *testing.T) {
+	var unfrozen []string
+	OnUnfreeze = func(key string, value interface{}) {
+		unfrozen = append(unfrozen, key)
+	}
+	defer func() { OnUnfreeze = nil }()
+
+	var store Store
+	store.SetImmutable("role", "user")
+	store.Set("name", "go-sessions")
+
+	store.Set("role", "admin")
+	if expected, v := "user", store.GetString("role"); v != expected {
+		t.Fatalf("expected the immutable entry to be kept as %s but got %s", expected, v)
+	}
+
+	if !store.Unfreeze("role") {
+		t.Fatalf("expected the immutable entry to be unfrozen")
+	}
+
+	if store.Unfreeze("name") || store.Unfreeze("missing") {
+		t.Fatalf("expected only immutable entries to be unfrozen")
+	}
+
+	store.Set("role", "admin")
+	if expected, v := "admin", store.GetString("role"); v != expected {
+		t.Fatalf("expected the unfrozen entry to be changed to %s but got %s", expected, v)
+	}
+
+	if len(unfrozen) != 1 || unfrozen[0] != "role" {
+		t.Fatalf("expected the unfreeze to be reported once but got %v", unfrozen)
+	}
+}
+
+func TestSessionUnfreeze(t *testing.T) {
+	OnUnfreeze = func(key string, value interface{}) {
+		t.Fatalf("expected the global hook to not be called for the sessions")
+	}
+	defer func() { OnUnfreeze = nil }()
+
+	logger := new(testLogger)
+	manager := New(Config{Logger: logger, ReadOnly: func(r *http.Request) bool { return r.Method == http.MethodGet }})
+	db := &countingDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	sess.SetImmutable("role", "user")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	if reader := manager.Start(httptest.NewRecorder(), r); reader.Unfreeze("role") {
+		t.Fatalf("expected the read-only session to not be unfrozen")
+	}
+
+	syncs := atomic.LoadInt32(&db.syncs)
+	if !sess.Unfreeze("role") || sess.Unfreeze("role") || sess.Unfreeze("missing") {
+		t.Fatalf("expected only the immutable entry to be unfrozen")
+	}
+	if got := atomic.LoadInt32(&db.syncs); got != syncs+1 {
+		t.Fatalf("expected the unfreeze to be synced once but got %d syncs", got-syncs)
+	}
+	if !logger.has(`warn sessions: the immutable key "role"`) {
+		t.Fatalf("expected the unfreeze to be logged but got %v", logger.events)
+	}
+
+	db.mu.Lock()
+	stored := db.stores[sess.ID()].Values
+	db.mu.Unlock()
+	stored.Set("role", "admin")
+	if got := stored.GetString("role"); got != "admin" {
+		t.Fatalf("expected the stored entry to be mutable but got %q", got)
+	}
+}
+
+func TestStoreSetE(t *testing.T) {
+	var store Store
+	store.SetImmutable("role", "admin")
+
+	if _, _, err := store.SetE("role", "user"); err != ErrEntryImmutable {
+		t.Fatalf("expected %v but got %v", ErrEntryImmutable, err)
+	}
+	if got := store.GetString("role"); got != "admin" {
+		t.Fatalf("expected the immutable value to be kept but got %s", got)
+	}
+	if _, _, err := store.SaveE("role", "owner", true); err != nil || store.GetString("role") != "owner" {
+		t.Fatalf("expected the immutable entry to be updated by an immutable save but got %v", err)
+	}
+
+	entry, inserted, err := store.SetE("name", "kataras")
+	if err != nil || !inserted || entry.Immutable() {
+		t.Fatalf("expected a new mutable entry but got %v, %v, %v", entry, inserted, err)
+	}
+
+	sess := New(Config{}).provider.Init("sid", 0)
+	sess.SetImmutable("role", "admin")
+	if err = sess.SetE("role", "user"); err != ErrEntryImmutable || sess.GetString("role") != "admin" {
+		t.Fatalf("expected the session's immutable entry to be kept but got %v", err)
+	}
+	if err = sess.SetE("name", "kataras"); err != nil || sess.GetString("name") != "kataras" {
+		t.Fatalf("expected the value to be set but got %v", err)
+	}
+}
+
+func TestStoreSetImmutableCopy(t *testing.T) {
+	type profile struct {
+		Roles []string
+		Meta  map[string]int
+	}
+
+	roles := []string{"admin"}
+	meta := map[string]int{"level": 1}
+	p := &profile{Roles: roles, Meta: meta}
+
+	var store Store
+	store.SetImmutable("roles", roles)
+	store.SetImmutable("meta", meta)
+	store.SetImmutable("profile", p)
+
+	roles[0] = "guest"
+	meta["level"] = 2
+	p.Roles = nil
+
+	if got := store.Get("roles").([]string); got[0] != "admin" {
+		t.Fatalf("expected the stored slice to be kept but got %v", got)
+	}
+	if got := store.Get("meta").(map[string]int); got["level"] != 1 {
+		t.Fatalf("expected the stored map to be kept but got %v", got)
+	}
+	if got := store.Get("profile").(profile); len(got.Roles) != 1 || got.Meta["level"] != 1 {
+		t.Fatalf("expected the stored struct to be kept but got %v", got)
+	}
+
+	// a self-referential value is copied once.
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	store.SetImmutable("cyclic", cyclic)
+}
+
+func TestStoreImmutableNestedCopy(t *testing.T) {
+	type order struct {
+		Items []map[string]int
+	}
+
+	var store Store
+	store.SetImmutable("orders", []map[string]int{{"qty": 1}})
+	store.SetImmutable("order", order{Items: []map[string]int{{"qty": 1}}})
+
+	store.Get("orders").([]map[string]int)[0]["qty"] = 2
+	if got := store.Get("orders").([]map[string]int)[0]["qty"]; got != 1 {
+		t.Fatalf("expected the nested map to be copied but got %d", got)
+	}
+
+	store.Get("order").(order).Items[0]["qty"] = 2
+	if got := store.Get("order").(order).Items[0]["qty"]; got != 1 {
+		t.Fatalf("expected the struct's nested slice to be copied but got %d", got)
+	}
+
+	defer func(depth int) { ImmutableCopyDepth = depth }(ImmutableCopyDepth)
+	ImmutableCopyDepth = 1
+	store.Get("orders").([]map[string]int)[0]["qty"] = 3
+	if got := store.Get("orders").([]map[string]int)[0]["qty"]; got != 3 {
+		t.Fatalf("expected the elements to be shared below the copy depth but got %d", got)
+	}
+
+	ImmutableCopyDepth = -1
+	roles := []string{"admin"}
+	store.SetImmutable("roles", roles)
+	roles[0] = "guest"
+	if got := store.Get("roles").([]string)[0]; got != "guest" {
+		t.Fatalf("expected no copies when they are disabled but got %s", got)
+	}
+}
+
+func TestStoreGetEntry(t *testing.T) {
+	var store Store
+	store.Set("nil", nil)
+	store.SetImmutable("roles", []string{"admin"})
+
+	if entry, ok := store.GetEntry("nil"); !ok || entry.ValueRaw != nil || !store.Exists("nil") {
+		t.Fatalf("expected the key with a nil value to exist")
+	}
+	if _, ok := store.GetEntry("missing"); ok || store.Exists("missing") {
+		t.Fatalf("expected the missing key to not exist")
+	}
+
+	entry, ok := store.GetEntry("roles")
+	if !ok || !entry.Immutable() {
+		t.Fatalf("expected the immutable entry but got %v", entry)
+	}
+	entry.ValueRaw.([]string)[0] = "guest"
+	if got := store.Get("roles").([]string)[0]; got != "admin" {
+		t.Fatalf("expected the immutable value to be kept but got %s", got)
+	}
+
+	sess := New(Config{}).provider.Init("sid", 0)
+	sess.Set("nil", nil)
+	if !sess.Exists("nil") || sess.Exists("missing") {
+		t.Fatalf("expected the session's key with a nil value to exist")
+	}
+	if _, ok := sess.GetEntry("nil"); !ok {
+		t.Fatalf("expected the session's entry")
+	}
+}
+
+func TestStoreVisitWith(t *testing.T) {
+	var store Store
+	for _, key := range []string{"cart.1", "name", "cart.2", "cart.3", "cart.4"} {
+		store.Set(key, key)
+	}
+
+	var keys []string
+	store.VisitWith(VisitOptions{Prefix: "cart.", Offset: 1, Limit: 2}, func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	if expected := []string{"cart.2", "cart.3"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v but got %v", expected, keys)
+	}
+
+	keys = keys[0:0]
+	store.VisitWith(VisitOptions{Predicate: func(key string, value interface{}) bool {
+		return key != "name"
+	}}, func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+
+	if expected := []string{"cart.1", "cart.2", "cart.3"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected the visitor to stop at %v but got %v", expected, keys)
+	}
+}
+
+func TestStoreAppend(t *testing.T) {
+	var store Store
+	if _, inserted := store.Append("viewed", "a", "b"); !inserted {
+		t.Fatalf("expected the list to be inserted")
+	}
+	store.Append("viewed", "c")
+
+	viewed := store.GetSlice("viewed")
+	if expected := []interface{}{"a", "b", "c"}; !reflect.DeepEqual(viewed, expected) {
+		t.Fatalf("expected %v but got %v", expected, viewed)
+	}
+
+	// an append to the returned list doesn't modify the entry.
+	_ = append(viewed, "x")
+	store.Append("viewed", "d")
+	if v := store.GetSlice("viewed"); len(v) != 4 || v[3] != "d" {
+		t.Fatalf("expected the entry to be appended only by the store but got %v", v)
+	}
+
+	// an append doesn't modify the list of a copy of the store, even if it has spare capacity.
+	store.Set("spare", append(make([]interface{}, 0, 8), "a"))
+	snapshot := append(Store(nil), store...)
+	store.Append("spare", "b")
+	snapshot.Append("spare", "c")
+	if v := store.GetSlice("spare"); len(v) != 2 || v[1] != "b" {
+		t.Fatalf("expected the list of the store to be kept but got %v", v)
+	}
+	if v := snapshot.GetSlice("spare"); len(v) != 2 || v[1] != "c" {
+		t.Fatalf("expected the list of the snapshot to be kept but got %v", v)
+	}
+	store.Remove("spare")
+
+	store.Set("tags", []string{"go"})
+	store.Append("tags", "sessions")
+	if expected, v := []interface{}{"go", "sessions"}, store.GetSlice("tags"); !reflect.DeepEqual(v, expected) {
+		t.Fatalf("expected a typed slice to be converted to %v but got %v", expected, v)
+	}
+
+	store.SetImmutable("roles", []string{"user"})
+	store.Append("roles", "admin")
+	if v := store.GetSlice("roles"); len(v) != 1 {
+		t.Fatalf("expected the immutable entry to be kept but got %v", v)
+	}
+
+	if v := store.GetSlice("missing"); v != nil {
+		t.Fatalf("expected nil for a missing entry but got %v", v)
+	}
+
+	data, err := GobSerialize(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := GobDeserialize(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := decoded.GetSlice("viewed"); len(v) != 4 {
+		t.Fatalf("expected the list to be gob encoded but got %v", v)
+	}
+}
+
+func TestStoreValidateRepair(t *testing.T) {
+	type unregistered struct{ Name string }
+
+	store := Store{
+		{Key: "name", ValueRaw: "go-sessions"},
+		{Key: "name", ValueRaw: "duplicate"},
+		{Key: "frozen", ValueRaw: nil, immutable: true},
+		{Key: "custom", ValueRaw: unregistered{Name: "kataras"}},
+		{Key: "days", ValueRaw: 1},
+	}
+
+	problems := store.Validate()
+	expected := []ProblemKind{ProblemDuplicateKey, ProblemNilImmutable, ProblemUnserializable}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems but got %v", len(expected), problems)
+	}
+
+	for i, p := range problems {
+		if p.Kind != expected[i] || p.Index != i+1 {
+			t.Fatalf("expected the %s at %d but got %s", expected[i], i+1, p)
+		}
+	}
+
+	fixed := append(Store(nil), store...)
+	if got := fixed.Repair(RepairFix); len(got) != len(problems) {
+		t.Fatalf("expected the %d problems to be repaired but got %v", len(problems), got)
+	}
+
+	if len(fixed) != 3 || fixed.GetString("name") != "go-sessions" || fixed[1].Key != "frozen" || fixed[1].immutable {
+		t.Fatalf("expected the nil immutable value to be fixed but got %#v", fixed)
+	}
+
+	store.Repair(RepairDrop)
+	if len(store) != 2 || store[0].Key != "name" || store[1].Key != "days" {
+		t.Fatalf("expected the problematic entries to be dropped but got %#v", store)
+	}
+
+	if problems = store.Validate(); problems != nil {
+		t.Fatalf("expected a valid store but got %v", problems)
+	}
+}
+
+func TestStoreListOperations(t *testing.T) {
+	var store Store
+	if n, err := store.AppendTo("cart", "apple", "pear"); err != nil || n != 2 {
+		t.Fatalf("expected 2 items but got %d, %v", n, err)
+	}
+	store.AppendTo("cart", "apple")
+
+	view := store.GetSlice("cart")
+	if !store.RemoveFrom("cart", "apple") || store.ListLen("cart") != 1 {
+		t.Fatalf("expected every apple to be removed but got %v", store.GetSlice("cart"))
+	}
+	if view[0] != "apple" {
+		t.Fatalf("expected the previous list to be kept but got %v", view)
+	}
+	if store.RemoveFrom("cart", "banana") || store.RemoveFrom("missing", "apple") {
+		t.Fatalf("expected nothing to be removed")
+	}
+
+	store.Set("typed", []string{"a", "b"})
+	if store.ListLen("typed") != 2 || store.ListLen("missing") != 0 {
+		t.Fatalf("expected the length of the typed list")
+	}
+
+	store.SetImmutable("frozen", []string{"a"})
+	if _, err := store.AppendTo("frozen", "b"); err != ErrEntryImmutable || store.RemoveFrom("frozen", "a") {
+		t.Fatalf("expected the immutable list to be kept")
+	}
+
+	sess := New(Config{}).provider.Init("sid", 0)
+	if n, err := sess.AppendTo("cart", "apple", "pear"); err != nil || n != 2 {
+		t.Fatalf("expected 2 items but got %d, %v", n, err)
+	}
+	if !sess.RemoveFrom("cart", "pear") || sess.ListLen("cart") != 1 {
+		t.Fatalf("expected the pear to be removed but got %v", sess.GetSlice("cart"))
+	}
+
+	sess.Set("spare", append(make([]interface{}, 0, 8), "a"))
+	snapshot := append(Store(nil), sess.values...)
+	sess.Append("spare", "b")
+	snapshot.Append("spare", "c")
+	if v := sess.GetSlice("spare"); len(v) != 2 || v[1] != "b" {
+		t.Fatalf("expected the list of the session to not be shared with its snapshot but got %v", v)
+	}
+}
+
+func TestStoreCompareAndSwap(t *testing.T) {
+	var store Store
+	if !store.SetIfAbsent("token", "t1") || store.SetIfAbsent("token", "t2") || store.GetString("token") != "t1" {
+		t.Fatalf("expected the token to be set once")
+	}
+
+	if store.CompareAndSwap("token", "t2", "t3") || !store.CompareAndSwap("token", "t1", "t3") || store.GetString("token") != "t3" {
+		t.Fatalf("expected the token to be swapped only if it's equal to the old value")
+	}
+	if !store.CompareAndSwap("missing", nil, 1) {
+		t.Fatalf("expected a missing key to be equal to nil")
+	}
+
+	sess := New(Config{}).provider.Init("sid", 0)
+	sess.Set("token", "t1")
+
+	var wg sync.WaitGroup
+	var consumed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sess.CompareAndSwap("token", "t1", "used") {
+				atomic.AddInt32(&consumed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if consumed != 1 {
+		t.Fatalf("expected the token to be consumed once but got %d", consumed)
+	}
+	if !sess.SetIfAbsent("once", true) || sess.SetIfAbsent("once", false) {
+		t.Fatalf("expected the session's value to be set once")
+	}
+}
@@ -0,0 +1,293 @@
+// Package metrics exports the metrics of a sessions manager to prometheus.
+//
+// Usage:
+// m, err := metrics.New(manager, prometheus.DefaultRegisterer)
+// manager.UseDatabase(m.Database("redis", redis.New(...)))
+package metrics
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is the default namespace of the metrics.
+const DefaultNamespace = "sessions"
+
+// Options are the options of the metrics.
+type Options struct {
+	// Namespace is the namespace of the metrics, i.e "sessions_created_total".
+	//
+	// Defaults to the `DefaultNamespace`
+	Namespace string
+	// ConstLabels are the labels of all metrics, i.e the app's name.
+	//
+	// Defaults to nil
+	ConstLabels prometheus.Labels
+	// LatencyBuckets are the buckets of the latency histograms, in seconds.
+	//
+	// Defaults to the prometheus.DefBuckets
+	LatencyBuckets []float64
+	// SizeBuckets are the buckets of the serialization size histogram, in bytes.
+	//
+	// Defaults to 64 bytes up to 256KB, exponentially
+	SizeBuckets []float64
+}
+
+// Metrics are the prometheus metrics of a sessions manager.
+type Metrics struct {
+	created   prometheus.Counter
+	destroyed prometheus.Counter
+	expired   prometheus.Counter
+	latency   *prometheus.HistogramVec
+	size      prometheus.Histogram
+	pools     *poolCollector
+}
+
+// New registers the metrics of the "manager" to the "reg":
+// the created, destroyed and expired sessions counters, the active sessions gauge,
+// the memory hits and misses counters and their hit ratio, see `sessions.Sessions#Stats`,
+// and the histograms of the latency of the databases and the serialization size,
+// which are observed by the `Database` and the `Transcoder` wrappers.
+func New(manager *sessions.Sessions, reg prometheus.Registerer, opts ...Options) (*Metrics, error) {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Namespace == "" {
+		o.Namespace = DefaultNamespace
+	}
+
+	if len(o.LatencyBuckets) == 0 {
+		o.LatencyBuckets = prometheus.DefBuckets
+	}
+
+	if len(o.SizeBuckets) == 0 {
+		o.SizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+	}
+
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Namespace: o.Namespace, Name: name, Help: help, ConstLabels: o.ConstLabels})
+	}
+
+	m := &Metrics{
+		created:   counter("created_total", "The number of the created sessions."),
+		destroyed: counter("destroyed_total", "The number of the destroyed sessions."),
+		expired:   counter("expired_total", "The number of the expired sessions."),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   o.Namespace,
+			Name:        "database_duration_seconds",
+			Help:        "The latency of the session database operations.",
+			ConstLabels: o.ConstLabels,
+			Buckets:     o.LatencyBuckets,
+		}, []string{"database", "operation"}),
+		size: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   o.Namespace,
+			Name:        "serialized_bytes",
+			Help:        "The size of the serialized sessions.",
+			ConstLabels: o.ConstLabels,
+			Buckets:     o.SizeBuckets,
+		}),
+		pools: newPoolCollector(o),
+	}
+
+	stat := func(fn func(sessions.Stats) float64) func() float64 {
+		return func() float64 { return fn(manager.Stats()) }
+	}
+
+	collectors := []prometheus.Collector{
+		m.created, m.destroyed, m.expired, m.latency, m.size, m.pools,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: o.Namespace, Name: "active", Help: "The number of the sessions in memory.", ConstLabels: o.ConstLabels,
+		}, stat(func(s sessions.Stats) float64 { return float64(s.Active) })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: o.Namespace, Name: "cache_hits_total", Help: "The number of the reads of a session which was in memory.", ConstLabels: o.ConstLabels,
+		}, stat(func(s sessions.Stats) float64 { return float64(s.Hits) })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: o.Namespace, Name: "cache_misses_total", Help: "The number of the reads of a session which was loaded from the databases or created.", ConstLabels: o.ConstLabels,
+		}, stat(func(s sessions.Stats) float64 { return float64(s.Misses) })),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: o.Namespace, Name: "cache_hit_ratio", Help: "The ratio of the reads of a session which was in memory.", ConstLabels: o.ConstLabels,
+		}, stat(func(s sessions.Stats) float64 {
+			if total := s.Hits + s.Misses; total > 0 {
+				return float64(s.Hits) / float64(total)
+			}
+			return 0
+		})),
+	}
+
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	manager.OnCreate(func(string) { m.created.Inc() })
+	manager.OnDestroy(func(string) { m.destroyed.Inc() })
+	manager.OnExpire(func(string) { m.expired.Inc() })
+
+	return m, nil
+}
+
+func (m *Metrics) observe(name, operation string, started time.Time) {
+	m.latency.WithLabelValues(name, operation).Observe(time.Since(started).Seconds())
+}
+
+// Database returns the "db" which observes the latency of its `Load` and `Sync` operations,
+// the "name" is the value of the "database" label, i.e "redis".
+// The statistics of the connection pool of a "db" which implements the `sessions.PoolStater`
+// are exported too, i.e the "sessions_pool_active_connections".
+// The wrapper implements the `sessions.Scanner`, the `sessions.VersionedDatabase` and the `io.Closer` of the "db",
+// the other optional interfaces, i.e the `sessions.Locker`, are not.
+func (m *Metrics) Database(name string, db sessions.Database) sessions.Database {
+	if stater, ok := db.(sessions.PoolStater); ok {
+		m.pools.add(name, stater)
+	}
+	return &database{Database: db, name: name, metrics: m}
+}
+
+type database struct {
+	sessions.Database
+	name    string
+	metrics *Metrics
+}
+
+func (db *database) Load(sid string) sessions.RemoteStore {
+	defer db.metrics.observe(db.name, "load", time.Now())
+	return db.Database.Load(sid)
+}
+
+func (db *database) Sync(p sessions.SyncPayload) {
+	operation := "sync"
+	if p.Action == sessions.ActionDestroy {
+		operation = "destroy"
+	}
+
+	defer db.metrics.observe(db.name, operation, time.Now())
+	db.Database.Sync(p)
+}
+
+func (db *database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	operation := "sync"
+	if p.Action == sessions.ActionDestroy {
+		operation = "destroy"
+	}
+
+	defer db.metrics.observe(db.name, operation, time.Now())
+	return sessions.SyncDatabaseVersion(db.Database, p)
+}
+
+func (db *database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	if scanner, ok := db.Database.(sessions.Scanner); ok {
+		defer db.metrics.observe(db.name, "scan", time.Now())
+		scanner.Scan(visitor)
+	}
+}
+
+func (db *database) Close() error {
+	if closer, ok := db.Database.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// poolCollector collects the statistics of the connection pools of the databases, see `Metrics#Database`.
+type poolCollector struct {
+	active     *prometheus.Desc
+	inUse      *prometheus.Desc
+	idle       *prometheus.Desc
+	maxActive  *prometheus.Desc
+	dials      *prometheus.Desc
+	dialErrors *prometheus.Desc
+
+	mu    sync.Mutex
+	names []string
+	pools map[string]sessions.PoolStater
+}
+
+func newPoolCollector(o Options) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(o.Namespace, "pool", name), help, []string{"database"}, o.ConstLabels)
+	}
+
+	return &poolCollector{
+		active:     desc("active_connections", "The number of the connections of the database's pool, in use and idle."),
+		inUse:      desc("in_use_connections", "The number of the connections of the database's pool which are in use."),
+		idle:       desc("idle_connections", "The number of the idle connections of the database's pool."),
+		maxActive:  desc("max_connections", "The maximum number of the connections of the database's pool, zero means no limit."),
+		dials:      desc("dials_total", "The number of the dialed connections of the database's pool."),
+		dialErrors: desc("dial_errors_total", "The number of the failed dials of the database's pool."),
+		pools:      make(map[string]sessions.PoolStater),
+	}
+}
+
+func (c *poolCollector) add(name string, stater sessions.PoolStater) {
+	c.mu.Lock()
+	if _, ok := c.pools[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.pools[name] = stater
+	c.mu.Unlock()
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.active
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.maxActive
+	ch <- c.dials
+	ch <- c.dialErrors
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	names := append([]string(nil), c.names...)
+	pools := make([]sessions.PoolStater, len(names))
+	for i, name := range names {
+		pools[i] = c.pools[name]
+	}
+	c.mu.Unlock()
+
+	for i, name := range names {
+		stats := pools[i].PoolStats()
+		ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(stats.Active), name)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), name)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), name)
+		ch <- prometheus.MustNewConstMetric(c.maxActive, prometheus.GaugeValue, float64(stats.MaxActive), name)
+		ch <- prometheus.MustNewConstMetric(c.dials, prometheus.CounterValue, float64(stats.Dials), name)
+		ch <- prometheus.MustNewConstMetric(c.dialErrors, prometheus.CounterValue, float64(stats.DialErrors), name)
+	}
+}
+
+// Transcoder returns the "t" which observes the size of the serialized sessions,
+// i.e to be passed to the `Transcoder` of a session database.
+// If "t" is nil then the `sessions.DefaultTranscoder` is used.
+func (m *Metrics) Transcoder(t sessions.Transcoder) sessions.Transcoder {
+	if t == nil {
+		t = sessions.DefaultTranscoder
+	}
+	return &transcoder{Transcoder: t, metrics: m}
+}
+
+type transcoder struct {
+	sessions.Transcoder
+	metrics *Metrics
+}
+
+// FormatVersioned reports whether the observed transcoder is a `sessions.FormatVersioner`.
+func (t *transcoder) FormatVersioned() bool {
+	v, ok := t.Transcoder.(sessions.FormatVersioner)
+	return ok && v.FormatVersioned()
+}
+
+func (t *transcoder) Marshal(v interface{}) ([]byte, error) {
+	b, err := t.Transcoder.Marshal(v)
+	if err == nil {
+		t.metrics.size.Observe(float64(len(b)))
+	}
+	return b, err
+}
@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gather returns the metrics of the "reg" by their names.
+func gather(t *testing.T, reg *prometheus.Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, f := range families {
+		byName[f.GetName()] = f
+	}
+	return byName
+}
+
+// value returns the value of the counter or the gauge "name", without labels.
+func value(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	f, ok := gather(t, reg)[name]
+	if !ok || len(f.GetMetric()) != 1 {
+		t.Fatalf("expected the metric %s to be registered", name)
+	}
+
+	m := f.GetMetric()[0]
+	if m.GetCounter() != nil {
+		return m.GetCounter().GetValue()
+	}
+	return m.GetGauge().GetValue()
+}
+
+// latency returns the number of the observed latencies of the "operation" of the database "name".
+func latency(t *testing.T, reg *prometheus.Registry, name, operation string) uint64 {
+	t.Helper()
+
+	f, ok := gather(t, reg)[DefaultNamespace+"_database_duration_seconds"]
+	if !ok {
+		return 0
+	}
+
+	for _, m := range f.GetMetric() {
+		labels := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["database"] == name && labels["operation"] == operation {
+			return m.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func TestSessions(t *testing.T) {
+	clock := sessions.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := sessionstest.NewManager(t, sessions.Config{Expires: time.Minute, Clock: clock})
+
+	reg := prometheus.NewRegistry()
+	if _, err := New(manager, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil)).Release()
+	// the next request of the client reads the session from memory.
+	manager.Start(httptest.NewRecorder(), sessionstest.NextRequest(w, http.MethodGet, "/", nil)).Release()
+
+	if got := value(t, reg, "sessions_created_total"); got != 1 {
+		t.Fatalf("expected 1 created session but got %v", got)
+	}
+	if got := value(t, reg, "sessions_active"); got != 1 {
+		t.Fatalf("expected 1 active session but got %v", got)
+	}
+	if hits, misses := value(t, reg, "sessions_cache_hits_total"), value(t, reg, "sessions_cache_misses_total"); hits != 1 || misses != 0 {
+		t.Fatalf("expected 1 hit and no misses but got %v hits and %v misses", hits, misses)
+	}
+
+	manager.Destroy(httptest.NewRecorder(), sessionstest.NextRequest(w, http.MethodGet, "/", nil))
+	if got := value(t, reg, "sessions_destroyed_total"); got != 1 {
+		t.Fatalf("expected 1 destroyed session but got %v", got)
+	}
+	if got := value(t, reg, "sessions_active"); got != 0 {
+		t.Fatalf("expected no active sessions but got %v", got)
+	}
+
+	// the cookie of the destroyed session is not in memory.
+	manager.Start(httptest.NewRecorder(), sessionstest.NextRequest(w, http.MethodGet, "/", nil)).Release()
+	if got := value(t, reg, "sessions_cache_misses_total"); got != 1 {
+		t.Fatalf("expected 1 miss but got %v", got)
+	}
+	if got := value(t, reg, "sessions_cache_hit_ratio"); got != 0.5 {
+		t.Fatalf("expected the hit ratio of 0.5 but got %v", got)
+	}
+
+	clock.Advance(time.Minute)
+	if got := value(t, reg, "sessions_expired_total"); got != 1 {
+		t.Fatalf("expected 1 expired session but got %v", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+
+	reg := prometheus.NewRegistry()
+	if _, err := New(manager, reg, Options{Namespace: "app", ConstLabels: prometheus.Labels{"app": "shop"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, ok := gather(t, reg)["app_created_total"]
+	if !ok {
+		t.Fatalf("expected the metrics to be registered in the namespace")
+	}
+	if labels := f.GetMetric()[0].GetLabel(); len(labels) != 1 || labels[0].GetValue() != "shop" {
+		t.Fatalf("expected the const labels to be set but got %v", labels)
+	}
+
+	// registered twice.
+	if _, err := New(manager, reg, Options{Namespace: "app"}); err == nil {
+		t.Fatalf("expected the error of the registry")
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(sessionstest.NewManager(t, sessions.Config{}), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := sessionstest.NewDatabase()
+	db := m.Database("test", backend)
+
+	var values sessions.Store
+	values.Set("name", "kataras")
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionInsert, Store: sessions.RemoteStore{Values: values}})
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session to be loaded from the database but got %v", loaded.Values)
+	}
+	db.(sessions.Scanner).Scan(func(string, sessions.RemoteStore) bool { return true })
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+
+	for _, operation := range []string{"load", "sync", "scan", "destroy"} {
+		if n := latency(t, reg, "test", operation); n != 1 {
+			t.Fatalf("expected 1 observed latency of the %s but got %d", operation, n)
+		}
+	}
+
+	versioned, ok := db.(sessions.VersionedDatabase)
+	if !ok {
+		t.Fatalf("expected the database to be versioned")
+	}
+
+	p := sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionInsert, Store: sessions.RemoteStore{Values: values, Version: 1}}
+	if _, err = versioned.SyncVersion(p); err != nil {
+		t.Fatal(err)
+	}
+	// another instance loaded the version 0 too.
+	if _, err = versioned.SyncVersion(p); err != sessions.ErrVersionConflict {
+		t.Fatalf("expected the version conflict of the database but got %v", err)
+	}
+	if n := latency(t, reg, "test", "sync"); n != 3 {
+		t.Fatalf("expected 3 observed latencies of the sync but got %d", n)
+	}
+}
+
+// plainTranscoder is a transcoder which doesn't write the format header.
+type plainTranscoder struct{ sessions.JSONTranscoder }
+
+func TestTranscoder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(sessionstest.NewManager(t, sessions.Config{}), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := m.Transcoder(nil)
+	b, err := tr.Marshal(map[string]string{"name": "kataras"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]string
+	if err = tr.Unmarshal(b, &got); err != nil || got["name"] != "kataras" {
+		t.Fatalf("expected the value to be decoded but got %v, %v", got, err)
+	}
+
+	h := gather(t, reg)["sessions_serialized_bytes"].GetMetric()[0].GetHistogram()
+	if h.GetSampleCount() != 1 || h.GetSampleSum() != float64(len(b)) {
+		t.Fatalf("expected the size of %d bytes to be observed but got %d samples of %v bytes", len(b), h.GetSampleCount(), h.GetSampleSum())
+	}
+
+	if !tr.(sessions.FormatVersioner).FormatVersioned() {
+		t.Fatalf("expected the default transcoder to be format versioned")
+	}
+	if m.Transcoder(plainTranscoder{}).(sessions.FormatVersioner).FormatVersioned() {
+		t.Fatalf("expected a transcoder without the format header to not be format versioned")
+	}
+}
+
+// pooledDatabase is a database which keeps a connection pool.
+type pooledDatabase struct {
+	sessions.Database
+	stats sessions.PoolStats
+}
+
+func (db *pooledDatabase) PoolStats() sessions.PoolStats {
+	return db.stats
+}
+
+func TestPool(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(sessionstest.NewManager(t, sessions.Config{}), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the databases without a pool are not exported.
+	m.Database("memory", sessionstest.NewDatabase())
+	if _, ok := gather(t, reg)[DefaultNamespace+"_pool_active_connections"]; ok {
+		t.Fatal("expected no pool metrics of a database without a pool")
+	}
+
+	db := &pooledDatabase{Database: sessionstest.NewDatabase(), stats: sessions.PoolStats{Active: 3, InUse: 1, Idle: 2, MaxActive: 10, Dials: 5, DialErrors: 2}}
+	m.Database("redis", db)
+
+	expected := map[string]float64{
+		"pool_active_connections": 3,
+		"pool_in_use_connections": 1,
+		"pool_idle_connections":   2,
+		"pool_max_connections":    10,
+		"pool_dials_total":        5,
+		"pool_dial_errors_total":  2,
+	}
+	for name, v := range expected {
+		if got := value(t, reg, DefaultNamespace+"_"+name); got != v {
+			t.Fatalf("expected the %s to be %v but got %v", name, v, got)
+		}
+	}
+
+	// the stats are read on each collection.
+	db.stats.InUse = 2
+	if got := value(t, reg, DefaultNamespace+"_pool_in_use_connections"); got != 2 {
+		t.Fatalf("expected the current in use connections but got %v", got)
+	}
+}
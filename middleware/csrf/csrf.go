@@ -0,0 +1,78 @@
+// Package csrf provides a middleware which rejects the state-changing requests
+// without a valid CSRF token, the token is stored to the user's session,
+// see `sessions.Session#CSRFToken` and `sessions.Sessions#VerifyCSRF`.
+package csrf
+
+import (
+	"net/http"
+
+	"github.com/kataras/go-sessions"
+	"github.com/valyala/fasthttp"
+)
+
+// Options are the options of the CSRF middleware.
+type Options struct {
+	// ErrorHandler is called when the request's CSRF token is missing or invalid,
+	// the next handler is not executed.
+	//
+	// Defaults to a 403 Forbidden response.
+	ErrorHandler http.Handler
+	// ErrorHandlerFasthttp same as `ErrorHandler` but for the `NewFasthttp`.
+	//
+	// Defaults to a 403 Forbidden response.
+	ErrorHandlerFasthttp fasthttp.RequestHandler
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+func forbiddenFasthttp(ctx *fasthttp.RequestCtx) {
+	ctx.Error(fasthttp.StatusMessage(fasthttp.StatusForbidden), fasthttp.StatusForbidden)
+}
+
+// New returns a new net/http middleware which verifies the CSRF token
+// of the POST, PUT, PATCH and DELETE requests.
+//
+// Usage:
+// http.ListenAndServe(":8080", csrf.New(manager)(mux))
+func New(manager *sessions.Sessions, opts ...Options) func(http.Handler) http.Handler {
+	var errorHandler http.Handler = http.HandlerFunc(forbidden)
+	if len(opts) > 0 && opts[0].ErrorHandler != nil {
+		errorHandler = opts[0].ErrorHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := manager.VerifyCSRF(r); err != nil {
+				errorHandler.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewFasthttp returns a new fasthttp middleware which verifies the CSRF token
+// of the POST, PUT, PATCH and DELETE requests.
+//
+// Usage:
+// fasthttp.ListenAndServe(":8080", csrf.NewFasthttp(manager)(handler))
+func NewFasthttp(manager *sessions.Sessions, opts ...Options) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	errorHandler := fasthttp.RequestHandler(forbiddenFasthttp)
+	if len(opts) > 0 && opts[0].ErrorHandlerFasthttp != nil {
+		errorHandler = opts[0].ErrorHandlerFasthttp
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if err := manager.VerifyCSRFFasthttp(ctx); err != nil {
+				errorHandler(ctx)
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
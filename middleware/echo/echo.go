@@ -0,0 +1,83 @@
+// Package echo provides the session middleware of the Echo web framework,
+// the session of the request is read by the `Get`.
+//
+// Usage:
+// import sessionsecho "github.com/kataras/go-sessions/middleware/echo"
+// e.Use(sessionsecho.New(manager))
+// sess := sessionsecho.Get(c)
+package echo
+
+import (
+	"errors"
+
+	"github.com/kataras/go-sessions"
+	"github.com/labstack/echo/v4"
+)
+
+// ErrResponseCommitted returned by the `RegenerateID` and the `Destroy`
+// when the response is already committed, so the session cookie can't be changed.
+var ErrResponseCommitted = errors.New("echo sessions: the response is already committed")
+
+// contextKey is the echo context's key of the session.
+const contextKey = "github.com/kataras/go-sessions.session"
+
+// New returns a new echo middleware which starts the session of each request,
+// the session is stored to the echo context, read it by the `Get`,
+// and to the request's context, read it by the `sessions.FromContext`.
+// The session is released when the next handlers return, see `sessions.Config#SingleWriter`.
+//
+// The session cookie can't be set after the response is committed,
+// if a previous middleware already committed it then no session is started.
+// The cookie changes of the handlers should use the `RegenerateID` and the `Destroy`
+// which report the `ErrResponseCommitted`.
+func New(manager *sessions.Sessions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Response().Committed {
+				return next(c)
+			}
+
+			sess := manager.Start(c.Response(), c.Request())
+			defer sess.Release()
+
+			set(c, sess)
+			return next(c)
+		}
+	}
+}
+
+func set(c echo.Context, sess *sessions.Session) {
+	c.Set(contextKey, sess)
+	c.SetRequest(c.Request().WithContext(sessions.NewContext(c.Request().Context(), sess)))
+}
+
+// Get returns the session of the echo context,
+// it returns nil if the `New` middleware is not registered or the response was committed before it.
+func Get(c echo.Context) *sessions.Session {
+	sess, _ := c.Get(contextKey).(*sessions.Session)
+	return sess
+}
+
+// RegenerateID moves the session of the request to a new session id and sends it to the client,
+// see `sessions.Sessions#RegenerateID`.
+// It returns the `ErrResponseCommitted` if the response is committed, the session is not changed.
+func RegenerateID(manager *sessions.Sessions, c echo.Context) (*sessions.Session, error) {
+	if c.Response().Committed {
+		return nil, ErrResponseCommitted
+	}
+
+	sess := manager.RegenerateID(c.Response(), c.Request())
+	set(c, sess)
+	return sess, nil
+}
+
+// Destroy removes the session of the request and its cookie, see `sessions.Sessions#Destroy`.
+// It returns the `ErrResponseCommitted` if the response is committed, the session is not destroyed.
+func Destroy(manager *sessions.Sessions, c echo.Context) error {
+	if c.Response().Committed {
+		return ErrResponseCommitted
+	}
+
+	manager.Destroy(c.Response(), c.Request())
+	return nil
+}
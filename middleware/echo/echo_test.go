@@ -0,0 +1,125 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/labstack/echo/v4"
+)
+
+func serve(e *echo.Echo, path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	// the session is released after each request, or the next one blocks.
+	manager := sessions.New(sessions.Config{SingleWriter: true})
+
+	e := echo.New()
+	e.Use(New(manager))
+	e.GET("/set", func(c echo.Context) error {
+		Get(c).Set("name", "kataras")
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/get", func(c echo.Context) error {
+		if sessions.FromContext(c.Request().Context()) != Get(c) {
+			return c.String(http.StatusInternalServerError, "the session of the request's context is not the session of the echo context")
+		}
+		return c.String(http.StatusOK, Get(c).GetString("name"))
+	})
+
+	w := serve(e, "/set", nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatalf("expected the session cookie to be set")
+	}
+
+	for i := 0; i < 2; i++ {
+		if w = serve(e, "/get", cookies); w.Code != http.StatusOK || w.Body.String() != "kataras" {
+			t.Fatalf("expected the session value but got %d: %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestCommitted(t *testing.T) {
+	manager := sessions.New(sessions.Config{})
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().WriteHeader(http.StatusAccepted)
+			return next(c)
+		}
+	})
+	e.Use(New(manager))
+
+	var regenerateErr, destroyErr error
+	var started bool
+	e.GET("/", func(c echo.Context) error {
+		started = Get(c) != nil
+		_, regenerateErr = RegenerateID(manager, c)
+		destroyErr = Destroy(manager, c)
+		return nil
+	})
+
+	w := serve(e, "/", nil)
+	if started || len(w.Result().Cookies()) > 0 {
+		t.Fatalf("expected no session after the response is committed")
+	}
+	if regenerateErr != ErrResponseCommitted || destroyErr != ErrResponseCommitted {
+		t.Fatalf("expected the ErrResponseCommitted but got %v and %v", regenerateErr, destroyErr)
+	}
+}
+
+func TestRegenerateAndDestroy(t *testing.T) {
+	manager := sessions.New(sessions.Config{})
+
+	e := echo.New()
+	e.Use(New(manager))
+	e.GET("/visit", func(c echo.Context) error {
+		Get(c).Set("name", "kataras")
+		return nil
+	})
+	e.GET("/login", func(c echo.Context) error {
+		sess, err := RegenerateID(manager, c)
+		if err != nil {
+			return err
+		}
+		if Get(c) != sess || sess.GetString("name") != "kataras" {
+			return c.String(http.StatusInternalServerError, "the regenerated session is not the session of the echo context")
+		}
+		return c.String(http.StatusOK, sess.ID())
+	})
+	e.GET("/logout", func(c echo.Context) error {
+		return Destroy(manager, c)
+	})
+
+	visit := serve(e, "/visit", nil).Result().Cookies()
+	w := serve(e, "/login", visit)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the session to be regenerated but got %d: %s", w.Code, w.Body.String())
+	}
+
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessions.DefaultCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != w.Body.String() || cookie.Value == visit[0].Value {
+		t.Fatalf("expected the cookie of the regenerated session id %s but got %v", w.Body.String(), cookie)
+	}
+
+	w = serve(e, "/logout", []*http.Cookie{cookie})
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) == 0 || w.Result().Cookies()[0].MaxAge >= 0 {
+		t.Fatalf("expected the session cookie to be removed but got %v", w.Result().Cookies())
+	}
+}
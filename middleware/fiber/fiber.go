@@ -0,0 +1,41 @@
+// Package fiber provides the session middleware of the Fiber web framework,
+// the session of the request is read by the `Get`.
+//
+// Fiber runs on fasthttp, the sessions are started by the `sessions.Sessions#StartFasthttp`
+// of the fiber context's *fasthttp.RequestCtx, which reads and writes the session cookie.
+//
+// Usage:
+// import sessionsfiber "github.com/kataras/go-sessions/middleware/fiber"
+// app.Use(sessionsfiber.New(manager))
+// sess := sessionsfiber.Get(c)
+package fiber
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kataras/go-sessions"
+)
+
+// contextKey is the fiber locals' key of the session.
+const contextKey = "github.com/kataras/go-sessions.session"
+
+// New returns a new fiber middleware which starts the session of each request,
+// the session is stored to the fiber locals, read it by the `Get`,
+// and to the user values of the *fasthttp.RequestCtx, read it by the `sessions.FromContext(c.Context())`.
+// The session is released when the next handlers return, see `sessions.Config#SingleWriter`.
+func New(manager *sessions.Sessions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sess := manager.StartFasthttp(c.Context())
+		defer sess.Release()
+
+		c.Locals(contextKey, sess)
+		sessions.NewContext(c.Context(), sess)
+		return c.Next()
+	}
+}
+
+// Get returns the session of the fiber context,
+// it returns nil if the `New` middleware is not registered.
+func Get(c *fiber.Ctx) *sessions.Session {
+	sess, _ := c.Locals(contextKey).(*sessions.Session)
+	return sess
+}
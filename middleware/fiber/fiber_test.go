@@ -0,0 +1,74 @@
+package fiber
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kataras/go-sessions"
+)
+
+func serve(t *testing.T, app *fiber.App, path string, cookies []*http.Cookie) (*http.Response, string) {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+
+	resp, err := app.Test(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	// the session is released after each request, or the next one blocks.
+	manager := sessions.New(sessions.Config{SingleWriter: true})
+
+	app := fiber.New()
+	app.Use(New(manager))
+	app.Get("/set", func(c *fiber.Ctx) error {
+		Get(c).Set("name", "kataras")
+		return nil
+	})
+	app.Get("/get", func(c *fiber.Ctx) error {
+		if sessions.FromContext(c.Context()) != Get(c) {
+			return c.Status(http.StatusInternalServerError).SendString("the session of the user values is not the session of the fiber locals")
+		}
+		return c.SendString(Get(c).GetString("name"))
+	})
+
+	resp, _ := serve(t, app, "/set", nil)
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		t.Fatalf("expected the session cookie to be set")
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp, body := serve(t, app, "/get", cookies); resp.StatusCode != http.StatusOK || body != "kataras" {
+			t.Fatalf("expected the session value but got %d: %s", resp.StatusCode, body)
+		}
+	}
+}
+
+func TestGetWithoutMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if Get(c) != nil {
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+		return nil
+	})
+
+	if resp, _ := serve(t, app, "/", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected no session without the middleware")
+	}
+}
@@ -0,0 +1,43 @@
+// Package gin provides the session middleware of the Gin web framework,
+// the session of the request is read by the `Get`.
+//
+// Usage:
+// import sessionsgin "github.com/kataras/go-sessions/middleware/gin"
+// router.Use(sessionsgin.New(manager))
+// sess := sessionsgin.Get(c)
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kataras/go-sessions"
+)
+
+// contextKey is the gin context's key of the session.
+const contextKey = "github.com/kataras/go-sessions.session"
+
+// New returns a new gin middleware which starts the session of each request,
+// the session is stored to the gin context, read it by the `Get`,
+// and to the request's context, read it by the `sessions.FromContext`.
+// The session is released when the next handlers return, see `sessions.Config#SingleWriter`.
+func New(manager *sessions.Sessions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess := manager.Start(c.Writer, c.Request)
+		defer sess.Release()
+
+		c.Set(contextKey, sess)
+		c.Request = c.Request.WithContext(sessions.NewContext(c.Request.Context(), sess))
+		c.Next()
+	}
+}
+
+// Get returns the session of the gin context,
+// it returns nil if the `New` middleware is not registered.
+func Get(c *gin.Context) *sessions.Session {
+	if v, ok := c.Get(contextKey); ok {
+		if sess, ok := v.(*sessions.Session); ok {
+			return sess
+		}
+	}
+
+	return nil
+}
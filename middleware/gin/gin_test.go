@@ -0,0 +1,68 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kataras/go-sessions"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(router *gin.Engine, path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	// the session is released after each request, or the next one blocks.
+	manager := sessions.New(sessions.Config{SingleWriter: true})
+
+	router := gin.New()
+	router.Use(New(manager))
+	router.GET("/set", func(c *gin.Context) {
+		Get(c).Set("name", "kataras")
+	})
+	router.GET("/get", func(c *gin.Context) {
+		fromRequest := sessions.FromContext(c.Request.Context())
+		if fromRequest != Get(c) {
+			c.String(http.StatusInternalServerError, "the session of the request's context is not the session of the gin context")
+			return
+		}
+		c.String(http.StatusOK, Get(c).GetString("name"))
+	})
+
+	w := serve(router, "/set", nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatalf("expected the session cookie to be set")
+	}
+
+	for i := 0; i < 2; i++ {
+		if w = serve(router, "/get", cookies); w.Code != http.StatusOK || w.Body.String() != "kataras" {
+			t.Fatalf("expected the session value but got %d: %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGetWithoutMiddleware(t *testing.T) {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		if Get(c) != nil {
+			c.Status(http.StatusInternalServerError)
+		}
+	})
+
+	if w := serve(router, "/", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected no session without the middleware")
+	}
+}
@@ -0,0 +1,121 @@
+// Package grpc provides the gRPC server interceptors which propagate the sessions of the HTTP edge
+// to the gRPC services, the session id is read from the request's metadata and the session
+// is attached to the RPC's context, read it by the `sessions.FromContext`.
+//
+// Usage:
+// import sessionsgrpc "github.com/kataras/go-sessions/middleware/grpc"
+// grpc.NewServer(grpc.UnaryInterceptor(sessionsgrpc.UnaryServerInterceptor(manager)))
+// sess := sessions.FromContext(ctx)
+//
+// The clients send the session id, the value of the session cookie, by the `WithSession`.
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/kataras/go-sessions"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// DefaultMetadataKey is the metadata key of the session id, see `Options#MetadataKey`.
+const DefaultMetadataKey = "x-session-id"
+
+// Options are the options of the interceptors.
+type Options struct {
+	// MetadataKey is the metadata key which carries the session id,
+	// the id of a new session is sent back to the client as a response header of the same key.
+	//
+	// Defaults to "x-session-id"
+	MetadataKey string
+}
+
+func metadataKey(opts []Options) string {
+	if len(opts) > 0 && opts[0].MetadataKey != "" {
+		return opts[0].MetadataKey
+	}
+
+	return DefaultMetadataKey
+}
+
+// sessionID returns the session id of the incoming metadata of the "ctx".
+func sessionID(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// clientContext returns a copy of the "ctx" which carries the peer's address and the user agent of the RPC,
+// the signals of the `sessions.Config#RiskScorer`, see `sessions.NewClientContext`.
+func clientContext(ctx context.Context) context.Context {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	return sessions.NewClientContext(ctx, ip, sessionID(ctx, "user-agent"))
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which starts the session
+// of the RPC's metadata session id, see `sessions.Sessions#StartContext`,
+// and attaches it to the handler's context.
+// The session is released when the handler returns, its changes are synced to the session databases
+// as they happen, so the HTTP edge which shares the same backend sees them.
+func UnaryServerInterceptor(manager *sessions.Sessions, opts ...Options) grpc.UnaryServerInterceptor {
+	key := metadataKey(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		sess := manager.StartContext(clientContext(ctx), sessionID(ctx, key), func(value string) {
+			grpc.SetHeader(ctx, metadata.Pairs(key, value))
+		})
+		defer sess.Release()
+
+		return handler(sessions.NewContext(ctx, sess), req)
+	}
+}
+
+// serverStream overrides the context of a server stream with the session's one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which starts the session
+// of the stream's metadata session id and attaches it to the stream's context, see `UnaryServerInterceptor`.
+// The session is released when the stream's handler returns.
+func StreamServerInterceptor(manager *sessions.Sessions, opts ...Options) grpc.StreamServerInterceptor {
+	key := metadataKey(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		sess := manager.StartContext(clientContext(ctx), sessionID(ctx, key), func(value string) {
+			ss.SetHeader(metadata.Pairs(key, value))
+		})
+		defer sess.Release()
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: sessions.NewContext(ctx, sess)})
+	}
+}
+
+// WithSession returns a copy of the client's "ctx" which sends the session id "value",
+// i.e the value of the session cookie of the HTTP edge, to the RPCs,
+// the "opts" should be the ones of the server's interceptors, see `Options#MetadataKey`.
+func WithSession(ctx context.Context, value string, opts ...Options) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, metadataKey(opts), value)
+}
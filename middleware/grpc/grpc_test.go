@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// serverTransportStream records the headers of a unary RPC.
+type serverTransportStream struct {
+	header metadata.MD
+}
+
+func (s *serverTransportStream) Method() string { return "/test.Service/Unary" }
+
+func (s *serverTransportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *serverTransportStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *serverTransportStream) SetTrailer(metadata.MD) error { return nil }
+
+// fakeServerStream records the headers of a stream RPC.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// incoming returns the server's context of the client's "ctx" of the `WithSession`.
+func incoming(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	manager := sessions.New(sessions.Config{})
+	opts := Options{MetadataKey: "x-sid"}
+	interceptor := UnaryServerInterceptor(manager, opts)
+
+	set := func(ctx context.Context, req interface{}) (interface{}, error) {
+		sessions.FromContext(ctx).Set("name", req)
+		return nil, nil
+	}
+	get := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return sessions.FromContext(ctx).GetString("name"), nil
+	}
+
+	stream := new(serverTransportStream)
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	if _, err := interceptor(ctx, "kataras", &grpc.UnaryServerInfo{}, set); err != nil {
+		t.Fatal(err)
+	}
+
+	values := stream.header.Get("x-sid")
+	if len(values) != 1 {
+		t.Fatalf("expected the id of the new session to be sent by the metadata key but got %v", stream.header)
+	}
+
+	ctx = incoming(WithSession(context.Background(), values[0], opts))
+	name, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "kataras" {
+		t.Fatalf("expected the session of the metadata's id but got %v", name)
+	}
+
+	// the session id of another key is not read.
+	ctx = incoming(WithSession(context.Background(), values[0]))
+	if name, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, get); name != "" {
+		t.Fatalf("expected a new session for the default metadata key but got %v", name)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var signals sessions.RiskSignals
+	manager := sessions.New(sessions.Config{RiskScorer: sessions.RiskScorerFunc(func(s sessions.RiskSignals) float64 {
+		signals = s
+		return 0
+	})})
+	interceptor := StreamServerInterceptor(manager)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50051}})
+	ss := &fakeServerStream{ctx: ctx}
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ interface{}, stream grpc.ServerStream) error {
+		sessions.FromContext(stream.Context()).Set("name", "kataras")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := ss.header.Get(DefaultMetadataKey)
+	if len(values) != 1 {
+		t.Fatalf("expected the id of the new session to be sent but got %v", ss.header)
+	}
+	if signals.IP != "10.0.0.1" {
+		t.Fatalf("expected the peer's address to be assessed but got %q", signals.IP)
+	}
+
+	ss = &fakeServerStream{ctx: incoming(WithSession(context.Background(), values[0]))}
+	var name string
+	err = interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ interface{}, stream grpc.ServerStream) error {
+		name = sessions.FromContext(stream.Context()).GetString("name")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "kataras" || len(ss.header) != 0 {
+		t.Fatalf("expected the session of the metadata's id to be kept but got %q and %v", name, ss.header)
+	}
+}
@@ -0,0 +1,215 @@
+// Package secureheaders provides a middleware which sets the security headers
+// that are coupled with the session's lifecycle: "Cache-Control" on the responses
+// which carry a session, so shared caches don't store them, and "Clear-Site-Data"
+// on the responses which destroy the session, so the browser purges the client-side state on logout.
+package secureheaders
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/valyala/fasthttp"
+)
+
+// Options are the options of the security headers middleware.
+type Options struct {
+	// CacheControl is the "Cache-Control" header value of the responses which carry a session,
+	// it's set unless the handler already set a "private" or "no-store" one.
+	// Empty value disables it.
+	//
+	// Defaults to "private".
+	CacheControl string
+	// ClearSiteData are the directives of the "Clear-Site-Data" header
+	// which is set when the session is destroyed, it overrides the `sessions.Config#ClearSiteData`.
+	// Empty value disables it.
+	//
+	// Defaults to "cache", "cookies" and "storage".
+	ClearSiteData []string
+}
+
+// DefaultOptions returns the default options of the security headers middleware.
+func DefaultOptions() Options {
+	return Options{
+		CacheControl:  "private",
+		ClearSiteData: []string{"cache", "cookies", "storage"},
+	}
+}
+
+// ErrNotHijacker is returned by the `Hijack` of the middleware's response writer
+// when the underline response writer doesn't support the hijacking of its connection.
+var ErrNotHijacker = errors.New("secureheaders: the response writer is not a http.Hijacker")
+
+type headers struct {
+	cookieName    string
+	header        string
+	cacheControl  string
+	clearSiteData string
+}
+
+func newHeaders(manager *sessions.Sessions, opts []Options) *headers {
+	o := DefaultOptions()
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	h := &headers{
+		cookieName:   manager.Config().Cookie,
+		header:       manager.Config().Header,
+		cacheControl: o.CacheControl,
+	}
+
+	if len(o.ClearSiteData) > 0 {
+		directives := make([]string, 0, len(o.ClearSiteData))
+		for _, directive := range o.ClearSiteData {
+			directives = append(directives, `"`+strings.Trim(directive, `"`)+`"`)
+		}
+		h.clearSiteData = strings.Join(directives, ", ")
+	}
+
+	return h
+}
+
+// set sets the headers based on the session's state,
+// "carried" reports whether the response carries a session and "destroyed" whether it destroys it.
+func (h *headers) set(set func(key, value string), cacheControl string, carried, destroyed bool) {
+	if destroyed && h.clearSiteData != "" {
+		set("Clear-Site-Data", h.clearSiteData)
+	}
+
+	if (carried || destroyed) && h.cacheControl != "" &&
+		!strings.Contains(cacheControl, "private") && !strings.Contains(cacheControl, "no-store") {
+		set("Cache-Control", h.cacheControl)
+	}
+}
+
+// carried reports whether the session id is carried by the "value" of the `sessions.Config#Header`, if it's set.
+func (h *headers) carried(value func(key string) string) bool {
+	return h.header != "" && value(h.header) != ""
+}
+
+// isExpired reports whether a response cookie removes the client's cookie.
+func isExpired(maxAge int, expires time.Time) bool {
+	return maxAge < 0 || (!expires.IsZero() && expires.Before(time.Now()))
+}
+
+// New returns a new net/http middleware which sets the session's security headers.
+//
+// Usage:
+// http.ListenAndServe(":8080", secureheaders.New(manager)(mux))
+func New(manager *sessions.Sessions, opts ...Options) func(http.Handler) http.Handler {
+	h := newHeaders(manager, opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			carried := sessions.GetCookie(r, h.cookieName) != "" || h.carried(r.Header.Get)
+			rw := &responseWriter{ResponseWriter: w, headers: h, carried: carried}
+			next.ServeHTTP(rw, r)
+			rw.setHeaders()
+		})
+	}
+}
+
+// responseWriter sets the headers right before the response's headers are written.
+type responseWriter struct {
+	http.ResponseWriter
+	headers     *headers
+	carried     bool
+	wroteHeader bool
+}
+
+func (w *responseWriter) setHeaders() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	header := w.Header()
+	carried, destroyed := w.carried || w.headers.carried(header.Get), false
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		if cookie.Name != w.headers.cookieName {
+			continue
+		}
+
+		if isExpired(cookie.MaxAge, cookie.Expires) {
+			destroyed = true
+		} else {
+			carried = true
+		}
+	}
+
+	w.headers.set(header.Set, header.Get("Cache-Control"), carried, destroyed)
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sets the headers and sends the buffered data to the client,
+// if the underline response writer is a http.Flusher.
+func (w *responseWriter) Flush() {
+	w.setHeaders()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection of the underline response writer, i.e for a websocket upgrade,
+// it returns the `ErrNotHijacker` if it's not a http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrNotHijacker
+	}
+
+	// the connection is not a response anymore, its headers are not sent.
+	w.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underline response writer, used by the http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NewFasthttp returns a new fasthttp middleware which sets the session's security headers.
+//
+// Usage:
+// fasthttp.ListenAndServe(":8080", secureheaders.NewFasthttp(manager)(handler))
+func NewFasthttp(manager *sessions.Sessions, opts ...Options) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	h := newHeaders(manager, opts)
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			carried := sessions.GetCookieFasthttp(ctx, h.cookieName) != "" ||
+				h.carried(func(key string) string { return string(ctx.Request.Header.Peek(key)) })
+			next(ctx)
+
+			carried = carried || h.carried(func(key string) string { return string(ctx.Response.Header.Peek(key)) })
+
+			destroyed := false
+			cookie := fasthttp.AcquireCookie()
+			cookie.SetKey(h.cookieName)
+			if ctx.Response.Header.Cookie(cookie) {
+				if isExpired(cookie.MaxAge(), cookie.Expire()) {
+					destroyed = true
+				} else {
+					carried = true
+				}
+			}
+			fasthttp.ReleaseCookie(cookie)
+
+			h.set(ctx.Response.Header.Set, string(ctx.Response.Header.Peek("Cache-Control")), carried, destroyed)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package secureheaders
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNew(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	handler := New(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			manager.Start(w, r).Set("name", "kataras")
+		case "/logout":
+			manager.Destroy(w, r)
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+	if got := w.Header().Get("Cache-Control"); got != "" {
+		t.Fatalf("expected no Cache-Control on a response without a session but got %q", got)
+	}
+
+	login := httptest.NewRecorder()
+	handler.ServeHTTP(login, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if got := login.Header().Get("Cache-Control"); got != "private" {
+		t.Fatalf("expected the private Cache-Control on the response which sets the session but got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, sessionstest.NextRequest(login, http.MethodGet, "/public", nil))
+	if got := w.Header().Get("Cache-Control"); got != "private" {
+		t.Fatalf("expected the private Cache-Control on the request which carries the session but got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, sessionstest.NextRequest(login, http.MethodGet, "/logout", nil))
+	if got := w.Header().Get("Clear-Site-Data"); got != `"cache", "cookies", "storage"` {
+		t.Fatalf("expected the Clear-Site-Data on the logout but got %q", got)
+	}
+}
+
+func TestNewHeaderTransport(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{Header: "X-Session-ID", DisableCookie: true})
+	handler := New(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			manager.Start(w, r).Set("name", "kataras")
+		}
+	}))
+
+	login := httptest.NewRecorder()
+	handler.ServeHTTP(login, httptest.NewRequest(http.MethodGet, "/login", nil))
+	sid := login.Header().Get("X-Session-ID")
+	if sid == "" {
+		t.Fatalf("expected the session id to be sent by the header")
+	}
+	if got := login.Header().Get("Cache-Control"); got != "private" {
+		t.Fatalf("expected the private Cache-Control on the response which sends the session header but got %q", got)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/public", nil)
+	r.Header.Set("X-Session-ID", sid)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Cache-Control"); got != "private" {
+		t.Fatalf("expected the private Cache-Control on the request which carries the session header but got %q", got)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	handler := New(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		manager.Start(w, r).Set("name", "kataras")
+		w.(http.Flusher).Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !w.Flushed {
+		t.Fatalf("expected the flush to be passed to the underline response writer")
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private" {
+		t.Fatalf("expected the headers to be set before the flush but got %q", got)
+	}
+}
+
+// hijacker is a response recorder which can be hijacked.
+type hijacker struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestHijack(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+
+	var err error
+	handler := New(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err = w.(http.Hijacker).Hijack()
+	}))
+
+	w := &hijacker{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil || !w.hijacked {
+		t.Fatalf("expected the hijack to be passed to the underline response writer but got %v", err)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != ErrNotHijacker {
+		t.Fatalf("expected the ErrNotHijacker but got %v", err)
+	}
+}
+
+func TestNewFasthttp(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{Header: "X-Session-ID"})
+	handler := NewFasthttp(manager)(func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/logout" {
+			manager.DestroyFasthttp(ctx)
+		}
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/public")
+	handler(&ctx)
+	if got := ctx.Response.Header.Peek("Cache-Control"); len(got) != 0 {
+		t.Fatalf("expected no Cache-Control on a response without a session but got %q", got)
+	}
+
+	ctx = fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/public")
+	ctx.Request.Header.Set("X-Session-ID", "sid")
+	handler(&ctx)
+	if got := string(ctx.Response.Header.Peek("Cache-Control")); got != "private" {
+		t.Fatalf("expected the private Cache-Control on the request which carries the session header but got %q", got)
+	}
+
+	ctx = fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/logout")
+	ctx.Request.Header.SetCookie(manager.Config().Cookie, "sid")
+	handler(&ctx)
+	if got := string(ctx.Response.Header.Peek("Clear-Site-Data")); got != `"cache", "cookies", "storage"` {
+		t.Fatalf("expected the Clear-Site-Data on the logout but got %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+// Package stdmux provides route-scoped session requirements for the net/http routers,
+// the middlewares have the func(http.Handler) http.Handler form, so they work with
+// the plain http.ServeMux, chi and gorilla/mux alike.
+//
+// Usage:
+// import "github.com/kataras/go-sessions/middleware/stdmux"
+// mux.Handle("/account", stdmux.RequireSession(manager, accountHandler))
+// r.With(stdmux.Require(manager)).Get("/account", account) // chi
+// r.Use(stdmux.Require(manager, stdmux.Options{Keys: []string{"user"}})) // gorilla/mux
+package stdmux
+
+import (
+	"net/http"
+
+	"github.com/kataras/go-sessions"
+)
+
+// Options are the options of the session requirement.
+type Options struct {
+	// Keys are the session keys which the session should carry, i.e "user" for the authenticated routes.
+	//
+	// Defaults to nil, the session should carry at least one value
+	Keys []string
+	// ErrorHandler is called when the request has no valid session,
+	// the next handler is not executed.
+	//
+	// Defaults to a 401 Unauthorized response.
+	ErrorHandler http.Handler
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// valid reports whether the "sess" meets the requirement of the "keys".
+func valid(sess *sessions.Session, keys []string) bool {
+	if len(keys) == 0 {
+		return len(sess.GetAll()) > 0
+	}
+
+	for _, key := range keys {
+		if sess.Get(key) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Require returns a new net/http middleware which executes the next handler
+// only if the request has a valid session, see `Options#Keys`, otherwise it responds with 401.
+// The session is read from the request's context, if the `sessions.Sessions#Handler` is registered,
+// otherwise it's started and released by the middleware and it's injected to the request's context,
+// the handlers read it by the `Get`.
+func Require(manager *sessions.Sessions, opts ...Options) func(http.Handler) http.Handler {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	errorHandler := options.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = http.HandlerFunc(unauthorized)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess := sessions.FromContext(r.Context())
+			if sess == nil {
+				sess = manager.Start(w, r)
+				defer sess.Release()
+
+				r = r.WithContext(sessions.NewContext(r.Context(), sess))
+			}
+
+			if !valid(sess, options.Keys) {
+				errorHandler.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession executes the "next" handler only if the request has a session with values,
+// otherwise it responds with 401, see `Require`.
+func RequireSession(manager *sessions.Sessions, next http.Handler) http.Handler {
+	return Require(manager)(next)
+}
+
+// RequireSessionFunc same as `RequireSession` but it accepts a handler func.
+func RequireSessionFunc(manager *sessions.Sessions, next http.HandlerFunc) http.Handler {
+	return Require(manager)(next)
+}
+
+// Get returns the session of the request, it returns nil
+// if the request didn't pass through the `Require` or the `sessions.Sessions#Handler`.
+func Get(r *http.Request) *sessions.Session {
+	return sessions.FromContext(r.Context())
+}
@@ -0,0 +1,76 @@
+package stdmux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+func account(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(Get(r).GetString("user")))
+}
+
+func TestRequireSessionUnauthorized(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	handler := RequireSessionFunc(manager, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("expected the next handler to be skipped")
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/account", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d but got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestRequireErrorHandler(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	login := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	handler := Require(manager, Options{Keys: []string{"user"}, ErrorHandler: login})(http.HandlerFunc(account))
+
+	// the session has a value but not the required key.
+	sess := sessionstest.NewSession(t, map[string]interface{}{"theme": "dark"})
+	r := httptest.NewRequest(http.MethodGet, "/account", nil)
+	r = r.WithContext(sessions.NewContext(r.Context(), sess))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/login" {
+		t.Fatalf("expected a redirect to the login but got %d: %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestRequirePassThrough(t *testing.T) {
+	manager := sessionstest.NewManager(t, sessions.Config{})
+	handler := Require(manager, Options{Keys: []string{"user"}})(http.HandlerFunc(account))
+
+	// the session is started by the middleware, from the cookie of the login.
+	w := httptest.NewRecorder()
+	login := httptest.NewRequest(http.MethodPost, "/login", nil)
+	sess := manager.Start(w, login)
+	sess.Set("user", "kataras")
+	sess.Release()
+
+	r := sessionstest.NextRequest(w, http.MethodGet, "/account", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "kataras" {
+		t.Fatalf("expected the session to pass through but got %d: %s", w.Code, w.Body.String())
+	}
+
+	// the session of the request's context, i.e by the `sessions.Sessions#Handler`, is used as it is.
+	injected := sessionstest.NewSession(t, map[string]interface{}{"user": "makis"})
+	r = httptest.NewRequest(http.MethodGet, "/account", nil)
+	r = r.WithContext(sessions.NewContext(r.Context(), injected))
+
+	w = httptest.NewRecorder()
+	RequireSession(manager, http.HandlerFunc(account)).ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "makis" {
+		t.Fatalf("expected the injected session to pass through but got %d: %s", w.Code, w.Body.String())
+	}
+}
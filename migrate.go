@@ -0,0 +1,136 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotScanner returned by the `Migrate` when the source database doesn't implement the `Scanner`.
+var ErrNotScanner = errors.New("sessions: the source database doesn't implement the sessions.Scanner")
+
+// MigrateProgress reports the progress of a `Migrate`.
+type MigrateProgress struct {
+	// SessionID is the id of the last scanned session.
+	SessionID string
+	// Migrated is the number of the sessions which are written to the target so far.
+	Migrated int
+	// Skipped is the number of the expired, the filtered and the existing sessions which are not written.
+	Skipped int
+	// Failed is the number of the sessions which the target failed to write,
+	// they are kept on the source, see `MigrateOptions#Delete`.
+	Failed int
+}
+
+// MigrateOptions are the options of the `Migrate`.
+type MigrateOptions struct {
+	// Progress if not nil it's called after each scanned session, i.e to print the progress of a migration tool.
+	//
+	// Defaults to nil
+	Progress func(p MigrateProgress)
+	// Filter if not nil it's called for each session of the source, it returns false to skip the session.
+	//
+	// Defaults to nil, all sessions are migrated
+	Filter func(sid string, store RemoteStore) bool
+	// SkipExisting if true then the sessions which exist on the target are kept as they are,
+	// i.e on a re-run while the app writes its sessions to the target.
+	//
+	// Defaults to false, the sessions of the target are overridden
+	SkipExisting bool
+	// Delete if true then the migrated sessions are destroyed from the source, after the scan,
+	// the sessions which the target failed to write are kept.
+	//
+	// Defaults to false
+	Delete bool
+}
+
+// Migrate copies the sessions of the "from" database to the "to" database, i.e to switch from a boltdb to a redis
+// without logging the users out. The "from" database should implement the `Scanner`.
+// Each session keeps its values, its creation time and its expiration time,
+// the expired sessions are skipped and the target sets the remaining lifetime of the rest.
+//
+// It stops and returns the "ctx"'s error if it's canceled, the sessions which are migrated so far are kept.
+// The returned progress reports the migrated, the skipped and the failed sessions,
+// the failures of the target are reported if it implements the `TrySync(SyncPayload) error`, i.e the redis database.
+// Close the "to" database afterwards if it writes asynchronously, see `CloseDatabase`.
+//
+// Usage:
+// progress, err := sessions.Migrate(ctx, boltDB, redisDB, sessions.MigrateOptions{Progress: printProgress})
+func Migrate(ctx context.Context, from, to Database, opts ...MigrateOptions) (MigrateProgress, error) {
+	var o MigrateOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	var progress MigrateProgress
+
+	scanner, ok := from.(Scanner)
+	if !ok {
+		return progress, ErrNotScanner
+	}
+
+	var migrated []string
+	scanner.Scan(func(sid string, store RemoteStore) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		progress.SessionID = sid
+		switch written, err := migrate(to, sid, store, o); {
+		case err != nil:
+			progress.Failed++
+		case written:
+			progress.Migrated++
+			if o.Delete {
+				migrated = append(migrated, sid)
+			}
+		default:
+			progress.Skipped++
+		}
+
+		if o.Progress != nil {
+			o.Progress(progress)
+		}
+		return true
+	})
+
+	// the source is modified after the scan, i.e a boltdb can't be written inside its iteration.
+	for _, sid := range migrated {
+		from.Sync(SyncPayload{SessionID: sid, Action: ActionDestroy})
+	}
+
+	return progress, ctx.Err()
+}
+
+// fallibleDatabase is a database which reports the errors of its writes, i.e the redis database.
+type fallibleDatabase interface {
+	TrySync(p SyncPayload) error
+}
+
+// migrate writes the session "sid" to the "to" database, it reports whether it's written
+// and the error of the "to" database, if it's a `fallibleDatabase`.
+func migrate(to Database, sid string, store RemoteStore, opts MigrateOptions) (bool, error) {
+	if store.Lifetime.HasExpired() {
+		return false, nil
+	}
+
+	if opts.Filter != nil && !opts.Filter(sid, store) {
+		return false, nil
+	}
+
+	if opts.SkipExisting {
+		if existing := to.Load(sid); len(existing.Values) > 0 || !existing.Lifetime.IsZero() {
+			return false, nil
+		}
+	}
+
+	p := SyncPayload{SessionID: sid, Action: ActionInsert, Store: store}
+	if fallible, ok := to.(fallibleDatabase); ok {
+		if err := fallible.TrySync(p); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	to.Sync(p)
+	return true, nil
+}
@@ -0,0 +1,124 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMigrate(t *testing.T) {
+	from, to := newConcurrentDatabase(), newConcurrentDatabase()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	from.Sync(SyncPayload{SessionID: "alive", Action: ActionInsert, Store: RemoteStore{
+		Values:   Store{{Key: "name", ValueRaw: "kataras"}},
+		Lifetime: LifeTime{Time: expiresAt},
+	}})
+	from.Sync(SyncPayload{SessionID: "expired", Action: ActionInsert, Store: RemoteStore{
+		Values:   Store{{Key: "name", ValueRaw: "gone"}},
+		Lifetime: LifeTime{Time: time.Now().Add(-time.Hour)},
+	}})
+	from.Sync(SyncPayload{SessionID: "existing", Action: ActionInsert, Store: RemoteStore{
+		Values: Store{{Key: "name", ValueRaw: "old"}},
+	}})
+	to.Sync(SyncPayload{SessionID: "existing", Action: ActionInsert, Store: RemoteStore{
+		Values: Store{{Key: "name", ValueRaw: "new"}},
+	}})
+
+	var reports int
+	progress, err := Migrate(context.Background(), from, to, MigrateOptions{
+		Progress:     func(MigrateProgress) { reports++ },
+		SkipExisting: true,
+		Delete:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if progress.Migrated != 1 || progress.Skipped != 2 || reports != 3 {
+		t.Fatalf("expected 1 migrated and 2 skipped sessions on 3 reports but got %#v on %d", progress, reports)
+	}
+
+	store := to.Load("alive")
+	if got := store.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the migrated value but got %q", got)
+	}
+	if !store.Lifetime.Equal(expiresAt) {
+		t.Fatalf("expected the expiration time %s to be kept but got %s", expiresAt, store.Lifetime.Time)
+	}
+
+	existing := to.Load("existing")
+	if got := existing.Values.GetString("name"); got != "new" {
+		t.Fatalf("expected the existing session of the target to be kept but got %q", got)
+	}
+	if got := to.Load("expired"); len(got.Values) != 0 {
+		t.Fatal("expected the expired session to be skipped")
+	}
+
+	if got := from.Load("alive"); len(got.Values) != 0 {
+		t.Fatal("expected the migrated session to be deleted from the source")
+	}
+	if got := from.Load("existing"); len(got.Values) == 0 {
+		t.Fatal("expected the skipped session to be kept on the source")
+	}
+}
+
+func TestMigrateCanceled(t *testing.T) {
+	from, to := newConcurrentDatabase(), newConcurrentDatabase()
+	from.Sync(SyncPayload{SessionID: "sid", Action: ActionInsert, Store: RemoteStore{Values: Store{{Key: "key", ValueRaw: "value"}}}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Migrate(ctx, from, to); err != context.Canceled {
+		t.Fatalf("expected the context's error but got %v", err)
+	}
+	if len(to.Load("sid").Values) != 0 {
+		t.Fatal("expected no migrated sessions")
+	}
+
+	if _, err := Migrate(context.Background(), struct{ Database }{from}, to); err != ErrNotScanner {
+		t.Fatalf("expected the ErrNotScanner but got %v", err)
+	}
+}
+
+// failingDatabase is a session database which fails to write the sessions of its "fail" ids.
+type failingDatabase struct {
+	*concurrentDatabase
+	fail map[string]bool
+}
+
+func (db *failingDatabase) TrySync(p SyncPayload) error {
+	if db.fail[p.SessionID] {
+		return errors.New("connection refused")
+	}
+
+	db.Sync(p)
+	return nil
+}
+
+func TestMigrateFailed(t *testing.T) {
+	from := newConcurrentDatabase()
+	to := &failingDatabase{concurrentDatabase: newConcurrentDatabase(), fail: map[string]bool{"failed": true}}
+
+	for _, sid := range []string{"migrated", "failed"} {
+		from.Sync(SyncPayload{SessionID: sid, Action: ActionInsert, Store: RemoteStore{Values: Store{{Key: "name", ValueRaw: sid}}}})
+	}
+
+	progress, err := Migrate(context.Background(), from, to, MigrateOptions{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if progress.Migrated != 1 || progress.Failed != 1 || progress.Skipped != 0 {
+		t.Fatalf("expected 1 migrated and 1 failed session but got %#v", progress)
+	}
+
+	if got := from.Load("failed"); len(got.Values) == 0 {
+		t.Fatal("expected the session which failed to be written to be kept on the source")
+	}
+	if got := from.Load("migrated"); len(got.Values) != 0 {
+		t.Fatal("expected the migrated session to be deleted from the source")
+	}
+}
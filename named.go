@@ -0,0 +1,105 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// namedSessions are the managers of the named sessions of a manager, see `Sessions#StartNamed`.
+type namedSessions struct {
+	mu       sync.Mutex
+	managers map[string]*Sessions
+}
+
+func newNamedSessions() *namedSessions {
+	return &namedSessions{managers: make(map[string]*Sessions)}
+}
+
+// close closes the named sessions' managers, all of them are closed even if some fail,
+// it returns their errors.
+func (n *namedSessions) close(ctx context.Context) []error {
+	n.mu.Lock()
+	managers := make([]*Sessions, 0, len(n.managers))
+	for _, manager := range n.managers {
+		managers = append(managers, manager)
+	}
+	n.mu.Unlock()
+
+	var errs []error
+	for _, manager := range managers {
+		if err := manager.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// namedCookie returns the default cookie's name of the "name" sessions.
+func (s *Sessions) namedCookie(name string) string {
+	return s.config.Cookie + "_" + name
+}
+
+// AddNamed registers the manager of the "name" sessions, by its own configuration, see `StartNamed`.
+// If the "cfg"'s cookie is empty or it's the manager's one, then the cookie's name is the manager's cookie + "_" + name.
+// It replaces a previous manager of the same name, it's not closed.
+//
+// The returned manager is independent, i.e its `UseDatabase` registers the backends of the "name" sessions only.
+func (s *Sessions) AddNamed(name string, cfg Config) *Sessions {
+	if cfg.Cookie == "" || cfg.Cookie == s.config.Cookie {
+		cfg.Cookie = s.namedCookie(name)
+	}
+
+	manager := New(cfg)
+	s.named.mu.Lock()
+	s.named.managers[name] = manager
+	s.named.mu.Unlock()
+	return manager
+}
+
+// Named returns the manager of the "name" sessions, see `AddNamed`,
+// if it's missing then it's created by the manager's configuration, with the manager's cookie + "_" + name cookie,
+// without databases.
+func (s *Sessions) Named(name string) *Sessions {
+	s.named.mu.Lock()
+	defer s.named.mu.Unlock()
+
+	manager, ok := s.named.managers[name]
+	if !ok {
+		cfg := s.config
+		cfg.Cookie = s.namedCookie(name)
+		manager = New(cfg)
+		s.named.managers[name] = manager
+	}
+
+	return manager
+}
+
+// StartNamed starts the "name" session of the request of the `Default` manager, see `Sessions#StartNamed`.
+func StartNamed(name string, w http.ResponseWriter, r *http.Request) *Session {
+	return Default.StartNamed(name, w, r)
+}
+
+// StartNamed starts the "name" session of the request, a session which is independent of the request's `Start` one,
+// with its own cookie, lifetime and databases, i.e a short-lived "auth" session next to a long-lived "prefs" session.
+// Its manager is registered by the `AddNamed`, see `Named`.
+//
+// Usage:
+// manager.AddNamed("prefs", sessions.Config{Expires: 365 * 24 * time.Hour}).UseDatabase(db)
+// prefs := manager.StartNamed("prefs", w, r)
+func (s *Sessions) StartNamed(name string, w http.ResponseWriter, r *http.Request) *Session {
+	return s.Named(name).Start(w, r)
+}
+
+// StartNamedFasthttp starts the "name" session of the request of the `Default` manager, see `Sessions#StartNamed`.
+func StartNamedFasthttp(name string, ctx *fasthttp.RequestCtx) *Session {
+	return Default.StartNamedFasthttp(name, ctx)
+}
+
+// StartNamedFasthttp starts the "name" session of the request, see `Sessions#StartNamed`.
+func (s *Sessions) StartNamedFasthttp(name string, ctx *fasthttp.RequestCtx) *Session {
+	return s.Named(name).StartFasthttp(ctx)
+}
@@ -0,0 +1,53 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStartNamed(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	prefs := manager.AddNamed("prefs", Config{Expires: 24 * time.Hour})
+	db := newConcurrentDatabase()
+	prefs.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	sess := manager.Start(w, r)
+	sess.Set("user", "kataras")
+	named := manager.StartNamed("prefs", w, r)
+	named.Set("theme", "dark")
+
+	if sess.ID() == named.ID() {
+		t.Fatalf("expected independent sessions")
+	}
+	if named.Get("user") != nil || sess.Get("theme") != nil {
+		t.Fatalf("expected the values of the sessions to be independent")
+	}
+
+	if c := findCookie(w, DefaultCookieName+"_prefs"); c == nil || c.MaxAge <= int(time.Hour.Seconds()) {
+		t.Fatalf("expected the named session's own cookie and lifetime but got %v", c)
+	}
+
+	db.mu.Lock()
+	_, stored := db.stores[named.ID()]
+	_, leaked := db.stores[sess.ID()]
+	db.mu.Unlock()
+	if !stored || leaked {
+		t.Fatalf("expected only the named session to be stored to its database")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	if got := manager.StartNamed("prefs", httptest.NewRecorder(), r); got.ID() != named.ID() {
+		t.Fatalf("expected the named session to be restored by its cookie")
+	}
+
+	if lazy := manager.Named("cart"); lazy.Config().Cookie != DefaultCookieName+"_cart" || manager.Named("cart") != lazy {
+		t.Fatalf("expected a single lazily created manager")
+	}
+}
@@ -0,0 +1,95 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ErrNumberOverflow is returned by the numeric getters
+// when the stored number doesn't fit to the requested type.
+var ErrNumberOverflow = errors.New("sessions: number overflows the requested type")
+
+// numberToInt64 converts the json.Number, *big.Int and *big.Rat values to int64,
+// it reports false if the "v" is not one of them.
+func numberToInt64(v interface{}) (int64, bool, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, true, ErrNumberOverflow
+		}
+		return i, true, err
+	case *big.Int:
+		if !n.IsInt64() {
+			return 0, true, ErrNumberOverflow
+		}
+		return n.Int64(), true, nil
+	case *big.Rat:
+		if !n.IsInt() {
+			return 0, true, fmt.Errorf("%s is not an integer", n.RatString())
+		}
+		if !n.Num().IsInt64() {
+			return 0, true, ErrNumberOverflow
+		}
+		return n.Num().Int64(), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// numberToInt same as `numberToInt64` but it checks for int overflows too.
+func numberToInt(v interface{}) (int, bool, error) {
+	i, ok, err := numberToInt64(v)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	if int64(int(i)) != i {
+		return 0, true, ErrNumberOverflow
+	}
+
+	return int(i), true, nil
+}
+
+// numberToFloat64 converts the json.Number, *big.Int and *big.Rat values to float64,
+// it reports false if the "v" is not one of them.
+func numberToFloat64(v interface{}) (float64, bool, error) {
+	var f float64
+	switch n := v.(type) {
+	case json.Number:
+		var err error
+		if f, err = n.Float64(); err != nil {
+			return 0, true, err
+		}
+	case *big.Int:
+		f, _ = new(big.Float).SetInt(n).Float64()
+	case *big.Rat:
+		f, _ = n.Float64()
+	default:
+		return 0, false, nil
+	}
+
+	if math.IsInf(f, 0) {
+		return 0, true, ErrNumberOverflow
+	}
+
+	return f, true, nil
+}
+
+// numberToFloat32 same as `numberToFloat64` but it checks for float32 overflows too.
+func numberToFloat32(v interface{}) (float32, bool, error) {
+	f, ok, err := numberToFloat64(v)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+
+	if math.Abs(f) > math.MaxFloat32 {
+		return 0, true, ErrNumberOverflow
+	}
+
+	return float32(f), true, nil
+}
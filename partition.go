@@ -0,0 +1,139 @@
+package sessions
+
+import (
+	"time"
+)
+
+// PartitionKeyPrefix is the prefix of the session keys which the partitions are stored to,
+// i.e the "admin" partition is stored to the "partition:admin" key, see `Session#Partition`.
+const PartitionKeyPrefix = "partition:"
+
+// PartitionConfig is the configuration of a session partition, see `Config#Partitions`.
+type PartitionConfig struct {
+	// Expires is the lifetime of the partition, since its first `Partition#Set`,
+	// the partition is removed when it expires, the base session is kept.
+	//
+	// Defaults to 0, the partition expires with the session
+	Expires time.Duration
+	// Transcoder serializes the partition's values, independently of the session databases' serialization.
+	//
+	// Defaults to the `DefaultTranscoder`
+	Transcoder Transcoder
+}
+
+// partitionPayload is the serialized form of a partition.
+type partitionPayload struct {
+	Values  Store
+	Expires time.Time
+}
+
+// Partition is an isolated set of values of a session, with its own expiration and serialization,
+// i.e the elevated-privilege data of an admin area which should expire faster than the session,
+// without a separate cookie. See `Session#Partition`.
+type Partition struct {
+	sess   *Session
+	name   string
+	key    string
+	config PartitionConfig
+}
+
+// Partition returns the partition of the "name", its configuration is the `Config#Partitions` entry of the "name".
+// The partition is stored, serialized by its transcoder, to the `PartitionKeyPrefix` + "name" key of the session,
+// so it's saved to the session databases and it's removed by the session's `Clear` and `Destroy` too.
+//
+// A partition is read and written as a whole, use the `Config#SingleWriter`
+// when the concurrent requests of a session modify the same partition.
+func (s *Session) Partition(name string) *Partition {
+	var config PartitionConfig
+	if p := s.provider; p != nil && p.config != nil {
+		config = p.config.Partitions[name]
+	}
+
+	if config.Transcoder == nil {
+		config.Transcoder = DefaultTranscoder
+	}
+
+	return &Partition{sess: s, name: name, key: PartitionKeyPrefix + name, config: config}
+}
+
+// Name returns the name of the partition.
+func (p *Partition) Name() string {
+	return p.name
+}
+
+// load returns the values of the partition, false if it's missing, invalid or expired.
+// An expired or invalid partition is removed.
+func (p *Partition) load() (partitionPayload, bool) {
+	var payload partitionPayload
+
+	b, ok := p.sess.Get(p.key).([]byte)
+	if !ok {
+		return payload, false
+	}
+
+	if err := p.config.Transcoder.Unmarshal(b, &payload); err != nil ||
+		(!payload.Expires.IsZero() && !payload.Expires.After(p.sess.provider.now())) {
+		p.sess.Delete(p.key)
+		return partitionPayload{}, false
+	}
+
+	return payload, true
+}
+
+func (p *Partition) save(payload partitionPayload) error {
+	b, err := p.config.Transcoder.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	p.sess.Set(p.key, b)
+	return nil
+}
+
+// Get returns the value of the "key" of the partition, nil if it's missing or the partition expired.
+func (p *Partition) Get(key string) interface{} {
+	payload, _ := p.load()
+	return payload.Values.Get(key)
+}
+
+// GetString same as `Get` but returns its string representation,
+// it returns empty if the value is not a string.
+func (p *Partition) GetString(key string) string {
+	v, _ := p.Get(key).(string)
+	return v
+}
+
+// Set sets the "value" of the "key" to the partition, the partition is created,
+// and its expiration starts, if it's missing or expired.
+// It returns the error of the partition's transcoder, the partition is not changed.
+func (p *Partition) Set(key string, value interface{}) error {
+	payload, ok := p.load()
+	if !ok && p.config.Expires > 0 {
+		payload.Expires = p.sess.provider.now().Add(p.config.Expires)
+	}
+
+	payload.Values.Set(key, value)
+	return p.save(payload)
+}
+
+// Delete removes the "key" of the partition and reports whether it was removed.
+func (p *Partition) Delete(key string) bool {
+	payload, ok := p.load()
+	if !ok || !payload.Values.Remove(key) {
+		return false
+	}
+
+	return p.save(payload) == nil
+}
+
+// ExpiresAt returns the expiration time of the partition,
+// it's zero if the partition is missing or it expires with the session.
+func (p *Partition) ExpiresAt() time.Time {
+	payload, _ := p.load()
+	return payload.Expires
+}
+
+// Destroy removes the partition from the session, the base session is kept.
+func (p *Partition) Destroy() {
+	p.sess.Delete(p.key)
+}
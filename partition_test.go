@@ -0,0 +1,87 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPartition(t *testing.T) {
+	manager := New(Config{
+		Partitions: map[string]PartitionConfig{
+			"admin": {Expires: time.Hour, Transcoder: JSONTranscoder{}},
+		},
+	})
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("user", "kataras")
+
+	admin := sess.Partition("admin")
+	if err := admin.Set("role", "owner"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := admin.GetString("role"); got != "owner" {
+		t.Fatalf("expected the partition's value %q but got %q", "owner", got)
+	}
+
+	if sess.Get("role") != nil {
+		t.Fatal("expected the partition's values to be isolated from the session")
+	}
+
+	if _, ok := sess.Get(PartitionKeyPrefix + "admin").([]byte); !ok {
+		t.Fatal("expected the partition to be stored serialized")
+	}
+
+	if expires := admin.ExpiresAt(); expires.IsZero() || expires.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("expected the partition to expire in an hour but got %s", expires)
+	}
+
+	// the partition expired, the session is kept.
+	b, _ := JSONTranscoder{}.Marshal(partitionPayload{Values: Store{{Key: "role", ValueRaw: "owner"}}, Expires: time.Now().Add(-time.Second)})
+	sess.Set(PartitionKeyPrefix+"admin", b)
+	if admin.Get("role") != nil {
+		t.Fatal("expected the expired partition to be empty")
+	}
+
+	if sess.Get(PartitionKeyPrefix+"admin") != nil {
+		t.Fatal("expected the expired partition to be removed")
+	}
+
+	if got := sess.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the session's value to be kept but got %q", got)
+	}
+
+	// a partition without configuration expires with the session.
+	web := sess.Partition("web")
+	web.Set("theme", "dark")
+	if !web.ExpiresAt().IsZero() {
+		t.Fatal("expected the partition to expire with the session")
+	}
+
+	if !web.Delete("theme") || web.Get("theme") != nil {
+		t.Fatal("expected the partition's value to be deleted")
+	}
+
+	web.Destroy()
+	if sess.Get(PartitionKeyPrefix+"web") != nil {
+		t.Fatal("expected the partition to be destroyed")
+	}
+}
+
+func TestPartitionClock(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{Clock: clock, Partitions: map[string]PartitionConfig{"admin": {Expires: time.Hour}}})
+
+	admin := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)).Partition("admin")
+	admin.Set("role", "owner")
+	if expires := admin.ExpiresAt(); !expires.Equal(clock.Now().Add(time.Hour)) {
+		t.Fatalf("expected the partition to expire in an hour by the manager's clock but got %s", expires)
+	}
+
+	clock.Advance(time.Hour)
+	if admin.Get("role") != nil {
+		t.Fatal("expected the partition to expire by the manager's clock")
+	}
+}
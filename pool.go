@@ -0,0 +1,56 @@
+package sessions
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool reuses the buffers of the gob encodings, see `GobSerialize`.
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+func acquireBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// maxPooledBuffer is the capacity of the largest buffer which is returned to the pool,
+// the rare large sessions should not keep their buffers alive.
+const maxPooledBuffer = 64 << 10
+
+func releaseBuffer(w *bytes.Buffer) {
+	if w.Cap() > maxPooledBuffer {
+		return
+	}
+
+	w.Reset()
+	bufferPool.Put(w)
+}
+
+// storePool reuses the backing arrays of the throwaway copies of the values, see `Session#fitSize`.
+// The values of the sessions are never pooled, a handler may still hold a destroyed session.
+var storePool sync.Pool
+
+// acquireStore returns an empty store, its backing array may be a released one.
+func acquireStore() Store {
+	if r, ok := storePool.Get().(*Store); ok {
+		return *r
+	}
+	return nil
+}
+
+// maxPooledStore is the capacity of the largest store which is returned to the pool.
+const maxPooledStore = 64
+
+// releaseStore returns the backing array of the "r" to the pool,
+// the "r" must not be used by anyone else after that.
+func releaseStore(r Store) {
+	if cap(r) == 0 || cap(r) > maxPooledStore {
+		return
+	}
+
+	r = r[:cap(r)]
+	for i := range r {
+		r[i] = Entry{} // release the values.
+	}
+	r = r[:0]
+	storePool.Put(&r)
+}
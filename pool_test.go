@@ -0,0 +1,58 @@
+package sessions
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGobSerializePooled(t *testing.T) {
+	var a, b Store
+	a.Set("name", "kataras")
+	b.Set("name", "makis")
+
+	first, err := GobSerialize(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append([]byte(nil), first...)
+
+	if _, err = GobSerialize(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, expected) {
+		t.Fatalf("expected the serialized bytes to not share the pooled buffer")
+	}
+}
+
+func TestReleaseStore(t *testing.T) {
+	store := make(Store, 0, 4)
+	store.Set("name", "kataras")
+	backing := store[:1]
+	releaseStore(store)
+
+	if backing[0].ValueRaw != nil || backing[0].Key != "" {
+		t.Fatalf("expected the released entries to be cleared but got %#v", backing[0])
+	}
+
+	if got := acquireStore(); got.Len() != 0 {
+		t.Fatalf("expected an empty store but got %d entries", got.Len())
+	}
+}
+
+func TestDestroyedSessionValuesNotReused(t *testing.T) {
+	manager := New(Config{})
+	held := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	held.Set("user", "kataras")
+	manager.DestroyByID(held.ID())
+
+	for i := 0; i < 10; i++ {
+		sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		sess.Set("user", "makis")
+	}
+
+	if got := held.values.GetString("user"); got != "kataras" {
+		t.Fatalf("expected the values of the destroyed session to be kept but got %q", got)
+	}
+}
@@ -1,7 +1,10 @@
 package sessions
 
 import (
+	"context"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,42 +12,124 @@ type (
 	// provider contains the sessions and external databases (load and update).
 	// It's the session memory manager
 	provider struct {
-		// we don't use RWMutex because all actions have read and write at the same action function.
-		// (or write to a *Session's value which is race if we don't lock)
-		// narrow locks are fasters but are useless here.
+		// mu guards the databases, the index and the invalidator,
+		// the sessions are guarded by their shards, see `sessionRegistry`.
 		mu        sync.Mutex
-		sessions  map[string]*Session
+		sessions  *sessionRegistry
 		databases []Database
+		// index maps the bound claims, i.e the login's subject, to the session ids.
+		index *sessionIndex
+		// config is the manager's configuration, it's set by the `New`.
+		config *Config
+		// hooks are the lifecycle callbacks, see `Sessions#OnCreate`.
+		hooks lifecycleHooks
+		// invalidator broadcasts the invalidations, the origin is the id of this app instance,
+		// see `Sessions#UseInvalidator`, unsubscribe are called on `Close`.
+		invalidator Invalidator
+		origin      string
+		unsubscribe []func() error
+		// hits and misses are the reads of the sessions, atomic, see `Sessions#Stats`.
+		hits   uint64
+		misses uint64
+		// closed is closed when the databases are closed, closeErr is their error, see `Close`.
+		closeOnce sync.Once
+		closed    chan struct{}
+		closeErr  error
 	}
 )
 
-// newProvider returns a new sessions provider
-func newProvider() *provider {
+// newProvider returns a new sessions provider,
+// its in-memory sessions are sharded across the "shards" locks, see `Config#Shards`.
+func newProvider(shards int) *provider {
 	return &provider{
-		sessions:  make(map[string]*Session, 0),
+		sessions:  newSessionRegistry(shards),
 		databases: make([]Database, 0),
+		index:     newSessionIndex(),
 	}
 }
 
 // RegisterDatabase adds a session database
 // a session db doesn't have write access
 func (p *provider) RegisterDatabase(db Database) {
+	if setter, ok := db.(LoggerSetter); ok && p.config != nil && p.config.Logger != nil {
+		setter.SetLogger(p.config.Logger)
+	}
+	if setter, ok := db.(ClockSetter); ok && p.config != nil && p.config.Clock != nil {
+		setter.SetClock(p.config.Clock)
+	}
+	if setter, ok := db.(EvictionNotifierSetter); ok {
+		setter.SetEvictionNotifier(p.notifyEviction)
+	}
+
 	p.mu.Lock() // for any case
 	p.databases = append(p.databases, db)
 	p.mu.Unlock()
 }
 
-// newSession returns a new session from sessionid
-func (p *provider) newSession(sid string, expires time.Duration) *Session {
+// notifyEviction fires the `OnEvict` callbacks of a session which is evicted by a session database,
+// see `EvictionNotifierSetter`.
+func (p *provider) notifyEviction(sid string, reason EvictionReason, snapshot Store) {
+	p.hooks.fireEvict(eviction{sid: sid, reason: reason, snapshot: snapshot})
+}
+
+// newSession returns a new session from sessionid,
+// it reports whether the session is created or restored from a database.
+func (p *provider) newSession(sid string, expires time.Duration) (*Session, bool) {
+	sess := &Session{sessionState: &sessionState{
+		sid:       sid,
+		provider:  p,
+		flashes:   make(map[string]*flashMessage),
+		writer:    make(chan struct{}, 1),
+		createdAt: p.now(),
+	}, requestState: new(requestState)}
+
+	// the session id may change by the `Session#RegenerateID`,
+	// so the session is destroyed by its current one.
 	onExpire := func() {
-		p.Destroy(sid)
+		p.mu.Lock()
+		sid := sess.sid
+		found, ok := p.sessions.get(sid)
+		expired := ok && found.sessionState == sess.sessionState
+		var ev eviction
+		if expired {
+			ev = p.deleteSession(sess, EvictionExpired)
+		}
+		p.mu.Unlock()
+
+		if expired {
+			p.hooks.fireExpire(sid)
+			p.hooks.fireEvict(ev)
+		}
 	}
 
-	values, lifetime := p.loadSessionFromDB(sid)
+	stored := p.loadSessionFromDB(sid)
+	values, lifetime := stored.Values, stored.Lifetime
+	created := len(values) == 0 && lifetime.IsZero()
+	atomic.StoreUint64(&sess.version, stored.Version)
+	if !stored.CreatedAt.IsZero() {
+		sess.createdAt = stored.CreatedAt
+	}
+	sess.extensions = stored.Extensions
+
+	deadline := p.absoluteDeadline(sess.createdAt)
+	exceeded := !deadline.IsZero() && !deadline.After(p.now())
+	if exceeded {
+		// the stored session exceeded the absolute lifetime, start over.
+		values, lifetime = nil, LifeTime{}
+		sess.createdAt = p.now()
+		sess.extensions = 0
+		created = true
+		deadline = p.absoluteDeadline(sess.createdAt)
+	}
+
+	lifetime.clock = p.clock()
 	// simple and straight:
 	if !lifetime.IsZero() {
 		// if stored time is not zero
 		// start a timer based on the stored time, if not expired.
+		if !deadline.IsZero() && lifetime.Time.After(deadline) {
+			lifetime.Time = deadline
+		}
 		lifetime.Revive(onExpire)
 	} else {
 		// Remember:  if db not exist or it has been expired
@@ -53,6 +138,16 @@ func (p *provider) newSession(sid string, expires time.Duration) *Session {
 		// Even if the database has an unlimited session (possible by a previous app run)
 		// priority to the "expires" is given,
 		// again if <=0 then it does nothing.
+		if cfg := p.config; cfg != nil && cfg.IdleTimeout > 0 && (expires <= 0 || cfg.IdleTimeout < expires) {
+			// the idle timeout comes first, it's extended on each `Start`, see `touch`.
+			expires = cfg.IdleTimeout
+		}
+
+		if !deadline.IsZero() {
+			if remaining := deadline.Sub(p.now()); expires <= 0 || remaining < expires {
+				expires = remaining
+			}
+		}
 		lifetime.Begin(expires, onExpire)
 	}
 
@@ -83,24 +178,29 @@ func (p *provider) newSession(sid string, expires time.Duration) *Session {
 	// 	lifetime.Reset(expires)
 	// }
 
-	sess := &Session{
-		sid:      sid,
-		provider: p,
-		values:   values,
-		flashes:  make(map[string]*flashMessage),
-		lifetime: lifetime,
+	sess.values = values
+	sess.lifetime = lifetime
+
+	if exceeded {
+		syncDatabases(p.databases, acquireSyncPayload(sess, ActionDestroy))
 	}
 
-	return sess
+	return sess, created
 }
 
-func (p *provider) loadSessionFromDB(sid string) (Store, LifeTime) {
+// loadSessionFromDB loads the session of the "sid" from the databases, their values are merged,
+// the creation time is the earliest and the version and the extensions are the greatest of them.
+func (p *provider) loadSessionFromDB(sid string) RemoteStore {
 	var store Store
 	var lifetime LifeTime
+	var createdAt time.Time
+	var version uint64
+	var extensions int
 
 	firstValidIdx := 1
 	for i, n := 0, len(p.databases); i < n; i++ {
 		storeDB := p.databases[i].Load(sid)
+		storeDB.Lifetime.clock = p.clock()
 		if storeDB.Lifetime.HasExpired() { // if expired then skip this db
 			firstValidIdx++
 			continue
@@ -111,6 +211,19 @@ func (p *provider) loadSessionFromDB(sid string) (Store, LifeTime) {
 			lifetime = storeDB.Lifetime
 		}
 
+		if !storeDB.CreatedAt.IsZero() && (createdAt.IsZero() || storeDB.CreatedAt.Before(createdAt)) {
+			// keep the earliest creation time.
+			createdAt = storeDB.CreatedAt
+		}
+
+		if storeDB.Version > version {
+			version = storeDB.Version
+		}
+
+		if storeDB.Extensions > extensions {
+			extensions = storeDB.Extensions
+		}
+
 		if n == firstValidIdx {
 			// if one database then set the store as it is
 			store = storeDB.Values
@@ -129,49 +242,236 @@ func (p *provider) loadSessionFromDB(sid string) (Store, LifeTime) {
 
 	/// TODO: bug on destroy doesn't being remove the file
 	// we will have to see it, it's not db's problem it's here on provider destroy or lifetime onExpire.
-	return store, lifetime
+	return RemoteStore{Values: store, Lifetime: lifetime, CreatedAt: createdAt, Version: version, Extensions: extensions}
+}
+
+// clock returns the clock of the sessions, see `Config#Clock`.
+func (p *provider) clock() Clock {
+	if cfg := p.config; cfg != nil && cfg.Clock != nil {
+		return cfg.Clock
+	}
+	return SystemClock
+}
+
+// now returns the current time of the sessions' clock.
+func (p *provider) now() time.Time {
+	return p.clock().Now()
+}
+
+// absoluteDeadline returns the time that the session created at "createdAt" expires
+// regardless of its activity, see `Config#MaxAbsoluteLifetime`. Zero time means no limit.
+func (p *provider) absoluteDeadline(createdAt time.Time) time.Time {
+	if cfg := p.config; cfg != nil && cfg.MaxAbsoluteLifetime > 0 {
+		return createdAt.Add(cfg.MaxAbsoluteLifetime)
+	}
+
+	return time.Time{}
+}
+
+// limit shortens the "d" extension of the session's lifetime to its absolute deadline,
+// it returns false if the deadline has passed.
+func (p *provider) limit(sess *Session, d time.Duration) (time.Duration, bool) {
+	deadline := p.absoluteDeadline(sess.createdAt)
+	if deadline.IsZero() {
+		return d, true
+	}
+
+	remaining := deadline.Sub(p.now())
+	if remaining <= 0 {
+		return 0, false
+	}
+
+	if d > remaining {
+		d = remaining
+	}
+	return d, true
 }
 
 // Init creates the session  and returns it
 func (p *provider) Init(sid string, expires time.Duration) *Session {
-	newSession := p.newSession(sid, expires)
-	p.mu.Lock()
-	p.sessions[sid] = newSession
-	p.mu.Unlock()
-	return newSession
+	sess, _ := p.init(sid, expires)
+	return sess
+}
+
+// init creates the session and reports whether it's a new one or it's restored from a database.
+func (p *provider) init(sid string, expires time.Duration) (*Session, bool) {
+	newSession, created := p.newSession(sid, expires)
+	p.sessions.set(sid, newSession)
+
+	if created {
+		p.hooks.fireCreate(sid)
+	}
+	return newSession, created
 }
 
 // UpdateExpiration update expire date of a session.
 // if expires > 0 then it updates the destroy task.
 // if expires <=0 then it does nothing, to destroy a session call the `Destroy` func instead.
-func (p *provider) UpdateExpiration(sid string, expires time.Duration) bool {
+//
+// The extension is limited by the `Config#MaxExtensions` and `Config#MaxExtendedLifetime`,
+// it returns the applied expiration duration and false if the session can't be extended.
+func (p *provider) UpdateExpiration(sid string, expires time.Duration) (time.Duration, bool) {
 	if expires <= 0 {
-		return false
+		return 0, false
 	}
 
-	p.mu.Lock()
-	sess, found := p.sessions[sid]
-	p.mu.Unlock()
+	sess, found := p.sessions.get(sid)
 	if !found {
-		return false
+		return 0, false
+	}
+
+	sess.mu.Lock()
+	expires, ok := p.extension(sess, expires)
+	if !ok {
+		sess.mu.Unlock()
+		return 0, false
 	}
 
+	sess.extensions++
 	sess.lifetime.Shift(expires)
-	return true
+	stored := sess.values.Len() > 0
+	sess.mu.Unlock()
+
+	// the extensions are persisted, see `Config#MaxExtensions`.
+	if stored && len(p.databases) > 0 {
+		syncDatabases(p.databases, acquireSyncPayload(sess, ActionUpdate))
+	}
+	return expires, true
+}
+
+// extension limits the "d" extension of the session's lifetime by the `Config#MaxExtensions`,
+// the `Config#MaxExtendedLifetime` and the `Config#MaxAbsoluteLifetime`,
+// it returns false if the session can't be extended. It's called with the session locked.
+func (p *provider) extension(sess *Session, d time.Duration) (time.Duration, bool) {
+	if cfg := p.config; cfg != nil {
+		if cfg.MaxExtensions > 0 && sess.extensions >= cfg.MaxExtensions {
+			return 0, false
+		}
+
+		if cfg.MaxExtendedLifetime > 0 {
+			remaining := sess.createdAt.Add(cfg.MaxExtendedLifetime).Sub(p.now())
+			if remaining <= 0 {
+				return 0, false
+			}
+
+			if d > remaining {
+				d = remaining
+			}
+		}
+	}
+
+	return p.limit(sess, d)
+}
+
+// touch extends the lifetime of the session by the `Config#IdleTimeout`,
+// up to its absolute expiration, the `Config#Expires`.
+// The new lifetime is synced to the session databases, so the session is not expired
+// at its previous deadline after a restart or by another app instance, see `Config#Shared`.
+func (p *provider) touch(sess *Session) {
+	cfg := p.config
+	if cfg == nil || cfg.IdleTimeout <= 0 {
+		return
+	}
+
+	sess.mu.Lock()
+	d := cfg.IdleTimeout
+	if cfg.Expires > 0 {
+		remaining := sess.createdAt.Add(cfg.Expires).Sub(p.now())
+		if remaining <= 0 {
+			sess.mu.Unlock()
+			return
+		}
+
+		if d > remaining {
+			d = remaining
+		}
+	}
+
+	d, ok := p.extension(sess, d)
+	if !ok {
+		sess.mu.Unlock()
+		return
+	}
+
+	sess.extensions++
+	sess.lifetime.Shift(d)
+	// a session without values is not stored.
+	stored := sess.values.Len() > 0
+	sess.mu.Unlock()
+
+	if stored && len(p.databases) > 0 {
+		syncDatabases(p.databases, acquireSyncPayload(sess, ActionUpdate))
+	}
+}
+
+// refresh reloads the values and the lifetime of the session from the databases
+// if they are shared with other services, see `Config#Shared`.
+func (p *provider) refresh(sess *Session) {
+	if cfg := p.config; cfg == nil || !cfg.Shared || len(p.databases) == 0 {
+		return
+	}
+
+	// if nothing is loaded then the session was destroyed by another service.
+	stored := p.loadSessionFromDB(sess.ID())
+
+	sess.mu.Lock()
+	sess.values = stored.Values
+	atomic.StoreUint64(&sess.version, stored.Version)
+	if !stored.Lifetime.IsZero() {
+		// the other service may have extended it.
+		sess.lifetime.Shift(stored.Lifetime.Sub(p.now()))
+	}
+	if stored.Extensions > sess.extensions {
+		sess.extensions = stored.Extensions
+	}
+	sess.mu.Unlock()
 }
 
 // Read returns the store which sid parameter belongs
 func (p *provider) Read(sid string, expires time.Duration) *Session {
-	p.mu.Lock()
-	if sess, found := p.sessions[sid]; found {
+	if sess, found := p.sessions.get(sid); found {
 		sess.runFlashGC() // run the flash messages GC, new request here of existing session
-		p.mu.Unlock()
-
+		atomic.AddUint64(&p.hits, 1)
+		p.refresh(sess)
+		// after the refresh, which restores the stored lifetime.
+		p.touch(sess)
 		return sess
 	}
-	p.mu.Unlock()
 
-	return p.Init(sid, expires) // if not found create new
+	atomic.AddUint64(&p.misses, 1)
+	sess, created := p.init(sid, expires) // if not found create new
+	if created {
+		p.logger().Debugf("sessions: session %s is missing or expired, a new one is created", sid)
+	}
+	return sess
+}
+
+// Lookup returns the session of the "sid" from the memory or the databases,
+// unlike the `Read` it returns false, and no session is created, if the session doesn't exist.
+func (p *provider) Lookup(sid string, expires time.Duration) (*Session, bool) {
+	if _, found := p.sessions.get(sid); !found {
+		if stored := p.loadSessionFromDB(sid); len(stored.Values) == 0 && stored.Lifetime.IsZero() {
+			return nil, false
+		}
+	}
+
+	return p.Read(sid, expires), true
+}
+
+// Find returns the session of the "sid" from the memory or, by a direct load, from the databases,
+// unlike the `Lookup` the stored session is not loaded to the memory, its changes are synced to the databases.
+// It returns false if the session doesn't exist.
+func (p *provider) Find(sid string) (*Session, bool) {
+	if sess, found := p.sessions.get(sid); found {
+		return sess, true
+	}
+
+	stored := p.loadSessionFromDB(sid)
+	if len(stored.Values) == 0 && stored.Lifetime.IsZero() {
+		return nil, false
+	}
+
+	return p.detached(sid, stored), true
 }
 
 // Destroy destroys the session, removes all sessions and flash values,
@@ -179,10 +479,21 @@ func (p *provider) Read(sid string, expires time.Duration) *Session {
 // this called from sessionManager which removes the client's cookie also.
 func (p *provider) Destroy(sid string) {
 	p.mu.Lock()
-	if sess, found := p.sessions[sid]; found {
-		p.deleteSession(sess)
+	sess, found := p.sessions.get(sid)
+	var ev eviction
+	if found {
+		ev = p.deleteSession(sess, EvictionDestroyed)
+	} else {
+		// the session may be stored to the databases only, i.e revoked through the `Sessions#Visit`.
+		syncDatabases(p.databases, acquireSyncPayload(&Session{sessionState: &sessionState{sid: sid}, requestState: new(requestState)}, ActionDestroy))
 	}
 	p.mu.Unlock()
+
+	if found {
+		p.hooks.fireDestroy(sid)
+		p.hooks.fireEvict(ev)
+	}
+	p.broadcast(sid)
 }
 
 // DestroyAll removes all sessions
@@ -190,13 +501,254 @@ func (p *provider) Destroy(sid string) {
 // Client's session cookie will still exist but it will be reseted on the next request.
 func (p *provider) DestroyAll() {
 	p.mu.Lock()
-	for _, sess := range p.sessions {
-		p.deleteSession(sess)
+	sessions := p.sessions.snapshot()
+	sids := make([]string, 0, len(sessions))
+	evictions := make([]eviction, 0, len(sessions))
+	for sid, sess := range sessions {
+		evictions = append(evictions, p.deleteSession(sess, EvictionDestroyed))
+		sids = append(sids, sid)
+	}
+	p.mu.Unlock()
+
+	p.hooks.fireDestroy(sids...)
+	p.hooks.fireEvict(evictions...)
+	p.broadcast(sids...)
+}
+
+// Visit calls the "visitor" for each session of the memory and then for each session
+// of the databases that implement the `Scanner` which is not loaded to the memory yet,
+// the visitor can return false to stop the iteration, see `VisitWith`.
+func (p *provider) Visit(visitor func(sid string, sess *Session) bool) {
+	p.VisitWith(VisitOptions{}, visitor)
+}
+
+// VisitWith same as `Visit` but only the sessions which pass the "opts" filter and are inside
+// the "opts" offset and limit are visited, the "opts.Prefix" is matched against the session id
+// and the "opts.Predicate" receives the session id and the *Session.
+// The stored sessions are streamed from the databases, the scan stops when the visitor returns false.
+func (p *provider) VisitWith(opts VisitOptions, visitor func(sid string, sess *Session) bool) {
+	sessions := p.sessions.snapshot()
+	p.mu.Lock()
+	databases := p.databases
+	p.mu.Unlock()
+
+	skipped, visited := 0, 0
+	// visit reports whether the iteration should continue.
+	visit := func(sid string, sess *Session) bool {
+		if opts.Limit > 0 && visited >= opts.Limit {
+			return false
+		}
+
+		if opts.Prefix != "" && !strings.HasPrefix(sid, opts.Prefix) {
+			return true
+		}
+
+		if opts.Predicate != nil && !opts.Predicate(sid, sess) {
+			return true
+		}
+
+		if skipped < opts.Offset {
+			skipped++
+			return true
+		}
+
+		visited++
+		return visitor(sid, sess) && (opts.Limit <= 0 || visited < opts.Limit)
+	}
+
+	for sid, sess := range sessions {
+		if !visit(sid, sess) {
+			return
+		}
+	}
+
+	var scanners []Scanner
+	for _, db := range databases {
+		if scanner, ok := db.(Scanner); ok {
+			scanners = append(scanners, scanner)
+		}
+	}
+
+	// the session ids of the previous scanners, a session may be stored to many databases.
+	var scanned map[string]struct{}
+	if len(scanners) > 1 {
+		scanned = make(map[string]struct{})
+	}
+
+	for i, scanner := range scanners {
+		stopped := false
+		last := i == len(scanners)-1
+		scanner.Scan(func(sid string, store RemoteStore) bool {
+			if _, ok := sessions[sid]; ok || store.Lifetime.HasExpired() {
+				return true
+			}
+
+			if scanned != nil {
+				if _, ok := scanned[sid]; ok {
+					return true
+				}
+				if !last {
+					scanned[sid] = struct{}{}
+				}
+			}
+
+			stopped = !visit(sid, p.detached(sid, store))
+			return !stopped
+		})
+
+		if stopped {
+			return
+		}
+	}
+}
+
+// detached returns a session of the "store" which is not loaded to the memory,
+// its changes are synced to the databases.
+func (p *provider) detached(sid string, store RemoteStore) *Session {
+	return &Session{sessionState: &sessionState{
+		sid:       sid,
+		provider:  p,
+		values:    store.Values,
+		lifetime:  LifeTime{Time: store.Lifetime.Time, clock: p.clock()},
+		createdAt: store.CreatedAt,
+		flashes:   make(map[string]*flashMessage),
+		writer:    make(chan struct{}, 1),
+	}, requestState: new(requestState)}
+}
+
+// Bind adds the session to the "claim"'s sessions, see `DestroyByClaim`.
+func (p *provider) Bind(sid string, claim string) {
+	p.mu.Lock()
+	if _, found := p.sessions.get(sid); found {
+		p.index.add(claim, sid)
+	}
+	p.mu.Unlock()
+}
+
+// DestroyByClaim destroys the sessions which are bound to the "claim",
+// returns the number of the destroyed sessions.
+func (p *provider) DestroyByClaim(claim string) int {
+	var (
+		sids      []string
+		evictions []eviction
+	)
+	p.mu.Lock()
+	for _, sid := range p.index.get(claim) {
+		if sess, found := p.sessions.get(sid); found {
+			evictions = append(evictions, p.deleteSession(sess, EvictionRevoked))
+			sids = append(sids, sid)
+		}
+	}
+	p.mu.Unlock()
+
+	p.hooks.fireDestroy(sids...)
+	p.hooks.fireEvict(evictions...)
+	p.broadcast(sids...)
+	return len(sids)
+}
+
+// Regenerate moves the session to the "newSid", the old session id is removed
+// from the memory and the registered databases and the session's data are saved under the new one.
+func (p *provider) Regenerate(sess *Session, newSid string) {
+	p.mu.Lock()
+	oldSid := sess.sid
+	// the registered session is kept, the "sess" may be the handle of a request.
+	found, ok := p.sessions.get(oldSid)
+	if !ok || found.sessionState != sess.sessionState {
+		// i.e a session of the `CookieStore`, it's not stored to the server.
+		sess.mu.Lock()
+		sess.sid = newSid
+		sess.mu.Unlock()
+		p.mu.Unlock()
+		return
 	}
+
+	p.sessions.delete(oldSid)
+	syncDatabases(p.databases, acquireSyncPayload(sess, ActionDestroy))
+
+	sess.mu.Lock()
+	sess.sid = newSid
+	sess.mu.Unlock()
+
+	p.sessions.set(newSid, found)
+	p.index.rename(oldSid, newSid)
 	p.mu.Unlock()
+
+	syncDatabases(p.databases, acquireSyncPayload(sess, ActionCreate))
+
+	p.hooks.fireDestroy(oldSid)
+	p.hooks.fireCreate(newSid)
+	p.broadcast(oldSid)
 }
 
-func (p *provider) deleteSession(sess *Session) {
-	delete(p.sessions, sess.sid)
+// deleteSession removes the session from the memory and the databases,
+// it returns the eviction which should be fired after the lock is released.
+func (p *provider) deleteSession(sess *Session, reason EvictionReason) eviction {
+	ev := eviction{sid: sess.sid, reason: reason}
+	if p.hooks.hasEvict() {
+		sess.mu.RLock()
+		ev.snapshot = append(Store(nil), sess.values...)
+		sess.mu.RUnlock()
+	}
+
+	p.sessions.delete(sess.sid)
+	p.index.remove(sess.sid)
 	syncDatabases(p.databases, acquireSyncPayload(sess, ActionDestroy))
+	sess.end()
+	return ev
+}
+
+// Close stops the expiration timers of the sessions, flushes the modified sessions to the registered databases
+// and closes them, by their `Shutdowner` or their io.Closer, in the order of their registration,
+// it returns the context's error if the deadline passed before all databases closed
+// or a `CloseError` if more than one failed to close.
+// The databases are closed once, the next calls wait for the first one to complete.
+func (p *provider) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		p.closed = make(chan struct{})
+
+		sessions := p.sessions.snapshot()
+		for _, sess := range sessions {
+			sess.lifetime.stop()
+		}
+
+		p.mu.Lock()
+		databases := p.databases
+		unsubscribe := p.unsubscribe
+		p.unsubscribe = nil
+		p.mu.Unlock()
+
+		for _, fn := range unsubscribe {
+			fn()
+		}
+
+		go func() {
+			defer close(p.closed)
+
+			if len(databases) > 0 {
+				for _, sess := range sessions {
+					if ctx.Err() != nil {
+						break
+					}
+					sess.flush(databases)
+				}
+			}
+
+			var errs []error
+			for _, db := range databases {
+				if err := CloseDatabase(ctx, db); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			p.closeErr = joinCloseErrors(errs)
+		}()
+	})
+
+	select {
+	case <-p.closed:
+		return p.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,313 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests of this file exercise the manager from many goroutines at once,
+// they are meant to run with the race detector:
+// go test -race -run Concurrent .
+
+const (
+	concurrentWorkers    = 16
+	concurrentIterations = 200
+)
+
+// concurrentDatabase is a session database which reads the whole store
+// on each sync, as the real databases do when they serialize it.
+type concurrentDatabase struct {
+	mu     sync.Mutex
+	stores map[string]RemoteStore
+}
+
+func newConcurrentDatabase() *concurrentDatabase {
+	return &concurrentDatabase{stores: make(map[string]RemoteStore)}
+}
+
+func (db *concurrentDatabase) Load(sid string) RemoteStore {
+	db.mu.Lock()
+	store := db.stores[sid]
+	db.mu.Unlock()
+	return store
+}
+
+func (db *concurrentDatabase) Sync(p SyncPayload) {
+	if p.Action == ActionDestroy {
+		db.mu.Lock()
+		delete(db.stores, p.SessionID)
+		db.mu.Unlock()
+		return
+	}
+
+	data, err := p.Store.Serialize()
+	if err != nil {
+		return
+	}
+
+	store, err := DecodeRemoteStore(data)
+	if err != nil {
+		return
+	}
+
+	db.mu.Lock()
+	db.stores[p.SessionID] = store
+	db.mu.Unlock()
+}
+
+func (db *concurrentDatabase) Scan(visitor func(sid string, store RemoteStore) bool) {
+	db.mu.Lock()
+	stores := make(map[string]RemoteStore, len(db.stores))
+	for sid, store := range db.stores {
+		stores[sid] = store
+	}
+	db.mu.Unlock()
+
+	for sid, store := range stores {
+		if !visitor(sid, store) {
+			return
+		}
+	}
+}
+
+// runConcurrently calls the "fn" from "concurrentWorkers" goroutines, "concurrentIterations" times each.
+func runConcurrently(fn func(worker, i int)) {
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrentWorkers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < concurrentIterations; i++ {
+				fn(worker, i)
+			}
+		}(worker)
+	}
+	wg.Wait()
+}
+
+func testConcurrentSessions(t *testing.T, manager *Sessions) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	manager.Start(w, r).Release()
+	shared := w.Result().Cookies()[0]
+
+	manager.OnUpdate(func(sid string, action Action, key string) {})
+
+	runConcurrently(func(worker, i int) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if worker%4 != 0 {
+			// most of the workers share the same session.
+			r.AddCookie(shared)
+		}
+		w := httptest.NewRecorder()
+
+		sess := manager.Start(w, r)
+		defer sess.Release()
+
+		key := fmt.Sprintf("key%d", i%8)
+		sess.Set(key, i)
+		sess.Get(key)
+		sess.GetString("name")
+		sess.GetAll()
+		sess.VisitAll(func(k string, v interface{}) {})
+		sess.SetFlash("flash", i)
+		sess.GetFlash("flash")
+		sess.ID()
+
+		switch i % 50 {
+		case 10:
+			sess.Delete(key)
+		case 20:
+			manager.ShiftExpiration(w, r)
+		case 30:
+			manager.RegenerateID(w, r)
+		case 40:
+			manager.Destroy(w, r)
+		case 49:
+			sess.Clear()
+		}
+	})
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	testConcurrentSessions(t, New(Config{Expires: time.Hour}))
+}
+
+func TestConcurrentSessionsDatabase(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(newConcurrentDatabase())
+	testConcurrentSessions(t, manager)
+}
+
+func TestConcurrentSessionsSingleWriter(t *testing.T) {
+	manager := New(Config{Expires: time.Hour, SingleWriter: true, PrivilegeKeys: []string{"key3"}})
+	manager.UseDatabase(newConcurrentDatabase())
+	testConcurrentSessions(t, manager)
+}
+
+func TestConcurrentSingleWriterCounter(t *testing.T) {
+	manager := New(Config{Expires: time.Hour, SingleWriter: true})
+	w := httptest.NewRecorder()
+	manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil)).Release()
+	shared := findCookie(w, DefaultCookieName)
+
+	runConcurrently(func(worker, i int) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(shared)
+		sess := manager.Start(httptest.NewRecorder(), r)
+		// an unguarded read-modify-write, the requests are serialized.
+		n, err := sess.GetInt("counter")
+		if err != nil {
+			n = 0
+		}
+		sess.Set("counter", n+1)
+		sess.Release()
+		sess.Release()
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(shared)
+	sess := manager.Start(httptest.NewRecorder(), r)
+	defer sess.Release()
+	if expected, got := concurrentWorkers*concurrentIterations, sess.Get("counter"); got != expected {
+		t.Fatalf("expected %d serialized increments but got %v", expected, got)
+	}
+}
+
+// startShared starts the session of the "cookie" with the "ctx" of its request.
+func startShared(ctx context.Context, manager *Sessions, cookie *http.Cookie) *Session {
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	r.AddCookie(cookie)
+	return manager.Start(httptest.NewRecorder(), r)
+}
+
+func TestSingleWriterRelease(t *testing.T) {
+	manager := New(Config{Expires: time.Hour, SingleWriter: true, SingleWriterTimeout: 20 * time.Millisecond})
+	w := httptest.NewRecorder()
+	first := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	shared := findCookie(w, DefaultCookieName)
+
+	busy := startShared(context.Background(), manager, shared)
+	if !busy.ReadOnly() {
+		t.Fatalf("expected the session of the request which couldn't hold it to be read-only")
+	}
+	if err := busy.SetE("name", "makis"); err != ErrReadOnly {
+		t.Fatalf("expected %v but got %v", ErrReadOnly, err)
+	}
+
+	// the request which doesn't hold the session can't release the holder's one.
+	busy.Release()
+	if again := startShared(context.Background(), manager, shared); !again.ReadOnly() {
+		t.Fatalf("expected the session to be still held by the first request")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if canceled := startShared(ctx, manager, shared); !canceled.ReadOnly() {
+		t.Fatalf("expected the request of a done context to not wait for the session")
+	}
+
+	first.Release()
+	second := startShared(context.Background(), manager, shared)
+	if second.ReadOnly() {
+		t.Fatalf("expected the released session to be held by the next request")
+	}
+
+	// a second release of the first request doesn't release the second one's hold.
+	first.Release()
+	if third := startShared(context.Background(), manager, shared); !third.ReadOnly() {
+		t.Fatalf("expected the session to be still held by the second request")
+	}
+
+	second.Release()
+}
+
+func TestDistributedSingleWriter(t *testing.T) {
+	db := &lockerDatabase{concurrentDatabase: newConcurrentDatabase(), locks: make(map[string]string)}
+	cfg := Config{Expires: time.Hour, DistributedSingleWriter: true, SingleWriterTimeout: 100 * time.Millisecond}
+	node1, node2 := New(cfg), New(cfg)
+	node1.UseDatabase(db)
+	node2.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	first := node1.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	first.Set("items", 1)
+	shared := findCookie(w, DefaultCookieName)
+
+	if busy := startShared(context.Background(), node2, shared); !busy.ReadOnly() {
+		t.Fatalf("expected the session to be held by the other app instance")
+	}
+
+	first.Set("items", 2)
+	first.Release()
+
+	second := startShared(context.Background(), node2, shared)
+	defer second.Release()
+	if second.ReadOnly() {
+		t.Fatalf("expected the released session to be held by the other app instance")
+	}
+	if expected, got := 2, second.Get("items"); got != expected {
+		t.Fatalf("expected the session to be reloaded after the lock, %d but got %v", expected, got)
+	}
+}
+
+func TestConcurrentDestroyAll(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(newConcurrentDatabase())
+
+	runConcurrently(func(worker, i int) {
+		if worker == 0 && i%20 == 0 {
+			manager.DestroyAll()
+			return
+		}
+
+		sid := fmt.Sprintf("sid%d", i%10)
+		sess := manager.provider.Read(sid, time.Hour)
+		sess.Set("worker", worker)
+		sess.BindLogin("subject", "")
+		if i%25 == 0 {
+			manager.DestroyByLogin("subject", "")
+		}
+	})
+}
+
+func TestConcurrentCookieStore(t *testing.T) {
+	store, err := NewCookieStore(Config{Expires: time.Hour}, [][]byte{[]byte("01234567890123456789012345678901")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	if err = store.Save(w, r, store.Start(w, r)); err != nil {
+		t.Fatal(err)
+	}
+	shared := w.Result().Cookies()[0]
+
+	sess := store.Start(w, r)
+	runConcurrently(func(worker, i int) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(shared)
+		w := httptest.NewRecorder()
+
+		if worker%2 == 0 {
+			// a session which is shared between goroutines.
+			sess.Set(fmt.Sprintf("key%d", i%8), i)
+			if err := store.Save(w, r, sess); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		s := store.Start(w, r)
+		s.Set("name", "go-sessions")
+		if err := store.Save(w, r, s); err != nil {
+			t.Error(err)
+		}
+	})
+}
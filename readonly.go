@@ -0,0 +1,39 @@
+package sessions
+
+import (
+	"errors"
+)
+
+// ErrReadOnly returned by the writes of a read-only session, i.e the `SetE`, see `Session#SetReadOnly`.
+var ErrReadOnly = errors.New("sessions: the session is read-only")
+
+// SetReadOnly marks the request's session as read-only until it's released,
+// the sessions of the other requests of the same session id are not affected, its writes are rejected, the ones which return an error return the `ErrReadOnly`,
+// so the session databases are never synced, i.e for the GET-heavy APIs.
+// The manager marks the sessions of the requests which the `Config#ReadOnly` reports.
+//
+// The flash messages and the CSRF tokens are not affected.
+func (s *Session) SetReadOnly() {
+	s.mu.Lock()
+	s.readOnly = true
+	s.mu.Unlock()
+}
+
+// ReadOnly reports whether the session is read-only, see `SetReadOnly`.
+func (s *Session) ReadOnly() bool {
+	s.mu.RLock()
+	readOnly := s.readOnly
+	s.mu.RUnlock()
+	return readOnly
+}
+
+// rejectWrite reports whether a write of the "key" is rejected because the session is read-only,
+// the rejection is logged, see `Config#Logger`. It's called with the session locked.
+func (s *Session) rejectWrite(key string) bool {
+	if !s.readOnly {
+		return false
+	}
+
+	s.provider.logger().Warnf("sessions: write of the key %q to the read-only session %s", key, s.sid)
+	return true
+}
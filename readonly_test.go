@@ -0,0 +1,88 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	manager := New(Config{ReadOnly: func(r *http.Request) bool { return r.Method == http.MethodGet }})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	sess.Set("name", "kataras")
+	sess.Release()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	db.mu.Lock()
+	before := db.stores[sess.ID()].Version
+	db.mu.Unlock()
+
+	sess = manager.Start(httptest.NewRecorder(), r)
+	if !sess.ReadOnly() {
+		t.Fatalf("expected the session of the GET request to be read-only")
+	}
+
+	sess.Set("name", "other")
+	sess.Append("list", 1)
+	if err := sess.SetE("name", "other"); err != ErrReadOnly {
+		t.Fatalf("expected %v but got %v", ErrReadOnly, err)
+	}
+	if _, err := sess.Increment("hits", 1); err != ErrReadOnly {
+		t.Fatalf("expected %v but got %v", ErrReadOnly, err)
+	}
+	if sess.Delete("name") {
+		t.Fatalf("expected the delete to be rejected")
+	}
+	sess.Clear()
+
+	if got := sess.GetString("name"); got != "kataras" || sess.Exists("list") {
+		t.Fatalf("expected the values to be kept but got %v", sess.GetAll())
+	}
+
+	db.mu.Lock()
+	after := db.stores[sess.ID()].Version
+	db.mu.Unlock()
+	if after != before {
+		t.Fatalf("expected the databases to not be synced")
+	}
+
+	sess.Release()
+	if sess.ReadOnly() {
+		t.Fatalf("expected the read-only mode to end on release")
+	}
+}
+
+func TestReadOnlyPerRequest(t *testing.T) {
+	manager := New(Config{ReadOnly: func(r *http.Request) bool { return r.Method == http.MethodGet }})
+
+	w := httptest.NewRecorder()
+	writer := manager.Start(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	reader := manager.Start(httptest.NewRecorder(), r)
+
+	if !reader.ReadOnly() || writer.ReadOnly() {
+		t.Fatalf("expected only the session of the GET request to be read-only")
+	}
+
+	if err := writer.SetE("name", "kataras"); err != nil {
+		t.Fatalf("expected the write of the concurrent request to be accepted but got %v", err)
+	}
+	if got := reader.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the requests to share the session's values but got %q", got)
+	}
+
+	reader.Release()
+	if err := reader.SetE("name", "makis"); err != nil {
+		t.Fatalf("expected the released session to be writable but got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegenerateID(t *testing.T) {
+	manager := New(Config{})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	sess.Set("name", "go-sessions")
+	oldSid := sess.ID()
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	w = httptest.NewRecorder()
+	regenerated := manager.RegenerateID(w, r)
+
+	if regenerated.ID() != sess.ID() || sess.ID() == oldSid {
+		t.Fatalf("expected the session to be moved to a new session id")
+	}
+
+	if cookie := w.Result().Cookies()[0]; cookie.Value != sess.ID() {
+		t.Fatalf("expected the cookie to be updated to %s but got %s", sess.ID(), cookie.Value)
+	}
+
+	if _, found := manager.provider.sessions.get(oldSid); found {
+		t.Fatalf("expected the old session id to be removed")
+	}
+
+	current, _ := manager.provider.sessions.get(sess.ID())
+	if expected, v := "go-sessions", current.GetString("name"); v != expected {
+		t.Fatalf("expected the session's data to be kept but got %q", v)
+	}
+}
+
+func TestPrivilegeKeys(t *testing.T) {
+	for _, singleWriter := range []bool{true, false} {
+		testPrivilegeKeys(t, New(Config{SingleWriter: singleWriter, PrivilegeKeys: []string{"user"}}))
+	}
+}
+
+func testPrivilegeKeys(t *testing.T, manager *Sessions) {
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	oldSid := sess.ID()
+
+	sess.Set("name", "go-sessions")
+	if sess.ID() != oldSid {
+		t.Fatalf("expected the session id to be kept on a non-privilege key change")
+	}
+
+	sess.Set("user", "kataras")
+	sess.Release()
+
+	if sess.ID() == oldSid {
+		t.Fatalf("expected the session id to be regenerated on a privilege key change")
+	}
+
+	cookies := w.Result().Cookies()
+	if last := cookies[len(cookies)-1]; last.Value != sess.ID() {
+		t.Fatalf("expected the cookie to be updated to %s but got %s", sess.ID(), last.Value)
+	}
+}
+
+func TestPrivilegeKeysPerRequest(t *testing.T) {
+	manager := New(Config{PrivilegeKeys: []string{"user"}})
+
+	w1 := httptest.NewRecorder()
+	sess1 := manager.Start(w1, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookie := findCookie(w1, DefaultCookieName)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	w2 := httptest.NewRecorder()
+	sess2 := manager.Start(w2, r)
+
+	sess2.Set("user", "kataras")
+	if sess1.ID() == cookie.Value {
+		t.Fatalf("expected the session id to be regenerated")
+	}
+
+	if updated := findCookie(w2, DefaultCookieName); updated == nil || updated.Value != sess2.ID() {
+		t.Fatalf("expected the cookie of the request which changed the privileges to be updated")
+	}
+	if n := len(w1.Result().Cookies()); n != 1 {
+		t.Fatalf("expected the cookie of the other request to be kept but got %d cookies", n)
+	}
+}
@@ -0,0 +1,106 @@
+package sessions
+
+import (
+	"runtime"
+	"sync"
+)
+
+// sessionShard is a part of the in-memory sessions, with its own lock.
+type sessionShard struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// sessionRegistry is the in-memory sessions of the provider, keyed by their ids,
+// the ids are sharded across several locks so the concurrent requests of different sessions
+// don't wait for a single one, see `Config#Shards`.
+//
+// The provider's lock is still held by the operations which remove or move sessions,
+// i.e the `Destroy` and the `Regenerate`, the lock order is provider, shard and then session.
+type sessionRegistry struct {
+	shards []sessionShard
+}
+
+// defaultShards returns the default number of the shards, a multiple of the usable CPUs.
+func defaultShards() int {
+	return runtime.GOMAXPROCS(0) * 4
+}
+
+func newSessionRegistry(n int) *sessionRegistry {
+	if n <= 0 {
+		n = defaultShards()
+	}
+
+	r := &sessionRegistry{shards: make([]sessionShard, n)}
+	for i := range r.shards {
+		r.shards[i].sessions = make(map[string]*Session)
+	}
+	return r
+}
+
+// shard returns the shard of the "sid", by its FNV-1a hash.
+func (r *sessionRegistry) shard(sid string) *sessionShard {
+	if len(r.shards) == 1 {
+		return &r.shards[0]
+	}
+
+	return &r.shards[fnv32(sid)%uint32(len(r.shards))]
+}
+
+// fnv32 returns the FNV-1a hash of the "s".
+func fnv32(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+func (r *sessionRegistry) get(sid string) (*Session, bool) {
+	shard := r.shard(sid)
+	shard.mu.RLock()
+	sess, found := shard.sessions[sid]
+	shard.mu.RUnlock()
+	return sess, found
+}
+
+func (r *sessionRegistry) set(sid string, sess *Session) {
+	shard := r.shard(sid)
+	shard.mu.Lock()
+	shard.sessions[sid] = sess
+	shard.mu.Unlock()
+}
+
+func (r *sessionRegistry) delete(sid string) {
+	shard := r.shard(sid)
+	shard.mu.Lock()
+	delete(shard.sessions, sid)
+	shard.mu.Unlock()
+}
+
+// len returns the number of the sessions.
+func (r *sessionRegistry) len() int {
+	n := 0
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.RLock()
+		n += len(shard.sessions)
+		shard.mu.RUnlock()
+	}
+	return n
+}
+
+// snapshot returns a copy of the sessions, so they can be visited without the shards' locks.
+func (r *sessionRegistry) snapshot() map[string]*Session {
+	sessions := make(map[string]*Session)
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.RLock()
+		for sid, sess := range shard.sessions {
+			sessions[sid] = sess
+		}
+		shard.mu.RUnlock()
+	}
+	return sessions
+}
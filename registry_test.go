@@ -0,0 +1,42 @@
+package sessions
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSessionRegistry(t *testing.T) {
+	for _, shards := range []int{0, 1, 7} {
+		manager := New(Config{Shards: shards})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					sid := strconv.Itoa(i) + "-" + strconv.Itoa(j)
+					manager.provider.Read(sid, 0).Set("n", j)
+					if j%2 == 0 {
+						manager.DestroyByID(sid)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if active := manager.Stats().Active; active != 8*25 {
+			t.Fatalf("[%d shards] expected %d active sessions but got %d", shards, 8*25, active)
+		}
+
+		visited := 0
+		manager.Visit(func(string, *Session) bool {
+			visited++
+			return true
+		})
+		if visited != 8*25 {
+			t.Fatalf("[%d shards] expected %d visited sessions but got %d", shards, 8*25, visited)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package sessions
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DefaultRememberMeCookieName the remember-me cookie's name, see `Config#RememberMeCookie`.
+	DefaultRememberMeCookieName = "remember_me"
+	// DefaultRememberMeExpires the lifetime of a remember-me series, see `Config#RememberMeExpires`.
+	DefaultRememberMeExpires = 30 * 24 * time.Hour
+	// RememberMeUserKey is the session key which the user id of a restored remember-me login is stored to.
+	RememberMeUserKey = "remember_me_user"
+)
+
+const (
+	// rememberMeGrace is the duration which the previous token of a series is still accepted after its rotation,
+	// so the concurrent requests of a browser which carry the same cookie are not reported as a theft.
+	rememberMeGrace = 10 * time.Second
+	// rememberMeLocks is the number of the locks of the remember-me series, see `Sessions#recall`.
+	rememberMeLocks = 64
+)
+
+// RememberMeToken is a persistent login of a user, stored by the `RememberMeStore`.
+//
+// The client's cookie holds the series and the current token, the store keeps only the token's hash.
+// The token is replaced on each use, the series is kept for the whole lifetime of the login.
+type RememberMeToken struct {
+	Series string
+	UserID string
+	// Hash is the SHA-256 of the current token.
+	Hash []byte
+	// PreviousHash is the SHA-256 of the token before the last rotation.
+	PreviousHash []byte
+	// RotatedAt is the time of the last rotation.
+	RotatedAt time.Time
+	// Expires is the expiration time of the series, it's not extended by the rotations.
+	Expires time.Time
+}
+
+// RememberMeStore stores the remember-me tokens by their series, see `Config#RememberMe`.
+// The implementations should be safe for concurrent use.
+type RememberMeStore interface {
+	// Load returns the token of the "series" and true, or false if it's missing.
+	Load(series string) (RememberMeToken, bool)
+	// Save stores the token, it replaces the token of the same series.
+	Save(token RememberMeToken)
+	// Delete removes the token of the "series".
+	Delete(series string)
+	// DeleteUser removes all the tokens of the "userID".
+	DeleteUser(userID string)
+}
+
+// RememberMeSwapper is an optional interface of a `RememberMeStore` which replaces the tokens atomically,
+// i.e a store which is shared by many app instances, so the concurrent rotations of a series
+// by different instances don't override each other, see `Config#RememberMe`.
+// The rotations of a single instance are serialized without it.
+type RememberMeSwapper interface {
+	// CompareAndSwap stores the "token" only if the stored token of its series has the "hash",
+	// it reports whether it's stored.
+	CompareAndSwap(token RememberMeToken, hash []byte) bool
+}
+
+type memoryRememberMeStore struct {
+	mu     sync.Mutex
+	tokens map[string]RememberMeToken
+}
+
+// NewMemoryRememberMeStore returns a `RememberMeStore` which keeps the tokens in memory,
+// the logins are lost when the application restarts, a persistent store should be used in production.
+func NewMemoryRememberMeStore() RememberMeStore {
+	return &memoryRememberMeStore{tokens: make(map[string]RememberMeToken)}
+}
+
+func (m *memoryRememberMeStore) Load(series string) (RememberMeToken, bool) {
+	m.mu.Lock()
+	token, ok := m.tokens[series]
+	m.mu.Unlock()
+	return token, ok
+}
+
+func (m *memoryRememberMeStore) Save(token RememberMeToken) {
+	m.mu.Lock()
+	m.tokens[token.Series] = token
+	m.mu.Unlock()
+}
+
+func (m *memoryRememberMeStore) CompareAndSwap(token RememberMeToken, hash []byte) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.tokens[token.Series]; !ok || subtle.ConstantTimeCompare(stored.Hash, hash) != 1 {
+		return false
+	}
+
+	m.tokens[token.Series] = token
+	return true
+}
+
+func (m *memoryRememberMeStore) Delete(series string) {
+	m.mu.Lock()
+	delete(m.tokens, series)
+	m.mu.Unlock()
+}
+
+func (m *memoryRememberMeStore) DeleteUser(userID string) {
+	m.mu.Lock()
+	for series, token := range m.tokens {
+		if token.UserID == userID {
+			delete(m.tokens, series)
+		}
+	}
+	m.mu.Unlock()
+}
+
+// randomToken returns 256 random bits, base64-url encoded.
+func randomToken() string {
+	b := make([]byte, 32)
+	randomBytes(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
+// remember issues a new series for the "userID" and returns the cookie value and its expiration time.
+func (s *Sessions) remember(userID string) (string, time.Time) {
+	store := s.config.RememberMe
+	if store == nil || userID == "" {
+		return "", time.Time{}
+	}
+
+	series, token := randomToken(), randomToken()
+	expires := s.provider.now().Add(s.config.RememberMeExpires)
+	store.Save(RememberMeToken{Series: series, UserID: userID, Hash: hashToken(token), Expires: expires})
+	return series + "." + token, expires
+}
+
+// recall verifies the remember-me "cookieValue" and restores its login to the "sess",
+// which is moved to a new session id if "regenerate" is true.
+// It returns the rotated cookie value and its expiration time, the value is empty if the cookie
+// should be kept as it's, and false if the cookie is invalid and it should be removed.
+func (s *Sessions) recall(ctx context.Context, sess *Session, cookieValue string, regenerate bool) (string, time.Time, bool) {
+	idx := strings.IndexByte(cookieValue, '.')
+	if idx <= 0 {
+		return "", time.Time{}, false
+	}
+	series, token := cookieValue[:idx], cookieValue[idx+1:]
+
+	// the concurrent requests of the series are verified one by one,
+	// the first one rotates the token and the rest find it as the previous one.
+	mu := &s.seriesLocks[fnv32(series)%rememberMeLocks]
+	mu.Lock()
+	value, stored, ok := s.rotate(series, token)
+	mu.Unlock()
+	if !ok {
+		return "", time.Time{}, false
+	}
+
+	if regenerate {
+		// the session id came from the client, don't restore the login to an id which may be planted.
+		sess.regenerateID(ctx)
+	}
+
+	sess.BindUser(stored.UserID)
+	sess.Set(RememberMeUserKey, stored.UserID)
+	return value, stored.Expires, true
+}
+
+// rotate verifies the "token" of the "series" and replaces it with a new one,
+// it returns the new cookie value, empty if the token was just rotated by a concurrent request,
+// and false if the token is invalid.
+func (s *Sessions) rotate(series, token string) (string, RememberMeToken, bool) {
+	store := s.config.RememberMe
+	hash := hashToken(token)
+
+	for {
+		stored, found := store.Load(series)
+		if !found {
+			return "", stored, false
+		}
+
+		now := s.provider.now()
+		if !stored.Expires.After(now) {
+			store.Delete(series)
+			return "", stored, false
+		}
+
+		switch {
+		case subtle.ConstantTimeCompare(hash, stored.Hash) == 1:
+			token = randomToken()
+			rotated := stored
+			rotated.PreviousHash, rotated.Hash, rotated.RotatedAt = stored.Hash, hashToken(token), now
+
+			if swapper, ok := store.(RememberMeSwapper); ok {
+				if !swapper.CompareAndSwap(rotated, stored.Hash) {
+					// rotated by another app instance meanwhile, verify it again.
+					continue
+				}
+			} else {
+				store.Save(rotated)
+			}
+			return series + "." + token, rotated, true
+		case len(stored.PreviousHash) > 0 && now.Sub(stored.RotatedAt) < rememberMeGrace &&
+			subtle.ConstantTimeCompare(hash, stored.PreviousHash) == 1:
+			// a concurrent request of the same browser, the rotated token is sent by the first one.
+			return "", stored, true
+		default:
+			// the series is valid but its token was already used, by the user or by an attacker
+			// who copied the cookie, there is no way to tell which one this is: revoke the user's logins.
+			s.DestroyByUser(stored.UserID)
+			s.provider.hooks.fireRememberMeTheft(stored.UserID)
+			return "", stored, false
+		}
+	}
+}
+
+// restorable reports whether the remember-me login can be restored to the "sess",
+// only the sessions without values are restored.
+func (s *Sessions) restorable(sess *Session) bool {
+	if s.config.RememberMe == nil {
+		return false
+	}
+
+	sess.mu.RLock()
+	empty := sess.values.Len() == 0
+	sess.mu.RUnlock()
+	return empty
+}
+
+// rememberMeCookie returns a new remember-me cookie.
+func (s *Sessions) rememberMeCookie(value string, expires time.Time, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.config.RememberMeCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(expires.Sub(s.provider.now()).Seconds()),
+		HttpOnly: true,
+		Secure:   s.secureCookie(secure) || isSecurePrefixed(s.config.RememberMeCookie),
+		SameSite: s.config.CookieSameSite,
+	}
+}
+
+// restore restores the remember-me login of the request to the "sess" and reports whether it's restored.
+func (s *Sessions) restore(w http.ResponseWriter, r *http.Request, sess *Session, regenerate bool) bool {
+	if !s.restorable(sess) {
+		return false
+	}
+
+	cookieValue := GetCookie(r, s.config.RememberMeCookie)
+	if cookieValue == "" {
+		return false
+	}
+
+	value, expires, ok := s.recall(r.Context(), sess, cookieValue, regenerate)
+	if !ok {
+		RemoveCookie(w, r, s.config.RememberMeCookie)
+		return false
+	}
+
+	if value != "" {
+		s.addCookie(w, s.rememberMeCookie(value, expires, r.TLS != nil))
+	}
+	return true
+}
+
+// restoreFasthttp restores the remember-me login of the request to the "sess" and reports whether it's restored.
+func (s *Sessions) restoreFasthttp(ctx *fasthttp.RequestCtx, sess *Session, regenerate bool) bool {
+	if !s.restorable(sess) {
+		return false
+	}
+
+	cookieValue := GetCookieFasthttp(ctx, s.config.RememberMeCookie)
+	if cookieValue == "" {
+		return false
+	}
+
+	value, expires, ok := s.recall(ctx, sess, cookieValue, regenerate)
+	if !ok {
+		RemoveCookieFasthttp(ctx, s.config.RememberMeCookie)
+		return false
+	}
+
+	if value != "" {
+		s.addRememberMeCookieFasthttp(ctx, value, expires)
+	}
+	return true
+}
+
+func (s *Sessions) addRememberMeCookieFasthttp(ctx *fasthttp.RequestCtx, value string, expires time.Time) {
+	c := s.rememberMeCookie(value, expires, ctx.IsTLS())
+
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey(c.Name)
+	cookie.SetValue(c.Value)
+	cookie.SetPath(c.Path)
+	cookie.SetExpire(c.Expires)
+	cookie.SetHTTPOnly(c.HttpOnly)
+	cookie.SetSecure(c.Secure)
+	cookie.SetSameSite(sameSiteFasthttp(c.SameSite))
+	cookie.SetPartitioned(s.config.CookiePartitioned)
+	AddCookieFasthttp(ctx, cookie)
+	fasthttp.ReleaseCookie(cookie)
+}
+
+// Remember issues a remember-me login for the "userID", see `Sessions#Remember`.
+func Remember(w http.ResponseWriter, r *http.Request, userID string) {
+	Default.Remember(w, r, userID)
+}
+
+// Remember issues a remember-me login for the "userID" and sends its cookie to the client,
+// it should be called after a successful login when the user asked to stay logged in.
+// When the session of the client is lost, i.e after a browser restart or its expiration,
+// the `Start` restores the login to the new session: the session is bound to the user, see `Session#BindUser`,
+// and the user id is stored to the `RememberMeUserKey`.
+//
+// The token of the cookie is replaced on each restore, a reuse of an old token means that the cookie was stolen,
+// all the sessions and the remember-me logins of the user are revoked and the `OnRememberMeTheft` callbacks are fired.
+//
+// It does nothing if the `Config#RememberMe` is nil.
+func (s *Sessions) Remember(w http.ResponseWriter, r *http.Request, userID string) {
+	if value, expires := s.remember(userID); value != "" {
+		s.addCookie(w, s.rememberMeCookie(value, expires, r.TLS != nil))
+	}
+}
+
+// RememberFasthttp issues a remember-me login for the "userID", see `Sessions#Remember`.
+func RememberFasthttp(ctx *fasthttp.RequestCtx, userID string) {
+	Default.RememberFasthttp(ctx, userID)
+}
+
+// RememberFasthttp issues a remember-me login for the "userID", see `Sessions#Remember`.
+func (s *Sessions) RememberFasthttp(ctx *fasthttp.RequestCtx, userID string) {
+	if value, expires := s.remember(userID); value != "" {
+		s.addRememberMeCookieFasthttp(ctx, value, expires)
+	}
+}
+
+// forget removes the series of the remember-me "cookieValue".
+func (s *Sessions) forget(cookieValue string) {
+	if store := s.config.RememberMe; store != nil {
+		if idx := strings.IndexByte(cookieValue, '.'); idx > 0 {
+			store.Delete(cookieValue[:idx])
+		}
+	}
+}
+
+// Forget removes the remember-me login of the request and its cookie,
+// it's called by the `Destroy` too.
+func Forget(w http.ResponseWriter, r *http.Request) {
+	Default.Forget(w, r)
+}
+
+// Forget removes the remember-me login of the request and its cookie,
+// it's called by the `Destroy` too.
+func (s *Sessions) Forget(w http.ResponseWriter, r *http.Request) {
+	if s.config.RememberMe == nil {
+		return
+	}
+
+	s.forget(GetCookie(r, s.config.RememberMeCookie))
+	RemoveCookie(w, r, s.config.RememberMeCookie)
+}
+
+// ForgetFasthttp removes the remember-me login of the request and its cookie,
+// it's called by the `DestroyFasthttp` too.
+func ForgetFasthttp(ctx *fasthttp.RequestCtx) {
+	Default.ForgetFasthttp(ctx)
+}
+
+// ForgetFasthttp removes the remember-me login of the request and its cookie,
+// it's called by the `DestroyFasthttp` too.
+func (s *Sessions) ForgetFasthttp(ctx *fasthttp.RequestCtx) {
+	if s.config.RememberMe == nil {
+		return
+	}
+
+	s.forget(GetCookieFasthttp(ctx, s.config.RememberMeCookie))
+	RemoveCookieFasthttp(ctx, s.config.RememberMeCookie)
+}
@@ -0,0 +1,188 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestRememberMe(t *testing.T) {
+	store := NewMemoryRememberMeStore()
+	manager := New(Config{RememberMe: store})
+
+	var stolen []string
+	manager.OnRememberMeTheft(func(userID string) { stolen = append(stolen, userID) })
+
+	w := httptest.NewRecorder()
+	manager.Remember(w, httptest.NewRequest(http.MethodGet, "/login", nil), "user1")
+	remembered := findCookie(w, DefaultRememberMeCookieName)
+	if remembered == nil {
+		t.Fatal("expected the remember-me cookie")
+	}
+
+	// the browser restarted, only the remember-me cookie is sent.
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(remembered)
+	w = httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	if got := sess.GetString(RememberMeUserKey); got != "user1" {
+		t.Fatalf("expected the login to be restored but got %q", got)
+	}
+
+	rotated := findCookie(w, DefaultRememberMeCookieName)
+	if rotated == nil || rotated.Value == remembered.Value {
+		t.Fatal("expected the remember-me token to be rotated")
+	}
+
+	// a session with values is not restored again.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	r.AddCookie(rotated)
+	w = httptest.NewRecorder()
+	manager.Start(w, r)
+	if findCookie(w, DefaultRememberMeCookieName) != nil {
+		t.Fatal("expected the remember-me cookie to be kept for a live session")
+	}
+
+	// the old token is reused after the grace period: theft.
+	series := remembered.Value[:strings.IndexByte(remembered.Value, '.')]
+	token, _ := store.Load(series)
+	token.RotatedAt = token.RotatedAt.Add(-time.Minute)
+	store.Save(token)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(remembered)
+	w = httptest.NewRecorder()
+	sess = manager.Start(w, r)
+	if got := sess.GetString(RememberMeUserKey); got != "" {
+		t.Fatalf("expected the stolen token to be rejected but the login of %q was restored", got)
+	}
+
+	if len(stolen) != 1 || stolen[0] != "user1" {
+		t.Fatalf("expected the theft to be reported but got %v", stolen)
+	}
+
+	if manager.Count() != 1 {
+		t.Fatalf("expected the restored session of the user to be destroyed but got %d sessions", manager.Count())
+	}
+
+	// the series is revoked, the legit cookie doesn't work either.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(rotated)
+	if got := manager.Start(httptest.NewRecorder(), r).GetString(RememberMeUserKey); got != "" {
+		t.Fatalf("expected the series to be revoked but the login of %q was restored", got)
+	}
+}
+
+func TestForget(t *testing.T) {
+	store := NewMemoryRememberMeStore()
+	manager := New(Config{RememberMe: store})
+
+	w := httptest.NewRecorder()
+	manager.Remember(w, httptest.NewRequest(http.MethodGet, "/login", nil), "user1")
+	remembered := findCookie(w, DefaultRememberMeCookieName)
+
+	r := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	r.AddCookie(remembered)
+	w = httptest.NewRecorder()
+	manager.Destroy(w, r)
+
+	if c := findCookie(w, DefaultRememberMeCookieName); c == nil || c.MaxAge >= 0 {
+		t.Fatal("expected the remember-me cookie to be removed")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(remembered)
+	if got := manager.Start(httptest.NewRecorder(), r).GetString(RememberMeUserKey); got != "" {
+		t.Fatalf("expected the login to be forgotten but the login of %q was restored", got)
+	}
+}
+
+func TestRememberMeConcurrentRotation(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{RememberMe: NewMemoryRememberMeStore(), Clock: clock})
+
+	var stolen int32
+	manager.OnRememberMeTheft(func(string) { atomic.AddInt32(&stolen, 1) })
+
+	w := httptest.NewRecorder()
+	manager.Remember(w, httptest.NewRequest(http.MethodGet, "/login", nil), "user1")
+	remembered := findCookie(w, DefaultRememberMeCookieName)
+	if !remembered.Expires.Equal(clock.Now().Add(DefaultRememberMeExpires).Truncate(time.Second)) {
+		t.Fatalf("expected the expiration of the series by the manager's clock but got %s", remembered.Expires)
+	}
+
+	// the browser restarted and it sends the same cookie by many requests at once.
+	const requests = 8
+	rotated := make(chan *http.Cookie, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(remembered)
+			w := httptest.NewRecorder()
+			if sess := manager.Start(w, r); sess.GetString(RememberMeUserKey) != "user1" {
+				t.Errorf("expected the login to be restored by each request")
+			}
+			if c := findCookie(w, DefaultRememberMeCookieName); c != nil {
+				rotated <- c
+			}
+		}()
+	}
+	wg.Wait()
+	close(rotated)
+
+	if n := atomic.LoadInt32(&stolen); n != 0 {
+		t.Fatalf("expected no theft on the concurrent requests but got %d", n)
+	}
+
+	var cookies []*http.Cookie
+	for c := range rotated {
+		cookies = append(cookies, c)
+	}
+	if len(cookies) != 1 {
+		t.Fatalf("expected the token to be rotated once but got %d rotations", len(cookies))
+	}
+
+	// the next request of the browser carries the rotated token.
+	clock.Advance(time.Minute)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	if sess := manager.Start(httptest.NewRecorder(), r); sess.GetString(RememberMeUserKey) != "user1" || stolen != 0 {
+		t.Fatalf("expected the rotated token to restore the login")
+	}
+}
+
+func TestRememberMeSwapper(t *testing.T) {
+	store := NewMemoryRememberMeStore()
+	swapper, ok := store.(RememberMeSwapper)
+	if !ok {
+		t.Fatal("expected the memory store to be a swapper")
+	}
+
+	store.Save(RememberMeToken{Series: "series", UserID: "user1", Hash: hashToken("first")})
+	if swapper.CompareAndSwap(RememberMeToken{Series: "series", Hash: hashToken("third")}, hashToken("second")) {
+		t.Fatal("expected the token of another hash to not be swapped")
+	}
+	if !swapper.CompareAndSwap(RememberMeToken{Series: "series", Hash: hashToken("second")}, hashToken("first")) {
+		t.Fatal("expected the token of the current hash to be swapped")
+	}
+	if swapper.CompareAndSwap(RememberMeToken{Series: "missing"}, nil) {
+		t.Fatal("expected a missing series to not be swapped")
+	}
+}
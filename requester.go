@@ -0,0 +1,134 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requester is the transport of the request which starts a session, see `Sessions#start`,
+// the net/http, the fasthttp and the context ones differ only by it.
+type requester interface {
+	// send sends the session id to the client, i.e by the session cookie.
+	send(sid string, expires time.Duration)
+	// restore restores the remember-me login of the request to the "sess", see `Config#RememberMeKey`.
+	restore(sess *Session, regenerate bool) bool
+	// client returns the signals of the `Config#RiskScorer`.
+	client() (ip, userAgent string)
+	// identify sets the anonymous id of the request to the "sess", see `Config#AnonymousIDKey`.
+	identify(sess *Session)
+	// readOnly reports whether the request's session is read-only, see `Config#ReadOnly`.
+	readOnly() bool
+	// hydrate returns the values of a new session, see `Config#Hydrator`.
+	hydrate() (Store, bool)
+}
+
+type httpRequester struct {
+	s *Sessions
+	w http.ResponseWriter
+	r *http.Request
+}
+
+var _ requester = (*httpRequester)(nil)
+
+func (req *httpRequester) send(sid string, expires time.Duration) {
+	req.s.updateCookie(req.w, req.r, sid, expires)
+}
+
+func (req *httpRequester) restore(sess *Session, regenerate bool) bool {
+	return req.s.restore(req.w, req.r, sess, regenerate)
+}
+
+func (req *httpRequester) client() (string, string) {
+	return requestIP(req.r), req.r.UserAgent()
+}
+
+func (req *httpRequester) identify(sess *Session) {
+	req.s.identify(req.w, req.r, sess)
+}
+
+func (req *httpRequester) readOnly() bool {
+	return req.s.config.ReadOnly != nil && req.s.config.ReadOnly(req.r)
+}
+
+func (req *httpRequester) hydrate() (Store, bool) {
+	if req.s.config.Hydrator == nil {
+		return nil, false
+	}
+	return req.s.config.Hydrator(req.r), true
+}
+
+type fasthttpRequester struct {
+	s   *Sessions
+	ctx *fasthttp.RequestCtx
+}
+
+var _ requester = (*fasthttpRequester)(nil)
+
+func (req *fasthttpRequester) send(sid string, expires time.Duration) {
+	req.s.updateCookieFasthttp(req.ctx, sid, expires)
+}
+
+func (req *fasthttpRequester) restore(sess *Session, regenerate bool) bool {
+	return req.s.restoreFasthttp(req.ctx, sess, regenerate)
+}
+
+func (req *fasthttpRequester) client() (string, string) {
+	return requestIPFasthttp(req.ctx), string(req.ctx.UserAgent())
+}
+
+func (req *fasthttpRequester) identify(sess *Session) {
+	req.s.identifyFasthttp(req.ctx, sess)
+}
+
+func (req *fasthttpRequester) readOnly() bool {
+	return req.s.config.ReadOnlyFasthttp != nil && req.s.config.ReadOnlyFasthttp(req.ctx)
+}
+
+func (req *fasthttpRequester) hydrate() (Store, bool) {
+	if req.s.config.HydratorFasthttp == nil {
+		return nil, false
+	}
+	return req.s.config.HydratorFasthttp(req.ctx), true
+}
+
+// contextRequester is the requester of the `StartContext`, the transports without cookies,
+// the client's signals are the ones of the `NewClientContext`.
+type contextRequester struct {
+	s   *Sessions
+	ctx context.Context
+	// sendValue is the "send" of the `StartContext`, it receives the encoded session id.
+	sendValue func(value string)
+}
+
+var _ requester = (*contextRequester)(nil)
+
+func (req *contextRequester) send(sid string, _ time.Duration) {
+	if req.sendValue != nil {
+		req.sendValue(req.s.encodeCookieValue(sid))
+	}
+}
+
+func (req *contextRequester) restore(*Session, bool) bool {
+	return false
+}
+
+func (req *contextRequester) client() (string, string) {
+	c, _ := req.ctx.Value(clientContextKey{}).(clientInfo)
+	return c.ip, c.userAgent
+}
+
+func (req *contextRequester) identify(*Session) {}
+
+func (req *contextRequester) readOnly() bool {
+	return req.s.config.ReadOnlyContext != nil && req.s.config.ReadOnlyContext(req.ctx)
+}
+
+func (req *contextRequester) hydrate() (Store, bool) {
+	if req.s.config.HydratorContext == nil {
+		return nil, false
+	}
+	return req.s.config.HydratorContext(req.ctx), true
+}
@@ -0,0 +1,145 @@
+package sessions
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RiskSignals are the signals of a session's request which the `RiskScorer` evaluates,
+// they are collected by the `Start` when the `Config#RiskScorer` is set.
+type RiskSignals struct {
+	// SessionID is the id of the session.
+	SessionID string
+	// IsNew reports whether it's the first request of the session which this manager sees,
+	// the signals are kept in memory, they are not stored to the session databases.
+	IsNew bool
+	// Age is the time passed since the creation of the session.
+	Age time.Duration
+	// IP is the client's address of this request
+	// and PreviousIP the client's address of the previous request of the session.
+	IP, PreviousIP string
+	// IPChanged reports whether the client's address changed since the previous request.
+	IPChanged bool
+	// UserAgentChanged reports whether the "User-Agent" header changed since the previous request.
+	UserAgentChanged bool
+	// Velocity is the number of the session's requests of the last minute, including this one.
+	Velocity int
+	// AuthLevel is the authentication level of the session, see `Session#SetAuthLevel`.
+	AuthLevel int
+}
+
+// RiskScorer evaluates the signals of a session's request and returns its risk score,
+// the application decides how to act on the score, i.e require a step-up authentication
+// or a captcha when it's higher than a threshold, see `Session#RiskScore`.
+//
+// The package collects the signals, the implementations only write the policy.
+type RiskScorer interface {
+	Score(signals RiskSignals) float64
+}
+
+// RiskScorerFunc is a function which implements the `RiskScorer`.
+type RiskScorerFunc func(signals RiskSignals) float64
+
+// Score calls the "fn".
+func (fn RiskScorerFunc) Score(signals RiskSignals) float64 {
+	return fn(signals)
+}
+
+// riskState is the per session state of the risk signals.
+type riskState struct {
+	ip          string
+	userAgent   string
+	windowStart time.Time
+	requests    int
+	authLevel   int
+	score       float64
+}
+
+// velocityWindow is the duration which the velocity of the requests is counted in.
+const velocityWindow = time.Minute
+
+// assess collects the signals of the request and stores the risk score to the session.
+func (s *Sessions) assess(sess *Session, ip, userAgent string) {
+	scorer := s.config.RiskScorer
+	if scorer == nil {
+		return
+	}
+
+	now := s.provider.now()
+
+	sess.mu.Lock()
+	state := &sess.risk
+	signals := RiskSignals{
+		SessionID:  sess.sid,
+		IsNew:      state.ip == "" && state.userAgent == "" && state.requests == 0,
+		Age:        now.Sub(sess.createdAt),
+		IP:         ip,
+		PreviousIP: state.ip,
+		AuthLevel:  state.authLevel,
+	}
+
+	if !signals.IsNew {
+		signals.IPChanged = state.ip != ip
+		signals.UserAgentChanged = state.userAgent != userAgent
+	}
+
+	if now.Sub(state.windowStart) > velocityWindow {
+		state.windowStart = now
+		state.requests = 0
+	}
+	state.requests++
+	signals.Velocity = state.requests
+
+	state.ip = ip
+	state.userAgent = userAgent
+	sess.mu.Unlock()
+
+	// the scorer may be slow, i.e a remote service, it runs without the lock.
+	score := scorer.Score(signals)
+
+	sess.mu.Lock()
+	sess.risk.score = score
+	sess.mu.Unlock()
+}
+
+// requestIP returns the client's address of the net/http request, without the port.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestIPFasthttp returns the client's address of the fasthttp request.
+func requestIPFasthttp(ctx *fasthttp.RequestCtx) string {
+	return ctx.RemoteIP().String()
+}
+
+// RiskScore returns the risk score of the session's latest request,
+// as evaluated by the `Config#RiskScorer`, zero if it's not set.
+func (s *Session) RiskScore() float64 {
+	s.mu.RLock()
+	score := s.risk.score
+	s.mu.RUnlock()
+	return score
+}
+
+// SetAuthLevel sets the authentication level of the session, i.e 1 after a password login
+// and 2 after a second factor, it's passed to the `Config#RiskScorer` as the `RiskSignals#AuthLevel`.
+func (s *Session) SetAuthLevel(level int) {
+	s.mu.Lock()
+	s.risk.authLevel = level
+	s.mu.Unlock()
+}
+
+// AuthLevel returns the authentication level of the session, see `SetAuthLevel`.
+func (s *Session) AuthLevel() int {
+	s.mu.RLock()
+	level := s.risk.authLevel
+	s.mu.RUnlock()
+	return level
+}
@@ -0,0 +1,47 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRiskScorer(t *testing.T) {
+	var signals []RiskSignals
+	manager := New(Config{RiskScorer: RiskScorerFunc(func(s RiskSignals) float64 {
+		signals = append(signals, s)
+		if s.IPChanged && s.AuthLevel < 2 {
+			return 1
+		}
+		return 0
+	})})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, r)
+	cookie := w.Result().Cookies()[0]
+
+	if !signals[0].IsNew || signals[0].IP != "10.0.0.1" || signals[0].Velocity != 1 {
+		t.Fatalf("unexpected signals of a new session: %#v", signals[0])
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.AddCookie(cookie)
+	manager.Start(httptest.NewRecorder(), r)
+
+	if s := signals[1]; s.IsNew || !s.IPChanged || s.PreviousIP != "10.0.0.1" || s.Velocity != 2 {
+		t.Fatalf("expected the address change to be reported but got: %#v", s)
+	}
+
+	if score := sess.RiskScore(); score != 1 {
+		t.Fatalf("expected the risk score to be stored to the session but got %v", score)
+	}
+
+	sess.SetAuthLevel(2)
+	manager.Start(httptest.NewRecorder(), r)
+	if score := sess.RiskScore(); score != 0 || signals[2].AuthLevel != 2 || signals[2].IPChanged {
+		t.Fatalf("expected the auth level to be reported and the score to be reset but got %v: %#v", score, signals[2])
+	}
+}
@@ -0,0 +1,157 @@
+package sessions
+
+import (
+	"strings"
+)
+
+// ScopedStore is a view of a `Store` whose keys are prefixed by its prefix, see `Store.Scope`.
+type ScopedStore struct {
+	store  *Store
+	prefix string
+}
+
+// Scope returns a view of the store whose `Set`, `Get` and `Remove` prefix the keys by the "prefix",
+// i.e "cart." or "auth.", so several middlewares can share the same values without key collisions.
+// The view shares the entries of the store, the changes of the one are visible to the other.
+func (r *Store) Scope(prefix string) ScopedStore {
+	return ScopedStore{store: r, prefix: prefix}
+}
+
+// Prefix returns the prefix of the scope's keys.
+func (s ScopedStore) Prefix() string {
+	return s.prefix
+}
+
+// Scope returns a nested scope, its keys are prefixed by both prefixes.
+func (s ScopedStore) Scope(prefix string) ScopedStore {
+	return ScopedStore{store: s.store, prefix: s.prefix + prefix}
+}
+
+// Get returns the value of the "key" of the scope, nil if not found.
+func (s ScopedStore) Get(key string) interface{} {
+	return s.store.Get(s.prefix + key)
+}
+
+// GetDefault returns the value of the "key" of the scope, "def" if not found.
+func (s ScopedStore) GetDefault(key string, def interface{}) interface{} {
+	return s.store.GetDefault(s.prefix+key, def)
+}
+
+// GetString returns the string value of the "key" of the scope, empty if not found.
+func (s ScopedStore) GetString(key string) string {
+	return s.store.GetString(s.prefix + key)
+}
+
+// Set saves the "value" to the "key" of the scope, see `Store.Set`,
+// the returned entry's key is the prefixed one.
+func (s ScopedStore) Set(key string, value interface{}) (Entry, bool) {
+	return s.store.Set(s.prefix+key, value)
+}
+
+// SetImmutable saves the immutable "value" to the "key" of the scope, see `Store.SetImmutable`.
+func (s ScopedStore) SetImmutable(key string, value interface{}) (Entry, bool) {
+	return s.store.SetImmutable(s.prefix+key, value)
+}
+
+// Remove deletes the "key" of the scope, returns true if an entry is actually removed.
+func (s ScopedStore) Remove(key string) bool {
+	return s.store.Remove(s.prefix + key)
+}
+
+// Visit calls the "visitor" for each entry of the scope, the keys are passed without the prefix.
+func (s ScopedStore) Visit(visitor func(key string, value interface{})) {
+	s.store.Visit(func(key string, value interface{}) {
+		if strings.HasPrefix(key, s.prefix) {
+			visitor(key[len(s.prefix):], value)
+		}
+	})
+}
+
+// Len returns the number of the entries of the scope.
+func (s ScopedStore) Len() int {
+	n := 0
+	for _, kv := range *s.store {
+		if strings.HasPrefix(kv.Key, s.prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset removes the entries of the scope, the rest of the store is kept.
+func (s ScopedStore) Reset() {
+	args := *s.store
+	n := 0
+	for i := range args {
+		if strings.HasPrefix(args[i].Key, s.prefix) {
+			continue
+		}
+		args[n] = args[i]
+		n++
+	}
+
+	for i := n; i < len(args); i++ {
+		args[i] = Entry{} // release the removed values.
+	}
+	*s.store = args[:n]
+}
+
+// SessionScope is a view of a session whose keys are prefixed by its prefix, see `Session#Scope`.
+type SessionScope struct {
+	sess   *Session
+	prefix string
+}
+
+// Scope returns a view of the session whose `Set`, `Get` and `Delete` prefix the keys by the "prefix",
+// so several middlewares can share one session without key collisions, see `Store.Scope`.
+// The changes are saved to the session databases as the session's ones.
+func (s *Session) Scope(prefix string) SessionScope {
+	return SessionScope{sess: s, prefix: prefix}
+}
+
+// Prefix returns the prefix of the scope's keys.
+func (s SessionScope) Prefix() string {
+	return s.prefix
+}
+
+// Get returns the value of the "key" of the scope, nil if not found.
+func (s SessionScope) Get(key string) interface{} {
+	return s.sess.Get(s.prefix + key)
+}
+
+// GetString returns the string value of the "key" of the scope, empty if not found.
+func (s SessionScope) GetString(key string) string {
+	return s.sess.GetString(s.prefix + key)
+}
+
+// Set saves the "value" to the "key" of the scope.
+func (s SessionScope) Set(key string, value interface{}) {
+	s.sess.Set(s.prefix+key, value)
+}
+
+// Delete removes the "key" of the scope, returns true if actually something was removed.
+func (s SessionScope) Delete(key string) bool {
+	return s.sess.Delete(s.prefix + key)
+}
+
+// VisitAll calls the "cb" for each entry of the scope, the keys are passed without the prefix.
+func (s SessionScope) VisitAll(cb func(key string, value interface{})) {
+	// the stored keys are normalized, see `Config#KeyNormalizer`.
+	prefix := s.sess.key(s.prefix)
+	s.sess.VisitWith(VisitOptions{Prefix: s.prefix}, func(key string, value interface{}) bool {
+		cb(key[len(prefix):], value)
+		return true
+	})
+}
+
+// Clear removes the entries of the scope, the rest of the session is kept.
+func (s SessionScope) Clear() {
+	var keys []string
+	s.VisitAll(func(key string, _ interface{}) {
+		keys = append(keys, key)
+	})
+
+	for _, key := range keys {
+		s.Delete(key)
+	}
+}
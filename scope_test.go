@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"testing"
+)
+
+func TestStoreScope(t *testing.T) {
+	var store Store
+	cart, auth := store.Scope("cart."), store.Scope("auth.")
+
+	cart.Set("id", "c1")
+	auth.Set("id", "u1")
+	if got := cart.GetString("id"); got != "c1" {
+		t.Fatalf("expected c1 but got %s", got)
+	}
+	if got := store.GetString("auth.id"); got != "u1" {
+		t.Fatalf("expected the key to be prefixed but got %q", got)
+	}
+
+	cart.Scope("items.").Set("1", 2)
+	if cart.Len() != 2 || auth.Len() != 1 {
+		t.Fatalf("expected 2 and 1 entries but got %d and %d", cart.Len(), auth.Len())
+	}
+
+	keys := make(map[string]bool)
+	cart.Visit(func(key string, _ interface{}) { keys[key] = true })
+	if !keys["id"] || !keys["items.1"] || len(keys) != 2 {
+		t.Fatalf("expected the scope's keys without the prefix but got %v", keys)
+	}
+
+	cart.Reset()
+	if store.Len() != 1 || auth.GetString("id") != "u1" {
+		t.Fatalf("expected only the scope's entries to be removed but got %v", store)
+	}
+
+	if !auth.Remove("id") || store.Len() != 0 {
+		t.Fatalf("expected the entry to be removed")
+	}
+}
+
+func TestSessionScope(t *testing.T) {
+	manager := New(Config{})
+	sess := manager.provider.Init("sid", 0)
+
+	cart := sess.Scope("cart.")
+	cart.Set("id", "c1")
+	sess.Set("id", "root")
+
+	if got := sess.GetString("cart.id"); got != "c1" {
+		t.Fatalf("expected the key to be prefixed but got %q", got)
+	}
+
+	cart.Clear()
+	if sess.Get("cart.id") != nil || sess.GetString("id") != "root" {
+		t.Fatalf("expected only the scope's entries to be removed but got %v", sess.GetAll())
+	}
+}
@@ -1,9 +1,11 @@
 package sessions
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type (
@@ -12,7 +14,15 @@ type (
 	// save or retrieve values based on a key.
 	//
 	// This is what will be returned when sess := sessions.Start().
+	// Each request gets its own handle of the session, which shares the session's values
+	// with the handles of the other requests of the same session id.
 	Session struct {
+		*sessionState
+		*requestState
+	}
+
+	// sessionState is the state of a session which is shared by the requests of its session id.
+	sessionState struct {
 		sid    string
 		isNew  bool
 		values Store // here are the real values
@@ -26,6 +36,49 @@ type (
 		mu       sync.RWMutex
 		lifetime LifeTime
 		provider *provider
+		// writer is the single-writer semaphore, it's filled by the request which holds the session,
+		// the holder, when the `Config#SingleWriter` is true.
+		writer chan struct{}
+		holder *requestState
+		// createdAt and extensions track the expiration extensions of the session,
+		// see `Config#MaxExtensions` and `Config#MaxExtendedLifetime`.
+		createdAt  time.Time
+		extensions int
+		// risk is the state of the risk signals, see `Config#RiskScorer`.
+		risk riskState
+		// ctx is created on the first `Context` call and cancelled when the session ends,
+		// ended is set when the session is destroyed or expired.
+		ctx    context.Context
+		cancel context.CancelFunc
+		ended  bool
+		// anonymousID is the anonymous id of the client, it's set by the `Start`, see `Config#AnonymousIDKey`.
+		anonymousID string
+		// version is the version of the session, atomic, see `Version`.
+		version uint64
+		// locker is the local lock of the `Lock`.
+		locker chan struct{}
+		// modified is set by the writes of any request, the modified sessions are flushed on `Sessions#Close`.
+		modified bool
+	}
+
+	// requestState is the state of a session which belongs to a single request, see `Sessions#hold`.
+	requestState struct {
+		// readOnly rejects the writes of the request, see `SetReadOnly`.
+		readOnly bool
+		// dirty is set by the writes of the request, see `Dirty`.
+		dirty bool
+		// conflict is the unresolved conflict of the request, see `Conflict`.
+		conflict error
+		// requester is the transport of the request, it sends the regenerated session id to the client,
+		// see `Config#PrivilegeKeys` and `Sessions#RegenerateIDContext`.
+		requester requester
+		// lockToken and lockedBy are the owner token and the databases of the distributed lock, see `Lock`.
+		lockToken string
+		lockedBy  []Locker
+		// journal is the undo journal of the request's mutations, see `Rollback`,
+		// it's recorded when journaling is set by the `Start`.
+		journal    []journalEntry
+		journaling bool
 	}
 
 	flashMessage struct {
@@ -37,16 +90,186 @@ type (
 
 // ID returns the session's ID.
 func (s *Session) ID() string {
-	return s.sid
+	s.mu.RLock()
+	sid := s.sid
+	s.mu.RUnlock()
+	return sid
 }
 
 // IsNew returns true if is's a new session
 func (s *Session) IsNew() bool {
-	return s.isNew
+	s.mu.RLock()
+	isNew := s.isNew
+	s.mu.RUnlock()
+	return isNew
+}
+
+// Context returns a context which is cancelled when the session is destroyed or expires,
+// the long running resources of the session, i.e a websocket pump or a watcher,
+// can use it to stop together with the session.
+// The context is not cancelled on `RegenerateID`, the session continues with its new id.
+//
+// The context of a session which already ended is returned cancelled.
+func (s *Session) Context() context.Context {
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		if s.ended {
+			s.cancel()
+		}
+	}
+	ctx := s.ctx
+	s.mu.Unlock()
+	return ctx
+}
+
+// end cancels the session's context, see `Context`.
+func (s *Session) end() {
+	s.mu.Lock()
+	s.ended = true
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// newRequest returns a new handle of the session for a request, it shares the session's state.
+func (s *Session) newRequest() *Session {
+	return &Session{sessionState: s.sessionState, requestState: new(requestState)}
+}
+
+// acquire blocks until the session is not held by another request and it's held by this one,
+// it returns the "ctx"'s error or the `ErrSessionBusy` after the "timeout", if it's positive, instead.
+func (s *Session) acquire(ctx context.Context, timeout time.Duration) error {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	select {
+	case s.writer <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-expired:
+		return ErrSessionBusy
+	}
+
+	s.mu.Lock()
+	s.holder = s.requestState
+	s.mu.Unlock()
+	return nil
+}
+
+// Release unlocks the session for the next request of the same session id,
+// it should be called when the handler is done with the session
+// if the manager is configured with `SingleWriter`, otherwise it does nothing.
+// The distributed lock of the `Config#DistributedSingleWriter` is released too.
+//
+// It's safe to call it more than once, only the request which holds the session releases it.
+func (s *Session) Release() {
+	s.mu.Lock()
+	s.requester = nil
+	s.readOnly = false
+	held := s.holder == s.requestState
+	if held {
+		s.holder = nil
+	}
+	locked := held && s.lockToken != ""
+	s.mu.Unlock()
+
+	if !held {
+		return
+	}
+
+	if locked {
+		if err := s.Unlock(); err != nil {
+			s.provider.logger().Warnf("sessions: unlock of the session %s: %v", s.ID(), err)
+		}
+	}
+
+	<-s.writer
+}
+
+// BindLogin binds this session to the "subject" and the session id
+// which the identity provider (OIDC or SAML) issued on the user's login,
+// so the sessions can be destroyed on an identity provider-initiated logout,
+// see `Sessions#DestroyByLogin` and the "logout" subpackage.
+//
+// Note that the bindings are kept in memory.
+func (s *Session) BindLogin(subject, idpSessionID string) {
+	sid := s.ID()
+	if subject != "" {
+		s.provider.Bind(sid, claimKey("subject", subject))
+	}
+
+	if idpSessionID != "" {
+		s.provider.Bind(sid, claimKey("idp_sid", idpSessionID))
+	}
+}
+
+// BindUser binds this session to the application's "userID",
+// so all the sessions of a user can be destroyed at once, i.e on a password change
+// or on a "log out everywhere" action, see `Sessions#DestroyByUser`.
+// The binding follows the session on `RegenerateID` and it's removed when the session is destroyed.
+//
+// Note that the bindings are kept in memory.
+func (s *Session) BindUser(userID string) {
+	if userID != "" {
+		s.provider.Bind(s.ID(), claimKey("user", userID))
+	}
+}
+
+// RegenerateID moves the session's data to a new session id, generated by the `Config#IDGenerator`,
+// and removes the old one from the server, the standard defense against session fixation.
+// It should be called on privilege changes, i.e after login.
+// Returns the new session id.
+//
+// The client's cookie is not updated, use the `Sessions#RegenerateID` instead.
+func (s *Session) RegenerateID() string {
+	return s.regenerateID(context.Background())
+}
+
+func (s *Session) regenerateID(ctx context.Context) string {
+	generate := RandomIDGenerator
+	if cfg := s.provider.config; cfg != nil && cfg.IDGenerator != nil {
+		generate = cfg.IDGenerator
+	}
+
+	newSid := generate(ctx)
+	s.provider.Regenerate(s, newSid)
+	return newSid
+}
+
+// onPrivilegeChange regenerates the session id and updates the client's cookie
+// if the "key" is one of the `Config#PrivilegeKeys`.
+func (s *Session) onPrivilegeChange(key string) {
+	cfg := s.provider.config
+	if cfg == nil || len(cfg.PrivilegeKeys) == 0 {
+		return
+	}
+
+	s.mu.RLock()
+	req := s.requester
+	s.mu.RUnlock()
+	if req == nil {
+		return
+	}
+
+	for _, privilegeKey := range cfg.PrivilegeKeys {
+		if s.key(privilegeKey) == key {
+			req.send(s.RegenerateID(), cfg.Expires)
+			return
+		}
+	}
 }
 
 // Get returns a value based on its "key".
 func (s *Session) Get(key string) interface{} {
+	key = s.key(key)
 	s.mu.RLock()
 	value := s.values.Get(key)
 	s.mu.RUnlock()
@@ -54,6 +277,27 @@ func (s *Session) Get(key string) interface{} {
 	return value
 }
 
+// GetEntry returns the entry of the "key" and true, or false if not found,
+// unlike the `Get` it separates a missing key from a key with a nil value, see `Store.GetEntry`.
+func (s *Session) GetEntry(key string) (Entry, bool) {
+	key = s.key(key)
+	s.mu.RLock()
+	entry, ok := s.values.GetEntry(key)
+	s.mu.RUnlock()
+
+	return entry, ok
+}
+
+// Exists reports whether the "key" exists, even if its value is nil.
+func (s *Session) Exists(key string) bool {
+	key = s.key(key)
+	s.mu.RLock()
+	ok := s.values.Exists(key)
+	s.mu.RUnlock()
+
+	return ok
+}
+
 // when running on the session manager removes any 'old' flash messages.
 func (s *Session) runFlashGC() {
 	s.mu.Lock()
@@ -67,7 +311,10 @@ func (s *Session) runFlashGC() {
 
 // HasFlash returns true if this session has available flash messages.
 func (s *Session) HasFlash() bool {
-	return len(s.flashes) > 0
+	s.mu.RLock()
+	has := len(s.flashes) > 0
+	s.mu.RUnlock()
+	return has
 }
 
 // GetFlash returns a stored flash message based on its "key"
@@ -80,33 +327,31 @@ func (s *Session) HasFlash() bool {
 // Fetching a message deletes it from the session.
 // This means that a message is meant to be displayed only on the first page served to the user.
 func (s *Session) GetFlash(key string) interface{} {
-	fv, ok := s.peekFlashMessage(key)
-	if !ok {
-		return nil
-	}
-	fv.shouldRemove = true
-	return fv.value
+	return s.getFlash(key, true)
 }
 
 // PeekFlash returns a stored flash message based on its "key".
 // Unlike GetFlash, this will keep the message valid for the next requests,
 // until GetFlashes or GetFlash("key").
 func (s *Session) PeekFlash(key string) interface{} {
-	fv, ok := s.peekFlashMessage(key)
-	if !ok {
-		return nil
-	}
-	return fv.value
+	return s.getFlash(key, false)
 }
 
-func (s *Session) peekFlashMessage(key string) (*flashMessage, bool) {
+// getFlash returns the flash message's value of the "key",
+// if "remove" is true then the message is removed on the next request.
+func (s *Session) getFlash(key string, remove bool) interface{} {
 	s.mu.Lock()
-	if fv, found := s.flashes[key]; found {
-		return fv, true
+	defer s.mu.Unlock()
+
+	fv, found := s.flashes[key]
+	if !found {
+		return nil
 	}
-	s.mu.Unlock()
 
-	return nil, false
+	if remove {
+		fv.shouldRemove = true
+	}
+	return fv.value
 }
 
 // GetString same as Get but returns as string, if nil then returns an empty string.
@@ -145,6 +390,13 @@ func (s *Session) GetInt(key string) (int, error) {
 		return strconv.Atoi(vstring)
 	}
 
+	if vint, nok, err := numberToInt(v); nok {
+		if err != nil {
+			return -1, err
+		}
+		return vint, nil
+	}
+
 	return -1, fmt.Errorf(errIntParseFormat, "int", key, v)
 }
 
@@ -164,6 +416,13 @@ func (s *Session) GetInt64(key string) (int64, error) {
 		return strconv.ParseInt(vstring, 10, 64)
 	}
 
+	if vint64, nok, err := numberToInt64(v); nok {
+		if err != nil {
+			return -1, err
+		}
+		return vint64, nil
+	}
+
 	return -1, fmt.Errorf(errIntParseFormat, "int64", key, v)
 
 }
@@ -192,6 +451,13 @@ func (s *Session) GetFloat32(key string) (float32, error) {
 		return float32(vfloat64), nil
 	}
 
+	if vfloat32, nok, err := numberToFloat32(v); nok {
+		if err != nil {
+			return -1, err
+		}
+		return vfloat32, nil
+	}
+
 	return -1, fmt.Errorf(errIntParseFormat, "float32", key, v)
 }
 
@@ -215,6 +481,13 @@ func (s *Session) GetFloat64(key string) (float64, error) {
 		return strconv.ParseFloat(vstring, 32)
 	}
 
+	if vfloat64, nok, err := numberToFloat64(v); nok {
+		if err != nil {
+			return -1, err
+		}
+		return vfloat64, nil
+	}
+
 	return -1, fmt.Errorf(errIntParseFormat, "float64", key, v)
 }
 
@@ -233,8 +506,8 @@ func (s *Session) GetBoolean(key string) (bool, error) {
 
 // GetAll returns a copy of all session's values.
 func (s *Session) GetAll() map[string]interface{} {
-	items := make(map[string]interface{}, len(s.values))
 	s.mu.RLock()
+	items := make(map[string]interface{}, len(s.values))
 	for _, kv := range s.values {
 		items[kv.Key] = kv.Value()
 	}
@@ -245,8 +518,8 @@ func (s *Session) GetAll() map[string]interface{} {
 // GetFlashes returns all flash messages as map[string](key) and interface{} value
 // NOTE: this will cause at remove all current flash messages on the next request of the same user.
 func (s *Session) GetFlashes() map[string]interface{} {
-	flashes := make(map[string]interface{}, len(s.flashes))
 	s.mu.Lock()
+	flashes := make(map[string]interface{}, len(s.flashes))
 	for key, v := range s.flashes {
 		flashes[key] = v.value
 		v.shouldRemove = true
@@ -257,19 +530,102 @@ func (s *Session) GetFlashes() map[string]interface{} {
 
 // VisitAll loop each one entry and calls the callback function func(key,value)
 func (s *Session) VisitAll(cb func(k string, v interface{})) {
+	s.mu.RLock()
 	s.values.Visit(cb)
+	s.mu.RUnlock()
+}
+
+// VisitWith loops over the entries which pass the "opts" filter and pagination, see `Store.VisitWith`,
+// the callback can return false to stop the iteration.
+// The "opts.Prefix" is normalized as the keys are, see `Config#KeyNormalizer`.
+func (s *Session) VisitWith(opts VisitOptions, cb func(k string, v interface{}) bool) {
+	if opts.Prefix != "" {
+		opts.Prefix = s.key(opts.Prefix)
+	}
+
+	s.mu.RLock()
+	s.values.VisitWith(opts, cb)
+	s.mu.RUnlock()
 }
 
 func (s *Session) set(key string, value interface{}, immutable bool) {
+	s.save(key, value, immutable, false)
+}
+
+// save saves the "value" to the "key" and syncs the session databases,
+// if "strict" then the `ErrEntryImmutable` is returned, and nothing is synced, when the entry is immutable.
+func (s *Session) save(key string, value interface{}, immutable, strict bool) error {
+	if err := checkRecursive(key, value); err != nil {
+		return err
+	}
+	s.registerTypes(value)
+
+	return s.update(key, immutable, func(key string) (interface{}, error) {
+		if strict && !immutable && s.values.isImmutable(key) {
+			return nil, ErrEntryImmutable
+		}
+		return value, nil
+	})
+}
+
+// registerTypes registers the types of the "values" if the `Config#AutoRegisterTypes` is true,
+// the types which are registered under a different name are logged, they are still encoded by that name.
+func (s *Session) registerTypes(values ...interface{}) {
+	if cfg := s.provider.config; cfg == nil || !cfg.AutoRegisterTypes {
+		return
+	}
+
+	for _, v := range values {
+		if err := registerType(v); err != nil {
+			s.provider.logger().Warnf("%v", err)
+		}
+	}
+}
+
+// update saves the value which the "compute" returns to the "key" and syncs the session databases,
+// the "compute" is called with the normalized key while the session is locked,
+// so it can read the current values, if it returns an error then nothing is saved.
+func (s *Session) update(key string, immutable bool, compute func(key string) (interface{}, error)) error {
+	key = s.key(key)
 	action := ActionCreate // defaults to create, means the first insert.
 
 	s.mu.Lock()
+	if s.rejectWrite(key) {
+		s.mu.Unlock()
+		return ErrReadOnly
+	}
+
+	value, err := compute(key)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	if s.values.unchanged(key, value, immutable) {
+		// nothing to write, the session databases are not synced.
+		s.mu.Unlock()
+		s.onPrivilegeChange(key)
+		return nil
+	}
+
+	trimmed, err := s.fitSize(key, func(values *Store) { values.Save(key, value, immutable) })
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.trim(trimmed)
+
 	isFirst := s.values.Len() == 0
+	s.recordSet(key)
 	entry, isNew := s.values.Save(key, value, immutable)
 	s.isNew = false
+	s.dirty = true
+	s.modified = true
 
 	s.mu.Unlock()
 
+	s.syncTrimmed(trimmed)
+
 	if !isFirst {
 		// we could use s.isNew
 		// which is setted at sessions.go#Start when values are empty
@@ -285,10 +641,55 @@ func (s *Session) set(key string, value interface{}, immutable bool) {
 	// that was not my commit so I will ask for permission first...
 	// rename the expireAt to expiresAt, it seems to make more sense to me
 
+	s.syncEntry(key, action, entry)
+	s.onPrivilegeChange(key)
+	return nil
+}
+
+// flush writes the whole session to the "databases" if it was modified and it's not ended, see `Sessions#Close`,
+// the values which are references, i.e pointers and slices, may be modified in place after their writes.
+func (s *Session) flush(databases []Database) {
+	s.mu.RLock()
+	modified := s.modified && !s.ended
+	s.mu.RUnlock()
+
+	if modified {
+		syncDatabases(databases, acquireSyncPayload(s, ActionUpdate))
+	}
+}
+
+// syncEntry syncs the write of the "key"'s entry to the session databases
+// and fires the update hooks, it's called after the session is unlocked.
+func (s *Session) syncEntry(key string, action Action, entry Entry) {
 	p := acquireSyncPayload(s, action)
 	p.Value = entry
 
 	syncDatabases(s.provider.databases, p)
+	s.provider.hooks.fireUpdate(p.SessionID, action, key)
+}
+
+// hydrate fills a new session with the "values", each value is written by the `update`,
+// so the `Config#MaxSessionSize` and the read-only state of the request are respected, see `Config#Hydrator`.
+// The session is still reported as new.
+func (s *Session) hydrate(values Store) error {
+	for _, entry := range values {
+		if err := s.save(entry.Key, entry.ValueRaw, entry.immutable, false); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.isNew = true
+	s.mu.Unlock()
+	return nil
+}
+
+// empty reports whether the session has no values, i.e a new session or the session of an unknown or expired id.
+func (s *Session) empty() bool {
+	s.mu.RLock()
+	empty := s.values.Len() == 0
+	s.mu.RUnlock()
+	return empty
 }
 
 // Set fills the session with an entry"value", based on its "key".
@@ -296,6 +697,13 @@ func (s *Session) Set(key string, value interface{}) {
 	s.set(key, value, false)
 }
 
+// SetE same as `Set` but it returns the `ErrEntryImmutable` if the "key"'s entry is immutable,
+// the session is not changed, instead of ignoring the value silently.
+// Self-referential values are rejected with an `ErrRecursiveValue` error, the `Set` ignores them.
+func (s *Session) SetE(key string, value interface{}) error {
+	return s.save(key, value, false, true)
+}
+
 // SetImmutable fills the session with an entry "value", based on its "key".
 // Unlike `Set`, the output value cannot be changed by the caller later on (when .Get)
 // An Immutable entry should be only changed with a `SetImmutable`, simple `Set` will not work
@@ -306,6 +714,49 @@ func (s *Session) SetImmutable(key string, value interface{}) {
 	s.set(key, value, true)
 }
 
+// Append appends the "values" to the list of the "key", see `Store.Append`,
+// the writes to an immutable entry are ignored, see `AppendTo` to get their error.
+func (s *Session) Append(key string, values ...interface{}) {
+	s.AppendTo(key, values...)
+}
+
+// GetSlice same as Get but returns the value as a list, see `Store.GetSlice`.
+// If not found returns nil.
+func (s *Session) GetSlice(key string) []interface{} {
+	key = s.key(key)
+	s.mu.RLock()
+	list := s.values.GetSlice(key)
+	s.mu.RUnlock()
+
+	return list
+}
+
+// Unfreeze makes the immutable entry of the "key" mutable again, see `Store.Unfreeze`,
+// the session databases are synced and the change is logged as a warning to the `Config#Logger`, for auditing.
+// A read-only session is not changed, see `SetReadOnly`.
+// Returns true if the entry exists and it was immutable.
+func (s *Session) Unfreeze(key string) bool {
+	key = s.key(key)
+	s.mu.Lock()
+	i := s.indexOf(key)
+	if i < 0 || !s.values[i].immutable || s.rejectWrite(key) {
+		s.mu.Unlock()
+		return false
+	}
+
+	s.recordSet(key)
+	s.values[i].immutable = false
+	entry := s.values[i]
+	s.dirty = true
+	s.modified = true
+	sid := s.sid
+	s.mu.Unlock()
+
+	s.provider.logger().Warnf("sessions: the immutable key %q of the session %s is unfrozen", key, sid)
+	s.syncEntry(key, ActionUpdate, entry)
+	return true
+}
+
 // SetFlash sets a flash message by its key.
 //
 // A flash message is used in order to keep a message in session through one or several requests of the same user.
@@ -335,18 +786,31 @@ func (s *Session) SetFlash(key string, value interface{}) {
 // Delete removes an entry by its key,
 // returns true if actually something was removed.
 func (s *Session) Delete(key string) bool {
+	key = s.key(key)
 	s.mu.Lock()
-	removed := s.values.Remove(key)
-	if removed {
-		s.isNew = false
+	if s.rejectWrite(key) {
+		s.mu.Unlock()
+		return false
+	}
+
+	s.recordRemove(key)
+	if !s.values.Remove(key) {
+		// nothing to write, the session databases are not synced.
+		s.mu.Unlock()
+		return false
 	}
+	s.isNew = false
+	s.dirty = true
+	s.modified = true
 	s.mu.Unlock()
 
 	p := acquireSyncPayload(s, ActionDelete)
 	p.Value = Entry{Key: key}
 	syncDatabases(s.provider.databases, p)
 
-	return removed
+	s.provider.hooks.fireUpdate(p.SessionID, ActionDelete, key)
+	s.onPrivilegeChange(key)
+	return true
 }
 
 // DeleteFlash removes a flash message by its key.
@@ -359,12 +823,27 @@ func (s *Session) DeleteFlash(key string) {
 // Clear removes all entries.
 func (s *Session) Clear() {
 	s.mu.Lock()
+	if s.rejectWrite("") {
+		s.mu.Unlock()
+		return
+	}
+
+	if s.values.Len() == 0 {
+		// nothing to write, the session databases are not synced.
+		s.mu.Unlock()
+		return
+	}
+
+	s.recordClear()
 	s.values.Reset()
 	s.isNew = false
+	s.dirty = true
+	s.modified = true
 	s.mu.Unlock()
 
 	p := acquireSyncPayload(s, ActionClear)
 	syncDatabases(s.provider.databases, p)
+	s.provider.hooks.fireUpdate(p.SessionID, ActionClear, "")
 }
 
 // ClearFlashes removes all flash messages.
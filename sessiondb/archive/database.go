@@ -0,0 +1,280 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/golog"
+)
+
+// DefaultFileMode used as the default database's "fileMode"
+// for creating the archive directory path and writing the archived session files.
+var (
+	DefaultFileMode = 0600
+)
+
+// Database is a write-only session database which archives the final snapshot
+// of the destroyed and expired sessions to the disk, compressed and (optionally) encrypted,
+// for a retention period before their final deletion, i.e for fraud investigations.
+//
+// It never restores the archived sessions back to the manager, its `Load` returns an empty store,
+// the archived sessions can be queried with the `Get` and `Visit` methods.
+type Database struct {
+	dir       string
+	retention time.Duration
+	aead      cipher.AEAD // nil if not encrypted.
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+	// clock is the source of the archive times, see `SetClock`.
+	clock sessions.Clock
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+// SetClock sets the clock of the archive times and of the retention,
+// the manager's `Config#Clock` is set on its `UseDatabase`.
+// Defaults to the `sessions.SystemClock`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	db.clock = clock
+}
+
+func (db *Database) now() time.Time {
+	if db.clock != nil {
+		return db.clock.Now()
+	}
+	return time.Now()
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// Record is an archived session.
+type Record struct {
+	SessionID  string
+	ArchivedAt time.Time
+	Store      sessions.RemoteStore
+}
+
+var (
+	// ErrRetentionMissing returned on `New` when the retention period is not positive.
+	ErrRetentionMissing = errors.New("archive: retention period is missing")
+	// ErrInvalidRecord returned when an archived file cannot be decrypted.
+	ErrInvalidRecord = errors.New("archive: invalid record")
+)
+
+// New creates and returns a new archive database based on the "directoryPath".
+// The "retention" is the duration that an archived session is kept,
+// the "key" is the AES key(16, 24 or 32 bytes) which the archived sessions are encrypted with,
+// if it's empty then the sessions are not encrypted.
+//
+// It will remove any archived sessions that their retention period passed.
+func New(directoryPath string, retention time.Duration, key []byte) (*Database, error) {
+	if retention <= 0 {
+		return nil, ErrRetentionMissing
+	}
+
+	if err := os.MkdirAll(directoryPath, 0700); err != nil {
+		return nil, err
+	}
+
+	db := &Database{dir: directoryPath, retention: retention}
+
+	if len(key) > 0 {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if db.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, db.Cleanup()
+}
+
+// Cleanup removes the archived sessions that their retention period passed,
+// it's being called automatically on `New` as well.
+func (db *Database) Cleanup() error {
+	deadline := db.now().Add(-db.retention)
+
+	files, err := ioutil.ReadDir(db.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		_, archivedAt, ok := parseFilename(f.Name())
+		if !ok || !archivedAt.Before(deadline) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(db.dir, f.Name())); err != nil {
+			db.log().Warnf("troubles when cleanup an archived session file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Load returns an empty store, archived sessions are never restored.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	return sessions.RemoteStore{}
+}
+
+// Sync archives the session on destroy or expiration, other actions are ignored.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	if p.Action != sessions.ActionDestroy || p.Store.Values.Len() == 0 {
+		return
+	}
+
+	if err := db.archive(p.SessionID, p.Store); err != nil {
+		db.log().Errorf("error while archiving the session(%s): %v", p.SessionID, err)
+	}
+}
+
+func (db *Database) archive(sid string, store sessions.RemoteStore) error {
+	b, err := store.Serialize()
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	if _, err = w.Write(b); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	b = buf.Bytes()
+
+	if db.aead != nil {
+		nonce := make([]byte, db.aead.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		b = db.aead.Seal(nonce, nonce, b, []byte(sid))
+	}
+
+	filename := sid + "." + strconv.FormatInt(db.now().UnixNano(), 10)
+	return ioutil.WriteFile(filepath.Join(db.dir, filename), b, os.FileMode(DefaultFileMode))
+}
+
+func (db *Database) read(filename string) (Record, error) {
+	sid, archivedAt, ok := parseFilename(filename)
+	if !ok {
+		return Record{}, ErrInvalidRecord
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(db.dir, filename))
+	if err != nil {
+		return Record{}, err
+	}
+
+	if db.aead != nil {
+		nonceSize := db.aead.NonceSize()
+		if len(b) < nonceSize {
+			return Record{}, ErrInvalidRecord
+		}
+		if b, err = db.aead.Open(nil, b[:nonceSize], b[nonceSize:], []byte(sid)); err != nil {
+			return Record{}, ErrInvalidRecord
+		}
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return Record{}, err
+	}
+	if b, err = ioutil.ReadAll(r); err != nil {
+		return Record{}, err
+	}
+
+	store, err := sessions.DecodeRemoteStore(b)
+	if err != nil {
+		return Record{}, fmt.Errorf("archive: decode %s: %v", filename, err)
+	}
+
+	return Record{SessionID: sid, ArchivedAt: archivedAt, Store: store}, nil
+}
+
+// Get returns the archived snapshots of a session id, oldest first.
+func (db *Database) Get(sid string) ([]Record, error) {
+	var records []Record
+	err := db.Visit(func(r Record) bool {
+		if r.SessionID == sid {
+			records = append(records, r)
+		}
+		return true
+	})
+	return records, err
+}
+
+// Visit calls the "visitor" for each archived session, oldest first,
+// until the visitor returns false.
+func (db *Database) Visit(visitor func(Record) bool) error {
+	files, err := ioutil.ReadDir(db.dir)
+	if err != nil {
+		return err
+	}
+
+	// sort by archive time, the filenames are prefixed by session ids.
+	sort.Slice(files, func(i, j int) bool {
+		_, ti, _ := parseFilename(files[i].Name())
+		_, tj, _ := parseFilename(files[j].Name())
+		return ti.Before(tj)
+	})
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		r, err := db.read(f.Name())
+		if err != nil {
+			db.log().Warnf("skip archived session file %s: %v", f.Name(), err)
+			continue
+		}
+
+		if !visitor(r) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// parseFilename returns the session id and the archive time of a "sid.unixnano" filename.
+func parseFilename(filename string) (string, time.Time, bool) {
+	idx := strings.LastIndexByte(filename, '.')
+	if idx <= 0 {
+		return "", time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(filename[idx+1:], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return filename[:idx], time.Unix(0, nanos), true
+}
@@ -0,0 +1,172 @@
+package archive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func newDatabase(t *testing.T, retention time.Duration, key []byte) (*Database, func()) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := New(dir, retention, key)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return db, func() { os.RemoveAll(dir) }
+}
+
+func payload(sid string, action sessions.Action, name string) sessions.SyncPayload {
+	p := sessionstest.Insert(sid, name, 0)
+	p.Action = action
+	return p
+}
+
+func TestArchiveOnDestroy(t *testing.T) {
+	db, remove := newDatabase(t, time.Hour, testKey)
+	defer remove()
+
+	manager := sessions.New(sessions.Config{})
+	manager.UseDatabase(db)
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "kataras")
+
+	if records, _ := db.Get(sess.ID()); len(records) != 0 {
+		t.Fatalf("expected the alive session not to be archived but got %d records", len(records))
+	}
+
+	manager.DestroyByID(sess.ID())
+
+	records, err := db.Get(sess.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the final snapshot of the destroyed session but got %v", records)
+	}
+
+	if store := db.Load(sess.ID()); len(store.Values) > 0 {
+		t.Fatalf("expected the archived session not to be restored but got %v", store.Values)
+	}
+}
+
+func TestEmptyNotArchived(t *testing.T) {
+	db, remove := newDatabase(t, time.Hour, nil)
+	defer remove()
+
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+
+	if records, _ := db.Get("sid"); len(records) != 0 {
+		t.Fatalf("expected the empty session not to be archived but got %d records", len(records))
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	db, remove := newDatabase(t, time.Hour, testKey)
+	defer remove()
+
+	db.Sync(payload("sid", sessions.ActionDestroy, "kataras"))
+
+	files, err := ioutil.ReadDir(db.dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected 1 archived file but got %d: %v", len(files), err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(db.dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("kataras")) {
+		t.Fatalf("expected the archived session to be encrypted")
+	}
+
+	// the records of another key are skipped.
+	other, err := New(db.dir, time.Hour, []byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := other.Get("sid"); len(records) != 0 {
+		t.Fatalf("expected the record not to be decrypted by another key but got %v", records)
+	}
+}
+
+func TestVisitOrder(t *testing.T) {
+	db, remove := newDatabase(t, time.Hour, nil)
+	defer remove()
+
+	clock := sessions.NewManualClock(time.Now())
+	db.SetClock(clock)
+
+	db.Sync(payload("sid", sessions.ActionDestroy, "kataras"))
+	clock.Advance(time.Millisecond)
+	db.Sync(payload("other", sessions.ActionDestroy, "makis"))
+	clock.Advance(time.Millisecond)
+	db.Sync(payload("sid", sessions.ActionDestroy, "gerasimos"))
+
+	var names []string
+	err := db.Visit(func(r Record) bool {
+		names = append(names, r.Store.Values.GetString("name"))
+		return len(names) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 2 || names[0] != "kataras" || names[1] != "makis" {
+		t.Fatalf("expected the oldest records first, until the visitor stops, but got %v", names)
+	}
+
+	records, _ := db.Get("sid")
+	if len(records) != 2 || records[1].Store.Values.GetString("name") != "gerasimos" {
+		t.Fatalf("expected the 2 snapshots of the session but got %v", records)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	db, remove := newDatabase(t, time.Hour, nil)
+	defer remove()
+
+	clock := sessions.NewManualClock(time.Now())
+	db.SetClock(clock)
+
+	db.Sync(payload("sid", sessions.ActionDestroy, "kataras"))
+	clock.Advance(2 * time.Hour)
+	db.Sync(payload("other", sessions.ActionDestroy, "makis"))
+
+	if err := db.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if records, _ := db.Get("sid"); len(records) != 0 {
+		t.Fatalf("expected the record of the passed retention to be removed")
+	}
+	if records, _ := db.Get("other"); len(records) != 1 {
+		t.Fatalf("expected the record within the retention to be kept")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(os.TempDir(), 0, nil); err != ErrRetentionMissing {
+		t.Fatalf("expected the ErrRetentionMissing but got %v", err)
+	}
+
+	if _, err := New(os.TempDir(), time.Hour, []byte("short")); err == nil {
+		t.Fatalf("expected an error on the invalid key size")
+	}
+}
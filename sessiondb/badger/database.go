@@ -23,6 +23,23 @@ type Database struct {
 	// it's initialized at `New` or `NewFromDB`.
 	// Can be used to get stats.
 	Service *badger.DB
+
+	transcoder sessions.Transcoder
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
 }
 
 // New creates and returns a new badger(key-value file-based) storage
@@ -94,7 +111,7 @@ func (db *Database) Cleanup() (err error) {
 			continue
 		}
 
-		storeDB, err := sessions.DecodeRemoteStore(b)
+		storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, b)
 		if err != nil {
 			errMsg += err.Error()
 			continue
@@ -121,6 +138,15 @@ func (db *Database) Async(useGoRoutines bool) *Database {
 	return db
 }
 
+// Transcoder sets the transcoder which is used to serialize and deserialize
+// the sessions of this database, i.e a `sessions.CompressionTranscoder`
+// to reduce the size of the database.
+// Defaults to nil, the `sessions.DefaultTranscoder` is used instead.
+func (db *Database) Transcoder(t sessions.Transcoder) *Database {
+	db.transcoder = t
+	return db
+}
+
 // Load loads the sessions from the badger(key-value file-based) session storage.
 func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 	bsid := []byte(sid)
@@ -137,18 +163,46 @@ func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 	b, err := item.Value()
 
 	if err != nil {
-		golog.Errorf("error while trying to get the serialized session(%s) from the remote store: %v", sid, err)
+		db.log().Errorf("error while trying to get the serialized session(%s) from the remote store: %v", sid, err)
 		return
 	}
 
-	storeDB, err = sessions.DecodeRemoteStore(b) // decode the whole value, as a remote store
+	storeDB, err = sessions.DecodeRemoteStoreWith(db.transcoder, b) // decode the whole value, as a remote store
 	if err != nil {
-		golog.Errorf("error while trying to load from the remote store: %v", err)
+		db.log().Errorf("error while trying to load from the remote store: %v", err)
 	}
 
 	return
 }
 
+// Scan calls the "visitor" for each non-expired session of the badger database,
+// it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	txn := db.Service.NewTransaction(false)
+	defer txn.Discard()
+
+	iter := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer iter.Close()
+
+	for iter.Rewind(); iter.Valid(); iter.Next() {
+		item := iter.Item()
+		b, err := item.Value()
+		if err != nil {
+			db.log().Errorf("error while trying to get the serialized session(%s) from the remote store: %v", item.Key(), err)
+			continue
+		}
+
+		storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, b)
+		if err != nil || storeDB.Lifetime.HasExpired() {
+			continue
+		}
+
+		if !visitor(string(item.Key()), storeDB) {
+			return
+		}
+	}
+}
+
 // Sync syncs the database with the session's (memory) store.
 func (db *Database) Sync(p sessions.SyncPayload) {
 	db.sync(p)
@@ -159,15 +213,15 @@ func (db *Database) sync(p sessions.SyncPayload) {
 
 	if p.Action == sessions.ActionDestroy {
 		if err := db.destroy(bsid); err != nil {
-			golog.Errorf("error while destroying a session(%s) from badger: %v",
+			db.log().Errorf("error while destroying a session(%s) from badger: %v",
 				p.SessionID, err)
 		}
 		return
 	}
 
-	s, err := p.Store.Serialize()
+	s, err := p.Store.SerializeWith(db.transcoder)
 	if err != nil {
-		golog.Errorf("error while serializing the remote store: %v", err)
+		db.log().Errorf("error while serializing the remote store: %v", err)
 	}
 
 	txn := db.Service.NewTransaction(true)
@@ -175,11 +229,11 @@ func (db *Database) sync(p sessions.SyncPayload) {
 	err = txn.Set(bsid, s, 0x00)
 	if err != nil {
 		txn.Discard()
-		golog.Errorf("error while trying to save the session(%s) to the database: %v", p.SessionID, err)
+		db.log().Errorf("error while trying to save the session(%s) to the database: %v", p.SessionID, err)
 		return
 	}
 	if err := txn.Commit(nil); err != nil { // Commit will call the Discard automatically.
-		golog.Errorf("error while committing the session(%s) changes to the database: %v", p.SessionID, err)
+		db.log().Errorf("error while committing the session(%s) changes to the database: %v", p.SessionID, err)
 	}
 }
 
@@ -202,7 +256,7 @@ func (db *Database) Close() error {
 func closeDB(db *Database) error {
 	err := db.Service.Close()
 	if err != nil {
-		golog.Warnf("closing the badger connection: %v", err)
+		db.log().Warnf("closing the badger connection: %v", err)
 	}
 	return err
 }
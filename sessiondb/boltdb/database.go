@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/boltdb/bolt"
@@ -26,8 +27,26 @@ type Database struct {
 	// Service is the underline BoltDB database connection,
 	// it's initialized at `New` or `NewFromDB`.
 	// Can be used to get stats.
-	Service *bolt.DB
-	async   bool
+	Service    *bolt.DB
+	async      bool
+	transcoder sessions.Transcoder
+	// pending waits the asynchronous writes on `Close`.
+	pending sync.WaitGroup
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
 }
 
 var (
@@ -97,14 +116,14 @@ func (db *Database) Cleanup() error {
 				continue
 			}
 
-			storeDB, err := sessions.DecodeRemoteStore(v)
+			storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, v)
 			if err != nil {
 				continue
 			}
 
 			if storeDB.Lifetime.HasExpired() {
 				if err := c.Delete(); err != nil {
-					golog.Warnf("troubles when cleanup a session remote store from BoltDB: %v", err)
+					db.log().Warnf("troubles when cleanup a session remote store from BoltDB: %v", err)
 				}
 			}
 		}
@@ -122,6 +141,15 @@ func (db *Database) Async(useGoRoutines bool) *Database {
 	return db
 }
 
+// Transcoder sets the transcoder which is used to serialize and deserialize
+// the sessions of this database, i.e a `sessions.CompressionTranscoder`
+// to reduce the size of the database.
+// Defaults to nil, the `sessions.DefaultTranscoder` is used instead.
+func (db *Database) Transcoder(t sessions.Transcoder) *Database {
+	db.transcoder = t
+	return db
+}
+
 // Load loads the sessions from the BoltDB(file-based) session storage.
 func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 	bsid := []byte(sid)
@@ -135,7 +163,7 @@ func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 			}
 
 			if bytes.Equal(k, bsid) { // session id should be the name of the key-value pair
-				storeDB, err = sessions.DecodeRemoteStore(v) // decode the whole value, as a remote store
+				storeDB, err = sessions.DecodeRemoteStoreWith(db.transcoder, v) // decode the whole value, as a remote store
 				break
 			}
 		}
@@ -143,16 +171,82 @@ func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 	})
 
 	if err != nil {
-		golog.Errorf("error while trying to load from the remote store: %v", err)
+		db.log().Errorf("error while trying to load from the remote store: %v", err)
 	}
 
 	return
 }
 
+// scanBatchSize is the number of the sessions which are read by a single transaction of the `Scan`.
+const scanBatchSize = 100
+
+// Scan calls the "visitor" for each non-expired session of the BoltDB table,
+// it implements the `sessions.Scanner`.
+// The sessions are read in batches and the visitor is called outside of the read transaction,
+// so it can write to the database, i.e destroy the visited sessions.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	type stored struct {
+		sid   string
+		store sessions.RemoteStore
+	}
+
+	var after []byte // the last read key.
+	for {
+		batch := make([]stored, 0, scanBatchSize)
+		done := false
+
+		err := db.Service.View(func(tx *bolt.Tx) error {
+			c := db.getBucket(tx).Cursor()
+			k, v := c.First()
+			if after != nil {
+				if k, v = c.Seek(after); k != nil && bytes.Equal(k, after) {
+					k, v = c.Next()
+				}
+			}
+
+			for ; k != nil && len(batch) < scanBatchSize; k, v = c.Next() {
+				after = append(after[:0], k...)
+				if len(k) == 0 { // empty key, continue to the next pair
+					continue
+				}
+
+				storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, v)
+				if err != nil || storeDB.Lifetime.HasExpired() {
+					continue
+				}
+
+				batch = append(batch, stored{sid: string(k), store: storeDB})
+			}
+
+			done = k == nil
+			return nil
+		})
+
+		if err != nil {
+			db.log().Errorf("error while scanning the remote stores: %v", err)
+			return
+		}
+
+		for _, s := range batch {
+			if !visitor(s.sid, s.store) {
+				return
+			}
+		}
+
+		if done {
+			return
+		}
+	}
+}
+
 // Sync syncs the database with the session's (memory) store.
 func (db *Database) Sync(p sessions.SyncPayload) {
 	if db.async {
-		go db.sync(p)
+		db.pending.Add(1)
+		go func() {
+			db.sync(p)
+			db.pending.Done()
+		}()
 	} else {
 		db.sync(p)
 	}
@@ -163,22 +257,22 @@ func (db *Database) sync(p sessions.SyncPayload) {
 
 	if p.Action == sessions.ActionDestroy {
 		if err := db.destroy(bsid); err != nil {
-			golog.Errorf("error while destroying a session(%s) from boltdb: %v",
+			db.log().Errorf("error while destroying a session(%s) from boltdb: %v",
 				p.SessionID, err)
 		}
 		return
 	}
 
-	s, err := p.Store.Serialize()
+	s, err := p.Store.SerializeWith(db.transcoder)
 	if err != nil {
-		golog.Errorf("error while serializing the remote store: %v", err)
+		db.log().Errorf("error while serializing the remote store: %v", err)
 	}
 
 	err = db.Service.Update(func(tx *bolt.Tx) error {
 		return db.getBucket(tx).Put(bsid, s)
 	})
 	if err != nil {
-		golog.Errorf("error while writing the session bucket: %v", err)
+		db.log().Errorf("error while writing the session bucket: %v", err)
 	}
 }
 
@@ -221,15 +315,17 @@ func (db *Database) Len() (num int) {
 	return
 }
 
-// Close shutdowns the BoltDB connection.
+// Close waits for the pending asynchronous writes, if any,
+// and shutdowns the BoltDB connection.
 func (db *Database) Close() error {
+	db.pending.Wait()
 	return closeDB(db)
 }
 
 func closeDB(db *Database) error {
 	err := db.Service.Close()
 	if err != nil {
-		golog.Warnf("closing the BoltDB connection: %v", err)
+		db.log().Warnf("closing the BoltDB connection: %v", err)
 	}
 
 	return err
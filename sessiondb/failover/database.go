@@ -0,0 +1,421 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/golog"
+)
+
+// DefaultCheckInterval is the default interval of the health checks and the re-syncs of the primary database.
+const DefaultCheckInterval = time.Second
+
+var (
+	// ErrDatabaseMissing returned on `New` when the primary or the secondary database is nil.
+	ErrDatabaseMissing = errors.New("failover: the primary and the secondary databases are required")
+	// ErrPrimaryUnchecked returned on `New` when the failures of the primary database can't be detected,
+	// it implements neither the `FallibleDatabase` nor the `sessions.Pinger`.
+	ErrPrimaryUnchecked = errors.New("failover: the primary database should implement the failover.FallibleDatabase or the sessions.Pinger")
+	// ErrPrimaryDown returned by the `Database#Ping` while the primary database is down, see `Database#Down`.
+	ErrPrimaryDown = errors.New("failover: the primary database is down")
+)
+
+// FallibleDatabase is an optional interface of a primary database which reports its failed loads and writes,
+// i.e the redis database, so the `Database` falls back to the secondary on the first failure.
+// Without it the failures of the primary are detected by its `sessions.Pinger`, on the `Options#CheckInterval`.
+type FallibleDatabase interface {
+	// TryLoad same as `Load` but it returns the error of the database,
+	// the missing sessions are returned as empty stores without an error.
+	TryLoad(sid string) (sessions.RemoteStore, error)
+	// TrySync same as `Sync` but it writes the session synchronously and it returns the error of the database.
+	TrySync(p sessions.SyncPayload) error
+}
+
+// Options are the options of the failover database.
+type Options struct {
+	// CheckInterval is the interval of the pings of the primary, if it implements the `sessions.Pinger`,
+	// and of the attempts to re-sync it while it's down.
+	//
+	// Defaults to the `DefaultCheckInterval`
+	CheckInterval time.Duration
+}
+
+// Database is a failover session database, the sessions are loaded from and written to the primary database,
+// i.e a `redis.Database`, and when it fails they are loaded from and written to the secondary one,
+// i.e a `memory.Database`, so a blip of the primary doesn't log the users out.
+//
+// The sessions which are written while the primary is down are re-synced to it when it recovers,
+// the database switches back to the primary after all of them are written, see `Options#CheckInterval`.
+// The secondary keeps the sessions of the outage only, they are removed from it after their re-sync.
+type Database struct {
+	primary   sessions.Database
+	secondary sessions.Database
+	opts      Options
+
+	// down is 1 while the primary is down, it's changed under the "mu".
+	down uint32
+	mu   sync.Mutex
+	// dirty are the sessions which were written to the secondary while the primary is down,
+	// by the generation of their latest write, so a session re-written during its re-sync is not dropped.
+	dirty map[string]uint64
+	gen   uint64
+
+	closeOnce sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+	// logger logs the failovers and the recoveries, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// New returns a new failover database of the "primary" with the "secondary" as its fallback.
+// The primary should implement the `FallibleDatabase` or the `sessions.Pinger`.
+func New(primary, secondary sessions.Database, opts ...Options) (*Database, error) {
+	if primary == nil || secondary == nil {
+		return nil, ErrDatabaseMissing
+	}
+
+	_, fallible := primary.(FallibleDatabase)
+	_, pinger := primary.(sessions.Pinger)
+	if !fallible && !pinger {
+		return nil, ErrPrimaryUnchecked
+	}
+
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = DefaultCheckInterval
+	}
+
+	db := &Database{
+		primary:   primary,
+		secondary: secondary,
+		opts:      o,
+		dirty:     make(map[string]uint64),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	go db.run()
+	return db, nil
+}
+
+// SetLogger sets the logger of the failovers and the recoveries, the manager's `Config#Logger` is set on its `UseDatabase`.
+// It's set to the primary and the secondary too, if they implement the `sessions.LoggerSetter`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+
+	for _, inner := range []sessions.Database{db.primary, db.secondary} {
+		if setter, ok := inner.(sessions.LoggerSetter); ok {
+			setter.SetLogger(logger)
+		}
+	}
+}
+
+// SetClock sets the manager's `sessions.Config#Clock`, on its `UseDatabase`,
+// to the primary and the secondary, if they implement the `sessions.ClockSetter`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	for _, inner := range []sessions.Database{db.primary, db.secondary} {
+		if setter, ok := inner.(sessions.ClockSetter); ok {
+			setter.SetClock(clock)
+		}
+	}
+}
+
+// SetEvictionNotifier sets the manager's notifier of the evicted sessions, on its `UseDatabase`,
+// to the primary and the secondary, if they implement the `sessions.EvictionNotifierSetter`.
+func (db *Database) SetEvictionNotifier(notify func(sid string, reason sessions.EvictionReason, snapshot sessions.Store)) {
+	for _, inner := range []sessions.Database{db.primary, db.secondary} {
+		if setter, ok := inner.(sessions.EvictionNotifierSetter); ok {
+			setter.SetEvictionNotifier(notify)
+		}
+	}
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// Down reports whether the primary is down, the sessions are served by the secondary meanwhile.
+func (db *Database) Down() bool {
+	return atomic.LoadUint32(&db.down) == 1
+}
+
+func (db *Database) fail(err error) {
+	db.mu.Lock()
+	if db.down == 0 {
+		atomic.StoreUint32(&db.down, 1)
+		db.log().Warnf("failover: the primary database is down, the sessions fall back to the secondary: %v", err)
+	}
+	db.mu.Unlock()
+}
+
+func (db *Database) run() {
+	defer close(db.stopped)
+
+	ticker := time.NewTicker(db.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+		}
+
+		if db.Down() {
+			db.recover()
+		} else if err := db.ping(); err != nil {
+			db.fail(err)
+		}
+	}
+}
+
+func (db *Database) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), db.opts.CheckInterval)
+	defer cancel()
+	return sessions.PingDatabase(ctx, db.primary)
+}
+
+func (db *Database) tryLoad(sid string) (sessions.RemoteStore, error) {
+	if fallible, ok := db.primary.(FallibleDatabase); ok {
+		return fallible.TryLoad(sid)
+	}
+	return db.primary.Load(sid), nil
+}
+
+func (db *Database) trySync(p sessions.SyncPayload) error {
+	if fallible, ok := db.primary.(FallibleDatabase); ok {
+		return fallible.TrySync(p)
+	}
+
+	db.primary.Sync(p)
+	return nil
+}
+
+// recover re-syncs the sessions of the outage to the primary
+// and switches back to it, it stays down if any of them fails.
+func (db *Database) recover() {
+	if err := db.ping(); err != nil {
+		return
+	}
+
+	for {
+		db.mu.Lock()
+		if len(db.dirty) == 0 {
+			atomic.StoreUint32(&db.down, 0)
+			db.mu.Unlock()
+			db.log().Warnf("failover: the primary database is up, the sessions are re-synced to it")
+			return
+		}
+
+		dirty := make(map[string]uint64, len(db.dirty))
+		for sid, gen := range db.dirty {
+			dirty[sid] = gen
+		}
+		db.mu.Unlock()
+
+		for sid, gen := range dirty {
+			p, err := db.resync(sid)
+			if err == nil {
+				err = db.trySync(p)
+			}
+			if err != nil {
+				db.log().Errorf("failover: error while re-syncing the session(%s) to the primary database: %v", sid, err)
+				return
+			}
+
+			db.mu.Lock()
+			if db.dirty[sid] == gen {
+				// it's not re-written meanwhile, the primary has its latest state.
+				delete(db.dirty, sid)
+				db.secondary.Sync(sessions.SyncPayload{SessionID: sid, Action: sessions.ActionDestroy})
+			}
+			db.mu.Unlock()
+		}
+	}
+}
+
+// resync returns the write of the outage's copy of the session to the primary.
+// The copy replaces the primary's session if it's a later version of it,
+// otherwise it was written without the primary's session, i.e the app was restarted during the outage,
+// and its values are merged to the primary's ones, so they are not lost.
+func (db *Database) resync(sid string) (sessions.SyncPayload, error) {
+	store := db.secondary.Load(sid)
+	if (len(store.Values) == 0 && store.Lifetime.IsZero()) || store.Lifetime.HasExpired() {
+		return sessions.SyncPayload{SessionID: sid, Action: sessions.ActionDestroy}, nil
+	}
+
+	primary, err := db.tryLoad(sid)
+	if err != nil {
+		return sessions.SyncPayload{}, err
+	}
+
+	if len(primary.Values) > 0 && store.Version <= primary.Version {
+		values := append(sessions.Store(nil), primary.Values...)
+		for _, entry := range store.Values {
+			values.Save(entry.Key, entry.ValueRaw, entry.Immutable())
+		}
+		store.Values = values
+
+		if primary.Lifetime.After(store.Lifetime.Time) {
+			store.Lifetime = primary.Lifetime
+		}
+		if !primary.CreatedAt.IsZero() && (store.CreatedAt.IsZero() || primary.CreatedAt.Before(store.CreatedAt)) {
+			store.CreatedAt = primary.CreatedAt
+		}
+		if primary.Extensions > store.Extensions {
+			store.Extensions = primary.Extensions
+		}
+		store.Version = primary.Version + 1
+	}
+
+	return sessions.SyncPayload{SessionID: sid, Action: sessions.ActionInsert, Store: store}, nil
+}
+
+// Load loads the session from the primary, or from the secondary while the primary is down.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	if !db.Down() {
+		store, err := db.tryLoad(sid)
+		if err == nil {
+			return store
+		}
+		db.fail(err)
+	}
+
+	return db.secondary.Load(sid)
+}
+
+// Sync writes the session to the primary, or to the secondary while the primary is down,
+// the latter are re-synced to the primary when it recovers.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	for {
+		if !db.Down() {
+			err := db.trySync(p)
+			if err == nil {
+				return
+			}
+			db.fail(err)
+		}
+
+		if db.syncSecondary(p) {
+			return
+		}
+	}
+}
+
+// SyncVersion same as `Sync` but the session is written to the primary by its `sessions.VersionedDatabase`,
+// the stored session is returned with the `sessions.ErrVersionConflict` on a conflict.
+// While the primary is down the session is written to the secondary, the last write wins,
+// it implements the `sessions.VersionedDatabase`.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	for {
+		if !db.Down() {
+			stored, err := sessions.SyncDatabaseVersion(db.primary, p)
+			if err == nil || err == sessions.ErrVersionConflict {
+				return stored, err
+			}
+			db.fail(err)
+		}
+
+		if db.syncSecondary(p) {
+			return sessions.RemoteStore{}, nil
+		}
+	}
+}
+
+// syncSecondary writes the session to the secondary and marks it for the re-sync,
+// it reports false if the primary is recovered meanwhile, the session should be written to the primary.
+func (db *Database) syncSecondary(p sessions.SyncPayload) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.down == 0 {
+		return false
+	}
+
+	db.secondary.Sync(p)
+	db.gen++
+	db.dirty[p.SessionID] = db.gen
+	return true
+}
+
+// Scan calls the "visitor" for each session of the primary, or of the secondary while the primary is down,
+// if it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	d := db.primary
+	if db.Down() {
+		d = db.secondary
+	}
+
+	if scanner, ok := d.(sessions.Scanner); ok {
+		scanner.Scan(visitor)
+	}
+}
+
+// Ping pings the primary and the secondary databases, those which implement the `sessions.Pinger`,
+// it returns the `ErrPrimaryDown` while the primary is down, even if the sessions are served by the secondary,
+// see `Down` and `Sessions#Healthy`.
+func (db *Database) Ping(ctx context.Context) error {
+	if err := sessions.PingDatabase(ctx, db.primary); err != nil {
+		return fmt.Errorf("failover: primary: %v", err)
+	}
+
+	if db.Down() {
+		return ErrPrimaryDown
+	}
+
+	if err := sessions.PingDatabase(ctx, db.secondary); err != nil {
+		return fmt.Errorf("failover: secondary: %v", err)
+	}
+	return nil
+}
+
+// Close stops the health checks, re-syncs the sessions of an outage if the primary is up again
+// and closes the primary and the secondary databases,
+// those which implement the `sessions.Shutdowner` or the `io.Closer`.
+func (db *Database) Close() error {
+	return db.Shutdown(context.Background())
+}
+
+// Shutdown same as `Close` but it returns the "ctx"'s error if its deadline passes first,
+// it implements the `sessions.Shutdowner`, the `Sessions#Close` calls it.
+func (db *Database) Shutdown(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		db.closeOnce.Do(func() {
+			close(db.done)
+			<-db.stopped
+		})
+
+		if db.Down() {
+			db.recover()
+		}
+
+		var firstErr error
+		for _, d := range []sessions.Database{db.primary, db.secondary} {
+			if err := sessions.CloseDatabase(ctx, d); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		errCh <- firstErr
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
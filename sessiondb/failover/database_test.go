@@ -0,0 +1,162 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+func TestSyncVersion(t *testing.T) {
+	primary, secondary := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(primary, secondary, Options{CheckInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err = db.SyncVersion(sessionstest.Insert("sid", "kataras", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := db.SyncVersion(sessionstest.Insert("sid", "makis", 1))
+	if err != sessions.ErrVersionConflict || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected a version conflict with the stored session of the primary but got %v, %v", err, stored.Values)
+	}
+
+	primary.FailSync(errors.New("connection refused"))
+	if _, err = db.SyncVersion(sessionstest.Insert("sid", "gerasimos", 2)); err != nil {
+		t.Fatalf("expected the session to be written to the secondary but got %v", err)
+	}
+
+	if !db.Down() {
+		t.Fatalf("expected the primary to be down")
+	}
+	if stored, _ := secondary.Stored("sid"); stored.Values.GetString("name") != "gerasimos" {
+		t.Fatalf("expected the session to be written to the secondary but got %v", stored.Values)
+	}
+}
+
+func newDatabase(t *testing.T) (*Database, *sessionstest.Database, *sessionstest.Database) {
+	primary, secondary := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(primary, secondary, Options{CheckInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, primary, secondary
+}
+
+func TestFallback(t *testing.T) {
+	db, primary, secondary := newDatabase(t)
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 1))
+	if err := db.Ping(context.Background()); err != nil {
+		t.Fatalf("expected the databases to be healthy but got %v", err)
+	}
+
+	primary.FailSync(errors.New("connection refused"))
+	if loaded := db.Load("sid"); len(loaded.Values) != 0 || !db.Down() {
+		t.Fatalf("expected the failed load to fall back to the empty secondary but got %v", loaded.Values)
+	}
+	if err := db.Ping(context.Background()); err != ErrPrimaryDown {
+		t.Fatalf("expected the primary to be reported down but got %v", err)
+	}
+
+	db.Sync(sessionstest.Insert("sid", "makis", 2))
+	if _, ok := secondary.Stored("sid"); !ok {
+		t.Fatalf("expected the session to be written to the secondary")
+	}
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the session to be loaded from the secondary but got %v", loaded.Values)
+	}
+
+	primary.FailPing(errors.New("connection refused"))
+	if err := db.Ping(context.Background()); err == nil {
+		t.Fatalf("expected the ping error of the primary")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	db, primary, secondary := newDatabase(t)
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("destroyed", "kataras", 1))
+
+	primary.FailSync(errors.New("connection refused"))
+	db.Sync(sessionstest.Insert("sid", "kataras", 1))
+	db.Sync(sessions.SyncPayload{SessionID: "destroyed", Action: sessions.ActionDestroy})
+
+	// still down.
+	db.recover()
+	if !db.Down() {
+		t.Fatalf("expected the primary to stay down while its writes fail")
+	}
+
+	primary.FailSync(nil)
+	db.recover()
+	if db.Down() {
+		t.Fatalf("expected the primary to be up after the re-sync")
+	}
+
+	if stored, ok := primary.Stored("sid"); !ok || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session of the outage to be re-synced to the primary but got %v", stored.Values)
+	}
+	if _, ok := primary.Stored("destroyed"); ok {
+		t.Fatalf("expected the session which was destroyed during the outage to be removed from the primary")
+	}
+	if _, ok := secondary.Stored("sid"); ok {
+		t.Fatalf("expected the re-synced session to be removed from the secondary")
+	}
+}
+
+func TestRecoverMerge(t *testing.T) {
+	db, primary, _ := newDatabase(t)
+	defer db.Close()
+
+	p := sessionstest.Insert("sid", "kataras", 3)
+	p.Store.Values.Set("cart", 2)
+	db.Sync(p)
+
+	// the app is restarted during the outage, the session is written without its stored values.
+	primary.FailSync(errors.New("connection refused"))
+	var values sessions.Store
+	values.Set("theme", "dark")
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionCreate, Store: sessions.RemoteStore{Values: values, Version: 1}})
+
+	primary.FailSync(nil)
+	db.recover()
+
+	stored, _ := primary.Stored("sid")
+	cart, _ := stored.Values.GetIntDefault("cart", 0)
+	if stored.Values.GetString("name") != "kataras" || cart != 2 || stored.Values.GetString("theme") != "dark" {
+		t.Fatalf("expected the values of the outage to be merged to the primary's session but got %v", stored.Values)
+	}
+	if stored.Version != 4 {
+		t.Fatalf("expected the next version of the primary's session but got %d", stored.Version)
+	}
+}
+
+func TestRecoverLaterVersion(t *testing.T) {
+	db, primary, _ := newDatabase(t)
+	defer db.Close()
+
+	p := sessionstest.Insert("sid", "kataras", 1)
+	p.Store.Values.Set("cart", 2)
+	db.Sync(p)
+
+	// the session of the primary is modified during the outage, its cart is removed.
+	primary.FailSync(errors.New("connection refused"))
+	db.Sync(sessionstest.Insert("sid", "makis", 2))
+
+	primary.FailSync(nil)
+	db.recover()
+
+	stored, _ := primary.Stored("sid")
+	if stored.Values.GetString("name") != "makis" || stored.Values.Exists("cart") {
+		t.Fatalf("expected the later version of the outage to replace the primary's session but got %v", stored.Values)
+	}
+}
@@ -1,10 +1,12 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/kataras/go-sessions"
 	"github.com/kataras/golog"
@@ -33,7 +35,25 @@ type Database struct {
 	// append or re-write a file
 	// create a file
 	// remove a file
-	async bool
+	async      bool
+	transcoder sessions.Transcoder
+	// pending waits the asynchronous writes on `Close`.
+	pending sync.WaitGroup
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
 }
 
 // New creates and returns a new file-storage database instance based on the "directoryPath".
@@ -94,6 +114,15 @@ func (db *Database) Async(useGoRoutines bool) *Database {
 	return db
 }
 
+// Transcoder sets the transcoder which is used to serialize and deserialize
+// the sessions of this database, i.e a `sessions.CompressionTranscoder`
+// to reduce the size of the session files.
+// Defaults to nil, the `sessions.DefaultTranscoder` is used instead.
+func (db *Database) Transcoder(t sessions.Transcoder) *Database {
+	db.transcoder = t
+	return db
+}
+
 func (db *Database) sessPath(sid string) string {
 	return filepath.Join(db.dir, sid)
 }
@@ -103,11 +132,37 @@ func (db *Database) Load(sid string) sessions.RemoteStore {
 	sessPath := db.sessPath(sid)
 	store, err := db.load(sessPath)
 	if err != nil {
-		golog.Error(err.Error())
+		db.log().Errorf("%v", err)
 	}
 	return store
 }
 
+// Scan calls the "visitor" for each non-expired session file of the directory,
+// it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	files, err := ioutil.ReadDir(db.dir)
+	if err != nil {
+		db.log().Errorf("error while reading the sessions directory: %v", err)
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		storeDB, err := db.load(db.sessPath(file.Name()))
+		if err != nil || storeDB.Lifetime.HasExpired() || (len(storeDB.Values) == 0 && storeDB.Lifetime.IsZero()) {
+			// not a session file.
+			continue
+		}
+
+		if !visitor(file.Name(), storeDB) {
+			return
+		}
+	}
+}
+
 func (db *Database) load(fileName string) (storeDB sessions.RemoteStore, loadErr error) {
 	f, err := os.OpenFile(fileName, os.O_RDONLY, db.fileMode)
 
@@ -125,7 +180,7 @@ func (db *Database) load(fileName string) (storeDB sessions.RemoteStore, loadErr
 		return
 	}
 
-	storeDB, err = sessions.DecodeRemoteStore(contents)
+	storeDB, err = sessions.DecodeRemoteStoreWith(db.transcoder, contents)
 
 	if err != nil { // we care for this error only
 		loadErr = fmt.Errorf("load error: %v", err)
@@ -138,7 +193,11 @@ func (db *Database) load(fileName string) (storeDB sessions.RemoteStore, loadErr
 // Sync syncs the database.
 func (db *Database) Sync(p sessions.SyncPayload) {
 	if db.async {
-		go db.sync(p)
+		db.pending.Add(1)
+		go func() {
+			db.sync(p)
+			db.pending.Done()
+		}()
 	} else {
 		db.sync(p)
 	}
@@ -149,13 +208,13 @@ func (db *Database) sync(p sessions.SyncPayload) {
 	// if destroy then remove the file from the disk
 	if p.Action == sessions.ActionDestroy {
 		if err := db.destroy(p.SessionID); err != nil {
-			golog.Errorf("error while destroying and removing the session file: %v", err)
+			db.log().Errorf("error while destroying and removing the session file: %v", err)
 		}
 		return
 	}
 
 	if err := db.override(p.SessionID, p.Store); err != nil {
-		golog.Errorf("error while writing the session file: %v", err)
+		db.log().Errorf("error while writing the session file: %v", err)
 	}
 
 }
@@ -195,7 +254,7 @@ func (db *Database) sync(p sessions.SyncPayload) {
 
 // on update, remove and clear, it re-writes the file to the current values(may empty).
 func (db *Database) override(sid string, store sessions.RemoteStore) error {
-	s, err := store.Serialize()
+	s, err := store.SerializeWith(db.transcoder)
 	if err != nil {
 		return err
 	}
@@ -206,6 +265,26 @@ func (db *Database) override(sid string, store sessions.RemoteStore) error {
 	)
 }
 
+// Ping reports whether the sessions' directory is accessible, it implements the `sessions.Pinger`,
+// see `Sessions#Healthy`.
+func (db *Database) Ping(ctx context.Context) error {
+	info, err := os.Stat(db.dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("file: %s is not a directory", db.dir)
+	}
+	return ctx.Err()
+}
+
+// Close waits for the pending asynchronous writes, if any.
+func (db *Database) Close() error {
+	db.pending.Wait()
+	return nil
+}
+
 // on destroy, it removes the file
 func (db *Database) destroy(sid string) error {
 	return db.expireSess(sid)
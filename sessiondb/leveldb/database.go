@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"runtime"
+	"sync"
 
 	"github.com/kataras/go-sessions"
 	"github.com/kataras/golog"
@@ -26,8 +27,26 @@ type Database struct {
 	// Service is the underline LevelDB database connection,
 	// it's initialized at `New` or `NewFromDB`.
 	// Can be used to get stats.
-	Service *leveldb.DB
-	async   bool
+	Service    *leveldb.DB
+	async      bool
+	transcoder sessions.Transcoder
+	// pending waits the asynchronous writes on `Close`.
+	pending sync.WaitGroup
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
 }
 
 // New creates and returns a new LevelDB(file-based) storage
@@ -81,14 +100,14 @@ func (db *Database) Cleanup() error {
 
 		if len(k) > 0 {
 			v := iter.Value()
-			storeDB, err := sessions.DecodeRemoteStore(v)
+			storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, v)
 			if err != nil {
 				continue
 			}
 
 			if storeDB.Lifetime.HasExpired() {
 				if err := db.Service.Delete(k, WriteOptions); err != nil {
-					golog.Warnf("troubles when cleanup a session remote store from LevelDB: %v", err)
+					db.log().Warnf("troubles when cleanup a session remote store from LevelDB: %v", err)
 				}
 			}
 		}
@@ -105,6 +124,15 @@ func (db *Database) Async(useGoRoutines bool) *Database {
 	return db
 }
 
+// Transcoder sets the transcoder which is used to serialize and deserialize
+// the sessions of this database, i.e a `sessions.CompressionTranscoder`
+// to reduce the size of the database.
+// Defaults to nil, the `sessions.DefaultTranscoder` is used instead.
+func (db *Database) Transcoder(t sessions.Transcoder) *Database {
+	db.transcoder = t
+	return db
+}
+
 // Load loads the sessions from the LevelDB(file-based) session storage.
 func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 	bsid := []byte(sid)
@@ -118,9 +146,9 @@ func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 		if len(k) > 0 {
 			v := iter.Value()
 			if bytes.Equal(k, bsid) { // session id should be the name of the key-value pair
-				store, err := sessions.DecodeRemoteStore(v) // decode the whole value, as a remote store
+				store, err := sessions.DecodeRemoteStoreWith(db.transcoder, v) // decode the whole value, as a remote store
 				if err != nil {
-					golog.Errorf("error while trying to load from the remote store: %v", err)
+					db.log().Errorf("error while trying to load from the remote store: %v", err)
 				} else {
 					storeDB = store
 				}
@@ -132,16 +160,46 @@ func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
 
 	iter.Release()
 	if err := iter.Error(); err != nil {
-		golog.Errorf("error while trying to iterate over the database: %v", err)
+		db.log().Errorf("error while trying to iterate over the database: %v", err)
 	}
 
 	return
 }
 
+// Scan calls the "visitor" for each non-expired session of the LevelDB database,
+// it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	iter := db.Service.NewIterator(nil, ReadOptions)
+	for iter.Next() {
+		k := iter.Key()
+		if len(k) == 0 {
+			continue
+		}
+
+		storeDB, err := sessions.DecodeRemoteStoreWith(db.transcoder, iter.Value())
+		if err != nil || storeDB.Lifetime.HasExpired() {
+			continue
+		}
+
+		if !visitor(string(k), storeDB) {
+			break
+		}
+	}
+
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		db.log().Errorf("error while trying to iterate over the database: %v", err)
+	}
+}
+
 // Sync syncs the database with the session's (memory) store.
 func (db *Database) Sync(p sessions.SyncPayload) {
 	if db.async {
-		go db.sync(p)
+		db.pending.Add(1)
+		go func() {
+			db.sync(p)
+			db.pending.Done()
+		}()
 	} else {
 		db.sync(p)
 	}
@@ -152,21 +210,21 @@ func (db *Database) sync(p sessions.SyncPayload) {
 
 	if p.Action == sessions.ActionDestroy {
 		if err := db.destroy(bsid); err != nil {
-			golog.Errorf("error while destroying a session(%s) from leveldb: %v",
+			db.log().Errorf("error while destroying a session(%s) from leveldb: %v",
 				p.SessionID, err)
 		}
 		return
 	}
 
-	s, err := p.Store.Serialize()
+	s, err := p.Store.SerializeWith(db.transcoder)
 	if err != nil {
-		golog.Errorf("error while serializing the remote store: %v", err)
+		db.log().Errorf("error while serializing the remote store: %v", err)
 	}
 
 	err = db.Service.Put(bsid, s, WriteOptions)
 
 	if err != nil {
-		golog.Errorf("error while writing the session(%s) to the database: %v", p.SessionID, err)
+		db.log().Errorf("error while writing the session(%s) to the database: %v", p.SessionID, err)
 	}
 }
 
@@ -174,15 +232,17 @@ func (db *Database) destroy(bsid []byte) error {
 	return db.Service.Delete(bsid, WriteOptions)
 }
 
-// Close shutdowns the LevelDB connection.
+// Close waits for the pending asynchronous writes, if any,
+// and shutdowns the LevelDB connection.
 func (db *Database) Close() error {
+	db.pending.Wait()
 	return closeDB(db)
 }
 
 func closeDB(db *Database) error {
 	err := db.Service.Close()
 	if err != nil {
-		golog.Warnf("closing the LevelDB connection: %v", err)
+		db.log().Warnf("closing the LevelDB connection: %v", err)
 	}
 
 	return err
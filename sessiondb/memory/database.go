@@ -0,0 +1,411 @@
+package memory
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/golog"
+)
+
+const (
+	// DefaultGCInterval is the default interval of the garbage collection of the expired sessions.
+	DefaultGCInterval = time.Minute
+	// DefaultGCBatchSize is the default maximum number of sessions which are evicted under a single lock.
+	DefaultGCBatchSize = 1000
+)
+
+// Options are the garbage collection options of the memory database.
+type Options struct {
+	// Interval is the interval of the garbage collection of the expired sessions,
+	// a negative value disables the background collection, the `Database#GC` can be called manually.
+	//
+	// Defaults to the `DefaultGCInterval`
+	Interval time.Duration
+	// BatchSize is the maximum number of the expired sessions which are evicted under a single lock,
+	// the lock is released between the batches so the requests are not blocked by a large collection.
+	//
+	// Defaults to the `DefaultGCBatchSize`
+	BatchSize int
+	// Jitter is the maximum random duration which is added to each interval,
+	// so the collections of many app instances don't happen at the same time.
+	//
+	// Defaults to 0
+	Jitter time.Duration
+	// OnCollect if not nil is called after each collection with the number of the reclaimed sessions.
+	//
+	// Defaults to nil
+	OnCollect func(reclaimed int)
+	// Clock is the source of the current time and of the collection's timer,
+	// the manager's `sessions.Config#Clock` is set on its `UseDatabase`, see `SetClock`.
+	//
+	// Defaults to the `sessions.SystemClock`
+	Clock sessions.Clock
+
+	// MaxSessions is the maximum number of the stored sessions,
+	// the least recently used sessions are evicted when it's exceeded.
+	//
+	// Defaults to 0, unlimited
+	MaxSessions int
+	// MaxBytes is the maximum total size of the stored sessions, by their serialized size,
+	// the least recently used sessions are evicted when it's exceeded.
+	//
+	// Defaults to 0, unlimited
+	MaxBytes int64
+}
+
+// entry is a stored session, an element of the recently used list.
+type entry struct {
+	sid   string
+	store sessions.RemoteStore
+	size  int64
+}
+
+// Database is an in-memory session database, the sessions are kept in memory and they are lost on restart.
+// It's useful to keep the sessions which are released by the manager's memory
+// and for tests, without an external service.
+//
+// The expired sessions are evicted by a background goroutine, see `Options`,
+// so a database with a lot of abandoned sessions doesn't grow until the process restarts.
+// Its capacity can be bounded too, i.e so a burst of anonymous traffic can't exhaust the memory,
+// the least recently used sessions are evicted first, see `Options#MaxSessions` and `Options#MaxBytes`.
+type Database struct {
+	mu       sync.Mutex
+	sessions map[string]*list.Element
+	recent   *list.List // the most recently used first.
+	size     int64
+
+	opts      Options
+	reclaimed uint64 // atomic.
+	closeOnce sync.Once
+	done      chan struct{}
+	// timer is the timer of the next collection, it's guarded by the "mu".
+	timer sessions.Timer
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+	// notify reports the evictions of the capacity, see `SetEvictionNotifier`, it's guarded by the "mu".
+	notify func(sid string, reason sessions.EvictionReason, snapshot sessions.Store)
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// New creates and returns a new memory database and starts its garbage collection, see `Options`.
+// Call the `Close` to stop the collection.
+func New(opts ...Options) *Database {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Interval == 0 {
+		o.Interval = DefaultGCInterval
+	}
+
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultGCBatchSize
+	}
+
+	if o.Clock == nil {
+		o.Clock = sessions.SystemClock
+	}
+
+	db := &Database{
+		sessions: make(map[string]*list.Element),
+		recent:   list.New(),
+		opts:     o,
+		done:     make(chan struct{}),
+	}
+
+	db.mu.Lock()
+	db.schedule()
+	db.mu.Unlock()
+
+	return db
+}
+
+// SetEvictionNotifier sets the notifier of the sessions which are evicted to keep the database in its capacity,
+// see `Options#MaxSessions` and `Options#MaxBytes`, they are reported with the `sessions.EvictionCapacity` reason,
+// the expired sessions are not reported. The manager's `sessions.Sessions#OnEvict` callbacks are set on its `UseDatabase`.
+func (db *Database) SetEvictionNotifier(notify func(sid string, reason sessions.EvictionReason, snapshot sessions.Store)) {
+	db.mu.Lock()
+	db.notify = notify
+	db.mu.Unlock()
+}
+
+// SetClock sets the clock of the database, the manager's `sessions.Config#Clock` is set on its `UseDatabase`,
+// the next collection is scheduled by the "clock", see `Options#Clock`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	db.mu.Lock()
+	if db.timer != nil {
+		db.timer.Stop()
+	}
+	db.opts.Clock = clock
+	db.schedule()
+	db.mu.Unlock()
+}
+
+// schedule schedules the next collection, if the background collection is enabled and the database is not closed.
+// The caller should hold the lock.
+func (db *Database) schedule() {
+	db.timer = nil
+	if db.opts.Interval <= 0 {
+		return
+	}
+
+	select {
+	case <-db.done:
+		return
+	default:
+	}
+
+	d := db.opts.Interval
+	if jitter := db.opts.Jitter; jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(jitter)))
+	}
+
+	var t sessions.Timer
+	t = db.opts.Clock.NewTimer(d, func() {
+		db.GC()
+
+		db.mu.Lock()
+		// the timer is replaced by the `SetClock`.
+		if db.timer == t {
+			db.schedule()
+		}
+		db.mu.Unlock()
+	})
+	db.timer = t
+}
+
+// expired reports whether the "store" is expired, by the `Options#Clock`, the caller should hold the lock.
+func (db *Database) expired(store sessions.RemoteStore) bool {
+	return !store.Lifetime.IsZero() && store.Lifetime.Time.Before(db.opts.Clock.Now())
+}
+
+// GC evicts the expired sessions, in batches, and returns the number of the reclaimed sessions.
+// It's called by the background collection, see `Options#Interval`.
+func (db *Database) GC() int {
+	reclaimed := 0
+
+	for {
+		n := 0
+		db.mu.Lock()
+		for _, elem := range db.sessions {
+			if !db.expired(elem.Value.(*entry).store) {
+				continue
+			}
+
+			db.remove(elem)
+			if n++; n == db.opts.BatchSize {
+				break
+			}
+		}
+		db.mu.Unlock()
+
+		reclaimed += n
+		if n < db.opts.BatchSize {
+			break
+		}
+	}
+
+	atomic.AddUint64(&db.reclaimed, uint64(reclaimed))
+	if cb := db.opts.OnCollect; cb != nil {
+		cb(reclaimed)
+	}
+
+	return reclaimed
+}
+
+// Reclaimed returns the total number of the sessions which have been evicted by the garbage collection.
+func (db *Database) Reclaimed() uint64 {
+	return atomic.LoadUint64(&db.reclaimed)
+}
+
+// Len returns the number of the stored sessions, the expired ones which are not collected yet are included.
+func (db *Database) Len() int {
+	db.mu.Lock()
+	n := len(db.sessions)
+	db.mu.Unlock()
+	return n
+}
+
+// Size returns the total serialized size of the stored sessions,
+// it's measured only if the `Options#MaxBytes` is set.
+func (db *Database) Size() int64 {
+	db.mu.Lock()
+	n := db.size
+	db.mu.Unlock()
+	return n
+}
+
+// remove removes the "elem" session, the caller should hold the lock.
+func (db *Database) remove(elem *list.Element) *entry {
+	e := db.recent.Remove(elem).(*entry)
+	delete(db.sessions, e.sid)
+	db.size -= e.size
+	return e
+}
+
+// Load returns a copy of the stored session of the "sid", an empty store if it's missing or expired.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	db.mu.Lock()
+	elem, ok := db.sessions[sid]
+	if !ok {
+		db.mu.Unlock()
+		return sessions.RemoteStore{}
+	}
+
+	db.recent.MoveToFront(elem)
+	store := elem.Value.(*entry).store
+	expired := db.expired(store)
+	db.mu.Unlock()
+
+	if expired {
+		return sessions.RemoteStore{}
+	}
+
+	store.Values = append(sessions.Store(nil), store.Values...)
+	return store
+}
+
+// Scan calls the "visitor" for each non-expired session, it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	db.mu.Lock()
+	stores := make(map[string]sessions.RemoteStore, len(db.sessions))
+	for sid, elem := range db.sessions {
+		if store := elem.Value.(*entry).store; !db.expired(store) {
+			stores[sid] = store
+		}
+	}
+	db.mu.Unlock()
+
+	for sid, store := range stores {
+		store.Values = append(sessions.Store(nil), store.Values...)
+		if !visitor(sid, store) {
+			return
+		}
+	}
+}
+
+// Sync stores the session of the payload, it's removed on destroy.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	if p.Action == sessions.ActionDestroy {
+		db.mu.Lock()
+		if elem, ok := db.sessions[p.SessionID]; ok {
+			db.remove(elem)
+		}
+		db.mu.Unlock()
+		return
+	}
+
+	e := db.newEntry(p)
+	db.mu.Lock()
+	db.store(e)
+}
+
+// newEntry returns the entry of the payload's session.
+func (db *Database) newEntry(p sessions.SyncPayload) *entry {
+	e := &entry{
+		sid: p.SessionID,
+		store: sessions.RemoteStore{
+			Values: append(sessions.Store(nil), p.Store.Values...),
+			// the expiration timer of the manager is not kept.
+			Lifetime:   sessions.LifeTime{Time: p.Store.Lifetime.Time},
+			CreatedAt:  p.Store.CreatedAt,
+			Version:    p.Store.Version,
+			Extensions: p.Store.Extensions,
+		},
+	}
+
+	if db.opts.MaxBytes > 0 {
+		b, err := e.store.Serialize()
+		if err != nil {
+			db.log().Errorf("error while measuring the session: %v", err)
+		}
+		e.size = int64(len(b))
+	}
+
+	return e
+}
+
+// SyncVersion same as `Sync` but the session is written only if it's missing or its stored version
+// is the previous version of the payload's, it implements the `sessions.VersionedDatabase`.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	if p.Action == sessions.ActionDestroy {
+		db.Sync(p)
+		return sessions.RemoteStore{}, nil
+	}
+
+	e := db.newEntry(p)
+
+	db.mu.Lock()
+	if elem, ok := db.sessions[p.SessionID]; ok {
+		if stored := elem.Value.(*entry).store; stored.Version != p.Store.Version-1 {
+			db.mu.Unlock()
+			stored.Values = append(sessions.Store(nil), stored.Values...)
+			return stored, sessions.ErrVersionConflict
+		}
+	}
+	db.store(e)
+
+	return sessions.RemoteStore{}, nil
+}
+
+// store stores the "e" session and evicts the least recently used sessions, if needed.
+// The caller should hold the lock, it's released.
+func (db *Database) store(e *entry) {
+	if elem, ok := db.sessions[e.sid]; ok {
+		db.remove(elem)
+	}
+	db.sessions[e.sid] = db.recent.PushFront(e)
+	db.size += e.size
+	evicted := db.evict()
+	notify := db.notify
+	db.mu.Unlock()
+
+	if notify != nil {
+		for _, e := range evicted {
+			notify(e.sid, sessions.EvictionCapacity, e.store.Values)
+		}
+	}
+}
+
+// evict removes the least recently used sessions while the capacity is exceeded
+// and returns them, the most recently used session is kept. The caller should hold the lock.
+func (db *Database) evict() (evicted []*entry) {
+	for db.recent.Len() > 1 &&
+		((db.opts.MaxSessions > 0 && db.recent.Len() > db.opts.MaxSessions) ||
+			(db.opts.MaxBytes > 0 && db.size > db.opts.MaxBytes)) {
+		evicted = append(evicted, db.remove(db.recent.Back()))
+	}
+
+	return
+}
+
+// Close stops the garbage collection.
+func (db *Database) Close() error {
+	db.closeOnce.Do(func() {
+		db.mu.Lock()
+		close(db.done)
+		if db.timer != nil {
+			db.timer.Stop()
+			db.timer = nil
+		}
+		db.mu.Unlock()
+	})
+	return nil
+}
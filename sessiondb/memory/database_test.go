@@ -0,0 +1,256 @@
+package memory
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+func insert(sid, name string, expiresAt time.Time) sessions.SyncPayload {
+	p := sessionstest.Insert(sid, name, 0)
+	p.Store.Lifetime = sessions.LifeTime{Time: expiresAt}
+	return p
+}
+
+func TestGC(t *testing.T) {
+	var collected []int
+	db := New(Options{Interval: -1, BatchSize: 2, OnCollect: func(reclaimed int) {
+		collected = append(collected, reclaimed)
+	}})
+	defer db.Close()
+
+	expired := time.Now().Add(-time.Second)
+	for i := 0; i < 5; i++ {
+		db.Sync(insert("expired"+strconv.Itoa(i), "kataras", expired))
+	}
+	db.Sync(insert("alive", "makis", time.Now().Add(time.Hour)))
+	db.Sync(insert("persistent", "gerasimos", time.Time{}))
+
+	if store := db.Load("expired0"); len(store.Values) > 0 {
+		t.Fatalf("expected the expired session not to be loaded before its collection but got %v", store.Values)
+	}
+
+	if n := db.GC(); n != 5 {
+		t.Fatalf("expected 5 reclaimed sessions but got %d", n)
+	}
+	if n := db.Len(); n != 2 {
+		t.Fatalf("expected the 2 alive sessions to be kept but got %d", n)
+	}
+	if n := db.Reclaimed(); n != 5 {
+		t.Fatalf("expected 5 reclaimed sessions in total but got %d", n)
+	}
+	if len(collected) != 1 || collected[0] != 5 {
+		t.Fatalf("expected the collection to be reported but got %v", collected)
+	}
+
+	if store := db.Load("alive"); store.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the alive session to be loaded but got %v", store.Values)
+	}
+}
+
+func TestBackgroundGC(t *testing.T) {
+	clock := sessions.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var collected []int
+	db := New(Options{Interval: time.Minute, Clock: clock, OnCollect: func(reclaimed int) {
+		collected = append(collected, reclaimed)
+	}})
+	defer db.Close()
+
+	db.Sync(insert("sid", "kataras", clock.Now().Add(30*time.Second)))
+	db.Sync(insert("alive", "makis", clock.Now().Add(time.Hour)))
+
+	clock.Advance(time.Minute)
+	if len(collected) != 1 || collected[0] != 1 {
+		t.Fatalf("expected the expired session to be collected in the background but got %v", collected)
+	}
+	if n := db.Len(); n != 1 {
+		t.Fatalf("expected the alive session after the collection but got %d sessions", n)
+	}
+
+	// the next collection is scheduled.
+	clock.Advance(time.Minute)
+	if len(collected) != 2 {
+		t.Fatalf("expected the next collection but got %v", collected)
+	}
+
+	db.Close()
+	clock.Advance(time.Minute)
+	if len(collected) != 2 {
+		t.Fatalf("expected no collection after the close but got %v", collected)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	db := New(Options{Interval: time.Minute})
+	defer db.Close()
+
+	clock := sessions.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := sessions.New(sessions.Config{Clock: clock})
+	manager.UseDatabase(db)
+
+	db.Sync(insert("sid", "kataras", clock.Now().Add(time.Second)))
+	if store := db.Load("sid"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session to be alive by the manager's clock")
+	}
+
+	clock.Advance(time.Minute)
+	if n := db.Len(); n != 0 {
+		t.Fatalf("expected the session to be collected by the manager's clock but got %d sessions", n)
+	}
+}
+
+func TestSync(t *testing.T) {
+	db := New(Options{Interval: -1})
+	defer db.Close()
+
+	p := insert("sid", "kataras", time.Time{})
+	db.Sync(p)
+
+	// the stored values are a copy.
+	p.Store.Values.Set("name", "makis")
+	store := db.Load("sid")
+	if store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the stored copy of the session but got %v", store.Values)
+	}
+
+	var visited []string
+	db.Scan(func(sid string, _ sessions.RemoteStore) bool {
+		visited = append(visited, sid)
+		return true
+	})
+	if len(visited) != 1 || visited[0] != "sid" {
+		t.Fatalf("expected the stored session to be scanned but got %v", visited)
+	}
+
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+	if n := db.Len(); n != 0 {
+		t.Fatalf("expected the destroyed session to be removed but got %d sessions", n)
+	}
+}
+
+func TestSyncVersion(t *testing.T) {
+	db := New(Options{Interval: -1})
+	defer db.Close()
+
+	p := insert("sid", "kataras", time.Time{})
+	p.Store.Version = 1
+	if _, err := db.SyncVersion(p); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := insert("sid", "makis", time.Time{})
+	stale.Store.Version = 1
+	stored, err := db.SyncVersion(stale)
+	if err != sessions.ErrVersionConflict || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected a version conflict with the stored session but got %v, %v", err, stored.Values)
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	var evicted []string
+	db := New(Options{Interval: -1, MaxSessions: 2})
+	defer db.Close()
+	db.SetEvictionNotifier(func(sid string, reason sessions.EvictionReason, snapshot sessions.Store) {
+		evicted = append(evicted, sid+":"+snapshot.GetString("name")+":"+reason.String())
+	})
+
+	db.Sync(insert("a", "kataras", time.Time{}))
+	db.Sync(insert("b", "makis", time.Time{}))
+	// "a" is used recently, "b" is the least recently used.
+	db.Load("a")
+	db.Sync(insert("c", "gerasimos", time.Time{}))
+
+	if len(evicted) != 1 || evicted[0] != "b:makis:capacity" {
+		t.Fatalf("expected the least recently used session to be evicted but got %v", evicted)
+	}
+	if n := db.Len(); n != 2 {
+		t.Fatalf("expected 2 sessions but got %d", n)
+	}
+	if store := db.Load("a"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the recently used session to be kept")
+	}
+
+	// a re-written session doesn't count twice.
+	db.Sync(insert("c", "gerasimos", time.Time{}))
+	if len(evicted) != 1 {
+		t.Fatalf("expected no eviction on an update but got %v", evicted)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	size := func(p sessions.SyncPayload) int64 {
+		b, err := p.Store.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int64(len(b))
+	}
+
+	p := insert("a", "kataras", time.Time{})
+	limit := 2*size(p) + size(p)/2
+
+	var evicted []string
+	db := New(Options{Interval: -1, MaxBytes: limit})
+	defer db.Close()
+	db.SetEvictionNotifier(func(sid string, _ sessions.EvictionReason, _ sessions.Store) {
+		evicted = append(evicted, sid)
+	})
+
+	db.Sync(p)
+	db.Sync(insert("b", "kataras", time.Time{}))
+	if n := db.Size(); n != 2*size(p) {
+		t.Fatalf("expected the size of 2 sessions, %d bytes, but got %d", 2*size(p), n)
+	}
+
+	db.Sync(insert("c", "kataras", time.Time{}))
+	if len(evicted) != 1 || evicted[0] != "a" || db.Size() > limit {
+		t.Fatalf("expected the least recently used session to be evicted to keep %d bytes but got %v and %d bytes", limit, evicted, db.Size())
+	}
+
+	db.Sync(sessions.SyncPayload{SessionID: "b", Action: sessions.ActionDestroy})
+	if n := db.Size(); n != size(p) {
+		t.Fatalf("expected the size of the destroyed session to be released but got %d bytes", n)
+	}
+}
+
+func TestMostRecentKept(t *testing.T) {
+	var evicted []string
+	db := New(Options{Interval: -1, MaxBytes: 1})
+	defer db.Close()
+	db.SetEvictionNotifier(func(sid string, _ sessions.EvictionReason, _ sessions.Store) {
+		evicted = append(evicted, sid)
+	})
+
+	db.Sync(insert("a", "kataras", time.Time{}))
+	db.Sync(insert("b", "makis", time.Time{}))
+
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatalf("expected the older session to be evicted but got %v", evicted)
+	}
+	if store := db.Load("b"); store.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the most recently used session to be kept even if it exceeds the capacity")
+	}
+}
+
+func TestOnEvict(t *testing.T) {
+	db := New(Options{Interval: -1, MaxSessions: 1})
+	defer db.Close()
+
+	manager := sessions.New(sessions.Config{})
+	manager.UseDatabase(db)
+
+	var evicted []string
+	manager.OnEvict(func(sid string, reason sessions.EvictionReason, snapshot sessions.Store) {
+		evicted = append(evicted, sid+":"+snapshot.GetString("name")+":"+reason.String())
+	})
+
+	db.Sync(insert("a", "kataras", time.Time{}))
+	db.Sync(insert("b", "makis", time.Time{}))
+	if len(evicted) != 1 || evicted[0] != "a:kataras:capacity" {
+		t.Fatalf("expected the eviction to be reported to the manager's hook but got %v", evicted)
+	}
+}
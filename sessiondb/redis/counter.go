@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"strings"
+)
+
+// counterKeySuffix is the suffix of the hashes of the session counters, see `Database#Increment`.
+const counterKeySuffix = ":counters"
+
+func isCounterKey(key string) bool {
+	return strings.HasSuffix(key, counterKeySuffix)
+}
+
+// Increment adds the "delta" to the "key" counter of the session "sid", by a "HINCRBY",
+// the "current" initializes a missing counter and the counters expire with the session.
+// It implements the `sessions.Incrementer`, see `sessions.Session#Increment`.
+func (db *Database) Increment(sid, key string, current, delta int64) (int64, error) {
+	if !db.connect() {
+		return 0, errNotConnected
+	}
+
+	return db.redis.HIncrBy(sid+counterKeySuffix, key, current, delta, sid)
+}
@@ -1,7 +1,11 @@
 package redis
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/kataras/go-sessions"
@@ -12,7 +16,50 @@ import (
 // Database the redis back-end session database for the sessions.
 type Database struct {
 	redis *service.Service
-	async bool
+	// replicas serve the loads, round-robin, see `Replicas`.
+	replicas []*service.Service
+	next     uint32
+	// written are the sessions which were written recently, they are loaded from the primary
+	// while the replicas lag behind, see `ReplicaLag`.
+	written    map[string]write
+	writtenMu  sync.Mutex
+	swept      time.Time
+	lag        time.Duration
+	async      bool
+	transcoder sessions.Transcoder
+	// pending waits the asynchronous writes on `Close`.
+	pending sync.WaitGroup
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+	// clock is the source of the current time, see `SetClock`.
+	clock sessions.Clock
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+// SetClock sets the clock of the sessions' expiration and of the `ReplicaLag`,
+// the manager's `Config#Clock` is set on its `UseDatabase`.
+// Defaults to the `sessions.SystemClock`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	db.clock = clock
+}
+
+func (db *Database) now() time.Time {
+	if db.clock != nil {
+		return db.clock.Now()
+	}
+	return time.Now()
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
 }
 
 // New returns a new redis database.
@@ -27,6 +74,26 @@ func (db *Database) Config() *service.Config {
 	return db.redis.Config
 }
 
+// Stats returns the current statistics of the redis connection pool,
+// i.e to be exported as metrics.
+func (db *Database) Stats() service.PoolStats {
+	return db.redis.Stats()
+}
+
+// PoolStats returns the `Stats` of the connection pool, it implements the `sessions.PoolStater`,
+// so the "metrics" subpackage exports them.
+func (db *Database) PoolStats() sessions.PoolStats {
+	stats := db.redis.Stats()
+	return sessions.PoolStats{
+		Active:     stats.ActiveCount,
+		InUse:      stats.InUseCount,
+		Idle:       stats.IdleCount,
+		MaxActive:  stats.MaxActive,
+		Dials:      stats.Dials,
+		DialErrors: stats.DialErrors,
+	}
+}
+
 // Async if true passed then it will use different
 // go routines to update the redis storage.
 func (db *Database) Async(useGoRoutines bool) *Database {
@@ -34,75 +101,278 @@ func (db *Database) Async(useGoRoutines bool) *Database {
 	return db
 }
 
-// Load loads the values to the underline.
-func (db *Database) Load(sid string) (storeDB sessions.RemoteStore) {
-	// values := make(map[string]interface{})
+// Transcoder sets the transcoder which is used to serialize and deserialize
+// the sessions of this database, i.e a `sessions.CompressionTranscoder`
+// to reduce the redis memory and network costs.
+// Defaults to nil, the `sessions.DefaultTranscoder` is used instead.
+func (db *Database) Transcoder(t sessions.Transcoder) *Database {
+	db.transcoder = t
+	return db
+}
+
+// connect connects to the redis on the first call, it reports whether the connection is valid.
+func (db *Database) connect() bool {
+	return db.dial() == nil
+}
 
+// dial same as `connect` but it returns the error of the connection.
+func (db *Database) dial() error {
 	if !db.redis.Connected { //yes, check every first time's session for valid redis connection
 		db.redis.Connect()
 		_, err := db.redis.PingPong()
 		if err != nil {
-			golog.Errorf("redis database error on connect: %v", err)
-			return
+			db.log().Errorf("redis database error on connect: %v", err)
+			return err
 		}
 	}
 
-	// fetch the values from this session id and copy-> store them
-	storeMaybe, err := db.redis.Get(sid)
-	// not exists yet, no problem return an empty remote store.
-	if err == nil {
-		storeB, ok := storeMaybe.([]byte)
-		if !ok {
-			golog.Errorf("something wrong, store should be stored as []byte but stored as %#v", storeMaybe)
-			return
+	return nil
+}
+
+// Load loads the values to the underline.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	storeDB, err := db.TryLoad(sid)
+	if err != nil {
+		db.log().Errorf("error while loading the session(%s) from redis: %v", sid, err)
+	}
+	return storeDB
+}
+
+// TryLoad same as `Load` but it returns the error of the redis server, i.e on a lost connection,
+// the missing sessions are returned as empty stores without an error.
+// It implements the `failover.FallibleDatabase`.
+func (db *Database) TryLoad(sid string) (sessions.RemoteStore, error) {
+	if replica := db.replica(); replica != nil {
+		version, written := db.writtenVersion(sid)
+		storeDB, err := db.load(replica, sid)
+		if err == nil && (len(storeDB.Values) > 0 || !storeDB.Lifetime.IsZero()) && (!written || storeDB.Version >= version) {
+			return storeDB, nil
 		}
 
-		storeDB, err = sessions.DecodeRemoteStore(storeB) // decode the whole value, as a remote store
 		if err != nil {
-			golog.Errorf(`error while trying to load session values(%s) from redis:
-			the retrieved value is not a sessions.RemoteStore type, please report that as bug, that should never occur: %v`,
-				sid, err)
+			db.log().Debugf("error while loading the session(%s) from the redis replica %s, it's loaded from the primary: %v",
+				sid, replica.Config.Addr, err)
 		}
 	}
 
+	if err := db.dial(); err != nil {
+		return sessions.RemoteStore{}, err
+	}
+
+	return db.load(db.redis, sid)
+}
+
+func (db *Database) load(r *service.Service, sid string) (storeDB sessions.RemoteStore, err error) {
+	// fetch the values from this session id and copy-> store them
+	storeMaybe, err := r.Get(sid)
+	if err != nil {
+		if service.ErrKeyNotFound.Equal(err) {
+			// not exists yet, no problem return an empty remote store.
+			err = nil
+		}
+		return
+	}
+
+	storeB, ok := storeMaybe.([]byte)
+	if !ok {
+		err = fmt.Errorf("something wrong, store should be stored as []byte but stored as %#v", storeMaybe)
+		return
+	}
+
+	storeDB, err = sessions.DecodeRemoteStoreWith(db.transcoder, storeB) // decode the whole value, as a remote store
+	if err != nil {
+		err = fmt.Errorf(`the retrieved value is not a sessions.RemoteStore type, please report that as bug, that should never occur: %v`, err)
+	}
+
 	return
 }
 
+// Scan calls the "visitor" for each session of the redis database, or of a read replica, see `Replicas`,
+// it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	r := db.redis
+	if replica := db.replica(); replica != nil {
+		r = replica
+	}
+
+	err := r.Keys(func(sid string) bool {
+		if isLockKey(sid) || isCounterKey(sid) {
+			return true
+		}
+
+		storeDB := db.Load(sid)
+		if storeDB.Lifetime.HasExpired() || (len(storeDB.Values) == 0 && storeDB.Lifetime.IsZero()) {
+			// expired or removed after the scan.
+			return true
+		}
+
+		return visitor(sid, storeDB)
+	})
+
+	if err != nil {
+		db.log().Errorf("error while scanning the redis keys: %v", err)
+	}
+}
+
 // Sync syncs the database.
 func (db *Database) Sync(p sessions.SyncPayload) {
 	if db.async {
-		go db.sync(p)
+		db.pending.Add(1)
+		go func() {
+			db.sync(p)
+			db.pending.Done()
+		}()
 	} else {
 		db.sync(p)
 	}
 }
 
 func (db *Database) sync(p sessions.SyncPayload) {
+	if err := db.TrySync(p); err != nil {
+		db.log().Errorf("error while syncing the session(%s) to redis: %v", p.SessionID, err)
+	}
+}
+
+// TrySync same as `Sync` but it writes the session synchronously, even if the database is `Async`,
+// and it returns the error of the redis server.
+// It implements the `failover.FallibleDatabase`.
+func (db *Database) TrySync(p sessions.SyncPayload) error {
+	if err := db.dial(); err != nil {
+		return err
+	}
+
 	if p.Action == sessions.ActionDestroy {
-		db.redis.Delete(p.SessionID)
-		return
+		if err := db.redis.Delete(p.SessionID); err != nil {
+			return err
+		}
+		db.wrote(p.SessionID, destroyedVersion)
+		return db.redis.Delete(p.SessionID + counterKeySuffix)
 	}
-	storeB, err := p.Store.Serialize()
+	storeB, err := p.Store.SerializeWith(db.transcoder)
 	if err != nil {
-		golog.Error("error while encoding the remote session store")
-		return
+		return fmt.Errorf("error while encoding the remote session store: %v", err)
 	}
 
 	// not expire if zero
 	seconds := 0
 
 	if lifetime := p.Store.Lifetime; !lifetime.IsZero() {
-		seconds = int(lifetime.Sub(time.Now()).Seconds())
+		seconds = int(lifetime.Sub(db.now()).Seconds())
+	}
+
+	if err = db.redis.Set(p.SessionID, storeB, seconds); err != nil {
+		return err
+	}
+
+	db.wrote(p.SessionID, p.Store.Version)
+	return nil
+}
+
+// SyncVersion same as `TrySync` but the session is written only if it's missing or its stored version
+// is the previous version of the payload's, by a WATCH/MULTI/EXEC transaction on the primary,
+// otherwise the stored session is returned with the `sessions.ErrVersionConflict`.
+// It implements the `sessions.VersionedDatabase`.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	if p.Action == sessions.ActionDestroy {
+		return sessions.RemoteStore{}, db.TrySync(p)
+	}
+
+	if err := db.dial(); err != nil {
+		return sessions.RemoteStore{}, err
+	}
+
+	storeB, err := p.Store.SerializeWith(db.transcoder)
+	if err != nil {
+		return sessions.RemoteStore{}, fmt.Errorf("error while encoding the remote session store: %v", err)
+	}
+
+	seconds := 0
+	if lifetime := p.Store.Lifetime; !lifetime.IsZero() {
+		seconds = int(lifetime.Sub(db.now()).Seconds())
 	}
 
-	db.redis.Set(p.SessionID, storeB, seconds)
+	var (
+		stored    sessions.RemoteStore
+		decodeErr error
+	)
+	_, ok, err := db.redis.CompareAndSet(p.SessionID, storeB, seconds, func(current []byte) bool {
+		if current == nil {
+			return true
+		}
+		stored, decodeErr = sessions.DecodeRemoteStoreWith(db.transcoder, current)
+		return decodeErr == nil && stored.Version == p.Store.Version-1
+	})
+	if err != nil {
+		return sessions.RemoteStore{}, err
+	}
+	if decodeErr != nil {
+		return sessions.RemoteStore{}, decodeErr
+	}
+	if !ok {
+		// the stored session replaces the local one.
+		db.wrote(p.SessionID, stored.Version)
+		return stored, sessions.ErrVersionConflict
+	}
+
+	db.wrote(p.SessionID, p.Store.Version)
+	return sessions.RemoteStore{}, nil
 }
 
-// Close shutdowns the redis connection.
+// Close waits for the pending asynchronous writes, if any,
+// and shutdowns the redis connection.
 func (db *Database) Close() error {
+	db.pending.Wait()
 	return closeDB(db)
 }
 
+// Ping sends a PING to the redis server, it implements the `sessions.Pinger`, see `Sessions#Healthy`.
+func (db *Database) Ping(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if err := db.dial(); err != nil {
+			errCh <- err
+			return
+		}
+
+		pong, err := db.redis.PingPong()
+		if err == nil && !pong {
+			err = errors.New("redis: unexpected reply to the PING")
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown same as `Close` but it returns the "ctx"'s error, and the connection is kept,
+// if its deadline passes before the pending asynchronous writes finish.
+// It implements the `sessions.Shutdowner`.
+func (db *Database) Shutdown(ctx context.Context) error {
+	written := make(chan struct{})
+	go func() {
+		db.pending.Wait()
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		return closeDB(db)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func closeDB(db *Database) error {
-	return db.redis.CloseConnection()
+	err := db.redis.CloseConnection()
+	for _, replica := range db.replicas {
+		if replicaErr := replica.CloseConnection(); replicaErr != nil && err == nil {
+			err = replicaErr
+		}
+	}
+	return err
 }
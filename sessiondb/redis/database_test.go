@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessiondb/redis/service"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+func TestSyncVersion(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	db := New(server.Config())
+	defer db.Close()
+
+	if _, err := db.SyncVersion(sessionstest.Insert("sid", "first", 1)); err != nil {
+		t.Fatalf("expected the missing session to be written but got %v", err)
+	}
+
+	// another instance loaded the version 0 too.
+	stored, err := db.SyncVersion(sessionstest.Insert("sid", "second", 1))
+	if err != sessions.ErrVersionConflict {
+		t.Fatalf("expected a version conflict but got %v", err)
+	}
+	if got := stored.Values.GetString("name"); got != "first" || stored.Version != 1 {
+		t.Fatalf("expected the stored session of the version 1 but got %q of the version %d", got, stored.Version)
+	}
+
+	if _, err = db.SyncVersion(sessionstest.Insert("sid", "third", 2)); err != nil {
+		t.Fatalf("expected the next version to be written but got %v", err)
+	}
+
+	loaded := db.Load("sid")
+	if got := loaded.Values.GetString("name"); got != "third" || loaded.Version != 2 {
+		t.Fatalf("expected the session of the version 2 to be loaded but got %q of the version %d", got, loaded.Version)
+	}
+}
+
+func TestCompareAndSetModified(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	db := New(server.Config())
+	defer db.Close()
+	if err := db.dial(); err != nil {
+		t.Fatal(err)
+	}
+
+	var checks []string
+	_, ok, err := db.redis.CompareAndSet("key", "value", 0, func(current []byte) bool {
+		checks = append(checks, string(current))
+		if len(checks) == 1 {
+			// written by another client after the check.
+			server.set("key", []byte("other"))
+		}
+		return true
+	})
+	if err != nil || !ok {
+		t.Fatalf("expected the key to be set but got %v, %v", ok, err)
+	}
+
+	if len(checks) != 2 || checks[1] != "other" {
+		t.Fatalf("expected the modified key to be checked again but got the checks %q", checks)
+	}
+
+	if v, _ := server.get("key"); string(v) != "value" {
+		t.Fatalf("expected the value to be set but got %q", v)
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	// the pool is not dialed before the connect.
+	if stats := (&service.Service{}).Stats(); stats != (service.PoolStats{}) {
+		t.Fatalf("expected the empty stats of a service without a pool but got %#v", stats)
+	}
+
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	cfg := server.Config()
+	cfg.MaxActive = 4
+	cfg.MaxIdle = 4
+	db := New(cfg)
+	defer db.Close()
+
+	if stats := db.PoolStats(); stats.MaxActive != 4 || stats.Active != 0 {
+		t.Fatalf("expected the limits of the pool before the connect but got %#v", stats)
+	}
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	db.Load("sid")
+
+	stats := db.PoolStats()
+	if stats.Dials != 1 || stats.Active != 1 || stats.InUse != 0 || stats.Idle != 1 || stats.DialErrors != 0 {
+		t.Fatalf("expected the idle connection of the commands but got %#v", stats)
+	}
+
+	var _ sessions.PoolStater = db
+
+	server.Close()
+	unreachable := New(cfg)
+	defer unreachable.Close()
+	unreachable.Load("sid")
+	if stats = unreachable.PoolStats(); stats.DialErrors != 1 || stats.InUse != 0 {
+		t.Fatalf("expected the failed dial to be counted but got %#v", stats)
+	}
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultInvalidationChannel is the default redis channel of the session invalidations, see `Database#Invalidator`.
+const DefaultInvalidationChannel = "sessions:invalidate"
+
+// errNotConnected returned by the `Invalidator` when the redis connection is not valid.
+var errNotConnected = errors.New("redis: not connected")
+
+// Invalidator broadcasts the session invalidations between the app instances over the redis pub/sub,
+// i.e so the instances drop their cached copy of a session which is destroyed or modified by another instance.
+// It implements the `sessions.Invalidator`, see `sessions.Sessions#UseInvalidator` and the `tiered` database.
+type Invalidator struct {
+	db      *Database
+	channel string
+}
+
+// Invalidator returns a new invalidator of the "channel" over the connection of the database,
+// the `DefaultInvalidationChannel` is used if it's empty.
+func (db *Database) Invalidator(channel string) *Invalidator {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+
+	return &Invalidator{db: db, channel: channel}
+}
+
+// Publish broadcasts the invalidation of the session "sid", the "origin" is the id of the publisher.
+func (i *Invalidator) Publish(origin, sid string) error {
+	if !i.db.connect() {
+		return errNotConnected
+	}
+
+	return i.db.redis.Publish(i.channel, []byte(origin+" "+sid))
+}
+
+// Subscribe calls the "onInvalidate" for each invalidation which is broadcasted by the `Publish`,
+// including the ones of this app instance, it returns a function which unsubscribes.
+func (i *Invalidator) Subscribe(onInvalidate func(origin, sid string)) (func() error, error) {
+	if !i.db.connect() {
+		return nil, errNotConnected
+	}
+
+	return i.db.redis.Subscribe(i.channel, func(message []byte) {
+		msg := string(message)
+		if idx := strings.IndexByte(msg, ' '); idx > 0 {
+			onInvalidate(msg[:idx], msg[idx+1:])
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvalidator(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	db := New(server.Config())
+	defer db.Close()
+
+	type invalidation struct{ origin, sid string }
+	received := make(chan invalidation, 1)
+
+	inv := db.Invalidator("")
+	unsubscribe, err := inv.Subscribe(func(origin, sid string) {
+		received <- invalidation{origin, sid}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = inv.Publish("node1", "sid"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-received:
+		if got.origin != "node1" || got.sid != "sid" {
+			t.Fatalf("expected the invalidation of the session sid by node1 but got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the invalidation to be received")
+	}
+
+	if err = unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	if err = inv.Publish("node1", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-received:
+		t.Fatalf("expected no invalidations after the unsubscribe but got %v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
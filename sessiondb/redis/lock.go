@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"strings"
+	"time"
+)
+
+// lockKeySuffix is the suffix of the keys of the session locks, see `Database#TryLock`.
+const lockKeySuffix = ":lock"
+
+func isLockKey(key string) bool {
+	return strings.HasSuffix(key, lockKeySuffix)
+}
+
+// TryLock acquires the lock of the session "sid" for the "ttl", by a "SET NX",
+// it implements the `sessions.Locker`, see `sessions.Session#Lock`.
+func (db *Database) TryLock(sid, token string, ttl time.Duration) (bool, error) {
+	if !db.connect() {
+		return false, errNotConnected
+	}
+
+	return db.redis.SetNX(sid+lockKeySuffix, token, ttl)
+}
+
+// Unlock releases the lock of the session "sid" if it's held by the "token",
+// it implements the `sessions.Locker`.
+func (db *Database) Unlock(sid, token string) error {
+	if !db.connect() {
+		return errNotConnected
+	}
+
+	_, err := db.redis.DeleteIf(sid+lockKeySuffix, token)
+	return err
+}
@@ -0,0 +1,258 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kataras/go-sessions/sessiondb/redis/service"
+)
+
+// fakeRedis is an in-process redis server of the commands which are used by the tests,
+// it speaks the RESP protocol, so the database is tested through its real connection pool.
+type fakeRedis struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	data map[string][]byte
+	// mods are incremented on each write of a key, the WATCH compares them.
+	mods map[string]uint64
+	// subscribers are the clients of the channels.
+	subscribers map[string][]*client
+}
+
+// client is a connection of the server, its writes are serialized,
+// the published messages are written by the connections of the publishers.
+type client struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (c *client) write(reply string) error {
+	c.mu.Lock()
+	_, err := io.WriteString(c.conn, reply)
+	c.mu.Unlock()
+	return err
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &fakeRedis{ln: ln, data: make(map[string][]byte), mods: make(map[string]uint64), subscribers: make(map[string][]*client)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(c)
+		}
+	}()
+
+	return r
+}
+
+func (r *fakeRedis) Close() error {
+	return r.ln.Close()
+}
+
+// Config returns the config of a service which connects to the server.
+func (r *fakeRedis) Config() service.Config {
+	cfg := service.DefaultConfig()
+	cfg.Addr = r.ln.Addr().String()
+	return cfg
+}
+
+func (r *fakeRedis) get(key string) ([]byte, bool) {
+	r.mu.Lock()
+	v, ok := r.data[key]
+	r.mu.Unlock()
+	return v, ok
+}
+
+// set writes a key directly, i.e to simulate the replication.
+func (r *fakeRedis) set(key string, value []byte) {
+	r.mu.Lock()
+	r.data[key] = value
+	r.mods[key]++
+	r.mu.Unlock()
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = rd.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(rd, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+
+	return args, nil
+}
+
+func (r *fakeRedis) serve(c net.Conn) {
+	cl := &client{conn: c}
+	defer func() {
+		r.unsubscribe(cl)
+		c.Close()
+	}()
+
+	var (
+		rd      = bufio.NewReader(c)
+		watched = make(map[string]uint64)
+		queue   [][]string
+		multi   bool
+	)
+
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "WATCH":
+			r.mu.Lock()
+			for _, key := range args[1:] {
+				watched[key] = r.mods[key]
+			}
+			r.mu.Unlock()
+			reply = "+OK\r\n"
+		case cmd == "UNWATCH":
+			watched = make(map[string]uint64)
+			reply = "+OK\r\n"
+		case cmd == "MULTI":
+			multi = true
+			reply = "+OK\r\n"
+		case cmd == "EXEC":
+			r.mu.Lock()
+			aborted := false
+			for key, mod := range watched {
+				if r.mods[key] != mod {
+					aborted = true
+				}
+			}
+			if aborted {
+				reply = "*-1\r\n"
+			} else {
+				reply = "*" + strconv.Itoa(len(queue)) + "\r\n"
+				for _, q := range queue {
+					reply += r.do(q)
+				}
+			}
+			r.mu.Unlock()
+			watched = make(map[string]uint64)
+			queue = nil
+			multi = false
+		case multi:
+			queue = append(queue, args)
+			reply = "+QUEUED\r\n"
+		case cmd == "SUBSCRIBE":
+			r.mu.Lock()
+			for i, channel := range args[1:] {
+				r.subscribers[channel] = append(r.subscribers[channel], cl)
+				reply += fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:%d\r\n", len(channel), channel, i+1)
+			}
+			r.mu.Unlock()
+		case cmd == "UNSUBSCRIBE" || cmd == "PUNSUBSCRIBE":
+			r.unsubscribe(cl)
+			kind := strings.ToLower(cmd)
+			reply = fmt.Sprintf("*3\r\n$%d\r\n%s\r\n$-1\r\n:0\r\n", len(kind), kind)
+		case cmd == "PUBLISH":
+			r.mu.Lock()
+			subscribers := r.subscribers[args[1]]
+			for _, sub := range subscribers {
+				sub.write(fmt.Sprintf("*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(args[2]), args[2]))
+			}
+			r.mu.Unlock()
+			reply = ":" + strconv.Itoa(len(subscribers)) + "\r\n"
+		default:
+			r.mu.Lock()
+			reply = r.do(args)
+			r.mu.Unlock()
+		}
+
+		if err = cl.write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// unsubscribe removes the "cl" from the subscribers of all channels.
+func (r *fakeRedis) unsubscribe(cl *client) {
+	r.mu.Lock()
+	for channel, subscribers := range r.subscribers {
+		kept := subscribers[:0]
+		for _, sub := range subscribers {
+			if sub != cl {
+				kept = append(kept, sub)
+			}
+		}
+		r.subscribers[channel] = kept
+	}
+	r.mu.Unlock()
+}
+
+// do runs a command and returns its reply, the caller should hold the lock.
+func (r *fakeRedis) do(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "ECHO":
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(args[1]), args[1])
+	case "GET":
+		v, ok := r.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		r.data[args[1]] = []byte(args[2])
+		r.mods[args[1]]++
+		return "+OK\r\n"
+	case "SETEX":
+		r.data[args[1]] = []byte(args[3])
+		r.mods[args[1]]++
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := r.data[key]; ok {
+				delete(r.data, key)
+				r.mods[key]++
+				n++
+			}
+		}
+		return ":" + strconv.Itoa(n) + "\r\n"
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/go-sessions/sessiondb/redis/service"
+)
+
+// DefaultReplicaLag is the default window of the reads of the written sessions from the primary, see `Database#ReplicaLag`.
+const DefaultReplicaLag = 5 * time.Second
+
+// destroyedVersion is the written version of a destroyed session, a replica which still has it is behind.
+const destroyedVersion = math.MaxUint64
+
+// write is a recent write of a session, see `Database#ReplicaLag`.
+type write struct {
+	version uint64
+	at      time.Time
+}
+
+// Replicas sets the read replicas of the redis server, for read-heavy deployments,
+// the sessions are loaded and scanned from the replicas, round-robin, and they are written to the primary.
+// Each config is the configuration of a replica, i.e a copy of the primary's `Config` with the replica's Addr.
+//
+// A session which is missing from a replica, i.e it's not replicated yet, or a failed load
+// is re-read from the primary, so the replication lag doesn't lose the fresh sessions.
+// A session which was written by this database is re-read from the primary too
+// while the replica has an older version of it, or the destroyed session, see `ReplicaLag`.
+// The locks, the counters and the invalidations are served by the primary.
+func (db *Database) Replicas(cfgs ...service.Config) *Database {
+	for _, cfg := range cfgs {
+		replica := service.New(cfg)
+		replica.Connect()
+		db.replicas = append(db.replicas, replica)
+	}
+	return db
+}
+
+// ReplicaLag sets the window of the read-your-writes of the read replicas, see `Replicas`,
+// it should exceed their replication lag. For this window after a write of a session by this database
+// the session is re-read from the primary if the replica has an older version of it, i.e it's not replicated yet,
+// so the next request reads the writes of the previous one. A negative value disables it.
+//
+// Defaults to the `DefaultReplicaLag`.
+func (db *Database) ReplicaLag(d time.Duration) *Database {
+	db.lag = d
+	return db
+}
+
+func (db *Database) replicaLag() time.Duration {
+	if db.lag == 0 {
+		return DefaultReplicaLag
+	}
+	return db.lag
+}
+
+// replica returns the next read replica, nil if there are none.
+func (db *Database) replica() *service.Service {
+	if len(db.replicas) == 0 {
+		return nil
+	}
+
+	i := atomic.AddUint32(&db.next, 1)
+	return db.replicas[int(i%uint32(len(db.replicas)))]
+}
+
+// wrote records the written "version" of the session "sid", if there are read replicas.
+func (db *Database) wrote(sid string, version uint64) {
+	lag := db.replicaLag()
+	if len(db.replicas) == 0 || lag < 0 {
+		return
+	}
+
+	now := db.now()
+	db.writtenMu.Lock()
+	if db.written == nil {
+		db.written = make(map[string]write)
+	}
+	db.written[sid] = write{version: version, at: now}
+
+	// the expired writes are removed once per window.
+	if now.Sub(db.swept) > lag {
+		for writtenSID, w := range db.written {
+			if now.Sub(w.at) > lag {
+				delete(db.written, writtenSID)
+			}
+		}
+		db.swept = now
+	}
+	db.writtenMu.Unlock()
+}
+
+// writtenVersion returns the version of the session "sid" which was written within the `ReplicaLag`,
+// it reports false if it wasn't.
+func (db *Database) writtenVersion(sid string) (uint64, bool) {
+	db.writtenMu.Lock()
+	defer db.writtenMu.Unlock()
+
+	w, ok := db.written[sid]
+	if !ok {
+		return 0, false
+	}
+
+	if db.now().Sub(w.at) > db.replicaLag() {
+		delete(db.written, sid)
+		return 0, false
+	}
+
+	return w.version, true
+}
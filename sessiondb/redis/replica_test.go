@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+// replicate writes the session of the payload to the "replica" server, as the replication does.
+func replicate(t *testing.T, replica *fakeRedis, p sessions.SyncPayload) {
+	b, err := p.Store.SerializeWith(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica.set(p.SessionID, b)
+}
+
+func TestReplicas(t *testing.T) {
+	primary, replica := newFakeRedis(t), newFakeRedis(t)
+	defer primary.Close()
+	defer replica.Close()
+
+	db := New(primary.Config()).Replicas(replica.Config())
+	defer db.Close()
+
+	// not replicated yet.
+	if err := db.TrySync(sessionstest.Insert("sid", "kataras", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the missing session of the replica to be loaded from the primary but got %v", loaded.Values)
+	}
+
+	// replicated, the primary is not read.
+	replicate(t, replica, sessionstest.Insert("sid", "replica", 1))
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "replica" {
+		t.Fatalf("expected the session to be loaded from the replica but got %v", loaded.Values)
+	}
+}
+
+func TestReplicasReadYourWrites(t *testing.T) {
+	primary, replica := newFakeRedis(t), newFakeRedis(t)
+	defer primary.Close()
+	defer replica.Close()
+
+	clock := sessions.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	db := New(primary.Config()).Replicas(replica.Config()).ReplicaLag(time.Second)
+	db.SetClock(clock)
+	defer db.Close()
+
+	replicate(t, replica, sessionstest.Insert("sid", "kataras", 1))
+	if err := db.TrySync(sessionstest.Insert("sid", "makis", 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "makis" || loaded.Version != 2 {
+		t.Fatalf("expected the written version 2 to be loaded from the primary but got %v of the version %d", loaded.Values, loaded.Version)
+	}
+
+	// after the lag the replica is trusted.
+	clock.Advance(2 * time.Second)
+	if loaded := db.Load("sid"); loaded.Version != 1 {
+		t.Fatalf("expected the session to be loaded from the replica after the lag but got the version %d", loaded.Version)
+	}
+}
+
+func TestReplicasDestroy(t *testing.T) {
+	primary, replica := newFakeRedis(t), newFakeRedis(t)
+	defer primary.Close()
+	defer replica.Close()
+
+	db := New(primary.Config()).Replicas(replica.Config())
+	defer db.Close()
+
+	p := sessionstest.Insert("sid", "kataras", 1)
+	if err := db.TrySync(p); err != nil {
+		t.Fatal(err)
+	}
+	replicate(t, replica, p)
+
+	if err := db.TrySync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy}); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded := db.Load("sid"); len(loaded.Values) > 0 {
+		t.Fatalf("expected the destroyed session not to be loaded from the lagging replica but got %v", loaded.Values)
+	}
+}
@@ -27,8 +27,16 @@ type Config struct {
 	MaxIdle int
 	// MaxActive 0 no limit
 	MaxActive int
+	// Wait if true and the MaxActive limit is reached then the
+	// session operations wait for a connection to be returned to the pool. Default false
+	Wait bool
 	// IdleTimeout  time.Duration(5) * time.Minute
 	IdleTimeout time.Duration
+	// MaxConnLifetime closes the connections which are older than this duration
+	// when they are taken from the pool. 0 no limit
+	MaxConnLifetime time.Duration
+	// DialTimeout the timeout for connecting to the redis server. 0 no timeout
+	DialTimeout time.Duration
 	// Prefix "myprefix-for-this-website". Default ""
 	Prefix string
 }
@@ -36,13 +44,16 @@ type Config struct {
 // DefaultConfig returns the default configuration for Redis service.
 func DefaultConfig() Config {
 	return Config{
-		Network:     DefaultRedisNetwork,
-		Addr:        DefaultRedisAddr,
-		Password:    "",
-		Database:    "",
-		MaxIdle:     0,
-		MaxActive:   0,
-		IdleTimeout: DefaultRedisIdleTimeout,
-		Prefix:      "",
+		Network:         DefaultRedisNetwork,
+		Addr:            DefaultRedisAddr,
+		Password:        "",
+		Database:        "",
+		MaxIdle:         0,
+		MaxActive:       0,
+		Wait:            false,
+		IdleTimeout:     DefaultRedisIdleTimeout,
+		MaxConnLifetime: 0,
+		DialTimeout:     0,
+		Prefix:          "",
 	}
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/garyburd/redigo/redis"
@@ -12,10 +13,20 @@ var (
 	ErrRedisClosed = errors.New("Redis is already closed")
 	// ErrKeyNotFound an error with message 'Key $thekey doesn't found'
 	ErrKeyNotFound = errors.New("Key '%s' doesn't found")
+	// ErrUnexpectedReply an error with message 'Unexpected reply of the $command command'
+	ErrUnexpectedReply = errors.New("Unexpected reply of the '%s' command")
+	// errConnExpired is returned by the pool's borrow test
+	// when a connection is older than the `Config#MaxConnLifetime`.
+	errConnExpired = errors.New("connection lifetime exceeded")
 )
 
 // Service the Redis service, contains the config and the redis pool
 type Service struct {
+	// the counters of the `Stats`, they are first for the 64-bit alignment of the atomic operations.
+	dials      uint64
+	dialErrors uint64
+	inUse      int64
+
 	// Connected is true when the Service has already connected
 	Connected bool
 	// Config the redis config for this redis
@@ -25,7 +36,7 @@ type Service struct {
 
 // PingPong sends a ping and receives a pong, if no pong received then returns false and filled error
 func (r *Service) PingPong() (bool, error) {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	msg, err := c.Do("PING")
 	if err != nil || msg == nil {
@@ -34,6 +45,66 @@ func (r *Service) PingPong() (bool, error) {
 	return (msg == "PONG"), nil
 }
 
+// PoolStats contains the statistics of the redis connection pool.
+type PoolStats struct {
+	// ActiveCount is the number of connections, in use and idle.
+	ActiveCount int
+	// InUseCount is the number of connections which are used by the commands of the service
+	// and IdleCount the number of connections which are waiting in the pool.
+	InUseCount int
+	IdleCount  int
+	// MaxActive and MaxIdle are the limits of the pool, see `Config`.
+	MaxActive int
+	MaxIdle   int
+	// Dials is the number of the dialed connections and DialErrors the number of the failed ones.
+	Dials      uint64
+	DialErrors uint64
+}
+
+// Stats returns the current statistics of the redis connection pool,
+// the limits only if it's not connected yet.
+func (r *Service) Stats() PoolStats {
+	stats := PoolStats{
+		InUseCount: int(atomic.LoadInt64(&r.inUse)),
+		Dials:      atomic.LoadUint64(&r.dials),
+		DialErrors: atomic.LoadUint64(&r.dialErrors),
+	}
+
+	if r.Config != nil {
+		stats.MaxActive = r.Config.MaxActive
+		stats.MaxIdle = r.Config.MaxIdle
+	}
+
+	if r.pool != nil {
+		stats.ActiveCount = r.pool.ActiveCount()
+		if idle := stats.ActiveCount - stats.InUseCount; idle > 0 {
+			stats.IdleCount = idle
+		}
+	}
+
+	return stats
+}
+
+// get borrows a connection of the pool, it's counted by the `Stats` until it's closed.
+func (r *Service) get() redis.Conn {
+	atomic.AddInt64(&r.inUse, 1)
+	return &borrowedConn{Conn: r.pool.Get(), inUse: &r.inUse}
+}
+
+// borrowedConn is a connection of the `get`, the in-use count is decremented on its first `Close`.
+type borrowedConn struct {
+	redis.Conn
+	inUse  *int64
+	closed int32
+}
+
+func (c *borrowedConn) Close() error {
+	if atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		atomic.AddInt64(c.inUse, -1)
+	}
+	return c.Conn.Close()
+}
+
 // CloseConnection closes the redis connection
 func (r *Service) CloseConnection() error {
 	if r.pool != nil {
@@ -45,7 +116,7 @@ func (r *Service) CloseConnection() error {
 // Set sets a key-value to the redis store.
 // The expiration is setted by the MaxAgeSeconds.
 func (r *Service) Set(key string, value interface{}, secondsLifetime int) (err error) {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	if c.Err() != nil {
 		return c.Err()
@@ -64,7 +135,7 @@ func (r *Service) Set(key string, value interface{}, secondsLifetime int) (err e
 // Get returns value, err by its key
 //returns nil and a filled error if something bad happened.
 func (r *Service) Get(key string) (interface{}, error) {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	if err := c.Err(); err != nil {
 		return nil, err
@@ -83,7 +154,7 @@ func (r *Service) Get(key string) (interface{}, error) {
 
 // GetAll returns all redis entries using the "SCAN" command (2.8+).
 func (r *Service) GetAll() (interface{}, error) {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	if err := c.Err(); err != nil {
 		return nil, err
@@ -102,11 +173,52 @@ func (r *Service) GetAll() (interface{}, error) {
 	return redisVal, nil
 }
 
+// Keys calls the "visitor" for each key which starts with the `Config#Prefix`, without the prefix,
+// using the "SCAN" command (2.8+), the visitor can return false to stop the iteration.
+func (r *Service) Keys(visitor func(key string) bool) error {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return err
+	}
+
+	cursor := 0
+	for {
+		values, err := redis.Values(c.Do("SCAN", cursor, "MATCH", r.Config.Prefix+"*"))
+		if err != nil {
+			return err
+		}
+
+		if len(values) != 2 {
+			return ErrUnexpectedReply.Format("SCAN")
+		}
+
+		if cursor, err = redis.Int(values[0], nil); err != nil {
+			return err
+		}
+
+		keys, err := redis.Strings(values[1], nil)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if !visitor(key[len(r.Config.Prefix):]) {
+				return nil
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
 // GetBytes returns value, err by its key
 // you can use utils.Deserialize((.GetBytes("yourkey"),&theobject{})
 //returns nil and a filled error if something wrong happens
 func (r *Service) GetBytes(key string) ([]byte, error) {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	if err := c.Err(); err != nil {
 		return nil, err
@@ -126,7 +238,7 @@ func (r *Service) GetBytes(key string) ([]byte, error) {
 
 // Delete removes redis entry by specific key
 func (r *Service) Delete(key string) error {
-	c := r.pool.Get()
+	c := r.get()
 	defer c.Close()
 	if _, err := c.Do("DEL", r.Config.Prefix+key); err != nil {
 		return err
@@ -134,14 +246,174 @@ func (r *Service) Delete(key string) error {
 	return nil
 }
 
-func dial(network string, addr string, pass string) (redis.Conn, error) {
+// SetNX sets the "value" to the "key" if it doesn't exist, with the "ttl" expiration,
+// it reports whether the key was set.
+func (r *Service) SetNX(key string, value interface{}, ttl time.Duration) (bool, error) {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return false, err
+	}
+
+	reply, err := c.Do("SET", r.Config.Prefix+key, value, "PX", int64(ttl/time.Millisecond), "NX")
+	if err != nil {
+		return false, err
+	}
+
+	return reply != nil, nil
+}
+
+// CompareAndSet sets the "value" to the "key", with the "secondsLifetime" expiration, 0 for no expiration,
+// only if the "check" accepts its current value, nil if the key is missing, by a WATCH/MULTI/EXEC transaction,
+// a key which is modified between the check and the set is checked again.
+// It returns the current value and whether the key was set.
+func (r *Service) CompareAndSet(key string, value interface{}, secondsLifetime int, check func(current []byte) bool) ([]byte, bool, error) {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return nil, false, err
+	}
+
+	key = r.Config.Prefix + key
+	for {
+		if _, err := c.Do("WATCH", key); err != nil {
+			return nil, false, err
+		}
+
+		current, err := redis.Bytes(c.Do("GET", key))
+		if err != nil && err != redis.ErrNil {
+			c.Do("UNWATCH")
+			return nil, false, err
+		}
+
+		if !check(current) {
+			_, err = c.Do("UNWATCH")
+			return current, false, err
+		}
+
+		c.Send("MULTI")
+		if secondsLifetime > 0 {
+			c.Send("SETEX", key, secondsLifetime, value)
+		} else {
+			c.Send("SET", key, value)
+		}
+
+		reply, err := c.Do("EXEC")
+		if err != nil {
+			return nil, false, err
+		}
+		if reply != nil {
+			return current, true, nil
+		}
+		// modified by another client after the check.
+	}
+}
+
+// deleteIfScript deletes a key only if its value is the expected one.
+var deleteIfScript = redis.NewScript(1, `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// DeleteIf removes the "key" if its value is the "value", atomically,
+// it reports whether the key was removed.
+func (r *Service) DeleteIf(key string, value interface{}) (bool, error) {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return false, err
+	}
+
+	n, err := redis.Int(deleteIfScript.Do(c, r.Config.Prefix+key, value))
+	return n > 0, err
+}
+
+// hincrbyScript increments a hash field, the field is initialized to ARGV[3] if it's missing,
+// and the hash expires with the KEYS[2] key.
+var hincrbyScript = redis.NewScript(2, `if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then redis.call("HSET", KEYS[1], ARGV[1], ARGV[3]) end
+local n = redis.call("HINCRBY", KEYS[1], ARGV[1], ARGV[2])
+local ttl = redis.call("PTTL", KEYS[2])
+if ttl > 0 then redis.call("PEXPIRE", KEYS[1], ttl) end
+return n`)
+
+// HIncrBy increments the "field" of the "key" hash by the "delta", atomically, and returns its new value,
+// a missing field is initialized to the "initial", the hash expires with the "expiresWith" key.
+func (r *Service) HIncrBy(key, field string, initial, delta int64, expiresWith string) (int64, error) {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return 0, err
+	}
+
+	return redis.Int64(hincrbyScript.Do(c, r.Config.Prefix+key, r.Config.Prefix+expiresWith, field, delta, initial))
+}
+
+// Publish publishes the "message" to the "channel", the channel is prefixed by the `Config#Prefix`.
+func (r *Service) Publish(channel string, message []byte) error {
+	c := r.get()
+	defer c.Close()
+	if err := c.Err(); err != nil {
+		return err
+	}
+
+	_, err := c.Do("PUBLISH", r.Config.Prefix+channel, message)
+	return err
+}
+
+// Subscribe subscribes to the "channel", the channel is prefixed by the `Config#Prefix`,
+// the "onMessage" is called, from a separate goroutine, for each message which is published to it.
+// It returns a function which unsubscribes, the subscription is closed on connection errors too.
+func (r *Service) Subscribe(channel string, onMessage func(message []byte)) (func() error, error) {
+	psc := redis.PubSubConn{Conn: r.get()}
+	if err := psc.Subscribe(r.Config.Prefix + channel); err != nil {
+		psc.Close()
+		return nil, err
+	}
+
+	// the connection is read only by this goroutine, the unsubscribe waits for it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				onMessage(v.Data)
+			case redis.Subscription:
+				if v.Count == 0 {
+					return
+				}
+			case error:
+				return
+			}
+		}
+	}()
+
+	return func() error {
+		err := psc.Unsubscribe()
+		<-done
+		if closeErr := psc.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// conn is a pooled connection which knows its age,
+// used when the `Config#MaxConnLifetime` is set.
+type conn struct {
+	redis.Conn
+	createdAt time.Time
+}
+
+func dial(network string, addr string, pass string, timeout time.Duration) (redis.Conn, error) {
 	if network == "" {
 		network = DefaultRedisNetwork
 	}
 	if addr == "" {
 		addr = DefaultRedisAddr
 	}
-	c, err := redis.Dial(network, addr)
+	var options []redis.DialOption
+	if timeout > 0 {
+		options = append(options, redis.DialConnectTimeout(timeout))
+	}
+	c, err := redis.Dial(network, addr, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -170,15 +442,18 @@ func (r *Service) Connect() {
 		c.Addr = DefaultRedisAddr
 	}
 
-	pool := &redis.Pool{IdleTimeout: DefaultRedisIdleTimeout, MaxIdle: c.MaxIdle, MaxActive: c.MaxActive}
-	pool.TestOnBorrow = func(c redis.Conn, t time.Time) error {
-		_, err := c.Do("PING")
+	pool := &redis.Pool{IdleTimeout: c.IdleTimeout, MaxIdle: c.MaxIdle, MaxActive: c.MaxActive, Wait: c.Wait}
+	pool.TestOnBorrow = func(red redis.Conn, t time.Time) error {
+		if pc, ok := red.(*conn); ok && time.Since(pc.createdAt) > c.MaxConnLifetime {
+			return errConnExpired
+		}
+		_, err := red.Do("PING")
 		return err
 	}
 
 	if c.Database != "" {
 		pool.Dial = func() (redis.Conn, error) {
-			red, err := dial(c.Network, c.Addr, c.Password)
+			red, err := dial(c.Network, c.Addr, c.Password, c.DialTimeout)
 			if err != nil {
 				return nil, err
 			}
@@ -190,9 +465,30 @@ func (r *Service) Connect() {
 		}
 	} else {
 		pool.Dial = func() (redis.Conn, error) {
-			return dial(c.Network, c.Addr, c.Password)
+			return dial(c.Network, c.Addr, c.Password, c.DialTimeout)
+		}
+	}
+
+	if c.MaxConnLifetime > 0 {
+		dialConn := pool.Dial
+		pool.Dial = func() (redis.Conn, error) {
+			red, err := dialConn()
+			if err != nil {
+				return nil, err
+			}
+			return &conn{Conn: red, createdAt: time.Now()}, nil
 		}
 	}
+	dialConn := pool.Dial
+	pool.Dial = func() (redis.Conn, error) {
+		atomic.AddUint64(&r.dials, 1)
+		red, err := dialConn()
+		if err != nil {
+			atomic.AddUint64(&r.dialErrors, 1)
+		}
+		return red, err
+	}
+
 	r.Connected = true
 	r.pool = pool
 }
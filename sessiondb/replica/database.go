@@ -0,0 +1,190 @@
+package replica
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/golog"
+
+	"google.golang.org/grpc"
+)
+
+// Primary is the session database which mirrors every session write
+// of the in-memory provider to a standby process through a gRPC stream.
+//
+// It doesn't load anything, the memory of the primary is the source of truth,
+// register it with `sessions.UseDatabase(replica.NewPrimary(conn))`.
+type Primary struct {
+	conn grpc.ClientConnInterface
+
+	mu     sync.Mutex
+	stream grpc.ClientStream
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Primary) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Primary) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// NewPrimary returns a new replication database which mirrors the sessions to the standby peer of the "conn",
+// the connection is not closed by the database.
+func NewPrimary(conn grpc.ClientConnInterface) *Primary {
+	return &Primary{conn: conn}
+}
+
+// Load returns an empty store, the primary keeps its sessions in memory.
+func (db *Primary) Load(sid string) sessions.RemoteStore {
+	return sessions.RemoteStore{}
+}
+
+// Sync sends the session's changes to the standby peer,
+// if the stream is broken then it re-opens it and retries once.
+func (db *Primary) Sync(p sessions.SyncPayload) {
+	m := &message{SessionID: p.SessionID, Destroy: p.Action == sessions.ActionDestroy}
+	if !m.Destroy {
+		b, err := p.Store.Serialize()
+		if err != nil {
+			db.log().Errorf("error while serializing the session(%s) for the standby: %v", p.SessionID, err)
+			return
+		}
+		m.Store = b
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for retry := 0; retry < 2; retry++ {
+		if db.stream == nil {
+			stream, err := db.conn.NewStream(context.Background(), &serviceDesc.Streams[0], replicateMethod, grpc.CallContentSubtype(codecName))
+			if err != nil {
+				db.log().Errorf("error while opening the replication stream to the standby: %v", err)
+				return
+			}
+			db.stream = stream
+		}
+
+		err := db.stream.SendMsg(m)
+		if err == nil {
+			return
+		}
+
+		db.log().Warnf("error while replicating the session(%s) to the standby: %v", p.SessionID, err)
+		db.stream = nil
+	}
+}
+
+// Close closes the replication stream, if any.
+func (db *Primary) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.stream == nil {
+		return nil
+	}
+
+	stream := db.stream
+	db.stream = nil
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	// wait for the standby to apply all received messages.
+	return stream.RecvMsg(new(message))
+}
+
+// Standby is the session database of the standby process,
+// it keeps the replicated sessions of the primary in memory, serialized,
+// so its session manager can take over the sessions on a failover.
+//
+// Register it to the standby's manager with `sessions.UseDatabase(standby)`
+// and to its gRPC server with `standby.Register(grpcServer)`.
+type Standby struct {
+	mu     sync.RWMutex
+	stores map[string][]byte
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// Defaults to the golog's default logger.
+func (db *Standby) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+}
+
+func (db *Standby) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// NewStandby returns a new, empty, standby database.
+func NewStandby() *Standby {
+	return &Standby{stores: make(map[string][]byte)}
+}
+
+// Register registers the replication service to the gRPC server
+// which the primary's connection is dialing.
+func (db *Standby) Register(srv *grpc.Server) {
+	srv.RegisterService(&serviceDesc, db)
+}
+
+func (db *Standby) apply(m *message) {
+	db.mu.Lock()
+	if m.Destroy {
+		delete(db.stores, m.SessionID)
+	} else {
+		db.stores[m.SessionID] = m.Store
+	}
+	db.mu.Unlock()
+}
+
+// Len reports the number of the replicated sessions.
+func (db *Standby) Len() int {
+	db.mu.RLock()
+	n := len(db.stores)
+	db.mu.RUnlock()
+	return n
+}
+
+// Load loads a replicated session.
+func (db *Standby) Load(sid string) (storeDB sessions.RemoteStore) {
+	db.mu.RLock()
+	b, ok := db.stores[sid]
+	db.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	storeDB, err := sessions.DecodeRemoteStore(b)
+	if err != nil {
+		db.log().Errorf("error while trying to load the replicated session(%s): %v", sid, err)
+	}
+	return
+}
+
+// Sync keeps the standby's memory up to date with the changes
+// made after a failover, when the standby serves the requests itself.
+func (db *Standby) Sync(p sessions.SyncPayload) {
+	m := &message{SessionID: p.SessionID, Destroy: p.Action == sessions.ActionDestroy}
+	if !m.Destroy {
+		b, err := p.Store.Serialize()
+		if err != nil {
+			db.log().Errorf("error while serializing the session(%s): %v", p.SessionID, err)
+			return
+		}
+		m.Store = b
+	}
+
+	db.apply(m)
+}
@@ -0,0 +1,121 @@
+package replica
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newPeers returns a standby which is served by an in-process gRPC server and a connection to it.
+func newPeers(t *testing.T) (*Standby, *grpc.ClientConn, func()) {
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	standby := NewStandby()
+	standby.Register(srv)
+	go srv.Serve(ln)
+
+	conn, err := grpc.NewClient("passthrough:///standby",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return standby, conn, func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	standby, conn, stop := newPeers(t)
+	defer stop()
+
+	primary := NewPrimary(conn)
+	primary.Sync(sessionstest.Insert("sid", "kataras", 0))
+	primary.Sync(sessionstest.Insert("other", "makis", 0))
+	primary.Sync(sessions.SyncPayload{SessionID: "other", Action: sessions.ActionDestroy})
+
+	// waits for the standby to apply the messages.
+	if err := primary.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := standby.Len(); n != 1 {
+		t.Fatalf("expected 1 replicated session but got %d", n)
+	}
+	if store := standby.Load("sid"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the replicated session but got %v", store.Values)
+	}
+	if store := standby.Load("other"); len(store.Values) > 0 {
+		t.Fatalf("expected the destroyed session not to be replicated but got %v", store.Values)
+	}
+
+	if store := primary.Load("sid"); len(store.Values) > 0 {
+		t.Fatalf("expected the primary to load nothing but got %v", store.Values)
+	}
+}
+
+func TestReplicateAfterClose(t *testing.T) {
+	standby, conn, stop := newPeers(t)
+	defer stop()
+
+	primary := NewPrimary(conn)
+	primary.Sync(sessionstest.Insert("sid", "kataras", 0))
+	if err := primary.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stream is re-opened.
+	primary.Sync(sessionstest.Insert("sid", "makis", 0))
+	if err := primary.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if store := standby.Load("sid"); store.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the session to be replicated by the re-opened stream but got %v", store.Values)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	standby, conn, stop := newPeers(t)
+	defer stop()
+
+	primary := NewPrimary(conn)
+	manager := sessions.New(sessions.Config{})
+	manager.UseDatabase(primary)
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "kataras")
+	if err := primary.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the standby takes over.
+	takeover := sessions.New(sessions.Config{})
+	takeover.UseDatabase(standby)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	restored := takeover.Start(httptest.NewRecorder(), r)
+	if got := restored.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the standby to restore the session but got %q", got)
+	}
+
+	restored.Set("name", "makis")
+	if store := standby.Load(sess.ID()); store.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the standby to keep the changes after the failover but got %v", store.Values)
+	}
+}
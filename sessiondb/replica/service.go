@@ -0,0 +1,75 @@
+package replica
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	codecName       = "gosessions-replica"
+	replicateMethod = "/gosessions.Replica/Replicate"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// message is the replication unit which is sent from the primary to the standby.
+type message struct {
+	SessionID string
+	Destroy   bool
+	// Store is the serialized `sessions.RemoteStore`, empty on destroy.
+	Store []byte
+}
+
+// codec is the gRPC codec of the replication messages,
+// the service is described by hand so it doesn't require generated protobuf code.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	w := new(bytes.Buffer)
+	err := gob.NewEncoder(w).Encode(v)
+	return w.Bytes(), err
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gosessions.Replica",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Replicate",
+			Handler:       replicateHandler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "replica",
+}
+
+// replicateHandler applies the primary's messages to the standby,
+// until the primary closes the stream.
+func replicateHandler(srv interface{}, stream grpc.ServerStream) error {
+	db := srv.(*Standby)
+	for {
+		m := new(message)
+		if err := stream.RecvMsg(m); err != nil {
+			if err == io.EOF {
+				return stream.SendMsg(new(message))
+			}
+			return err
+		}
+
+		db.apply(m)
+	}
+}
@@ -0,0 +1,195 @@
+package tiered
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/golog"
+)
+
+// ErrDatabaseMissing returned on `New` when the cache or the backend database is nil.
+var ErrDatabaseMissing = errors.New("tiered: the cache and the backend databases are required")
+
+// Database is a two-tier session database, an in-process cache, i.e a `memory.Database`,
+// in front of a persistent backend, i.e a `redis.Database`.
+// The sessions are read through the cache and they are written through to the backend,
+// so the hot sessions are loaded without a round-trip to the backend.
+//
+// When many app instances share the backend, the changes of a session are broadcasted
+// by the "invalidator", i.e the `redis.Database#Invalidator`, and the other instances drop their cached copy of it.
+type Database struct {
+	cache       sessions.Database
+	backend     sessions.Database
+	invalidator sessions.Invalidator
+	// origin is the id of this instance, its own invalidations are ignored.
+	origin      string
+	unsubscribe func() error
+	// logger logs the errors, see `SetLogger`.
+	logger sessions.Logger
+}
+
+// SetLogger sets the logger of the database's errors, the manager's `Config#Logger` is set on its `UseDatabase`.
+// It's set to the cache and the backend too, if they implement the `sessions.LoggerSetter`.
+// Defaults to the golog's default logger.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	db.logger = logger
+
+	for _, inner := range []sessions.Database{db.cache, db.backend} {
+		if setter, ok := inner.(sessions.LoggerSetter); ok {
+			setter.SetLogger(logger)
+		}
+	}
+}
+
+// SetClock sets the manager's `sessions.Config#Clock`, on its `UseDatabase`,
+// to the cache and the backend, if they implement the `sessions.ClockSetter`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	for _, inner := range []sessions.Database{db.cache, db.backend} {
+		if setter, ok := inner.(sessions.ClockSetter); ok {
+			setter.SetClock(clock)
+		}
+	}
+}
+
+// SetEvictionNotifier sets the manager's notifier of the evicted sessions, on its `UseDatabase`,
+// to the cache and the backend, if they implement the `sessions.EvictionNotifierSetter`.
+func (db *Database) SetEvictionNotifier(notify func(sid string, reason sessions.EvictionReason, snapshot sessions.Store)) {
+	for _, inner := range []sessions.Database{db.cache, db.backend} {
+		if setter, ok := inner.(sessions.EvictionNotifierSetter); ok {
+			setter.SetEvictionNotifier(notify)
+		}
+	}
+}
+
+func (db *Database) log() sessions.Logger {
+	if db.logger != nil {
+		return db.logger
+	}
+	return golog.Default
+}
+
+// New returns a new two-tier database of the "cache" in front of the "backend".
+// The "invalidator" can be nil if the backend is not shared with other app instances.
+func New(cache, backend sessions.Database, invalidator sessions.Invalidator) (*Database, error) {
+	if cache == nil || backend == nil {
+		return nil, ErrDatabaseMissing
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	db := &Database{cache: cache, backend: backend, invalidator: invalidator, origin: hex.EncodeToString(b)}
+
+	if invalidator != nil {
+		unsubscribe, err := invalidator.Subscribe(db.invalidate)
+		if err != nil {
+			return nil, err
+		}
+		db.unsubscribe = unsubscribe
+	}
+
+	return db, nil
+}
+
+func (db *Database) invalidate(origin, sid string) {
+	if origin == db.origin {
+		return
+	}
+
+	db.cache.Sync(sessions.SyncPayload{SessionID: sid, Action: sessions.ActionDestroy})
+}
+
+func isEmpty(store sessions.RemoteStore) bool {
+	return len(store.Values) == 0 && store.Lifetime.IsZero()
+}
+
+// Load loads the session from the cache, on a miss it's loaded from the backend and it's cached.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	if store := db.cache.Load(sid); !isEmpty(store) {
+		return store
+	}
+
+	store := db.backend.Load(sid)
+	if !isEmpty(store) && !store.Lifetime.HasExpired() {
+		db.cache.Sync(sessions.SyncPayload{SessionID: sid, Action: sessions.ActionInsert, Store: store})
+	}
+
+	return store
+}
+
+// Scan calls the "visitor" for each session of the backend, if it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	if scanner, ok := db.backend.(sessions.Scanner); ok {
+		scanner.Scan(visitor)
+	}
+}
+
+// Sync writes the session to the backend and the cache
+// and broadcasts its invalidation to the other app instances.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	db.backend.Sync(p)
+	db.cache.Sync(p)
+	db.publish(p.SessionID)
+}
+
+// SyncVersion same as `Sync` but the session is written to the backend by its `sessions.VersionedDatabase`,
+// on a conflict the cached copy is dropped and the stored session is returned with the `sessions.ErrVersionConflict`.
+// It implements the `sessions.VersionedDatabase`.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	stored, err := sessions.SyncDatabaseVersion(db.backend, p)
+	if err != nil {
+		// the cached copy is stale or the write failed, the next load reads the backend.
+		db.cache.Sync(sessions.SyncPayload{SessionID: p.SessionID, Action: sessions.ActionDestroy})
+		return stored, err
+	}
+
+	db.cache.Sync(p)
+	db.publish(p.SessionID)
+	return stored, nil
+}
+
+// publish broadcasts the invalidation of the session to the other app instances, if there is an invalidator.
+func (db *Database) publish(sid string) {
+	if db.invalidator != nil {
+		if err := db.invalidator.Publish(db.origin, sid); err != nil {
+			db.log().Errorf("error while broadcasting the session invalidation: %v", err)
+		}
+	}
+}
+
+// Ping pings the cache and the backend databases, those which implement the `sessions.Pinger`,
+// it implements the `sessions.Pinger` too, see `Sessions#Healthy`.
+func (db *Database) Ping(ctx context.Context) error {
+	if err := sessions.PingDatabase(ctx, db.cache); err != nil {
+		return err
+	}
+	return sessions.PingDatabase(ctx, db.backend)
+}
+
+// Close unsubscribes from the invalidations and closes the cache and the backend databases,
+// those which implement the `sessions.Shutdowner` or the `io.Closer`.
+func (db *Database) Close() error {
+	return db.Shutdown(context.Background())
+}
+
+// Shutdown same as `Close` but the "ctx" is passed to the cache and the backend databases,
+// it implements the `sessions.Shutdowner`.
+func (db *Database) Shutdown(ctx context.Context) error {
+	var firstErr error
+	if db.unsubscribe != nil {
+		firstErr = db.unsubscribe()
+	}
+
+	for _, d := range []sessions.Database{db.cache, db.backend} {
+		if err := sessions.CloseDatabase(ctx, d); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,215 @@
+package tiered
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+// testInvalidator broadcasts the invalidations in memory.
+type testInvalidator struct {
+	mu   sync.Mutex
+	subs map[int]func(origin, sid string)
+	next int
+}
+
+func (inv *testInvalidator) Publish(origin, sid string) error {
+	inv.mu.Lock()
+	subs := make([]func(origin, sid string), 0, len(inv.subs))
+	for _, sub := range inv.subs {
+		subs = append(subs, sub)
+	}
+	inv.mu.Unlock()
+
+	for _, sub := range subs {
+		sub(origin, sid)
+	}
+	return nil
+}
+
+func (inv *testInvalidator) Subscribe(onInvalidate func(origin, sid string)) (func() error, error) {
+	inv.mu.Lock()
+	if inv.subs == nil {
+		inv.subs = make(map[int]func(origin, sid string))
+	}
+	id := inv.next
+	inv.next++
+	inv.subs[id] = onInvalidate
+	inv.mu.Unlock()
+
+	return func() error {
+		inv.mu.Lock()
+		delete(inv.subs, id)
+		inv.mu.Unlock()
+		return nil
+	}, nil
+}
+
+func (inv *testInvalidator) len() int {
+	inv.mu.Lock()
+	n := len(inv.subs)
+	inv.mu.Unlock()
+	return n
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil, sessionstest.NewDatabase(), nil); err != ErrDatabaseMissing {
+		t.Fatalf("expected the missing cache to be reported but got %v", err)
+	}
+	if _, err := New(sessionstest.NewDatabase(), nil, nil); err != ErrDatabaseMissing {
+		t.Fatalf("expected the missing backend to be reported but got %v", err)
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	cache, backend := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(cache, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	backend.Sync(sessionstest.Insert("sid", "kataras", 1))
+
+	loaded := db.Load("sid")
+	if got := loaded.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session to be loaded from the backend but got %q", got)
+	}
+	if _, ok := cache.Stored("sid"); !ok {
+		t.Fatalf("expected the loaded session to be cached")
+	}
+
+	// the next loads are served by the cache.
+	backend.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+	loaded = db.Load("sid")
+	if got := loaded.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session to be loaded from the cache but got %q", got)
+	}
+
+	if loaded = db.Load("missing"); len(loaded.Values) != 0 {
+		t.Fatalf("expected an empty session but got %v", loaded.Values)
+	}
+	if _, ok := cache.Stored("missing"); ok {
+		t.Fatalf("expected the missing session to not be cached")
+	}
+}
+
+func TestWriteThrough(t *testing.T) {
+	cache, backend := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(cache, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	for name, d := range map[string]*sessionstest.Database{"cache": cache, "backend": backend} {
+		stored, ok := d.Stored("sid")
+		if got := stored.Values.GetString("name"); !ok || got != "kataras" {
+			t.Fatalf("expected the session to be written to the %s but got %q", name, got)
+		}
+	}
+
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+	for name, d := range map[string]*sessionstest.Database{"cache": cache, "backend": backend} {
+		if _, ok := d.Stored("sid"); ok {
+			t.Fatalf("expected the session to be removed from the %s", name)
+		}
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	inv := new(testInvalidator)
+	backend := sessionstest.NewDatabase()
+
+	cache1, cache2 := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db1, err := New(cache1, backend, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db2, err := New(cache2, backend, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db1.Sync(sessionstest.Insert("sid", "kataras", 0))
+	if loaded := db2.Load("sid"); loaded.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session to be loaded by the second instance but got %v", loaded.Values)
+	}
+
+	db1.Sync(sessionstest.Insert("sid", "makis", 0))
+	// the own invalidation of the first instance is ignored.
+	if _, ok := cache1.Stored("sid"); !ok {
+		t.Fatalf("expected the session to be kept in the cache of the writer")
+	}
+	if _, ok := cache2.Stored("sid"); ok {
+		t.Fatalf("expected the cached copy of the other instance to be dropped")
+	}
+	if loaded := db2.Load("sid"); loaded.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the modified session to be loaded by the second instance but got %v", loaded.Values)
+	}
+
+	if err = db1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = db2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := inv.len(); n != 0 {
+		t.Fatalf("expected the instances to unsubscribe on close but got %d subscribers", n)
+	}
+}
+
+func TestScan(t *testing.T) {
+	cache, backend := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(cache, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	backend.Sync(sessionstest.Insert("backend", "kataras", 0))
+	cache.Sync(sessionstest.Insert("cache", "makis", 0))
+
+	var sids []string
+	db.Scan(func(sid string, store sessions.RemoteStore) bool {
+		sids = append(sids, sid)
+		return true
+	})
+	if len(sids) != 1 || sids[0] != "backend" {
+		t.Fatalf("expected the sessions of the backend to be scanned but got %q", sids)
+	}
+}
+
+func TestSyncVersion(t *testing.T) {
+	cache, backend := sessionstest.NewDatabase(), sessionstest.NewDatabase()
+	db, err := New(cache, backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err = db.SyncVersion(sessionstest.Insert("sid", "kataras", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Stored("sid"); !ok {
+		t.Fatalf("expected the written session to be cached")
+	}
+
+	// written by another app instance.
+	backend.Sync(sessionstest.Insert("sid", "makis", 2))
+
+	stored, err := db.SyncVersion(sessionstest.Insert("sid", "gerasimos", 2))
+	if err != sessions.ErrVersionConflict || stored.Values.GetString("name") != "makis" {
+		t.Fatalf("expected a version conflict with the stored session of the backend but got %v, %v", err, stored.Values)
+	}
+
+	if _, ok := cache.Stored("sid"); ok {
+		t.Fatalf("expected the stale cached copy to be dropped on the conflict")
+	}
+	if loaded := db.Load("sid"); loaded.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the stored session to be loaded from the backend but got %v", loaded.Values)
+	}
+}
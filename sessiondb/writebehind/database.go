@@ -0,0 +1,350 @@
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+const (
+	// DefaultInterval is the default interval of the flushes.
+	DefaultInterval = time.Second
+	// DefaultMaxPending is the default number of the pending sessions which triggers a flush.
+	DefaultMaxPending = 100
+)
+
+// ErrBackendMissing returned on `New` when the backend database is nil.
+var ErrBackendMissing = errors.New("writebehind: the backend database is required")
+
+// BatchSyncer is an optional interface of a backend database which can write many sessions at once,
+// i.e on a single round-trip, the `Database` flushes the pending sessions to it.
+type BatchSyncer interface {
+	SyncBatch(payloads []sessions.SyncPayload)
+}
+
+// Options are the flush options of the write-behind database.
+type Options struct {
+	// Interval is the interval of the flushes of the pending sessions.
+	//
+	// Defaults to the `DefaultInterval`
+	Interval time.Duration
+	// MaxPending is the number of the pending sessions which triggers a flush before the interval.
+	//
+	// Defaults to the `DefaultMaxPending`
+	MaxPending int
+	// Clock is the clock of the interval, the manager's `sessions.Config#Clock` is set on its `UseDatabase`, see `SetClock`.
+	//
+	// Defaults to the `sessions.SystemClock`
+	Clock sessions.Clock
+}
+
+// Database is a write-behind session database, the `Sync` calls are queued
+// and they are flushed to the backend database in batches, on an interval or when
+// too many sessions are pending, see `Options`.
+// The changes of a session are coalesced, only its latest state is written on a flush,
+// so a chatty session, i.e one which is modified by every request, is written once per interval.
+//
+// The pending changes are lost if the process crashes before they are flushed,
+// call the `Flush` or the `Close` on shutdown, the `Sessions#Close` calls the `Shutdown`.
+type Database struct {
+	backend sessions.Database
+	opts    Options
+
+	mu      sync.Mutex
+	pending map[string]sessions.SyncPayload
+	order   []string // the session ids of the pending, in the order of their first sync.
+	// flushing are the sessions which are being written by the current flush.
+	flushing map[string]sessions.SyncPayload
+
+	flushMu sync.Mutex // serializes the flushes, so the writes are kept in order.
+	// closed is true after the `Shutdown`, the next syncs are written through, it's changed under the "mu".
+	closed bool
+	// timer is the timer of the next interval flush, it's guarded by the "mu".
+	timer     sessions.Timer
+	trigger   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// New returns a new write-behind database in front of the "backend".
+func New(backend sessions.Database, opts ...Options) (*Database, error) {
+	if backend == nil {
+		return nil, ErrBackendMissing
+	}
+
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Interval <= 0 {
+		o.Interval = DefaultInterval
+	}
+
+	if o.MaxPending <= 0 {
+		o.MaxPending = DefaultMaxPending
+	}
+
+	if o.Clock == nil {
+		o.Clock = sessions.SystemClock
+	}
+
+	db := &Database{
+		backend: backend,
+		opts:    o,
+		pending: make(map[string]sessions.SyncPayload),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	db.mu.Lock()
+	db.schedule()
+	db.mu.Unlock()
+
+	go db.run()
+	return db, nil
+}
+
+// run flushes the pending sessions when too many of them are pending, see `Options#MaxPending`.
+func (db *Database) run() {
+	defer close(db.stopped)
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-db.trigger:
+		}
+
+		db.Flush()
+	}
+}
+
+// schedule schedules the next interval flush, if the database is not closed, see `Options#Interval`.
+// The caller should hold the lock.
+func (db *Database) schedule() {
+	db.timer = nil
+	if db.closed {
+		return
+	}
+
+	var t sessions.Timer
+	t = db.opts.Clock.NewTimer(db.opts.Interval, func() {
+		db.Flush()
+
+		db.mu.Lock()
+		// the timer is replaced by the `SetClock` or stopped by the `Shutdown`.
+		if db.timer == t {
+			db.schedule()
+		}
+		db.mu.Unlock()
+	})
+	db.timer = t
+}
+
+// Load returns the pending state of the session, if any, so the writes are visible before they are flushed,
+// otherwise the session is loaded from the backend.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	db.mu.Lock()
+	p, ok := db.pending[sid]
+	if !ok {
+		p, ok = db.flushing[sid]
+	}
+	db.mu.Unlock()
+
+	if !ok {
+		return db.backend.Load(sid)
+	}
+
+	if p.Action == sessions.ActionDestroy {
+		return sessions.RemoteStore{}
+	}
+
+	store := p.Store
+	store.Values = append(sessions.Store(nil), store.Values...)
+	return store
+}
+
+// Scan flushes the pending sessions and calls the "visitor" for each session of the backend,
+// if it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	if scanner, ok := db.backend.(sessions.Scanner); ok {
+		db.Flush()
+		scanner.Scan(visitor)
+	}
+}
+
+// Sync queues the session of the payload, it replaces its previous pending state.
+// After the `Close` the session is written to the backend before it returns.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	db.mu.Lock()
+	if _, ok := db.pending[p.SessionID]; !ok {
+		db.order = append(db.order, p.SessionID)
+	}
+	db.pending[p.SessionID] = p
+	full := len(db.pending) >= db.opts.MaxPending
+	closed := db.closed
+	db.mu.Unlock()
+
+	if closed {
+		// there are no more flushes, it's written after the pending ones, if any.
+		db.Flush()
+		return
+	}
+
+	if full {
+		select {
+		case db.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SyncVersion writes the session through to the backend, after its pending state if any,
+// by the backend's `sessions.VersionedDatabase`, so the compare-and-swap of the `sessions.Config#OptimisticConcurrency`
+// is not deferred, it implements the `sessions.VersionedDatabase`.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	// no flush is running, the session is not being written.
+	db.flushMu.Lock()
+	defer db.flushMu.Unlock()
+
+	db.mu.Lock()
+	prev, ok := db.pending[p.SessionID]
+	if ok {
+		delete(db.pending, p.SessionID)
+		for i, sid := range db.order {
+			if sid == p.SessionID {
+				db.order = append(db.order[:i], db.order[i+1:]...)
+				break
+			}
+		}
+	}
+	db.mu.Unlock()
+
+	if ok {
+		db.backend.Sync(prev)
+	}
+
+	return sessions.SyncDatabaseVersion(db.backend, p)
+}
+
+// SetLogger sets the logger of the backend, if it implements the `sessions.LoggerSetter`,
+// the manager's `Config#Logger` is set on its `UseDatabase`.
+func (db *Database) SetLogger(logger sessions.Logger) {
+	if setter, ok := db.backend.(sessions.LoggerSetter); ok {
+		setter.SetLogger(logger)
+	}
+}
+
+// SetClock sets the clock of the interval flushes and of the backend, if it implements the `sessions.ClockSetter`,
+// the manager's `sessions.Config#Clock` is set on its `UseDatabase`, see `Options#Clock`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	db.mu.Lock()
+	if db.timer != nil {
+		db.timer.Stop()
+	}
+	db.opts.Clock = clock
+	db.schedule()
+	db.mu.Unlock()
+
+	if setter, ok := db.backend.(sessions.ClockSetter); ok {
+		setter.SetClock(clock)
+	}
+}
+
+// SetEvictionNotifier sets the manager's notifier of the evicted sessions, on its `UseDatabase`,
+// to the backend, if it implements the `sessions.EvictionNotifierSetter`.
+func (db *Database) SetEvictionNotifier(notify func(sid string, reason sessions.EvictionReason, snapshot sessions.Store)) {
+	if setter, ok := db.backend.(sessions.EvictionNotifierSetter); ok {
+		setter.SetEvictionNotifier(notify)
+	}
+}
+
+// Ping pings the backend, if it implements the `sessions.Pinger`, see `Sessions#Healthy`.
+func (db *Database) Ping(ctx context.Context) error {
+	return sessions.PingDatabase(ctx, db.backend)
+}
+
+// Pending returns the number of the sessions which are waiting to be flushed.
+func (db *Database) Pending() int {
+	db.mu.Lock()
+	n := len(db.pending)
+	db.mu.Unlock()
+	return n
+}
+
+// Flush writes the pending sessions to the backend, it's called on the interval
+// or when too many sessions are pending, see `Options`.
+func (db *Database) Flush() {
+	db.flushMu.Lock()
+	defer db.flushMu.Unlock()
+
+	db.mu.Lock()
+	if len(db.order) == 0 {
+		db.mu.Unlock()
+		return
+	}
+
+	payloads := make([]sessions.SyncPayload, 0, len(db.order))
+	for _, sid := range db.order {
+		payloads = append(payloads, db.pending[sid])
+	}
+	db.flushing = db.pending
+	db.pending = make(map[string]sessions.SyncPayload, len(payloads))
+	db.order = nil
+	db.mu.Unlock()
+
+	if batch, ok := db.backend.(BatchSyncer); ok {
+		batch.SyncBatch(payloads)
+	} else {
+		for _, p := range payloads {
+			db.backend.Sync(p)
+		}
+	}
+
+	db.mu.Lock()
+	db.flushing = nil
+	db.mu.Unlock()
+}
+
+// Close stops the flushes, writes the pending sessions to the backend
+// and closes the backend if it implements the `sessions.Shutdowner` or the `io.Closer`.
+func (db *Database) Close() error {
+	return db.Shutdown(context.Background())
+}
+
+// Shutdown same as `Close` but it returns the "ctx"'s error if its deadline passes
+// before the pending sessions are written and the backend is closed,
+// it implements the `sessions.Shutdowner`, the `Sessions#Close` calls it.
+func (db *Database) Shutdown(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		db.closeOnce.Do(func() {
+			db.mu.Lock()
+			db.closed = true
+			if db.timer != nil {
+				db.timer.Stop()
+				db.timer = nil
+			}
+			db.mu.Unlock()
+
+			close(db.done)
+			<-db.stopped
+		})
+
+		db.Flush()
+		errCh <- sessions.CloseDatabase(ctx, db.backend)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
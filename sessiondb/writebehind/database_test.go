@@ -0,0 +1,221 @@
+package writebehind
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+)
+
+func TestFlushOnClose(t *testing.T) {
+	backend := sessionstest.NewDatabase()
+	db, err := New(backend, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := sessions.New(sessions.Config{})
+	manager.UseDatabase(db)
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "kataras")
+
+	if _, ok := backend.Stored(sess.ID()); ok {
+		t.Fatalf("expected the session to be pending before the close")
+	}
+
+	if err = manager.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, ok := backend.Stored(sess.ID())
+	if !ok || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the pending session to be flushed on close but got %v", stored.Values)
+	}
+}
+
+// blockingDatabase blocks its syncs until the "release" is closed.
+type blockingDatabase struct {
+	*sessionstest.Database
+	syncing chan struct{}
+	release chan struct{}
+}
+
+func (db *blockingDatabase) Sync(p sessions.SyncPayload) {
+	select {
+	case db.syncing <- struct{}{}:
+	default:
+	}
+	<-db.release
+	db.Database.Sync(p)
+}
+
+func TestCoalesce(t *testing.T) {
+	backend := sessionstest.NewDatabase()
+	db, err := New(backend, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	db.Sync(sessionstest.Insert("sid", "makis", 0))
+	db.Sync(sessionstest.Insert("other", "gerasimos", 0))
+	if n := db.Pending(); n != 2 {
+		t.Fatalf("expected 2 pending sessions but got %d", n)
+	}
+
+	db.Flush()
+	syncs := backend.Syncs()
+	if len(syncs) != 2 || syncs[0].SessionID != "sid" || syncs[1].SessionID != "other" {
+		t.Fatalf("expected one write per session, in the order of their first sync, but got %v", syncs)
+	}
+	if name := syncs[0].Store.Values.GetString("name"); name != "makis" {
+		t.Fatalf("expected the latest state of the session to be written but got %q", name)
+	}
+}
+
+func TestMaxPending(t *testing.T) {
+	backend := &blockingDatabase{
+		Database: sessionstest.NewDatabase(),
+		syncing:  make(chan struct{}, 2),
+		release:  make(chan struct{}),
+	}
+	close(backend.release)
+
+	db, err := New(backend, Options{Interval: time.Hour, MaxPending: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("a", "kataras", 0))
+	db.Sync(sessionstest.Insert("b", "makis", 0))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-backend.syncing:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the pending sessions to be flushed before the interval")
+		}
+	}
+}
+
+func TestInterval(t *testing.T) {
+	clock := sessions.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	backend := sessionstest.NewDatabase()
+	db, err := New(backend, Options{Interval: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	manager := sessions.New(sessions.Config{Clock: clock})
+	manager.UseDatabase(db)
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	clock.Advance(30 * time.Second)
+	if _, ok := backend.Stored("sid"); ok {
+		t.Fatalf("expected the session to be pending before the interval")
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, ok := backend.Stored("sid"); !ok {
+		t.Fatalf("expected the session to be flushed on the interval of the manager's clock")
+	}
+
+	// the next interval is scheduled.
+	db.Sync(sessionstest.Insert("other", "makis", 0))
+	clock.Advance(time.Minute)
+	if _, ok := backend.Stored("other"); !ok {
+		t.Fatalf("expected the session to be flushed on the next interval")
+	}
+}
+
+func TestLoadWhileFlushing(t *testing.T) {
+	backend := &blockingDatabase{
+		Database: sessionstest.NewDatabase(),
+		syncing:  make(chan struct{}, 1),
+		release:  make(chan struct{}),
+	}
+	db, err := New(backend, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	flushed := make(chan struct{})
+	go func() {
+		db.Flush()
+		close(flushed)
+	}()
+	<-backend.syncing
+
+	if store := db.Load("sid"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session which is being flushed to be loaded but got %v", store.Values)
+	}
+
+	close(backend.release)
+	<-flushed
+	if store := db.Load("sid"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the flushed session to be loaded from the backend but got %v", store.Values)
+	}
+}
+
+func TestSyncAfterShutdown(t *testing.T) {
+	backend := sessionstest.NewDatabase()
+	db, err := New(backend, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	if err = db.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stored, ok := backend.Stored("sid"); !ok || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the pending session to be flushed on shutdown")
+	}
+
+	db.Sync(sessionstest.Insert("sid", "makis", 0))
+	if stored, _ := backend.Stored("sid"); stored.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the sync after the shutdown to be written through but got %v", stored.Values)
+	}
+	if n := db.Pending(); n != 0 {
+		t.Fatalf("expected no pending sessions after the shutdown but got %d", n)
+	}
+}
+
+func TestSyncVersion(t *testing.T) {
+	backend := sessionstest.NewDatabase()
+	db, err := New(backend, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	first := sessionstest.Insert("sid", "kataras", 0)
+	first.Store.Version = 1
+	db.Sync(first)
+
+	second := sessionstest.Insert("sid", "makis", 0)
+	second.Store.Version = 2
+	if _, err = db.SyncVersion(second); err != nil {
+		t.Fatalf("expected the next version to be written after the pending one but got %v", err)
+	}
+	if stored, _ := backend.Stored("sid"); stored.Values.GetString("name") != "makis" || stored.Version != 2 {
+		t.Fatalf("expected the versioned sync to be written through but got %v of the version %d", stored.Values, stored.Version)
+	}
+	if n := db.Pending(); n != 0 {
+		t.Fatalf("expected the pending session to be written before the versioned sync but got %d pending", n)
+	}
+
+	stored, err := db.SyncVersion(second)
+	if err != sessions.ErrVersionConflict || stored.Version != 2 {
+		t.Fatalf("expected a version conflict with the stored version 2 but got %v, %d", err, stored.Version)
+	}
+}
@@ -26,8 +26,10 @@
 package sessions
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -48,6 +50,10 @@ const (
 type Sessions struct {
 	config   Config
 	provider *provider
+	// named are the named sessions' managers, see `StartNamed`.
+	named *namedSessions
+	// seriesLocks serialize the rotations of the remember-me series, by their hash, see `recall`.
+	seriesLocks [rememberMeLocks]sync.Mutex
 }
 
 // Default instance of the sessions, used for package-level functions.
@@ -55,10 +61,18 @@ var Default = New(Config{}.Validate())
 
 // New returns the fast, feature-rich sessions manager.
 func New(cfg Config) *Sessions {
-	return &Sessions{
+	s := &Sessions{
 		config:   cfg.Validate(),
-		provider: newProvider(),
+		provider: newProvider(cfg.Shards),
+		named:    newNamedSessions(),
 	}
+	s.provider.config = &s.config
+	return s
+}
+
+// Config returns a copy of the manager's configuration.
+func (s *Sessions) Config() Config {
+	return s.config
 }
 
 // UseDatabase adds a session database to the manager's provider.
@@ -73,14 +87,24 @@ func (s *Sessions) UseDatabase(db Database) {
 
 // updateCookie gains the ability of updating the session browser cookie to any method which wants to update it
 func (s *Sessions) updateCookie(w http.ResponseWriter, r *http.Request, sid string, expires time.Duration) {
+	if header := s.config.Header; header != "" {
+		w.Header().Set(header, s.formatHeader(s.encodeCookieValue(sid)))
+	}
+
+	if s.config.DisableCookie {
+		return
+	}
+
 	cookie := &http.Cookie{}
 
 	// The RFC makes no mention of encoding url value, so here I think to encode both sessionid key and the value using the safe(to put and to use as cookie) url-encoding
 	cookie.Name = s.config.Cookie
 
 	cookie.Value = sid
-	cookie.Path = "/"
-	if !s.config.DisableSubdomainPersistence {
+	cookie.Path = s.config.CookiePath
+	if domain := s.config.CookieDomain; domain != "" {
+		cookie.Domain = domain
+	} else if !s.config.DisableSubdomainPersistence {
 
 		requestDomain := r.URL.Host
 		if portIdx := strings.IndexByte(requestDomain, ':'); portIdx > 0 {
@@ -111,7 +135,8 @@ func (s *Sessions) updateCookie(w http.ResponseWriter, r *http.Request, sid stri
 		}
 	}
 
-	cookie.HttpOnly = true
+	cookie.HttpOnly = !s.config.DisableHTTPOnly
+	cookie.SameSite = s.config.CookieSameSite
 	// MaxAge=0 means no 'Max-Age' attribute specified.
 	// MaxAge<0 means delete cookie now, equivalently 'Max-Age: 0'
 	// MaxAge>0 means Max-Age attribute present and given in seconds
@@ -126,13 +151,11 @@ func (s *Sessions) updateCookie(w http.ResponseWriter, r *http.Request, sid stri
 
 	// set the cookie to secure if this is a tls wrapped request
 	// and the configuration allows it.
-	if r.TLS != nil && s.config.CookieSecureTLS {
-		cookie.Secure = true
-	}
+	cookie.Secure = s.secureCookie(r.TLS != nil)
 
 	// encode the session id cookie client value right before send it.
 	cookie.Value = s.encodeCookieValue(cookie.Value)
-	AddCookie(w, cookie)
+	s.addCookie(w, cookie)
 }
 
 // Start starts the session for the particular request.
@@ -142,25 +165,90 @@ func Start(w http.ResponseWriter, r *http.Request) *Session {
 
 // Start starts the session for the particular request.
 func (s *Sessions) Start(w http.ResponseWriter, r *http.Request) *Session {
-	cookieValue := s.decodeCookieValue(GetCookie(r, s.config.Cookie))
-
-	if cookieValue == "" { // cookie doesn't exists, let's generate a session and add set a cookie
-		sid := s.config.SessionIDGenerator()
+	return s.start(r.Context(), s.requestValue(r), &httpRequester{s: s, w: w, r: r})
+}
 
-		sess := s.provider.Init(sid, s.config.Expires)
-		sess.isNew = sess.values.Len() == 0
+// hydrate fills the new "sess" with the "values" of the `Config#Hydrator`,
+// a value which can't be written, i.e the session is read-only or too large, is logged.
+func (s *Sessions) hydrate(sess *Session, values Store) {
+	if err := sess.hydrate(values); err != nil {
+		s.provider.logger().Warnf("sessions: hydration of the session %s: %v", sess.ID(), err)
+	}
+}
 
-		s.updateCookie(w, r, sid, s.config.Expires)
+// hold returns the handle of the session for the request of the "ctx",
+// it waits for the session to be released by other requests
+// if the manager is configured to use a single writer per session.
+// The session is returned read-only if the "ctx" is done or the `Config#SingleWriterTimeout` passes first.
+// The "req" is kept by the request's handle until it's released, i.e to send the id of the `Config#PrivilegeKeys`.
+func (s *Sessions) hold(ctx context.Context, sess *Session, req requester) *Session {
+	sess = sess.newRequest()
+	sess.requester = req
 
+	if !s.config.SingleWriter {
 		return sess
 	}
 
-	sess := s.provider.Read(cookieValue, s.config.Expires)
+	timeout := s.config.SingleWriterTimeout
+	err := sess.acquire(ctx, timeout)
+	if err == nil && s.config.DistributedSingleWriter {
+		lockCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			lockCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err = sess.Lock(lockCtx)
+		cancel()
+		if err != nil {
+			sess.Release()
+		}
+	}
+
+	if err != nil {
+		s.provider.logger().Warnf("sessions: the session %s is read-only, it's not held by the request: %v", sess.ID(), err)
+		sess.SetReadOnly()
+	}
 
 	return sess
 }
 
+// RegenerateID moves the request's session to a new session id and sends it
+// to the client, the old session id is removed from the server and the client,
+// the standard defense against session fixation.
+// It should be called on privilege changes, i.e after login.
+//
+// It doesn't wait for the session to be released, see `Config#SingleWriter`.
+func RegenerateID(w http.ResponseWriter, r *http.Request) *Session {
+	return Default.RegenerateID(w, r)
+}
+
+// RegenerateID moves the request's session to a new session id and sends it
+// to the client, the old session id is removed from the server and the client,
+// the standard defense against session fixation.
+// It should be called on privilege changes, i.e after login.
+//
+// It doesn't wait for the session to be released, see `Config#SingleWriter`.
+func (s *Sessions) RegenerateID(w http.ResponseWriter, r *http.Request) *Session {
+	var sess *Session
+	if cookieValue := s.decodeCookieValue(s.requestValue(r)); cookieValue != "" {
+		sess = s.provider.Read(cookieValue, s.config.Expires)
+		sess.regenerateID(r.Context())
+	} else {
+		sess = s.provider.Init(s.config.IDGenerator(r.Context()), s.config.Expires)
+	}
+
+	s.updateCookie(w, r, sess.ID(), s.config.Expires)
+	return sess
+}
+
 func (s *Sessions) updateCookieFasthttp(ctx *fasthttp.RequestCtx, sid string, expires time.Duration) {
+	if header := s.config.Header; header != "" {
+		ctx.Response.Header.Set(header, s.formatHeader(s.encodeCookieValue(sid)))
+	}
+
+	if s.config.DisableCookie {
+		return
+	}
+
 	cookie := fasthttp.AcquireCookie()
 	defer fasthttp.ReleaseCookie(cookie)
 
@@ -168,8 +256,10 @@ func (s *Sessions) updateCookieFasthttp(ctx *fasthttp.RequestCtx, sid string, ex
 	cookie.SetKey(s.config.Cookie)
 
 	cookie.SetValue(sid)
-	cookie.SetPath("/")
-	if !s.config.DisableSubdomainPersistence {
+	cookie.SetPath(s.config.CookiePath)
+	if domain := s.config.CookieDomain; domain != "" {
+		cookie.SetDomain(domain)
+	} else if !s.config.DisableSubdomainPersistence {
 
 		requestDomain := string(ctx.Host())
 		if portIdx := strings.IndexByte(requestDomain, ':'); portIdx > 0 {
@@ -200,7 +290,9 @@ func (s *Sessions) updateCookieFasthttp(ctx *fasthttp.RequestCtx, sid string, ex
 		}
 	}
 
-	cookie.SetHTTPOnly(true)
+	cookie.SetHTTPOnly(!s.config.DisableHTTPOnly)
+	cookie.SetSameSite(sameSiteFasthttp(s.config.CookieSameSite))
+	cookie.SetPartitioned(s.config.CookiePartitioned)
 	// MaxAge=0 means no 'Max-Age' attribute specified.
 	// MaxAge<0 means delete cookie now, equivalently 'Max-Age: 0'
 	// MaxAge>0 means Max-Age attribute present and given in seconds
@@ -214,10 +306,7 @@ func (s *Sessions) updateCookieFasthttp(ctx *fasthttp.RequestCtx, sid string, ex
 
 	// set the cookie to secure if this is a tls wrapped request
 	// and the configuration allows it.
-
-	if ctx.IsTLS() && s.config.CookieSecureTLS {
-		cookie.SetSecure(true)
-	}
+	cookie.SetSecure(s.secureCookie(ctx.IsTLS()))
 
 	// encode the session id cookie client value right before send it.
 	cookie.SetValue(s.encodeCookieValue(string(cookie.Value())))
@@ -231,21 +320,35 @@ func StartFasthttp(ctx *fasthttp.RequestCtx) *Session {
 
 // StartFasthttp starts the session for the particular request.
 func (s *Sessions) StartFasthttp(ctx *fasthttp.RequestCtx) *Session {
-	cookieValue := s.decodeCookieValue(GetCookieFasthttp(ctx, s.config.Cookie))
-
-	if cookieValue == "" { // cookie doesn't exists, let's generate a session and add set a cookie
-		sid := s.config.SessionIDGenerator()
-
-		sess := s.provider.Init(sid, s.config.Expires)
-		sess.isNew = sess.values.Len() == 0
+	return s.start(ctx, s.requestValueFasthttp(ctx), &fasthttpRequester{s: s, ctx: ctx})
+}
 
-		s.updateCookieFasthttp(ctx, sid, s.config.Expires)
+// RegenerateIDFasthttp moves the request's session to a new session id and sends it
+// to the client, the old session id is removed from the server and the client,
+// the standard defense against session fixation.
+// It should be called on privilege changes, i.e after login.
+//
+// It doesn't wait for the session to be released, see `Config#SingleWriter`.
+func RegenerateIDFasthttp(ctx *fasthttp.RequestCtx) *Session {
+	return Default.RegenerateIDFasthttp(ctx)
+}
 
-		return sess
+// RegenerateIDFasthttp moves the request's session to a new session id and sends it
+// to the client, the old session id is removed from the server and the client,
+// the standard defense against session fixation.
+// It should be called on privilege changes, i.e after login.
+//
+// It doesn't wait for the session to be released, see `Config#SingleWriter`.
+func (s *Sessions) RegenerateIDFasthttp(ctx *fasthttp.RequestCtx) *Session {
+	var sess *Session
+	if cookieValue := s.decodeCookieValue(s.requestValueFasthttp(ctx)); cookieValue != "" {
+		sess = s.provider.Read(cookieValue, s.config.Expires)
+		sess.regenerateID(ctx)
+	} else {
+		sess = s.provider.Init(s.config.IDGenerator(ctx), s.config.Expires)
 	}
 
-	sess := s.provider.Read(cookieValue, s.config.Expires)
-
+	s.updateCookieFasthttp(ctx, sess.ID(), s.config.Expires)
 	return sess
 }
 
@@ -282,10 +385,10 @@ func UpdateExpiration(w http.ResponseWriter, r *http.Request, expires time.Durat
 // UpdateExpiration change expire date of a session to a new date
 // by using timeout value passed by `expires` receiver.
 func (s *Sessions) UpdateExpiration(w http.ResponseWriter, r *http.Request, expires time.Duration) {
-	cookieValue := s.decodeCookieValue(GetCookie(r, s.config.Cookie))
+	cookieValue := s.decodeCookieValue(s.requestValue(r))
 
 	if cookieValue != "" {
-		if s.provider.UpdateExpiration(cookieValue, expires) {
+		if expires, ok := s.provider.UpdateExpiration(cookieValue, expires); ok {
 			s.updateCookie(w, r, cookieValue, expires)
 		}
 	}
@@ -300,10 +403,10 @@ func UpdateExpirationFasthttp(ctx *fasthttp.RequestCtx, expires time.Duration) {
 // UpdateExpirationFasthttp change expire date of a session to a new date
 // by using timeout value passed by `expires` receiver.
 func (s *Sessions) UpdateExpirationFasthttp(ctx *fasthttp.RequestCtx, expires time.Duration) {
-	cookieValue := s.decodeCookieValue(GetCookieFasthttp(ctx, s.config.Cookie))
+	cookieValue := s.decodeCookieValue(s.requestValueFasthttp(ctx))
 
 	if cookieValue != "" {
-		if s.provider.UpdateExpiration(cookieValue, expires) {
+		if expires, ok := s.provider.UpdateExpiration(cookieValue, expires); ok {
 			s.updateCookieFasthttp(ctx, cookieValue, expires)
 		}
 	}
@@ -324,11 +427,19 @@ func Destroy(w http.ResponseWriter, r *http.Request) {
 	Default.Destroy(w, r)
 }
 
-// Destroy remove the session data and remove the associated cookie.
+// Destroy remove the session data and remove the associated cookie,
+// the `Config#ClearSiteData` header is sent too.
+// The remember-me login of the request is removed as well, see `Forget`.
 func (s *Sessions) Destroy(w http.ResponseWriter, r *http.Request) {
-	cookieValue := GetCookie(r, s.config.Cookie)
+	cookieValue := s.requestValue(r)
 	s.destroy(cookieValue)
-	RemoveCookie(w, r, s.config.Cookie)
+	if !s.config.DisableCookie {
+		s.removeCookie(w, r)
+	}
+	s.Forget(w, r)
+	if value := clearSiteData(s.config.ClearSiteData); value != "" {
+		w.Header().Set(clearSiteDataHeaderKey, value)
+	}
 }
 
 // DestroyFasthttp remove the session data and remove the associated cookie.
@@ -336,11 +447,19 @@ func DestroyFasthttp(ctx *fasthttp.RequestCtx) {
 	Default.DestroyFasthttp(ctx)
 }
 
-// DestroyFasthttp remove the session data and remove the associated cookie.
+// DestroyFasthttp remove the session data and remove the associated cookie,
+// the `Config#ClearSiteData` header is sent too.
+// The remember-me login of the request is removed as well, see `ForgetFasthttp`.
 func (s *Sessions) DestroyFasthttp(ctx *fasthttp.RequestCtx) {
-	cookieValue := GetCookieFasthttp(ctx, s.config.Cookie)
+	cookieValue := s.requestValueFasthttp(ctx)
 	s.destroy(cookieValue)
-	RemoveCookieFasthttp(ctx, s.config.Cookie)
+	if !s.config.DisableCookie {
+		s.removeCookieFasthttp(ctx)
+	}
+	s.ForgetFasthttp(ctx)
+	if value := clearSiteData(s.config.ClearSiteData); value != "" {
+		ctx.Response.Header.Set(clearSiteDataHeaderKey, value)
+	}
 }
 
 // DestroyByID removes the session entry
@@ -367,6 +486,106 @@ func (s *Sessions) DestroyByID(sid string) {
 	s.provider.Destroy(sid)
 }
 
+// DestroyByLogin removes the sessions which are bound to the identity provider's "idpSessionID"
+// or, if it's empty, all the sessions of the "subject", see `Session#BindLogin`,
+// from the server-side memory (and database if registered).
+// Returns the number of the removed sessions.
+func DestroyByLogin(subject, idpSessionID string) int {
+	return Default.DestroyByLogin(subject, idpSessionID)
+}
+
+// DestroyByLogin removes the sessions which are bound to the identity provider's "idpSessionID"
+// or, if it's empty, all the sessions of the "subject", see `Session#BindLogin`,
+// from the server-side memory (and database if registered).
+// Returns the number of the removed sessions.
+func (s *Sessions) DestroyByLogin(subject, idpSessionID string) int {
+	if idpSessionID != "" {
+		return s.provider.DestroyByClaim(claimKey("idp_sid", idpSessionID))
+	}
+
+	if subject != "" {
+		return s.provider.DestroyByClaim(claimKey("subject", subject))
+	}
+
+	return 0
+}
+
+// DestroyByUser removes all the sessions which are bound to the "userID", see `Session#BindUser`,
+// from the server-side memory (and database if registered).
+// The remember-me logins of the user are removed too.
+// Returns the number of the removed sessions.
+func DestroyByUser(userID string) int {
+	return Default.DestroyByUser(userID)
+}
+
+// DestroyByUser removes all the sessions which are bound to the "userID", see `Session#BindUser`,
+// from the server-side memory (and database if registered).
+// The remember-me logins of the user are removed too.
+// Returns the number of the removed sessions.
+func (s *Sessions) DestroyByUser(userID string) int {
+	if userID == "" {
+		return 0
+	}
+
+	if store := s.config.RememberMe; store != nil {
+		store.DeleteUser(userID)
+	}
+
+	return s.provider.DestroyByClaim(claimKey("user", userID))
+}
+
+// Visit calls the "visitor" for each active session, the sessions of the memory
+// and the sessions of the databases that implement the `Scanner`,
+// the visitor can return false to stop the iteration.
+// It's useful to list the active sessions on an admin dashboard,
+// a session can be revoked with the `DestroyByID`.
+//
+// The sessions of the databases are streamed, see `VisitWith` to filter and paginate them.
+func Visit(visitor func(sid string, sess *Session) bool) {
+	Default.Visit(visitor)
+}
+
+// Visit calls the "visitor" for each active session, the sessions of the memory
+// and the sessions of the databases that implement the `Scanner`,
+// the visitor can return false to stop the iteration.
+// It's useful to list the active sessions on an admin dashboard,
+// a session can be revoked with the `DestroyByID`.
+//
+// The sessions of the databases are streamed, see `VisitWith` to filter and paginate them.
+func (s *Sessions) Visit(visitor func(sid string, sess *Session) bool) {
+	s.provider.Visit(visitor)
+}
+
+// VisitWith calls the "visitor" for each active session which passes the "opts" filter
+// and is inside the "opts" offset and limit, see `Sessions#VisitWith`.
+func VisitWith(opts VisitOptions, visitor func(sid string, sess *Session) bool) {
+	Default.VisitWith(opts, visitor)
+}
+
+// VisitWith same as `Visit` but only the sessions which pass the "opts" filter
+// and are inside the "opts" offset and limit are visited, i.e to paginate the sessions of an admin dashboard.
+// The "opts.Prefix" is matched against the session id and the "opts.Predicate"
+// is called with the session id and the *Session as its value.
+// The scan of the databases stops when the limit is reached or the visitor returns false.
+func (s *Sessions) VisitWith(opts VisitOptions, visitor func(sid string, sess *Session) bool) {
+	s.provider.VisitWith(opts, visitor)
+}
+
+// Count returns the number of the active sessions, see `Visit`.
+func Count() int {
+	return Default.Count()
+}
+
+// Count returns the number of the active sessions, see `Visit`.
+func (s *Sessions) Count() int {
+	n := 0
+	s.provider.Visit(func(string, *Session) bool {
+		n++
+		return true
+	})
+	return n
+}
+
 // DestroyAll removes all sessions
 // from the server-side memory (and database if registered).
 // Client's session cookie will still exist but it will be reseted on the next request.
@@ -381,6 +600,33 @@ func (s *Sessions) DestroyAll() {
 	s.provider.DestroyAll()
 }
 
+// Close gracefully shuts down the `Default` sessions manager, see `Sessions#Close`.
+//
+// It should be called on the server's graceful shutdown.
+func Close(ctx context.Context) error {
+	return Default.Close(ctx)
+}
+
+// Close stops the sessions' expiration timers, flushes the modified sessions to the registered session databases,
+// so the values which were modified in place, i.e the pointers and the slices, are not lost, and closes them
+// (those which implement the `Shutdowner` or the `io.Closer`, i.e redis, boltdb, badger and leveldb), waiting for
+// their pending asynchronous writes to finish, the write-behind queues are flushed
+// and the garbage collections of the memory databases are stopped, the invalidator is unsubscribed, see `UseInvalidator`.
+// The named sessions' managers are closed too, see `StartNamed`.
+// It returns the "ctx"'s error if its deadline passed before the shutdown completes,
+// the databases are closed once, a next call waits for the first one to complete.
+// All of them are closed even if some fail, if more than one fails then a `CloseError` of their errors is returned.
+//
+// It should be called on the server's graceful shutdown, i.e on SIGTERM.
+func (s *Sessions) Close(ctx context.Context) error {
+	errs := s.named.close(ctx)
+	if err := s.provider.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return joinCloseErrors(errs)
+}
+
 // let's keep these funcs simple, we can do it with two lines but we may add more things in the future.
 func (s *Sessions) decodeCookieValue(cookieValue string) string {
 	if cookieValue == "" {
@@ -394,6 +640,7 @@ func (s *Sessions) decodeCookieValue(cookieValue string) string {
 		if err == nil {
 			cookieValue = *cookieValueDecoded
 		} else {
+			s.provider.logger().Warnf("sessions: decode of the %s cookie: %v", s.config.Cookie, err)
 			cookieValue = ""
 		}
 	}
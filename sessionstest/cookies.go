@@ -0,0 +1,61 @@
+package sessionstest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// Cookie returns the cookie of the "name", i.e the `sessions.Config#Cookie`, which the "w" response sets,
+// nil if it's not set.
+func Cookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// NextRequest returns a new request which carries the cookies of the "w" response,
+// as the next request of the client, so the session of the "w" is loaded by its `Start`, see `CarryCookies`.
+//
+// Usage:
+// w := httptest.NewRecorder()
+// handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+// r := sessionstest.NextRequest(w, http.MethodGet, "/profile", nil)
+func NextRequest(w *httptest.ResponseRecorder, method, target string, body io.Reader) *http.Request {
+	return CarryCookies(w, httptest.NewRequest(method, target, body))
+}
+
+// CarryCookies adds the cookies which the "w" response sets to the "r" request and returns it,
+// the cookies of the same name are replaced and the deleted ones, i.e by the `sessions.Destroy`, are removed,
+// as a browser's cookie jar does. The cookies' paths and domains are not matched.
+func CarryCookies(w *httptest.ResponseRecorder, r *http.Request) *http.Request {
+	cookies := r.Cookies()
+	now := time.Now()
+
+	for _, set := range w.Result().Cookies() {
+		deleted := set.MaxAge < 0 || (!set.Expires.IsZero() && !set.Expires.After(now))
+
+		n := 0
+		for _, cookie := range cookies {
+			if cookie.Name != set.Name {
+				cookies[n] = cookie
+				n++
+			}
+		}
+		cookies = cookies[:n]
+
+		if !deleted {
+			cookies = append(cookies, &http.Cookie{Name: set.Name, Value: set.Value})
+		}
+	}
+
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	return r
+}
@@ -0,0 +1,272 @@
+package sessionstest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+// Database is an in-memory fake session database with failure injection,
+// its syncs can fail, see `FailSync`, and its loads can be slow, see `SetAcquireDelay`.
+// It implements the `sessions.VersionedDatabase`, the `sessions.Scanner`, the `sessions.Locker`, the `sessions.Pinger`,
+// the `sessions.ClockSetter` and the `failover.FallibleDatabase`.
+type Database struct {
+	mu      sync.Mutex
+	stores  map[string]sessions.RemoteStore
+	locks   map[string]lock
+	syncs   []sessions.SyncPayload
+	syncErr error
+	pingErr error
+	failed  int
+	delay   time.Duration
+	clock   sessions.Clock
+}
+
+type lock struct {
+	token     string
+	expiresAt time.Time
+}
+
+var (
+	_ sessions.VersionedDatabase = (*Database)(nil)
+	_ sessions.Scanner           = (*Database)(nil)
+	_ sessions.Locker            = (*Database)(nil)
+	_ sessions.Pinger            = (*Database)(nil)
+	_ sessions.ClockSetter       = (*Database)(nil)
+)
+
+// NewDatabase returns a new empty fake database.
+func NewDatabase() *Database {
+	return &Database{
+		stores: make(map[string]sessions.RemoteStore),
+		locks:  make(map[string]lock),
+		clock:  sessions.SystemClock,
+	}
+}
+
+// SetClock sets the clock of the expirations of the sessions and of the locks, see `TryLock`,
+// the manager's `sessions.Config#Clock` is set on its `UseDatabase`, i.e a `sessions.ManualClock`.
+func (db *Database) SetClock(clock sessions.Clock) {
+	db.mu.Lock()
+	db.clock = clock
+	db.mu.Unlock()
+}
+
+// expired reports whether the "store" is expired by the clock, the caller should hold the lock.
+func (db *Database) expired(store sessions.RemoteStore) bool {
+	return !store.Lifetime.IsZero() && store.Lifetime.Time.Before(db.clock.Now())
+}
+
+// FailSync makes the next syncs fail with the "err", they are dropped and counted, see `Failed`,
+// the `SyncVersion` returns it. A nil "err" recovers the database.
+func (db *Database) FailSync(err error) {
+	db.mu.Lock()
+	db.syncErr = err
+	db.mu.Unlock()
+}
+
+// FailPing makes the next pings fail with the "err", i.e to test the readiness probes, see `sessions.Sessions#Healthy`.
+// A nil "err" recovers the database.
+func (db *Database) FailPing(err error) {
+	db.mu.Lock()
+	db.pingErr = err
+	db.mu.Unlock()
+}
+
+// Ping returns the error of the `FailPing`, it implements the `sessions.Pinger`.
+func (db *Database) Ping(ctx context.Context) error {
+	db.mu.Lock()
+	err := db.pingErr
+	db.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// SetAcquireDelay delays each `Load` and `TryLock` by the "d",
+// i.e to test the timeouts of a slow session store.
+func (db *Database) SetAcquireDelay(d time.Duration) {
+	db.mu.Lock()
+	db.delay = d
+	db.mu.Unlock()
+}
+
+func (db *Database) wait() {
+	db.mu.Lock()
+	d := db.delay
+	db.mu.Unlock()
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Load returns a copy of the stored session of the "sid", an empty store if it's missing or expired.
+func (db *Database) Load(sid string) sessions.RemoteStore {
+	db.wait()
+
+	db.mu.Lock()
+	store, ok := db.stores[sid]
+	expired := ok && db.expired(store)
+	db.mu.Unlock()
+	if !ok || expired {
+		return sessions.RemoteStore{}
+	}
+
+	store.Values = append(sessions.Store(nil), store.Values...)
+	return store
+}
+
+// Sync stores the session of the payload, it's removed on destroy.
+// It's dropped if the database fails, see `FailSync`.
+func (db *Database) Sync(p sessions.SyncPayload) {
+	db.sync(p, false)
+}
+
+// TrySync same as `Sync` but it returns the error of the `FailSync`,
+// it implements the `failover.FallibleDatabase`.
+func (db *Database) TrySync(p sessions.SyncPayload) error {
+	_, err := db.sync(p, false)
+	return err
+}
+
+// TryLoad same as `Load` but it returns the error of the `FailSync`, the database is down,
+// it implements the `failover.FallibleDatabase`.
+func (db *Database) TryLoad(sid string) (sessions.RemoteStore, error) {
+	db.mu.Lock()
+	err := db.syncErr
+	db.mu.Unlock()
+
+	if err != nil {
+		return sessions.RemoteStore{}, err
+	}
+	return db.Load(sid), nil
+}
+
+// SyncVersion same as `Sync` but the session is written only if it's missing or its stored version
+// is the previous version of the payload's, it returns the error of the `FailSync` too.
+func (db *Database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	return db.sync(p, true)
+}
+
+func (db *Database) sync(p sessions.SyncPayload, versioned bool) (sessions.RemoteStore, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.syncErr != nil {
+		db.failed++
+		return sessions.RemoteStore{}, db.syncErr
+	}
+
+	if versioned && p.Action != sessions.ActionDestroy {
+		if stored, ok := db.stores[p.SessionID]; ok && stored.Version != p.Store.Version-1 {
+			stored.Values = append(sessions.Store(nil), stored.Values...)
+			return stored, sessions.ErrVersionConflict
+		}
+	}
+
+	p.Store.Values = append(sessions.Store(nil), p.Store.Values...)
+	db.syncs = append(db.syncs, p)
+
+	if p.Action == sessions.ActionDestroy {
+		delete(db.stores, p.SessionID)
+		return sessions.RemoteStore{}, nil
+	}
+
+	db.stores[p.SessionID] = sessions.RemoteStore{
+		Values: p.Store.Values,
+		// the expiration timer of the manager is not kept.
+		Lifetime:   sessions.LifeTime{Time: p.Store.Lifetime.Time},
+		CreatedAt:  p.Store.CreatedAt,
+		Version:    p.Store.Version,
+		Extensions: p.Store.Extensions,
+	}
+	return sessions.RemoteStore{}, nil
+}
+
+// Scan calls the "visitor" for each non-expired session, it implements the `sessions.Scanner`.
+func (db *Database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	db.mu.Lock()
+	stores := make(map[string]sessions.RemoteStore, len(db.stores))
+	for sid, store := range db.stores {
+		if !db.expired(store) {
+			stores[sid] = store
+		}
+	}
+	db.mu.Unlock()
+
+	for sid, store := range stores {
+		store.Values = append(sessions.Store(nil), store.Values...)
+		if !visitor(sid, store) {
+			return
+		}
+	}
+}
+
+// TryLock acquires the lock of the session "sid" for the "ttl", it implements the `sessions.Locker`.
+func (db *Database) TryLock(sid, token string, ttl time.Duration) (bool, error) {
+	db.wait()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := db.clock.Now()
+	if l, ok := db.locks[sid]; ok && l.token != token && now.Before(l.expiresAt) {
+		return false, nil
+	}
+
+	db.locks[sid] = lock{token: token, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Unlock releases the lock of the session "sid" if it's held by the "token".
+func (db *Database) Unlock(sid, token string) error {
+	db.mu.Lock()
+	if l, ok := db.locks[sid]; ok && l.token == token {
+		delete(db.locks, sid)
+	}
+	db.mu.Unlock()
+	return nil
+}
+
+// Stored returns a copy of the stored session of the "sid" and true, or false if it's missing.
+func (db *Database) Stored(sid string) (sessions.RemoteStore, bool) {
+	db.mu.Lock()
+	store, ok := db.stores[sid]
+	db.mu.Unlock()
+
+	store.Values = append(sessions.Store(nil), store.Values...)
+	return store, ok
+}
+
+// Syncs returns the successful syncs, in order, i.e to assert their actions.
+func (db *Database) Syncs() []sessions.SyncPayload {
+	db.mu.Lock()
+	syncs := append([]sessions.SyncPayload(nil), db.syncs...)
+	db.mu.Unlock()
+	return syncs
+}
+
+// Failed returns the number of the failed syncs, see `FailSync`.
+func (db *Database) Failed() int {
+	db.mu.Lock()
+	n := db.failed
+	db.mu.Unlock()
+	return n
+}
+
+// Insert returns the insert sync of the session "sid" with the "name" value at the "version",
+// the version is zero for the databases which are not versioned.
+func Insert(sid, name string, version uint64) sessions.SyncPayload {
+	var values sessions.Store
+	values.Set("name", name)
+	return sessions.SyncPayload{
+		SessionID: sid,
+		Action:    sessions.ActionInsert,
+		Store:     sessions.RemoteStore{Values: values, Version: version},
+	}
+}
@@ -0,0 +1,144 @@
+package sessionstest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+func TestFailSync(t *testing.T) {
+	db := NewDatabase()
+	errDown := errors.New("connection refused")
+
+	db.Sync(Insert("sid", "kataras", 0))
+	db.FailSync(errDown)
+
+	db.Sync(Insert("sid", "makis", 0))
+	if err := db.TrySync(Insert("sid", "makis", 0)); err != errDown {
+		t.Fatalf("expected the error of the failed database but got %v", err)
+	}
+	if _, err := db.SyncVersion(Insert("sid", "makis", 1)); err != errDown {
+		t.Fatalf("expected the error of the failed database but got %v", err)
+	}
+	if _, err := db.TryLoad("sid"); err != errDown {
+		t.Fatalf("expected the error of the failed database but got %v", err)
+	}
+
+	if n := db.Failed(); n != 3 {
+		t.Fatalf("expected 3 failed syncs but got %d", n)
+	}
+	if syncs := db.Syncs(); len(syncs) != 1 {
+		t.Fatalf("expected the failed syncs to be dropped but got %d syncs", len(syncs))
+	}
+	if stored, _ := db.Stored("sid"); stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the stored session to be kept but got %v", stored.Values)
+	}
+
+	db.FailSync(nil)
+	if err := db.TrySync(Insert("sid", "makis", 0)); err != nil {
+		t.Fatalf("expected the database to be recovered but got %v", err)
+	}
+	if store, err := db.TryLoad("sid"); err != nil || store.Values.GetString("name") != "makis" {
+		t.Fatalf("expected the synced session but got %v, %v", err, store.Values)
+	}
+}
+
+func TestSyncVersion(t *testing.T) {
+	db := NewDatabase()
+
+	if _, err := db.SyncVersion(Insert("sid", "kataras", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := db.SyncVersion(Insert("sid", "makis", 1))
+	if err != sessions.ErrVersionConflict || stored.Values.GetString("name") != "kataras" || stored.Version != 1 {
+		t.Fatalf("expected a version conflict with the stored session but got %v, %v", err, stored)
+	}
+
+	if _, err = db.SyncVersion(Insert("sid", "makis", 2)); err != nil {
+		t.Fatalf("expected the next version to be written but got %v", err)
+	}
+	if stored, _ := db.Stored("sid"); stored.Values.GetString("name") != "makis" || stored.Version != 2 {
+		t.Fatalf("expected the session of the version 2 but got %v", stored)
+	}
+
+	destroy := sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy}
+	if _, err = db.SyncVersion(destroy); err != nil {
+		t.Fatalf("expected the destroy to be written regardless of the version but got %v", err)
+	}
+	if _, ok := db.Stored("sid"); ok {
+		t.Fatalf("expected the session to be removed")
+	}
+}
+
+func TestSetAcquireDelay(t *testing.T) {
+	db := NewDatabase()
+	db.SetAcquireDelay(20 * time.Millisecond)
+
+	start := time.Now()
+	db.Load("sid")
+	if _, err := db.TryLock("sid", "token", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the load and the lock to be delayed but they took %s", elapsed)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	clock := sessions.NewManualClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	db := NewDatabase()
+	db.SetClock(clock)
+
+	if ok, err := db.TryLock("sid", "a", time.Minute); !ok || err != nil {
+		t.Fatalf("expected the lock to be acquired but got %v, %v", ok, err)
+	}
+	if ok, _ := db.TryLock("sid", "b", time.Minute); ok {
+		t.Fatalf("expected the lock to be held by the first token")
+	}
+	if ok, _ := db.TryLock("sid", "a", time.Minute); !ok {
+		t.Fatalf("expected the lock to be renewed by its token")
+	}
+
+	clock.Advance(time.Minute + time.Second)
+	if ok, _ := db.TryLock("sid", "b", time.Minute); !ok {
+		t.Fatalf("expected the expired lock to be acquired by the second token")
+	}
+
+	db.Unlock("sid", "a")
+	if ok, _ := db.TryLock("sid", "a", time.Minute); ok {
+		t.Fatalf("expected the unlock of a foreign token to be ignored")
+	}
+	db.Unlock("sid", "b")
+	if ok, _ := db.TryLock("sid", "a", time.Minute); !ok {
+		t.Fatalf("expected the released lock to be acquired")
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	clock := sessions.NewManualClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	db := NewDatabase()
+	db.SetClock(clock)
+
+	p := Insert("sid", "kataras", 0)
+	p.Store.Lifetime = sessions.LifeTime{Time: clock.Now().Add(time.Hour)}
+	db.Sync(p)
+
+	if store := db.Load("sid"); store.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the session to be loaded but got %v", store.Values)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if store := db.Load("sid"); len(store.Values) != 0 {
+		t.Fatalf("expected the session to be expired by the clock but got %v", store.Values)
+	}
+
+	n := 0
+	db.Scan(func(string, sessions.RemoteStore) bool { n++; return true })
+	if n != 0 {
+		t.Fatalf("expected the expired session to be skipped by the scan but got %d sessions", n)
+	}
+}
@@ -0,0 +1,52 @@
+// Package sessionstest provides the test fixtures of the sessions,
+// a fake session database with failure injection, the pre-populated sessions of the handlers' unit tests
+// and the cookie round-trips of the end-to-end tests, see `NextRequest`.
+//
+// Usage:
+// sess := sessionstest.NewSession(t, map[string]interface{}{"user": "kataras"})
+// r = r.WithContext(sessions.NewContext(r.Context(), sess))
+package sessionstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/go-sessions"
+)
+
+// NewManager returns a new sessions manager of the "cfg" which is closed when the test ends.
+func NewManager(t testing.TB, cfg sessions.Config) *sessions.Sessions {
+	t.Helper()
+
+	manager := sessions.New(cfg)
+	t.Cleanup(func() {
+		if err := manager.Close(context.Background()); err != nil {
+			t.Errorf("sessionstest: close of the sessions manager: %v", err)
+		}
+	})
+	return manager
+}
+
+// NewSession returns a session of a new manager which holds the "values",
+// as a session which is loaded by a request, it's not new and not dirty, see `Session#Dirty`.
+// Inject it to the request of the tested handler by the `sessions.NewContext`.
+func NewSession(t testing.TB, values map[string]interface{}) *sessions.Session {
+	t.Helper()
+
+	manager := NewManager(t, sessions.Config{})
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	for key, value := range values {
+		sess.Set(key, value)
+	}
+	sess.Release()
+
+	// start it again, as the next request of the client.
+	loaded := manager.Start(httptest.NewRecorder(), NextRequest(w, http.MethodGet, "/", nil))
+	if loaded.ID() != sess.ID() {
+		t.Fatalf("sessionstest: the session %s was not loaded by its cookie", sess.ID())
+	}
+	return loaded
+}
@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// Shutdowner is an optional interface of a `Database` which drains its pending writes,
+// i.e a write-behind queue, and closes its connections, within the "ctx"'s deadline.
+// The `Sessions#Close` prefers it over the io.Closer.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// CloseDatabase closes the "db" by its `Shutdowner`, or its io.Closer if it doesn't implement it,
+// it does nothing if the "db" implements none of them.
+// It's useful for the session databases which wrap other databases, i.e a tiered one.
+func CloseDatabase(ctx context.Context, db Database) error {
+	switch closer := db.(type) {
+	case Shutdowner:
+		return closer.Shutdown(ctx)
+	case io.Closer:
+		return closer.Close()
+	default:
+		return nil
+	}
+}
+
+// CloseError is the error of the `Sessions#Close` when more than one session database,
+// or named sessions' manager, failed to close, its errors are kept in the order of the closes.
+type CloseError struct {
+	Errors []error
+}
+
+func (e *CloseError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the closes, so the errors.Is and errors.As match each one of them.
+func (e *CloseError) Unwrap() []error {
+	return e.Errors
+}
+
+// joinCloseErrors returns nil, the single error of the "errs" or a `CloseError` of them,
+// the errors of a nested `CloseError`, i.e of a named sessions' manager, are flattened.
+func joinCloseErrors(errs []error) error {
+	var flat []error
+	for _, err := range errs {
+		if closeErr, ok := err.(*CloseError); ok {
+			flat = append(flat, closeErr.Errors...)
+			continue
+		}
+		flat = append(flat, err)
+	}
+
+	switch len(flat) {
+	case 0:
+		return nil
+	case 1:
+		return flat[0]
+	default:
+		return &CloseError{Errors: flat}
+	}
+}
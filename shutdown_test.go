@@ -0,0 +1,153 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// shutdownDatabase drains its pending writes on shutdown, as a write-behind database.
+type shutdownDatabase struct {
+	*concurrentDatabase
+	delay     time.Duration
+	shutdowns int32
+}
+
+func (db *shutdownDatabase) Shutdown(ctx context.Context) error {
+	atomic.AddInt32(&db.shutdowns, 1)
+	select {
+	case <-time.After(db.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (db *shutdownDatabase) Close() error {
+	return errors.New("expected the Shutdown to be preferred")
+}
+
+func TestCloseShutdowner(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	db := &shutdownDatabase{concurrentDatabase: newConcurrentDatabase(), delay: 50 * time.Millisecond}
+	manager.UseDatabase(db)
+	manager.provider.Init("sid", time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := manager.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to pass but got %v", err)
+	}
+
+	// the next call waits for the first one.
+	if err := manager.Close(context.Background()); err == nil || err.Error() != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected the error of the first shutdown but got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&db.shutdowns); n != 1 {
+		t.Fatalf("expected the database to be shut down once but got %d", n)
+	}
+}
+
+func TestCloseDrains(t *testing.T) {
+	manager := New(Config{})
+	db := &shutdownDatabase{concurrentDatabase: newConcurrentDatabase(), delay: 10 * time.Millisecond}
+	manager.UseDatabase(db)
+
+	if err := manager.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingCloser fails its close.
+type failingCloser struct {
+	*concurrentDatabase
+}
+
+func (db *failingCloser) Close() error {
+	return errors.New("close failed")
+}
+
+func TestCloseAfterNamedError(t *testing.T) {
+	manager := New(Config{})
+	manager.AddNamed("cart", Config{}).UseDatabase(&failingCloser{concurrentDatabase: newConcurrentDatabase()})
+	db := &shutdownDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+
+	if err := manager.Close(context.Background()); err == nil || err.Error() != "close failed" {
+		t.Fatalf("expected the error of the named sessions but got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&db.shutdowns); n != 1 {
+		t.Fatalf("expected the databases to be closed after the error of the named sessions but got %d shutdowns", n)
+	}
+}
+
+func TestCloseErrors(t *testing.T) {
+	manager := New(Config{})
+	errNamed, errFirst, errSecond := errors.New("named"), errors.New("first"), errors.New("second")
+	manager.AddNamed("cart", Config{}).UseDatabase(&errorCloser{concurrentDatabase: newConcurrentDatabase(), err: errNamed})
+	manager.UseDatabase(&errorCloser{concurrentDatabase: newConcurrentDatabase(), err: errFirst})
+	manager.UseDatabase(&errorCloser{concurrentDatabase: newConcurrentDatabase(), err: errSecond})
+
+	err := manager.Close(context.Background())
+	closeErr, ok := err.(*CloseError)
+	if !ok {
+		t.Fatalf("expected a CloseError but got %T: %v", err, err)
+	}
+
+	expected := []error{errNamed, errFirst, errSecond}
+	if len(closeErr.Errors) != len(expected) {
+		t.Fatalf("expected %d errors but got %v", len(expected), closeErr.Errors)
+	}
+	for i, err := range expected {
+		if closeErr.Errors[i] != err {
+			t.Fatalf("[%d] expected the error %v but got %v", i, err, closeErr.Errors[i])
+		}
+	}
+
+	if msg := closeErr.Error(); msg != "named; first; second" {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+// errorCloser fails its close with its error.
+type errorCloser struct {
+	*concurrentDatabase
+	err error
+}
+
+func (db *errorCloser) Close() error {
+	return db.err
+}
+
+func TestCloseFlushes(t *testing.T) {
+	manager := New(Config{Expires: time.Hour})
+	db := newConcurrentDatabase()
+	manager.UseDatabase(db)
+
+	cart := []string{"book"}
+	modified := manager.provider.Init("modified", time.Hour)
+	modified.Set("cart", cart)
+	// a value which is modified in place, it's not synced by the write.
+	cart[0] = "pen"
+
+	manager.provider.Init("untouched", time.Hour)
+
+	if err := manager.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := db.Load("modified")
+	if got, ok := stored.Values.Get("cart").([]string); !ok || len(got) != 1 || got[0] != "pen" {
+		t.Fatalf("expected the modified session to be flushed but got %v", stored.Values)
+	}
+	if _, ok := db.stores["untouched"]; ok {
+		t.Fatalf("expected the unmodified session not to be flushed")
+	}
+}
@@ -0,0 +1,151 @@
+package sessions
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrHashKeyMissing returned by the `NewCookieSigner` when no hash key was given.
+	ErrHashKeyMissing = errors.New("cookie signer: at least one hash key is required")
+	// ErrInvalidSignature returned by the `CookieSigner#Decode` when the cookie value
+	// is not signed by any of its hash keys, i.e it's forged or edited.
+	ErrInvalidSignature = errors.New("cookie signer: invalid cookie value signature")
+)
+
+// CookieSigner signs, and optionally encrypts, the session id cookie value
+// with HMAC-SHA256, so the clients can't forge or enumerate session ids by editing their cookies.
+//
+// Keys can be rotated, the first hash and block keys are used to sign and encrypt
+// and all of them are tried to verify and decrypt.
+//
+// Usage:
+// signer, err := sessions.NewCookieSigner([][]byte{newHashKey, oldHashKey}, nil)
+// sessions.New(sessions.Config{Encode: signer.Encode, Decode: signer.Decode})
+type CookieSigner struct {
+	hashKeys [][]byte
+	aeads    []cipher.AEAD
+}
+
+// NewCookieSigner returns a new cookie signer which signs
+// with the first of the "hashKeys", the keys should be at least 32 bytes.
+// If "blockKeys" are not empty then the cookie values are encrypted
+// with AES-GCM too, each key should be 16, 24 or 32 bytes.
+func NewCookieSigner(hashKeys [][]byte, blockKeys [][]byte) (*CookieSigner, error) {
+	if len(hashKeys) == 0 {
+		return nil, ErrHashKeyMissing
+	}
+
+	aeads, err := newAEADs(blockKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CookieSigner{hashKeys: hashKeys, aeads: aeads}, nil
+}
+
+func (c *CookieSigner) sign(key []byte, cookieName string, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(cookieName))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Encode signs, and encrypts if block keys are given, the "value" of the "cookieName" cookie.
+// It can be used as the `Config#Encode`.
+func (c *CookieSigner) Encode(cookieName string, value interface{}) (string, error) {
+	sid, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("cookie signer: expected a string value but got %T", value)
+	}
+
+	b := []byte(sid)
+	if len(c.aeads) > 0 {
+		aead := c.aeads[0]
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		b = aead.Seal(nonce, nonce, b, []byte(cookieName))
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	signature := base64.RawURLEncoding.EncodeToString(c.sign(c.hashKeys[0], cookieName, payload))
+	return payload + "." + signature, nil
+}
+
+// Decode verifies, and decrypts if block keys are given, the "cookieValue"
+// of the "cookieName" cookie and sets the session id to the "v",
+// which should be a *string or a **string.
+// It can be used as the `Config#Decode`.
+func (c *CookieSigner) Decode(cookieName string, cookieValue string, v interface{}) error {
+	dot := strings.LastIndexByte(cookieValue, '.')
+	if dot <= 0 {
+		return ErrInvalidSignature
+	}
+
+	payload := cookieValue[:dot]
+	signature, err := base64.RawURLEncoding.DecodeString(cookieValue[dot+1:])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	valid := false
+	for _, key := range c.hashKeys {
+		if hmac.Equal(signature, c.sign(key, cookieName, payload)) {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if len(c.aeads) > 0 {
+		if b, err = c.decrypt(cookieName, b); err != nil {
+			return err
+		}
+	}
+
+	sid := string(b)
+	switch ptr := v.(type) {
+	case *string:
+		*ptr = sid
+	case **string:
+		*ptr = &sid
+	default:
+		return fmt.Errorf("cookie signer: expected a *string but got %T", v)
+	}
+
+	return nil
+}
+
+func (c *CookieSigner) decrypt(cookieName string, b []byte) ([]byte, error) {
+	for _, aead := range c.aeads {
+		nonceSize := aead.NonceSize()
+		if len(b) < nonceSize {
+			continue
+		}
+
+		decrypted, err := aead.Open(nil, b[:nonceSize], b[nonceSize:], []byte(cookieName))
+		if err == nil {
+			return decrypted, nil
+		}
+	}
+
+	return nil, ErrDecryption
+}
@@ -0,0 +1,46 @@
+package sessions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCookieSigner(t *testing.T) {
+	oldKey, newKey := []byte(strings.Repeat("o", 32)), []byte(strings.Repeat("n", 32))
+	blockKey := []byte("0123456789abcdef")
+
+	old, err := NewCookieSigner([][]byte{oldKey}, [][]byte{blockKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookieValue, err := old.Encode(DefaultCookieName, "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(cookieValue, "sid") {
+		t.Fatalf("expected the session id to be encrypted but got %s", cookieValue)
+	}
+
+	rotated, err := NewCookieSigner([][]byte{newKey, oldKey}, [][]byte{blockKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := New(Config{Encode: rotated.Encode, Decode: rotated.Decode})
+	if sid := manager.decodeCookieValue(cookieValue); sid != "sid" {
+		t.Fatalf("expected a value signed by a previous key to be decoded but got %q", sid)
+	}
+
+	forged := cookieValue[:len(cookieValue)-2] + "xx"
+	if sid := manager.decodeCookieValue(forged); sid != "" {
+		t.Fatalf("expected a forged value to be rejected but got %q", sid)
+	}
+
+	var sid string
+	removed, _ := NewCookieSigner([][]byte{newKey}, [][]byte{blockKey})
+	if err = removed.Decode(DefaultCookieName, cookieValue, &sid); err != ErrInvalidSignature {
+		t.Fatalf("expected %v but got %v", ErrInvalidSignature, err)
+	}
+}
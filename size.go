@@ -0,0 +1,87 @@
+package sessions
+
+import (
+	"errors"
+)
+
+// ErrSessionTooLarge returned by the writes of a session, i.e the `SetE`,
+// when its serialized values would exceed the `Config#MaxSessionSize` and the `Config#TrimPolicy` can't make room.
+var ErrSessionTooLarge = errors.New("sessions: the serialized session exceeds the size limit")
+
+// TrimPolicy selects the entry to remove from the "values" so a write of the "key" fits the `Config#MaxSessionSize`,
+// it's called repeatedly until the session fits, it returns false to reject the write instead.
+// The "key"'s entry can't be removed, see `TrimOldest`.
+type TrimPolicy func(values Store, key string) (string, bool)
+
+// TrimOldest is a `TrimPolicy` which removes the oldest mutable entries first,
+// the entries are kept in the order they were inserted.
+func TrimOldest(values Store, key string) (string, bool) {
+	for _, kv := range values {
+		if kv.Key != key && !kv.immutable {
+			return kv.Key, true
+		}
+	}
+
+	return "", false
+}
+
+// storeSize returns the length of the serialized "values", see `Store.SerializeE`.
+// A store which can't be serialized reports zero, its error is reported by the session databases.
+func storeSize(values Store) int {
+	b, err := values.SerializeE()
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// fitSize applies the "write" of the "key" to a copy of the session's values
+// and returns the keys which should be removed so the session fits the `Config#MaxSessionSize`,
+// or the `ErrSessionTooLarge` if it can't fit. It's called with the session locked.
+func (s *Session) fitSize(key string, write func(values *Store)) ([]string, error) {
+	cfg := s.provider.config
+	if cfg == nil || cfg.MaxSessionSize <= 0 {
+		return nil, nil
+	}
+
+	values := append(acquireStore(), s.values...)
+	defer func() { releaseStore(values) }()
+	write(&values)
+
+	var trimmed []string
+	for storeSize(values) > cfg.MaxSessionSize {
+		var (
+			remove string
+			ok     bool
+		)
+		if cfg.TrimPolicy != nil {
+			remove, ok = cfg.TrimPolicy(values, key)
+		}
+
+		if !ok || remove == key || !values.Remove(remove) {
+			s.provider.logger().Warnf("sessions: write of the key %q exceeds the size limit of the session %s", key, s.sid)
+			return nil, ErrSessionTooLarge
+		}
+		trimmed = append(trimmed, remove)
+	}
+
+	return trimmed, nil
+}
+
+// trim removes the "keys" which the `fitSize` selected, it's called with the session locked.
+func (s *Session) trim(keys []string) {
+	for _, key := range keys {
+		s.recordRemove(key)
+		s.values.Remove(key)
+	}
+}
+
+// syncTrimmed syncs the removals of the trimmed "keys" to the session databases.
+func (s *Session) syncTrimmed(keys []string) {
+	for _, key := range keys {
+		p := acquireSyncPayload(s, ActionDelete)
+		p.Value = Entry{Key: key}
+		syncDatabases(s.provider.databases, p)
+		s.provider.hooks.fireUpdate(p.SessionID, ActionDelete, key)
+	}
+}
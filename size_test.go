@@ -0,0 +1,64 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxSessionSize(t *testing.T) {
+	var values Store
+	values.Set("a", strings.Repeat("a", 100))
+	limit := storeSize(values) + 50
+
+	manager := New(Config{MaxSessionSize: limit})
+	db := &countingDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	sess.Set("a", strings.Repeat("a", 100))
+	if err := sess.SetE("b", strings.Repeat("b", 100)); err != ErrSessionTooLarge {
+		t.Fatalf("expected %v but got %v", ErrSessionTooLarge, err)
+	}
+	sess.Append("c", strings.Repeat("c", 100))
+	if sess.Get("b") != nil || sess.Get("c") != nil {
+		t.Fatalf("expected the large writes to be rejected")
+	}
+
+	stored := db.stores[sess.ID()]
+	if stored.Values.Len() != 1 {
+		t.Fatalf("expected the rejected writes to not be synced but got %d entries", stored.Values.Len())
+	}
+}
+
+func TestTrimOldest(t *testing.T) {
+	var values Store
+	values.Set("a", strings.Repeat("a", 100))
+	limit := storeSize(values) + 50
+
+	manager := New(Config{MaxSessionSize: limit, TrimPolicy: TrimOldest})
+	db := &countingDatabase{concurrentDatabase: newConcurrentDatabase()}
+	manager.UseDatabase(db)
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	sess.SetImmutable("user", "kataras")
+	sess.Set("a", strings.Repeat("a", 100))
+	if err := sess.SetE("b", strings.Repeat("b", 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	if sess.Get("a") != nil || sess.GetString("user") != "kataras" || sess.Get("b") == nil {
+		t.Fatalf("expected the oldest mutable entry to be removed but got %v", sess.GetAll())
+	}
+
+	stored := db.stores[sess.ID()]
+	if stored.Values.Get("a") != nil || stored.Values.Get("b") == nil {
+		t.Fatalf("expected the removal to be synced but got %v", stored.Values)
+	}
+
+	// the written entry itself can't be trimmed.
+	if err := sess.SetE("b", strings.Repeat("b", limit)); err != ErrSessionTooLarge {
+		t.Fatalf("expected %v but got %v", ErrSessionTooLarge, err)
+	}
+}
@@ -0,0 +1,54 @@
+package sessions
+
+import (
+	"sync/atomic"
+)
+
+// Stats are the statistics of the sessions of a manager, see `Sessions#Stats`.
+type Stats struct {
+	// Active is the number of the sessions in memory,
+	// unlike the `Sessions#Count` the sessions which are stored to the databases only are not counted.
+	Active int
+	// Hits is the number of the reads of a session which was in memory,
+	// Misses is the number of the reads of a session which was loaded from the databases or created.
+	Hits   uint64
+	Misses uint64
+}
+
+// GetStats returns the statistics of the sessions, see `Sessions#Stats`.
+func GetStats() Stats {
+	return Default.Stats()
+}
+
+// Stats returns the statistics of the sessions, i.e to be exported as metrics.
+// It's cheap, the databases are not scanned.
+func (s *Sessions) Stats() Stats {
+	p := s.provider
+	active := p.sessions.len()
+
+	return Stats{
+		Active: active,
+		Hits:   atomic.LoadUint64(&p.hits),
+		Misses: atomic.LoadUint64(&p.misses),
+	}
+}
+
+// PoolStats are the statistics of the connection pool of a session database, see `PoolStater`.
+type PoolStats struct {
+	// Active is the number of the connections of the pool, in use and idle,
+	// InUse the number of the connections which are used and Idle the number of the ones which wait in the pool.
+	Active int
+	InUse  int
+	Idle   int
+	// MaxActive is the maximum number of the connections of the pool, zero means no limit.
+	MaxActive int
+	// Dials is the number of the dialed connections and DialErrors the number of the failed ones.
+	Dials      uint64
+	DialErrors uint64
+}
+
+// PoolStater is an optional interface of a session database which keeps a connection pool,
+// i.e the redis one, its statistics are exported by the "metrics" subpackage.
+type PoolStater interface {
+	PoolStats() PoolStats
+}
@@ -0,0 +1,30 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	manager := New(Config{})
+
+	w := httptest.NewRecorder()
+	manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	manager.Start(httptest.NewRecorder(), r)
+	manager.Start(httptest.NewRecorder(), r)
+
+	if expected, got := (Stats{Active: 1, Hits: 2, Misses: 0}), manager.Stats(); got != expected {
+		t.Fatalf("expected the stats to be %#v but got %#v", expected, got)
+	}
+
+	manager.Destroy(httptest.NewRecorder(), r)
+	manager.Start(httptest.NewRecorder(), r)
+
+	if expected, got := (Stats{Active: 1, Hits: 2, Misses: 1}), manager.Stats(); got != expected {
+		t.Fatalf("expected the stats to be %#v but got %#v", expected, got)
+	}
+}
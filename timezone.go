@@ -0,0 +1,132 @@
+package sessions
+
+import (
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// LocationKey is the session key which the preferred location of the session is stored to,
+// by its IANA name, see `Session#SetLocation`.
+const LocationKey = "location"
+
+func init() {
+	gob.Register(ZonedTime{})
+}
+
+// ZonedTime is a time value with an explicit zone, see `Session#SetTime`.
+//
+// The gob and json encodings of a time.Time keep its offset but not its location,
+// so the zone semantics, i.e the daylight saving time, are lost on the session databases' round-trips.
+// The ZonedTime keeps the instant in UTC and the location by its name instead.
+type ZonedTime struct {
+	UTC time.Time
+	// Zone is the name of the location, i.e "Europe/Athens".
+	Zone string
+	// Offset is the offset of the zone in seconds east of UTC,
+	// it's used when the zone can't be loaded, i.e a fixed zone or the "Local" of another machine.
+	Offset int
+}
+
+// NewZonedTime returns the zoned time of the "t".
+func NewZonedTime(t time.Time) ZonedTime {
+	_, offset := t.Zone()
+	return ZonedTime{UTC: t.UTC(), Zone: t.Location().String(), Offset: offset}
+}
+
+// Time returns the time of the zoned time in its zone.
+func (z ZonedTime) Time() time.Time {
+	return z.UTC.In(loadLocation(z.Zone, z.Offset))
+}
+
+// loadLocation returns the location of the "name",
+// a fixed zone of the "offset" if it can't be loaded.
+func loadLocation(name string, offset int) *time.Location {
+	switch name {
+	case "", "UTC":
+		if offset == 0 {
+			return time.UTC
+		}
+	case "Local":
+		// the local zone of the machine which stored the time may differ.
+	default:
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	return time.FixedZone(name, offset)
+}
+
+// SetTime sets the "t" to the "key" as a `ZonedTime`, so its zone is kept
+// by the session databases, read it by the `GetTime` or the `GetTimeIn`.
+func (s *Session) SetTime(key string, t time.Time) {
+	s.Set(key, NewZonedTime(t))
+}
+
+// GetTime returns the time value of the "key" in the zone it was stored with, see `SetTime`.
+// It accepts the time.Time values and the RFC3339 strings too,
+// and the `ZonedTime` values which are decoded by a json session database.
+// If not found then it returns the zero time and an error.
+func (s *Session) GetTime(key string) (time.Time, error) {
+	v := s.Get(key)
+
+	switch t := v.(type) {
+	case ZonedTime:
+		return t.Time(), nil
+	case *ZonedTime:
+		if t != nil {
+			return t.Time(), nil
+		}
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, t)
+	case map[string]interface{}:
+		// a ZonedTime of the JSONTranscoder.
+		if utc, ok := t["UTC"].(string); ok {
+			parsed, err := time.Parse(time.RFC3339Nano, utc)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			zone, _ := t["Zone"].(string)
+			offset, _, _ := numberToInt(t["Offset"])
+			if f, ok := t["Offset"].(float64); ok {
+				offset = int(f)
+			}
+			return ZonedTime{UTC: parsed, Zone: zone, Offset: offset}.Time(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(errIntParseFormat, "time", key, v)
+}
+
+// SetLocation sets the preferred location of the session, i.e the user's time zone,
+// it's stored to the `LocationKey` by its name, see `GetTimeIn`.
+func (s *Session) SetLocation(loc *time.Location) {
+	s.Set(LocationKey, loc.String())
+}
+
+// Location returns the preferred location of the session, see `SetLocation`,
+// it returns the time.UTC if it's missing or it can't be loaded.
+func (s *Session) Location() *time.Location {
+	if name := s.GetString(LocationKey); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	return time.UTC
+}
+
+// GetTimeIn same as `GetTime` but it returns the time in the preferred location of the session,
+// see `SetLocation`, i.e to render the stored times in the user's time zone.
+func (s *Session) GetTimeIn(key string) (time.Time, error) {
+	t, err := s.GetTime(key)
+	if err != nil {
+		return t, err
+	}
+
+	return t.In(s.Location()), nil
+}
@@ -0,0 +1,67 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionTimeZones(t *testing.T) {
+	athens, err := time.LoadLocation("Europe/Athens")
+	if err != nil {
+		t.Skip("the time zone database is missing")
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("the time zone database is missing")
+	}
+
+	sess := New(Config{}).Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	loggedIn := time.Date(2018, time.July, 1, 12, 0, 0, 0, athens)
+
+	// the round-trips of the gob and json session databases.
+	for _, transcoder := range []Transcoder{GobTranscoder{}, JSONTranscoder{}} {
+		sess.SetTime("logged_in", loggedIn)
+
+		b, err := transcoder.Marshal(Store{{Key: "logged_in", ValueRaw: sess.Get("logged_in")}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var store Store
+		if err = transcoder.Unmarshal(b, &store); err != nil {
+			t.Fatal(err)
+		}
+		sess.Set("logged_in", store.Get("logged_in"))
+
+		got, err := sess.GetTime("logged_in")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !got.Equal(loggedIn) || got.Location().String() != "Europe/Athens" {
+			t.Fatalf("%T: expected %s but got %s in %s", transcoder, loggedIn, got, got.Location())
+		}
+
+		// the zone rules are kept, not only the offset of the stored time.
+		if _, offset := got.AddDate(0, 6, 0).Zone(); offset != 2*60*60 {
+			t.Fatalf("%T: expected the winter offset of the zone but got %d", transcoder, offset)
+		}
+	}
+
+	sess.SetLocation(newYork)
+	got, err := sess.GetTimeIn("logged_in")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(loggedIn) || got.Location().String() != "America/New_York" || got.Hour() != 5 {
+		t.Fatalf("expected the time in New York but got %s", got)
+	}
+
+	if _, err = sess.GetTime("missing"); err == nil {
+		t.Fatal("expected an error for a missing time")
+	}
+}
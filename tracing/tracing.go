@@ -0,0 +1,179 @@
+// Package tracing traces the operations of the session databases with OpenTelemetry,
+// so the slow session loads and writes show up in the distributed traces.
+//
+// Usage:
+// manager.UseDatabase(tracing.Database(redis.New(...), tracing.Options{System: "redis"}))
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer.
+const instrumentationName = "github.com/kataras/go-sessions/tracing"
+
+// Options are the options of the traced database.
+type Options struct {
+	// TracerProvider creates the tracer of the spans.
+	//
+	// Defaults to the global provider, otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+	// System is the value of the "db.system" attribute, i.e "redis".
+	//
+	// Defaults to empty, the attribute is not set
+	System string
+}
+
+// Database returns the "db" which traces its operations, the `Load`, the `Sync` and the destroy,
+// the `Scan`, the `SyncVersion` of a `sessions.VersionedDatabase` and the `TryLock` and the `Unlock` of a `sessions.Locker`.
+// The session ids are hashed, the "session.id_hash" attribute, so they are not leaked to the traces.
+//
+// The database operations have no request context, their spans are the roots of new traces.
+// The wrapper implements the `sessions.Scanner`, the `sessions.VersionedDatabase`, the `sessions.Locker` and the `io.Closer` of the "db".
+func Database(db sessions.Database, opts ...Options) sessions.Database {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.TracerProvider == nil {
+		o.TracerProvider = otel.GetTracerProvider()
+	}
+
+	t := &database{Database: db, tracer: o.TracerProvider.Tracer(instrumentationName), system: o.System}
+	if locker, ok := db.(sessions.Locker); ok {
+		return &lockerDatabase{database: t, locker: locker}
+	}
+
+	return t
+}
+
+type database struct {
+	sessions.Database
+	tracer trace.Tracer
+	system string
+}
+
+// hashID returns the hex of the first 8 bytes of the SHA-256 of the "sid",
+// it identifies the session in the traces without its id.
+func hashID(sid string) string {
+	h := sha256.Sum256([]byte(sid))
+	return hex.EncodeToString(h[:8])
+}
+
+func (db *database) start(operation, sid string, attrs ...attribute.KeyValue) trace.Span {
+	if sid != "" {
+		attrs = append(attrs, attribute.String("session.id_hash", hashID(sid)))
+	}
+
+	if db.system != "" {
+		attrs = append(attrs, attribute.String("db.system", db.system))
+	}
+
+	attrs = append(attrs, attribute.String("db.operation", operation))
+
+	_, span := db.tracer.Start(context.Background(), "sessions."+operation,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return span
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (db *database) Load(sid string) sessions.RemoteStore {
+	span := db.start("load", sid)
+	store := db.Database.Load(sid)
+
+	found := len(store.Values) > 0 || !store.Lifetime.IsZero()
+	span.SetAttributes(attribute.Int("session.entries", len(store.Values)), attribute.Bool("session.found", found))
+	if found && !store.Lifetime.IsZero() {
+		span.SetAttributes(attribute.Int("session.ttl_seconds", int(time.Until(store.Lifetime.Time).Seconds())))
+	}
+	end(span, nil)
+	return store
+}
+
+func (db *database) Sync(p sessions.SyncPayload) {
+	if p.Action == sessions.ActionDestroy {
+		span := db.start("destroy", p.SessionID)
+		db.Database.Sync(p)
+		end(span, nil)
+		return
+	}
+
+	span := db.start("sync", p.SessionID, attribute.Int("session.entries", len(p.Store.Values)))
+	db.Database.Sync(p)
+	end(span, nil)
+}
+
+func (db *database) SyncVersion(p sessions.SyncPayload) (sessions.RemoteStore, error) {
+	if p.Action == sessions.ActionDestroy {
+		span := db.start("destroy", p.SessionID)
+		stored, err := sessions.SyncDatabaseVersion(db.Database, p)
+		end(span, err)
+		return stored, err
+	}
+
+	span := db.start("sync", p.SessionID, attribute.Int("session.entries", len(p.Store.Values)))
+	stored, err := sessions.SyncDatabaseVersion(db.Database, p)
+	conflict := err == sessions.ErrVersionConflict
+	span.SetAttributes(attribute.Bool("session.conflict", conflict))
+	if conflict {
+		// the conflict is not a failure of the database.
+		end(span, nil)
+	} else {
+		end(span, err)
+	}
+	return stored, err
+}
+
+func (db *database) Scan(visitor func(sid string, store sessions.RemoteStore) bool) {
+	if scanner, ok := db.Database.(sessions.Scanner); ok {
+		span := db.start("scan", "")
+		scanner.Scan(visitor)
+		end(span, nil)
+	}
+}
+
+func (db *database) Close() error {
+	if closer, ok := db.Database.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// lockerDatabase is a traced database of a `sessions.Locker`.
+type lockerDatabase struct {
+	*database
+	locker sessions.Locker
+}
+
+func (db *lockerDatabase) TryLock(sid, token string, ttl time.Duration) (bool, error) {
+	span := db.start("acquire", sid)
+	ok, err := db.locker.TryLock(sid, token, ttl)
+	span.SetAttributes(attribute.Bool("session.lock_acquired", ok))
+	end(span, err)
+	return ok, err
+}
+
+func (db *lockerDatabase) Unlock(sid, token string) error {
+	span := db.start("release", sid)
+	err := db.locker.Unlock(sid, token)
+	end(span, err)
+	return err
+}
@@ -0,0 +1,242 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/kataras/go-sessions/sessionstest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder is a tracer provider which records the ended spans.
+type recorder struct {
+	noop.TracerProvider
+
+	mu    sync.Mutex
+	spans []*span
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &tracer{recorder: r}
+}
+
+// ended returns the ended spans, in order.
+func (r *recorder) ended() []*span {
+	r.mu.Lock()
+	spans := append([]*span(nil), r.spans...)
+	r.mu.Unlock()
+	return spans
+}
+
+type tracer struct {
+	noop.Tracer
+	recorder *recorder
+}
+
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	s := &span{recorder: t.recorder, name: name, kind: cfg.SpanKind(), attrs: make(map[attribute.Key]attribute.Value)}
+	s.SetAttributes(cfg.Attributes()...)
+	return ctx, s
+}
+
+type span struct {
+	noop.Span
+	recorder *recorder
+
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errs   []error
+}
+
+func (s *span) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *span) RecordError(err error, _ ...trace.EventOption) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *span) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *span) End(...trace.SpanEndOption) {
+	s.recorder.mu.Lock()
+	s.recorder.spans = append(s.recorder.spans, s)
+	s.recorder.mu.Unlock()
+}
+
+// last returns the last ended span, it fails if its name is not the "name".
+func last(t *testing.T, r *recorder, name string) *span {
+	t.Helper()
+
+	spans := r.ended()
+	if len(spans) == 0 {
+		t.Fatalf("expected the span %s to be ended", name)
+	}
+
+	s := spans[len(spans)-1]
+	if s.name != name {
+		t.Fatalf("expected the span %s but got %s", name, s.name)
+	}
+	return s
+}
+
+func TestLoad(t *testing.T) {
+	r := new(recorder)
+	backend := sessionstest.NewDatabase()
+	db := Database(backend, Options{TracerProvider: r, System: "redis"})
+
+	db.Load("sid")
+	s := last(t, r, "sessions.load")
+	if s.kind != trace.SpanKindClient {
+		t.Fatalf("expected a client span but got %s", s.kind)
+	}
+	if got := s.attrs["db.system"].AsString(); got != "redis" {
+		t.Fatalf("expected the db.system redis but got %q", got)
+	}
+	if got := s.attrs["db.operation"].AsString(); got != "load" {
+		t.Fatalf("expected the db.operation load but got %q", got)
+	}
+	if s.attrs["session.found"].AsBool() {
+		t.Fatalf("expected the missing session to be not found")
+	}
+
+	hash := s.attrs["session.id_hash"].AsString()
+	if hash != hashID("sid") || len(hash) != 16 {
+		t.Fatalf("expected the hash of the session id but got %q", hash)
+	}
+
+	backend.Sync(sessionstest.Insert("sid", "kataras", 0))
+	db.Load("sid")
+	s = last(t, r, "sessions.load")
+	if !s.attrs["session.found"].AsBool() || s.attrs["session.entries"].AsInt64() != 1 {
+		t.Fatalf("expected the session of 1 entry to be found but got %v", s.attrs)
+	}
+
+	for _, s := range r.ended() {
+		for _, v := range s.attrs {
+			if v.Emit() == "sid" {
+				t.Fatalf("expected the session id to not be leaked to the span %s", s.name)
+			}
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	r := new(recorder)
+	backend := sessionstest.NewDatabase()
+	db := Database(backend, Options{TracerProvider: r})
+
+	db.Sync(sessionstest.Insert("sid", "kataras", 0))
+	s := last(t, r, "sessions.sync")
+	if s.attrs["session.entries"].AsInt64() != 1 {
+		t.Fatalf("expected the entries of the written session but got %v", s.attrs)
+	}
+	if _, ok := s.attrs["db.system"]; ok {
+		t.Fatalf("expected no db.system attribute without a system")
+	}
+	if _, ok := backend.Stored("sid"); !ok {
+		t.Fatalf("expected the session to be written to the database")
+	}
+
+	db.Sync(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy})
+	last(t, r, "sessions.destroy")
+	if _, ok := backend.Stored("sid"); ok {
+		t.Fatalf("expected the session to be removed from the database")
+	}
+
+	db.(sessions.Scanner).Scan(func(string, sessions.RemoteStore) bool { return true })
+	if s = last(t, r, "sessions.scan"); s.attrs["session.id_hash"].Type() != attribute.INVALID {
+		t.Fatalf("expected no session id hash on the scan")
+	}
+}
+
+func TestSyncVersion(t *testing.T) {
+	r := new(recorder)
+	backend := sessionstest.NewDatabase()
+	db := Database(backend, Options{TracerProvider: r}).(sessions.VersionedDatabase)
+
+	if _, err := db.SyncVersion(sessionstest.Insert("sid", "kataras", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if s := last(t, r, "sessions.sync"); s.attrs["session.conflict"].AsBool() || s.status == codes.Error {
+		t.Fatalf("expected the written session to not conflict but got %v", s.attrs)
+	}
+
+	// another instance loaded the version 0 too.
+	stored, err := db.SyncVersion(sessionstest.Insert("sid", "makis", 1))
+	if err != sessions.ErrVersionConflict || stored.Values.GetString("name") != "kataras" {
+		t.Fatalf("expected the version conflict with the stored session but got %v, %v", err, stored.Values)
+	}
+	s := last(t, r, "sessions.sync")
+	if !s.attrs["session.conflict"].AsBool() {
+		t.Fatalf("expected the conflict attribute to be set")
+	}
+	if s.status == codes.Error || len(s.errs) != 0 {
+		t.Fatalf("expected the conflict to not be recorded as an error")
+	}
+
+	errDown := errors.New("down")
+	backend.FailSync(errDown)
+	if _, err = db.SyncVersion(sessionstest.Insert("sid", "kataras", 2)); err != errDown {
+		t.Fatalf("expected the error of the database but got %v", err)
+	}
+	if s = last(t, r, "sessions.sync"); s.status != codes.Error || len(s.errs) != 1 || s.errs[0] != errDown {
+		t.Fatalf("expected the error of the database to be recorded")
+	}
+
+	if _, err = db.SyncVersion(sessions.SyncPayload{SessionID: "sid", Action: sessions.ActionDestroy}); err != errDown {
+		t.Fatalf("expected the error of the database but got %v", err)
+	}
+	if s = last(t, r, "sessions.destroy"); s.status != codes.Error {
+		t.Fatalf("expected the error of the destroy to be recorded")
+	}
+}
+
+func TestLocker(t *testing.T) {
+	r := new(recorder)
+	db := Database(sessionstest.NewDatabase(), Options{TracerProvider: r})
+
+	locker, ok := db.(sessions.Locker)
+	if !ok {
+		t.Fatalf("expected the database of a locker to be a locker")
+	}
+
+	if ok, err := locker.TryLock("sid", "token", time.Minute); err != nil || !ok {
+		t.Fatalf("expected the lock to be acquired but got %v, %v", ok, err)
+	}
+	if s := last(t, r, "sessions.acquire"); !s.attrs["session.lock_acquired"].AsBool() {
+		t.Fatalf("expected the lock_acquired attribute to be set")
+	}
+
+	if ok, _ := locker.TryLock("sid", "other", time.Minute); ok {
+		t.Fatalf("expected the held lock to not be acquired")
+	}
+	if s := last(t, r, "sessions.acquire"); s.attrs["session.lock_acquired"].AsBool() {
+		t.Fatalf("expected the lock_acquired attribute to be false")
+	}
+
+	if err := locker.Unlock("sid", "token"); err != nil {
+		t.Fatal(err)
+	}
+	last(t, r, "sessions.release")
+
+	// a database which is not a locker.
+	plain := struct{ sessions.Database }{sessionstest.NewDatabase()}
+	if _, ok := Database(plain, Options{TracerProvider: r}).(sessions.Locker); ok {
+		t.Fatalf("expected the database of a non-locker to not be a locker")
+	}
+}
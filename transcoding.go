@@ -0,0 +1,236 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Transcoder is the interface which should be implemented
+// by the serialization formats of the session's data.
+//
+// The `RemoteStore`(and its `Store`) is being marshaled by the `DefaultTranscoder`
+// right before saved to a session database and unmarshaled on `Load`,
+// so all session databases share the same serialization path.
+//
+// The values with a registered `SerializationHook` are encoded
+// by their hook before passed to the transcoder.
+type Transcoder interface {
+	// Marshal returns the byte representation of the "value",
+	// usually a `RemoteStore` or a `Store`.
+	Marshal(value interface{}) ([]byte, error)
+	// Unmarshal parses the "b" and stores the result
+	// to the value pointed by the "outPtr".
+	Unmarshal(b []byte, outPtr interface{}) error
+}
+
+// DefaultTranscoder is the transcoder which is being used to serialize and deserialize
+// the session databases' `RemoteStore` and the `Store.SerializeE`.
+// Developers can change it, i.e `sessions.DefaultTranscoder = sessions.JSONTranscoder{}`,
+// before the session manager's first usage.
+//
+// Defaults to the `GobTranscoder`.
+var DefaultTranscoder Transcoder = GobTranscoder{}
+
+// GobTranscoder is the default transcoder, it uses the "encoding/gob" package.
+//
+// Remember: custom types should be registered via `gob.Register`.
+type GobTranscoder struct{}
+
+var _ Transcoder = GobTranscoder{}
+
+// Marshal returns the gob encoding of the "value".
+func (GobTranscoder) Marshal(value interface{}) ([]byte, error) {
+	w := acquireBuffer()
+	err := gob.NewEncoder(w).Encode(value)
+	b := append([]byte(nil), w.Bytes()...)
+	releaseBuffer(w)
+	return b, err
+}
+
+// Unmarshal decodes the gob-encoded "b" to the "outPtr".
+func (GobTranscoder) Unmarshal(b []byte, outPtr interface{}) error {
+	return gob.NewDecoder(bytes.NewBuffer(b)).Decode(outPtr)
+}
+
+// FormatVersioned reports true, the gob encodings are prefixed by the header of their `FormatVersion`.
+func (GobTranscoder) FormatVersioned() bool {
+	return true
+}
+
+// JSONTranscoder is a transcoder which uses the "encoding/json" package,
+// it's useful when the session data should be read by non-Go services.
+//
+// Note that the entries' immutability is not kept
+// and numbers are decoded as float64.
+type JSONTranscoder struct{}
+
+var _ Transcoder = JSONTranscoder{}
+
+// Marshal returns the json encoding of the "value".
+func (JSONTranscoder) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the json-encoded "b" to the "outPtr".
+func (JSONTranscoder) Unmarshal(b []byte, outPtr interface{}) error {
+	return json.Unmarshal(b, outPtr)
+}
+
+// SerializationHook is a custom encoding of a session value,
+// i.e a compact encoding for a big struct value.
+// See `RegisterKeyHook` and `RegisterTypeHook`.
+type SerializationHook struct {
+	// Marshal should return the byte representation of the "value".
+	Marshal func(value interface{}) ([]byte, error)
+	// Unmarshal should return the value of the "data",
+	// which were written by the `Marshal`.
+	Unmarshal func(data []byte) (interface{}, error)
+}
+
+// EncodedValue is the value which is stored in place of
+// a value encoded by a `SerializationHook`, it's being decoded back automatically.
+type EncodedValue struct {
+	Hook string
+	Data []byte
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   = make(map[string]SerializationHook)
+)
+
+func init() {
+	gob.Register(EncodedValue{})
+}
+
+// RegisterKeyHook registers a custom encoding for the values of the "key",
+// it's consulted by the `RemoteStore.Serialize`, `DecodeRemoteStore` and `Store.SerializeE`
+// before falling back to the `DefaultTranscoder`.
+//
+// Hooks should be registered before the session manager's first usage.
+func RegisterKeyHook(key string, hook SerializationHook) {
+	hooksMu.Lock()
+	hooks["key:"+key] = hook
+	hooksMu.Unlock()
+}
+
+// RegisterTypeHook registers a custom encoding for the values of the same type of the "value",
+// it's consulted by the `RemoteStore.Serialize`, `DecodeRemoteStore` and `Store.SerializeE`
+// before falling back to the `DefaultTranscoder`. Key hooks have priority over type hooks.
+//
+// Hooks should be registered before the session manager's first usage.
+func RegisterTypeHook(value interface{}, hook SerializationHook) {
+	hooksMu.Lock()
+	hooks["type:"+reflect.TypeOf(value).String()] = hook
+	hooksMu.Unlock()
+}
+
+// hookOf returns the registered hook of the "key" or of the type of the "value".
+func hookOf(key string, value interface{}) (string, SerializationHook, bool) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+
+	if len(hooks) == 0 {
+		return "", SerializationHook{}, false
+	}
+
+	name := "key:" + key
+	if hook, ok := hooks[name]; ok {
+		return name, hook, true
+	}
+
+	if value != nil {
+		name = "type:" + reflect.TypeOf(value).String()
+		if hook, ok := hooks[name]; ok {
+			return name, hook, true
+		}
+	}
+
+	return "", SerializationHook{}, false
+}
+
+// encodeHooks returns a copy of the "store" with its hooked values encoded,
+// it returns the same "store" if no hooks are registered.
+func encodeHooks(store Store) (Store, error) {
+	var encoded Store
+	for i, kv := range store {
+		name, hook, ok := hookOf(kv.Key, kv.ValueRaw)
+		if !ok {
+			continue
+		}
+
+		data, err := hook.Marshal(kv.ValueRaw)
+		if err != nil {
+			return nil, fmt.Errorf("serialization hook of key %s: %v", kv.Key, err)
+		}
+
+		if encoded == nil {
+			encoded = make(Store, len(store))
+			copy(encoded, store)
+		}
+		encoded[i].ValueRaw = EncodedValue{Hook: name, Data: data}
+	}
+
+	if encoded == nil {
+		return store, nil
+	}
+	return encoded, nil
+}
+
+// decodeHooks decodes, in place, the values which were encoded by hooks.
+func decodeHooks(store Store) error {
+	for i := range store {
+		v, ok := toEncodedValue(store[i].ValueRaw)
+		if !ok {
+			continue
+		}
+
+		hooksMu.RLock()
+		hook, found := hooks[v.Hook]
+		hooksMu.RUnlock()
+		if !found {
+			return fmt.Errorf("serialization hook %s of key %s is not registered", v.Hook, store[i].Key)
+		}
+
+		value, err := hook.Unmarshal(v.Data)
+		if err != nil {
+			return fmt.Errorf("serialization hook of key %s: %v", store[i].Key, err)
+		}
+		store[i].ValueRaw = value
+	}
+
+	return nil
+}
+
+// toEncodedValue reports whether the "v" is an `EncodedValue`,
+// transcoders like the JSON one decode it as a map.
+func toEncodedValue(v interface{}) (EncodedValue, bool) {
+	switch ev := v.(type) {
+	case EncodedValue:
+		return ev, true
+	case map[string]interface{}:
+		if len(ev) != 2 {
+			return EncodedValue{}, false
+		}
+
+		hook, ok := ev["Hook"].(string)
+		if !ok {
+			return EncodedValue{}, false
+		}
+
+		switch data := ev["Data"].(type) {
+		case []byte:
+			return EncodedValue{Hook: hook, Data: data}, true
+		case string: // json encodes bytes as base64.
+			b, err := base64.StdEncoding.DecodeString(data)
+			return EncodedValue{Hook: hook, Data: b}, err == nil
+		}
+	}
+
+	return EncodedValue{}, false
+}
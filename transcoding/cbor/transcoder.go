@@ -0,0 +1,111 @@
+package cbor
+
+import (
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/kataras/go-sessions"
+)
+
+// Transcoder is the CBOR(RFC 8949) session transcoder,
+// a compact and self-describing binary format, useful when the size matters,
+// i.e for sessions stored inside cookies, or when the data should be read by non-Go services.
+//
+// Usage:
+// sessions.DefaultTranscoder = cbor.Transcoder{}
+//
+// Note that the custom struct values are decoded as map[interface{}]interface{}
+// and the integers as uint64 or int64 by the underline library.
+type Transcoder struct{}
+
+var _ sessions.Transcoder = Transcoder{}
+
+type (
+	// keys are encoded as integers to keep the output small.
+	entry struct {
+		Key   string      `cbor:"1,keyasint"`
+		Value interface{} `cbor:"2,keyasint"`
+	}
+
+	remoteStore struct {
+		Values []entry `cbor:"1,keyasint"`
+		// ExpiresAt is the session's expiration, omitted when the session doesn't expire.
+		ExpiresAt *time.Time `cbor:"2,keyasint,omitempty"`
+		// CreatedAt is the session's creation, omitted when it's unknown.
+		CreatedAt *time.Time `cbor:"3,keyasint,omitempty"`
+		// Version is the session's version, see `sessions.RemoteStore#Version`.
+		Version uint64 `cbor:"4,keyasint,omitempty"`
+		// Extensions is the number of the expiration extensions of the session, see `sessions.RemoteStore#Extensions`.
+		Extensions int `cbor:"5,keyasint,omitempty"`
+	}
+)
+
+func toEntries(store sessions.Store) []entry {
+	entries := make([]entry, 0, store.Len())
+	store.Visit(func(key string, value interface{}) {
+		entries = append(entries, entry{Key: key, Value: value})
+	})
+	return entries
+}
+
+func fromEntries(entries []entry) (store sessions.Store) {
+	for _, e := range entries {
+		store.Set(e.Key, e.Value)
+	}
+	return
+}
+
+// Marshal returns the CBOR encoding of the "value",
+// the `sessions.RemoteStore` and `sessions.Store` are encoded
+// using a compact, language-neutral, layout.
+func (Transcoder) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case sessions.RemoteStore:
+		s := remoteStore{Values: toEntries(v.Values), Version: v.Version, Extensions: v.Extensions}
+		if !v.Lifetime.IsZero() {
+			expiresAt := v.Lifetime.Time
+			s.ExpiresAt = &expiresAt
+		}
+		if !v.CreatedAt.IsZero() {
+			createdAt := v.CreatedAt
+			s.CreatedAt = &createdAt
+		}
+		return cbor.Marshal(s)
+	case sessions.Store:
+		return cbor.Marshal(toEntries(v))
+	default:
+		return cbor.Marshal(value)
+	}
+}
+
+// Unmarshal decodes the CBOR-encoded "b" to the "outPtr".
+func (Transcoder) Unmarshal(b []byte, outPtr interface{}) error {
+	switch v := outPtr.(type) {
+	case *sessions.RemoteStore:
+		var s remoteStore
+		if err := cbor.Unmarshal(b, &s); err != nil {
+			return err
+		}
+
+		v.Values = fromEntries(s.Values)
+		if s.ExpiresAt != nil {
+			v.Lifetime = sessions.LifeTime{Time: *s.ExpiresAt}
+		}
+		if s.CreatedAt != nil {
+			v.CreatedAt = *s.CreatedAt
+		}
+		v.Version = s.Version
+		v.Extensions = s.Extensions
+		return nil
+	case *sessions.Store:
+		var entries []entry
+		if err := cbor.Unmarshal(b, &entries); err != nil {
+			return err
+		}
+
+		*v = fromEntries(entries)
+		return nil
+	default:
+		return cbor.Unmarshal(b, outPtr)
+	}
+}
@@ -0,0 +1,127 @@
+package cbor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+)
+
+func TestRemoteStoreVersion(t *testing.T) {
+	b, err := Transcoder{}.Marshal(sessions.RemoteStore{Version: 42, Extensions: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.RemoteStore
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Version != 42 {
+		t.Fatalf("expected the version 42 but got %d", store.Version)
+	}
+	if store.Extensions != 3 {
+		t.Fatalf("expected the 3 extensions but got %d", store.Extensions)
+	}
+}
+
+func TestRemoteStore(t *testing.T) {
+	type user struct {
+		Name string
+	}
+
+	now := time.Now().Round(time.Second)
+	var values sessions.Store
+	values.Set("name", "kataras")
+	values.Set("age", 42)
+	values.Set("balance", -7)
+	values.Set("user", user{Name: "makis"})
+
+	expiresAt := now.Add(time.Hour)
+	b, err := sessions.RemoteStore{Values: values, Lifetime: sessions.LifeTime{Time: expiresAt}, CreatedAt: now}.SerializeWith(Transcoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := sessions.DecodeRemoteStoreWith(Transcoder{}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the string but got %q", got)
+	}
+	if got := store.Values.Get("age"); got != uint64(42) {
+		t.Fatalf("expected the uint64 42 but got %#v", got)
+	}
+	if got := store.Values.Get("balance"); got != int64(-7) {
+		t.Fatalf("expected the int64 -7 but got %#v", got)
+	}
+	if got, _ := store.Values.Get("user").(map[interface{}]interface{}); got["Name"] != "makis" {
+		t.Fatalf("expected the struct to be decoded as a map but got %#v", store.Values.Get("user"))
+	}
+
+	if !store.Lifetime.Time.Equal(expiresAt) || !store.CreatedAt.Equal(now) {
+		t.Fatalf("expected the expiration %v and the creation %v but got %v and %v", expiresAt, now, store.Lifetime.Time, store.CreatedAt)
+	}
+}
+
+func TestNoExpiration(t *testing.T) {
+	var values sessions.Store
+	values.Set("name", "kataras")
+
+	b, err := Transcoder{}.Marshal(sessions.RemoteStore{Values: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.RemoteStore
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if !store.Lifetime.IsZero() || !store.CreatedAt.IsZero() {
+		t.Fatalf("expected no expiration and no creation time but got %v and %v", store.Lifetime.Time, store.CreatedAt)
+	}
+}
+
+func TestSmallerThanGob(t *testing.T) {
+	var values sessions.Store
+	values.Set("user_id", 42)
+	values.Set("name", "kataras")
+
+	store := sessions.RemoteStore{Values: values, CreatedAt: time.Now()}
+	gob, err := store.SerializeWith(sessions.GobTranscoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := store.SerializeWith(Transcoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b) >= len(gob) {
+		t.Fatalf("expected the cbor encoding to be smaller than the %d bytes of gob but got %d", len(gob), len(b))
+	}
+}
+
+func TestStore(t *testing.T) {
+	var values sessions.Store
+	values.Set("name", "kataras")
+
+	b, err := Transcoder{}.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.Store
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Len() != 1 || store.GetString("name") != "kataras" {
+		t.Fatalf("expected the entries to be decoded but got %v", store)
+	}
+}
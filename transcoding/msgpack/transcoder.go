@@ -0,0 +1,110 @@
+package msgpack
+
+import (
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/vmihailenco/msgpack"
+)
+
+// Transcoder is the MessagePack session transcoder,
+// it's smaller and faster than gob and it can be read by non-Go services
+// which share the same session database, i.e redis.
+//
+// Usage:
+// sessions.DefaultTranscoder = msgpack.Transcoder{}
+//
+// Note that the custom struct values are decoded as map[string]interface{}
+// and the integers as int64 or uint64 by the underline library.
+type Transcoder struct{}
+
+var _ sessions.Transcoder = Transcoder{}
+
+type (
+	entry struct {
+		Key   string      `msgpack:"key"`
+		Value interface{} `msgpack:"value"`
+	}
+
+	remoteStore struct {
+		Values []entry `msgpack:"values"`
+		// ExpiresAt is the unix time in milliseconds of the session's expiration,
+		// zero means that the session doesn't expire.
+		ExpiresAt int64 `msgpack:"expires_at"`
+		// CreatedAt is the unix time in milliseconds of the session's creation,
+		// zero means that it's unknown.
+		CreatedAt int64 `msgpack:"created_at,omitempty"`
+		// Version is the session's version, see `sessions.RemoteStore#Version`.
+		Version uint64 `msgpack:"version,omitempty"`
+		// Extensions is the number of the expiration extensions of the session, see `sessions.RemoteStore#Extensions`.
+		Extensions int `msgpack:"extensions,omitempty"`
+	}
+)
+
+func toEntries(store sessions.Store) []entry {
+	entries := make([]entry, 0, store.Len())
+	store.Visit(func(key string, value interface{}) {
+		entries = append(entries, entry{Key: key, Value: value})
+	})
+	return entries
+}
+
+func fromEntries(entries []entry) (store sessions.Store) {
+	for _, e := range entries {
+		store.Set(e.Key, e.Value)
+	}
+	return
+}
+
+// Marshal returns the MessagePack encoding of the "value",
+// the `sessions.RemoteStore` and `sessions.Store` are encoded
+// using a language-neutral layout.
+func (Transcoder) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case sessions.RemoteStore:
+		s := remoteStore{Values: toEntries(v.Values), Version: v.Version, Extensions: v.Extensions}
+		if !v.Lifetime.IsZero() {
+			s.ExpiresAt = v.Lifetime.UnixNano() / int64(time.Millisecond)
+		}
+		if !v.CreatedAt.IsZero() {
+			s.CreatedAt = v.CreatedAt.UnixNano() / int64(time.Millisecond)
+		}
+		return msgpack.Marshal(s)
+	case sessions.Store:
+		return msgpack.Marshal(toEntries(v))
+	default:
+		return msgpack.Marshal(value)
+	}
+}
+
+// Unmarshal decodes the MessagePack-encoded "b" to the "outPtr".
+func (Transcoder) Unmarshal(b []byte, outPtr interface{}) error {
+	switch v := outPtr.(type) {
+	case *sessions.RemoteStore:
+		var s remoteStore
+		if err := msgpack.Unmarshal(b, &s); err != nil {
+			return err
+		}
+
+		v.Values = fromEntries(s.Values)
+		if s.ExpiresAt > 0 {
+			v.Lifetime = sessions.LifeTime{Time: time.Unix(0, s.ExpiresAt*int64(time.Millisecond))}
+		}
+		if s.CreatedAt > 0 {
+			v.CreatedAt = time.Unix(0, s.CreatedAt*int64(time.Millisecond))
+		}
+		v.Version = s.Version
+		v.Extensions = s.Extensions
+		return nil
+	case *sessions.Store:
+		var entries []entry
+		if err := msgpack.Unmarshal(b, &entries); err != nil {
+			return err
+		}
+
+		*v = fromEntries(entries)
+		return nil
+	default:
+		return msgpack.Unmarshal(b, outPtr)
+	}
+}
@@ -0,0 +1,130 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"github.com/vmihailenco/msgpack"
+)
+
+func TestRemoteStoreVersion(t *testing.T) {
+	b, err := Transcoder{}.Marshal(sessions.RemoteStore{Version: 42, Extensions: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.RemoteStore
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Version != 42 {
+		t.Fatalf("expected the version 42 but got %d", store.Version)
+	}
+	if store.Extensions != 3 {
+		t.Fatalf("expected the 3 extensions but got %d", store.Extensions)
+	}
+}
+
+func TestRemoteStore(t *testing.T) {
+	type user struct {
+		Name string `msgpack:"name"`
+	}
+
+	now := time.Now().Round(time.Millisecond)
+	var values sessions.Store
+	values.Set("name", "kataras")
+	values.Set("age", 42)
+	values.Set("admin", true)
+	values.Set("user", user{Name: "makis"})
+
+	expiresAt := now.Add(time.Hour)
+	b, err := sessions.RemoteStore{Values: values, Lifetime: sessions.LifeTime{Time: expiresAt}, CreatedAt: now}.SerializeWith(Transcoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := sessions.DecodeRemoteStoreWith(Transcoder{}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.Values.GetString("name"); got != "kataras" {
+		t.Fatalf("expected the string but got %q", got)
+	}
+	if got, err := store.Values.GetInt64("age"); err != nil || got != 42 {
+		t.Fatalf("expected the integer 42 but got %#v", store.Values.Get("age"))
+	}
+	if got := store.Values.Get("admin"); got != true {
+		t.Fatalf("expected true but got %#v", got)
+	}
+	if got, _ := store.Values.Get("user").(map[string]interface{}); got["name"] != "makis" {
+		t.Fatalf("expected the struct to be decoded as a map but got %#v", store.Values.Get("user"))
+	}
+
+	if !store.Lifetime.Time.Equal(expiresAt) || !store.CreatedAt.Equal(now) {
+		t.Fatalf("expected the expiration %v and the creation %v but got %v and %v", expiresAt, now, store.Lifetime.Time, store.CreatedAt)
+	}
+}
+
+// TestLayout decodes a session which is written by a non-Go service, with the keys of the layout.
+func TestLayout(t *testing.T) {
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"values":     []map[string]interface{}{{"key": "name", "value": "kataras"}},
+		"expires_at": int64(0),
+		"created_at": int64(60000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.RemoteStore
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Values.GetString("name") != "kataras" || !store.CreatedAt.Equal(time.Unix(60, 0)) || !store.Lifetime.IsZero() {
+		t.Fatalf("expected the store of the layout but got %v", store)
+	}
+}
+
+func TestSmallerThanGob(t *testing.T) {
+	var values sessions.Store
+	values.Set("user_id", 42)
+	values.Set("name", "kataras")
+
+	store := sessions.RemoteStore{Values: values, CreatedAt: time.Now()}
+	gob, err := store.SerializeWith(sessions.GobTranscoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := store.SerializeWith(Transcoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b) >= len(gob) {
+		t.Fatalf("expected the msgpack encoding to be smaller than the %d bytes of gob but got %d", len(gob), len(b))
+	}
+}
+
+func TestStore(t *testing.T) {
+	var values sessions.Store
+	values.Set("name", "kataras")
+
+	b, err := Transcoder{}.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.Store
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Len() != 1 || store.GetString("name") != "kataras" {
+		t.Fatalf("expected the entries to be decoded but got %v", store)
+	}
+}
@@ -0,0 +1,343 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Transcoder is the Protocol Buffers session transcoder,
+// it writes and reads the `Store` message described at the "session.proto" file,
+// so sessions can be shared with services written in other languages.
+//
+// Usage:
+// sessions.DefaultTranscoder = protobuf.Transcoder{}
+//
+// Strings, integers, floats, booleans, []byte and time.Time values are stored as typed values,
+// any other value is stored as json, therefore custom struct values
+// are decoded as map[string]interface{}. Integers are decoded as int and floats as float64.
+type Transcoder struct{}
+
+var _ sessions.Transcoder = Transcoder{}
+
+// ErrUnsupported is returned when the value to marshal or unmarshal
+// is not a `sessions.RemoteStore` or a `sessions.Store`.
+var ErrUnsupported = errors.New("protobuf: unsupported value, expected a sessions.RemoteStore or a sessions.Store")
+
+// field numbers, see the "session.proto" file.
+const (
+	storeEntries    protowire.Number = 1
+	storeExpiresAt  protowire.Number = 2
+	storeCreatedAt  protowire.Number = 3
+	storeVersion    protowire.Number = 4
+	storeExtensions protowire.Number = 5
+
+	entryKey   protowire.Number = 1
+	entryValue protowire.Number = 2
+
+	valueString protowire.Number = 1
+	valueInt    protowire.Number = 2
+	valueFloat  protowire.Number = 3
+	valueBool   protowire.Number = 4
+	valueBytes  protowire.Number = 5
+	valueTime   protowire.Number = 6
+	valueJSON   protowire.Number = 7
+)
+
+// Marshal returns the protobuf encoding of a `sessions.RemoteStore` or a `sessions.Store`.
+func (Transcoder) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case sessions.RemoteStore:
+		var expiresAt, createdAt int64
+		if !v.Lifetime.IsZero() {
+			expiresAt = v.Lifetime.UnixNano()
+		}
+		if !v.CreatedAt.IsZero() {
+			createdAt = v.CreatedAt.UnixNano()
+		}
+		return marshalStore(v.Values, expiresAt, createdAt, v.Version, v.Extensions)
+	case sessions.Store:
+		return marshalStore(v, 0, 0, 0, 0)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// Unmarshal decodes the protobuf-encoded "b" to a `*sessions.RemoteStore` or a `*sessions.Store`.
+func (Transcoder) Unmarshal(b []byte, outPtr interface{}) error {
+	switch v := outPtr.(type) {
+	case *sessions.RemoteStore:
+		store, expiresAt, createdAt, version, extensions, err := unmarshalStore(b)
+		if err != nil {
+			return err
+		}
+
+		v.Values = store
+		if expiresAt > 0 {
+			v.Lifetime = sessions.LifeTime{Time: time.Unix(0, expiresAt)}
+		}
+		if createdAt > 0 {
+			v.CreatedAt = time.Unix(0, createdAt)
+		}
+		v.Version = version
+		v.Extensions = extensions
+		return nil
+	case *sessions.Store:
+		store, _, _, _, _, err := unmarshalStore(b)
+		if err != nil {
+			return err
+		}
+
+		*v = store
+		return nil
+	default:
+		return ErrUnsupported
+	}
+}
+
+func marshalStore(store sessions.Store, expiresAt, createdAt int64, version uint64, extensions int) (b []byte, err error) {
+	store.Visit(func(key string, value interface{}) {
+		if err != nil {
+			return
+		}
+
+		var entry []byte
+		entry = protowire.AppendTag(entry, entryKey, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+
+		var val []byte
+		if val, err = marshalValue(value); err != nil {
+			err = fmt.Errorf("protobuf: entry %q: %v", key, err)
+			return
+		}
+		entry = protowire.AppendTag(entry, entryValue, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, val)
+
+		b = protowire.AppendTag(b, storeEntries, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt != 0 {
+		b = protowire.AppendTag(b, storeExpiresAt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(expiresAt))
+	}
+
+	if createdAt != 0 {
+		b = protowire.AppendTag(b, storeCreatedAt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(createdAt))
+	}
+
+	if version != 0 {
+		b = protowire.AppendTag(b, storeVersion, protowire.VarintType)
+		b = protowire.AppendVarint(b, version)
+	}
+
+	if extensions != 0 {
+		b = protowire.AppendTag(b, storeExtensions, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(extensions))
+	}
+
+	return b, nil
+}
+
+func marshalValue(value interface{}) (b []byte, err error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		b = protowire.AppendTag(b, valueString, protowire.BytesType)
+		return protowire.AppendString(b, v), nil
+	case int:
+		return appendInt(b, int64(v)), nil
+	case int8:
+		return appendInt(b, int64(v)), nil
+	case int16:
+		return appendInt(b, int64(v)), nil
+	case int32:
+		return appendInt(b, int64(v)), nil
+	case int64:
+		return appendInt(b, v), nil
+	case uint8:
+		return appendInt(b, int64(v)), nil
+	case uint16:
+		return appendInt(b, int64(v)), nil
+	case uint32:
+		return appendInt(b, int64(v)), nil
+	case float32:
+		return appendFloat(b, float64(v)), nil
+	case float64:
+		return appendFloat(b, v), nil
+	case bool:
+		b = protowire.AppendTag(b, valueBool, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v)), nil
+	case []byte:
+		b = protowire.AppendTag(b, valueBytes, protowire.BytesType)
+		return protowire.AppendBytes(b, v), nil
+	case time.Time:
+		b = protowire.AppendTag(b, valueTime, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(v.UnixNano())), nil
+	default:
+		jsonValue, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, valueJSON, protowire.BytesType)
+		return protowire.AppendBytes(b, jsonValue), nil
+	}
+}
+
+func appendInt(b []byte, v int64) []byte {
+	b = protowire.AppendTag(b, valueInt, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeZigZag(v))
+}
+
+func appendFloat(b []byte, v float64) []byte {
+	b = protowire.AppendTag(b, valueFloat, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+// walk calls the "visitor" for each field of the "b" message,
+// the visitor should return the number of the consumed bytes or a negative number on errors.
+func walk(b []byte, visitor func(num protowire.Number, typ protowire.Type, b []byte) int) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n = visitor(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+
+	return nil
+}
+
+func unmarshalStore(b []byte) (store sessions.Store, expiresAt, createdAt int64, version uint64, extensions int, err error) {
+	var entryErr error
+	err = walk(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch {
+		case num == storeEntries && typ == protowire.BytesType:
+			entry, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n
+			}
+
+			key, value, err := unmarshalEntry(entry)
+			if err != nil {
+				entryErr = err
+				return len(b)
+			}
+
+			store.Set(key, value)
+			return n
+		case num == storeExpiresAt && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			expiresAt = int64(v)
+			return n
+		case num == storeCreatedAt && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			createdAt = int64(v)
+			return n
+		case num == storeVersion && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			version = v
+			return n
+		case num == storeExtensions && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			extensions = int(v)
+			return n
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b)
+		}
+	})
+
+	if err == nil {
+		err = entryErr
+	}
+	return
+}
+
+func unmarshalEntry(b []byte) (key string, value interface{}, err error) {
+	var valueErr error
+	err = walk(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch {
+		case num == entryKey && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			key = v
+			return n
+		case num == entryValue && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n
+			}
+
+			value, valueErr = unmarshalValue(v)
+			return n
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b)
+		}
+	})
+
+	if err == nil {
+		err = valueErr
+	}
+	return
+}
+
+func unmarshalValue(b []byte) (value interface{}, err error) {
+	var jsonErr error
+	err = walk(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch {
+		case num == valueString && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			value = v
+			return n
+		case num == valueInt && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			value = int(protowire.DecodeZigZag(v))
+			return n
+		case num == valueFloat && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			value = math.Float64frombits(v)
+			return n
+		case num == valueBool && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			value = protowire.DecodeBool(v)
+			return n
+		case num == valueBytes && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			value = append([]byte(nil), v...)
+			return n
+		case num == valueTime && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			value = time.Unix(0, int64(v))
+			return n
+		case num == valueJSON && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n >= 0 {
+				jsonErr = json.Unmarshal(v, &value)
+			}
+			return n
+		default:
+			return protowire.ConsumeFieldValue(num, typ, b)
+		}
+	})
+
+	if err == nil {
+		err = jsonErr
+	}
+	return
+}
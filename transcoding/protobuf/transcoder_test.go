@@ -0,0 +1,152 @@
+package protobuf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/go-sessions"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestRemoteStoreVersion(t *testing.T) {
+	b, err := Transcoder{}.Marshal(sessions.RemoteStore{Version: 42, Extensions: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.RemoteStore
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Version != 42 {
+		t.Fatalf("expected the version 42 but got %d", store.Version)
+	}
+	if store.Extensions != 3 {
+		t.Fatalf("expected the 3 extensions but got %d", store.Extensions)
+	}
+}
+
+func TestRemoteStore(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	now := time.Unix(0, time.Now().UnixNano())
+	var values sessions.Store
+	values.Set("string", "kataras")
+	values.Set("int", 42)
+	values.Set("negative", int64(-7))
+	values.Set("float", 4.2)
+	values.Set("bool", true)
+	values.Set("bytes", []byte("go-sessions"))
+	values.Set("time", now)
+	values.Set("user", user{Name: "makis"})
+
+	expiresAt := now.Add(time.Hour)
+	b, err := sessions.RemoteStore{Values: values, Lifetime: sessions.LifeTime{Time: expiresAt}, CreatedAt: now}.SerializeWith(Transcoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := sessions.DecodeRemoteStoreWith(Transcoder{}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.Values.GetString("string"); got != "kataras" {
+		t.Fatalf("expected the string but got %q", got)
+	}
+	if got := store.Values.Get("int"); got != 42 {
+		t.Fatalf("expected the int 42 but got %#v", got)
+	}
+	if got := store.Values.Get("negative"); got != -7 {
+		t.Fatalf("expected the int -7 but got %#v", got)
+	}
+	if got := store.Values.Get("float"); got != 4.2 {
+		t.Fatalf("expected the float 4.2 but got %#v", got)
+	}
+	if got := store.Values.Get("bool"); got != true {
+		t.Fatalf("expected true but got %#v", got)
+	}
+	if got, _ := store.Values.Get("bytes").([]byte); string(got) != "go-sessions" {
+		t.Fatalf("expected the bytes but got %#v", store.Values.Get("bytes"))
+	}
+	if got, _ := store.Values.Get("time").(time.Time); !got.Equal(now) {
+		t.Fatalf("expected the time %v but got %#v", now, store.Values.Get("time"))
+	}
+	if got, _ := store.Values.Get("user").(map[string]interface{}); got["name"] != "makis" {
+		t.Fatalf("expected the struct to be decoded as a map but got %#v", store.Values.Get("user"))
+	}
+
+	if !store.Lifetime.Time.Equal(expiresAt) || !store.CreatedAt.Equal(now) {
+		t.Fatalf("expected the expiration %v and the creation %v but got %v and %v", expiresAt, now, store.Lifetime.Time, store.CreatedAt)
+	}
+}
+
+func TestStore(t *testing.T) {
+	var values sessions.Store
+	values.Set("name", "kataras")
+	values.Set("empty", nil)
+
+	b, err := Transcoder{}.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store sessions.Store
+	if err = (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Len() != 2 || store.GetString("name") != "kataras" || store.Get("empty") != nil {
+		t.Fatalf("expected the entries to be decoded but got %v", store)
+	}
+}
+
+// TestLayout decodes a store which is encoded by the field numbers of the "session.proto",
+// as a service of another language writes it.
+func TestLayout(t *testing.T) {
+	var value []byte
+	value = protowire.AppendTag(value, 1, protowire.BytesType) // string_value
+	value = protowire.AppendString(value, "kataras")
+
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType) // key
+	entry = protowire.AppendString(entry, "name")
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType) // value
+	entry = protowire.AppendBytes(entry, value)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType) // entries
+	b = protowire.AppendBytes(b, entry)
+	b = protowire.AppendTag(b, 3, protowire.VarintType) // created_at
+	b = protowire.AppendVarint(b, uint64(time.Unix(60, 0).UnixNano()))
+	b = protowire.AppendTag(b, 9, protowire.VarintType) // unknown, i.e a newer field
+	b = protowire.AppendVarint(b, 1)
+
+	var store sessions.RemoteStore
+	if err := (Transcoder{}).Unmarshal(b, &store); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Values.GetString("name") != "kataras" || !store.CreatedAt.Equal(time.Unix(60, 0)) || !store.Lifetime.IsZero() {
+		t.Fatalf("expected the store of the proto layout but got %v", store)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := (Transcoder{}).Marshal("kataras"); err != ErrUnsupported {
+		t.Fatalf("expected the ErrUnsupported on marshal but got %v", err)
+	}
+
+	var s string
+	if err := (Transcoder{}).Unmarshal(nil, &s); err != ErrUnsupported {
+		t.Fatalf("expected the ErrUnsupported on unmarshal but got %v", err)
+	}
+
+	var store sessions.RemoteStore
+	if err := (Transcoder{}).Unmarshal([]byte{0xff}, &store); err == nil {
+		t.Fatalf("expected an error on the malformed data")
+	}
+}
@@ -0,0 +1,140 @@
+package sessions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testCart struct {
+	Items []string
+}
+
+func TestSerializationHooks(t *testing.T) {
+	RegisterTypeHook(testCart{}, SerializationHook{
+		Marshal: func(value interface{}) ([]byte, error) {
+			return []byte(strings.Join(value.(testCart).Items, ",")), nil
+		},
+		Unmarshal: func(data []byte) (interface{}, error) {
+			return testCart{Items: strings.Split(string(data), ",")}, nil
+		},
+	})
+
+	for _, transcoder := range []Transcoder{GobTranscoder{}, JSONTranscoder{}} {
+		DefaultTranscoder = transcoder
+
+		var store RemoteStore
+		store.Values.Set("cart", testCart{Items: []string{"a", "b"}})
+		store.Values.Set("name", "go-sessions")
+
+		b, err := store.Serialize()
+		if err != nil {
+			t.Fatalf("%T: while serializing: %v", transcoder, err)
+		}
+
+		if _, ok := store.Values.Get("cart").(testCart); !ok {
+			t.Fatalf("%T: serialize should not modify the original store", transcoder)
+		}
+
+		got, err := DecodeRemoteStore(b)
+		if err != nil {
+			t.Fatalf("%T: while decoding: %v", transcoder, err)
+		}
+
+		cart, ok := got.Values.Get("cart").(testCart)
+		if !ok || len(cart.Items) != 2 || cart.Items[1] != "b" {
+			t.Fatalf("%T: expected the cart to be decoded by its hook but got %#v", transcoder, got.Values.Get("cart"))
+		}
+
+		if expected, v := "go-sessions", got.Values.GetString("name"); v != expected {
+			t.Fatalf("%T: expected %s but got %s", transcoder, expected, v)
+		}
+	}
+
+	DefaultTranscoder = GobTranscoder{}
+}
+
+func TestCompressionTranscoder(t *testing.T) {
+	var store RemoteStore
+	store.Values.Set("payload", strings.Repeat("go-sessions", 100))
+
+	plain, err := store.Serialize()
+	if err != nil {
+		t.Fatalf("while serializing: %v", err)
+	}
+
+	transcoder := NewCompressionTranscoder(nil, GzipCompressor{})
+	b, err := store.SerializeWith(transcoder)
+	if err != nil {
+		t.Fatalf("while serializing with compression: %v", err)
+	}
+
+	if len(b) >= len(plain) {
+		t.Fatalf("expected compressed data to be smaller than %d bytes but got %d", len(plain), len(b))
+	}
+
+	// compressed and data written before compression was enabled.
+	for _, data := range [][]byte{b, plain} {
+		got, err := DecodeRemoteStoreWith(transcoder, data)
+		if err != nil {
+			t.Fatalf("while decoding: %v", err)
+		}
+
+		if expected, v := store.Values.GetString("payload"), got.Values.GetString("payload"); v != expected {
+			t.Fatalf("expected the payload to be decoded but got %q", v)
+		}
+	}
+}
+
+func TestEncryptionTranscoder(t *testing.T) {
+	oldKey, newKey := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+
+	var store RemoteStore
+	store.Values.Set("name", "go-sessions")
+
+	old, err := NewEncryptionTranscoder(nil, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := store.SerializeWith(old)
+	if err != nil {
+		t.Fatalf("while serializing with encryption: %v", err)
+	}
+
+	if bytes.Contains(b, []byte("go-sessions")) {
+		t.Fatalf("expected the session data to be encrypted")
+	}
+
+	rotated, err := NewEncryptionTranscoder(nil, newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DecodeRemoteStoreWith(rotated, b)
+	if err != nil {
+		t.Fatalf("expected data encrypted by a previous key to be decrypted but got: %v", err)
+	}
+
+	if expected, v := "go-sessions", got.Values.GetString("name"); v != expected {
+		t.Fatalf("expected %s but got %s", expected, v)
+	}
+
+	removed, err := NewEncryptionTranscoder(nil, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = DecodeRemoteStoreWith(removed, b); err != ErrDecryption {
+		t.Fatalf("expected %v but got %v", ErrDecryption, err)
+	}
+
+	plain, err := store.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = DecodeRemoteStoreWith(removed, plain); err != ErrDecryption {
+		t.Fatalf("expected unencrypted data to be rejected but got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package sessions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// authorizationScheme is the scheme of the session id when the `Config#Header` is the "Authorization".
+const authorizationScheme = "Bearer "
+
+// isAuthorization reports whether the "header" is the Authorization header.
+func isAuthorization(header string) bool {
+	return strings.EqualFold(header, "Authorization")
+}
+
+// parseHeader returns the session id of the value of the `Config#Header`,
+// the "Bearer" scheme of the Authorization header is removed.
+func (s *Sessions) parseHeader(value string) string {
+	if !isAuthorization(s.config.Header) {
+		return value
+	}
+
+	if len(value) < len(authorizationScheme) || !strings.EqualFold(value[:len(authorizationScheme)], authorizationScheme) {
+		return ""
+	}
+
+	return strings.TrimSpace(value[len(authorizationScheme):])
+}
+
+// formatHeader returns the header value of the encoded session id.
+func (s *Sessions) formatHeader(value string) string {
+	if isAuthorization(s.config.Header) {
+		return authorizationScheme + value
+	}
+
+	return value
+}
+
+// requestValue returns the encoded session id of the request,
+// of the `Config#Header` if it's set and present, otherwise of the session cookie.
+func (s *Sessions) requestValue(r *http.Request) string {
+	if header := s.config.Header; header != "" {
+		if value := s.parseHeader(r.Header.Get(header)); value != "" {
+			return value
+		}
+	}
+
+	if s.config.DisableCookie {
+		return ""
+	}
+
+	return GetCookie(r, s.config.Cookie)
+}
+
+// requestValueFasthttp returns the encoded session id of the request, see `requestValue`.
+func (s *Sessions) requestValueFasthttp(ctx *fasthttp.RequestCtx) string {
+	if header := s.config.Header; header != "" {
+		if value := s.parseHeader(string(ctx.Request.Header.Peek(header))); value != "" {
+			return value
+		}
+	}
+
+	if s.config.DisableCookie {
+		return ""
+	}
+
+	return GetCookieFasthttp(ctx, s.config.Cookie)
+}
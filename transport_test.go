@@ -0,0 +1,71 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	manager := New(Config{Header: "X-Session-ID", DisableCookie: true})
+
+	w := httptest.NewRecorder()
+	manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil)).Set("name", "kataras")
+	sid := w.Header().Get("X-Session-ID")
+	if sid == "" {
+		t.Fatal("expected the session id to be sent by the response header")
+	}
+
+	if findCookie(w, DefaultCookieName) != nil {
+		t.Fatal("expected no session cookie")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Session-ID", sid)
+	w = httptest.NewRecorder()
+	if got := manager.Start(w, r).GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session of the header but got the name %q", got)
+	}
+
+	if w.Header().Get("X-Session-ID") != "" {
+		t.Fatal("expected the session id to be sent only for the new sessions")
+	}
+
+	// the cookie is ignored.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultCookieName, Value: sid})
+	if manager.Start(httptest.NewRecorder(), r).GetString("name") != "" {
+		t.Fatal("expected the session cookie to be ignored")
+	}
+}
+
+func TestAuthorizationTransport(t *testing.T) {
+	manager := New(Config{Header: "Authorization"})
+
+	w := httptest.NewRecorder()
+	sess := manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("name", "kataras")
+
+	authorization := w.Header().Get("Authorization")
+	if authorization != "Bearer "+sess.ID() {
+		t.Fatalf("expected the bearer session id but got %q", authorization)
+	}
+
+	if findCookie(w, DefaultCookieName) == nil {
+		t.Fatal("expected the session cookie to be sent too")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", strings.ToLower(authorization[:6])+authorization[6:])
+	if got := manager.Start(httptest.NewRecorder(), r).GetString("name"); got != "kataras" {
+		t.Fatalf("expected the session of the bearer token but got the name %q", got)
+	}
+
+	// other schemes are ignored.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Basic "+sess.ID())
+	if manager.Start(httptest.NewRecorder(), r).GetString("name") != "" {
+		t.Fatal("expected the basic authorization to be ignored")
+	}
+}
@@ -0,0 +1,136 @@
+package sessions
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var registeredTypes sync.Map // map[reflect.Type]error
+
+// RegisterTypes registers the types of the "values" to the gob encoding,
+// it should be called for the custom types, i.e structs, which are stored
+// to the sessions, before the session databases serialize them.
+//
+// It's safe to call it more than once for the same type.
+// It returns the error of the first type which is already registered to the gob encoding
+// under a different name, i.e by a `gob.RegisterName`, the type is still encoded by that name.
+func RegisterTypes(values ...interface{}) error {
+	var first error
+	for _, v := range values {
+		if err := registerType(v); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+func registerType(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	typ := reflect.TypeOf(v)
+	if stored, loaded := registeredTypes.Load(typ); loaded {
+		err, _ := stored.(error)
+		return err
+	}
+
+	err := gobRegister(v)
+	registeredTypes.Store(typ, err)
+	return err
+}
+
+// gobRegister calls the `gob.Register` and returns its panic as an error,
+// it panics when the type or the name is already registered to a different name or type.
+func gobRegister(v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sessions: register type %T: %v", v, r)
+		}
+	}()
+
+	gob.Register(v)
+	return nil
+}
+
+// ErrRecursiveValue is returned when a value which references itself, i.e a map which contains itself,
+// is set to a session, see `Session#SetE` and `Store#SetE`, it would hang the encoders.
+var ErrRecursiveValue = errors.New("sessions: recursive value")
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// checkRecursive returns an error if the "value" of the "key" is self-referential,
+// only the value which is set is walked, the stored values were checked when they were set.
+func checkRecursive(key string, value interface{}) error {
+	if isRecursive(reflect.ValueOf(value), make(map[visit]struct{})) {
+		return fmt.Errorf("%v: value of key %s", ErrRecursiveValue, key)
+	}
+
+	return nil
+}
+
+// isRecursive reports whether the "v" contains itself,
+// the "path" keeps the references of the current path only,
+// so shared, non-cyclic, references are allowed.
+func isRecursive(v reflect.Value, path map[visit]struct{}) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+
+		k := visit{ptr: v.Pointer(), typ: v.Type()}
+		if _, found := path[k]; found {
+			return true
+		}
+		path[k] = struct{}{}
+		defer delete(path, k)
+
+		switch v.Kind() {
+		case reflect.Ptr:
+			return isRecursive(v.Elem(), path)
+		case reflect.Map:
+			for _, key := range v.MapKeys() {
+				if isRecursive(key, path) || isRecursive(v.MapIndex(key), path) {
+					return true
+				}
+			}
+		default:
+			for i, n := 0, v.Len(); i < n; i++ {
+				if isRecursive(v.Index(i), path) {
+					return true
+				}
+			}
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			return isRecursive(v.Elem(), path)
+		}
+	case reflect.Array:
+		for i, n := 0, v.Len(); i < n; i++ {
+			if isRecursive(v.Index(i), path) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i, n := 0, v.NumField(); i < n; i++ {
+			// unexported fields are not encoded.
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			if isRecursive(v.Field(i), path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
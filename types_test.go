@@ -0,0 +1,89 @@
+package sessions
+
+import (
+	"encoding/gob"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type registeredType struct{ Name string }
+
+type renamedType struct{ Name string }
+
+func init() {
+	gob.RegisterName("sessions.renamed", renamedType{})
+}
+
+func TestRegisterTypes(t *testing.T) {
+	if err := RegisterTypes(registeredType{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// it's safe to register the same type again.
+	if err := RegisterTypes(registeredType{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var values Store
+	values.Set("value", registeredType{Name: "kataras"})
+	b, err := values.SerializeE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := GobDeserialize(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restored.Get("value").(registeredType); !ok || v.Name != "kataras" {
+		t.Fatalf("expected the registered type to be restored but got %#v", restored.Get("value"))
+	}
+}
+
+func TestRegisterTypesConflict(t *testing.T) {
+	err := RegisterTypes(registeredType{}, renamedType{})
+	if err == nil || !strings.HasPrefix(err.Error(), "sessions: register type") {
+		t.Fatalf("expected the error of the type which is registered under a different name but got %v", err)
+	}
+
+	if again := RegisterTypes(renamedType{}); again == nil || again.Error() != err.Error() {
+		t.Fatalf("expected the same error on the next registration but got %v", again)
+	}
+}
+
+func TestAutoRegisterTypesConflict(t *testing.T) {
+	logger := new(testLogger)
+	manager := New(Config{AutoRegisterTypes: true, Logger: logger})
+
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sess.Set("value", renamedType{Name: "kataras"})
+	sess.Append("list", renamedType{Name: "makis"})
+
+	if !logger.has("warn sessions: register type") {
+		t.Fatalf("expected the conflict to be logged but got %v", logger.events)
+	}
+	if v, ok := sess.Get("value").(renamedType); !ok || v.Name != "kataras" {
+		t.Fatalf("expected the value to be set regardless of the conflict but got %#v", sess.Get("value"))
+	}
+}
+
+func TestSessionSetRecursive(t *testing.T) {
+	m := map[string]interface{}{"name": "go-sessions"}
+	m["self"] = m
+
+	manager := New(Config{})
+	sess := manager.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := sess.SetE("recursive", m); err == nil || !strings.HasPrefix(err.Error(), ErrRecursiveValue.Error()) {
+		t.Fatalf("expected a recursive value error but got: %v", err)
+	}
+	if _, err := sess.AppendTo("list", "kataras", m); err == nil {
+		t.Fatalf("expected the recursive item to be rejected")
+	}
+
+	sess.Set("recursive", m)
+	if sess.Get("recursive") != nil || sess.Get("list") != nil {
+		t.Fatalf("expected the recursive values to be ignored")
+	}
+}
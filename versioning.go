@@ -0,0 +1,104 @@
+package sessions
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrVersionConflict returned by a `VersionedDatabase` when the session was modified by another
+// app instance since it was loaded, see `Config#OptimisticConcurrency` and `Session#Conflict`.
+var ErrVersionConflict = errors.New("sessions: the session was modified concurrently")
+
+// VersionedDatabase is an optional interface of a `Database` which supports the optimistic concurrency,
+// the sessions are written by a compare-and-swap of their `RemoteStore#Version`, see `Config#OptimisticConcurrency`.
+type VersionedDatabase interface {
+	Database
+	// SyncVersion same as `Sync` but the session is written only if it's missing
+	// or its stored version is the previous version of the payload's, `SyncPayload#Store.Version` - 1,
+	// otherwise it returns the stored session and the `ErrVersionConflict`.
+	// The other errors are handled by the database, like on `Sync`.
+	SyncVersion(p SyncPayload) (RemoteStore, error)
+}
+
+// SyncDatabaseVersion writes the payload to the "db" by its `VersionedDatabase#SyncVersion`,
+// or by its `Sync` if it doesn't implement it, the last write wins then.
+// It's useful for the session databases which wrap other databases, i.e a tiered one.
+func SyncDatabaseVersion(db Database, p SyncPayload) (RemoteStore, error) {
+	if versioned, ok := db.(VersionedDatabase); ok {
+		return versioned.SyncVersion(p)
+	}
+
+	db.Sync(p)
+	return RemoteStore{}, nil
+}
+
+// maxConflictRetries is the maximum number of the merges of a single write, see `Config#MergeConflict`.
+const maxConflictRetries = 3
+
+// versioned reports whether the payload should be written by a compare-and-swap, see `Config#OptimisticConcurrency`.
+func (p SyncPayload) versioned() bool {
+	if p.session == nil || p.Action == ActionDestroy || p.session.provider == nil {
+		return false
+	}
+
+	cfg := p.session.provider.config
+	return cfg != nil && cfg.OptimisticConcurrency
+}
+
+// syncVersion writes the payload to the "db" by a compare-and-swap,
+// on a conflict the stored session is merged, see `Config#MergeConflict`, and the merged values are written instead,
+// or the stored session replaces the local one.
+func (s *Session) syncVersion(db VersionedDatabase, p SyncPayload) {
+	for i := 0; ; i++ {
+		stored, err := db.SyncVersion(p)
+		if err != ErrVersionConflict {
+			if err != nil {
+				s.provider.logger().Errorf("sessions: sync of session %s: %v", p.SessionID, err)
+			}
+			return
+		}
+
+		var merged Store
+		ok := false
+		if merge := s.provider.config.MergeConflict; merge != nil && i < maxConflictRetries {
+			merged, ok = merge(p.SessionID, p.Store, stored)
+		}
+
+		s.mu.Lock()
+		if ok {
+			s.values = merged
+		} else {
+			// the local changes are dropped.
+			s.values = stored.Values
+			s.conflict = ErrVersionConflict
+		}
+		// the undo records refer to the replaced values.
+		s.journal = s.journal[:0]
+		atomic.StoreUint64(&s.version, stored.Version)
+		s.mu.Unlock()
+
+		if !ok {
+			return
+		}
+
+		p.Store.Values = append(Store(nil), merged...)
+		p.Store.Version = atomic.AddUint64(&s.version, 1)
+	}
+}
+
+// Version returns the version of the session, it's incremented on each write to the session databases
+// and it's persisted as the `RemoteStore#Version`.
+func (s *Session) Version() uint64 {
+	return atomic.LoadUint64(&s.version)
+}
+
+// Conflict returns the `ErrVersionConflict` if a write of the session, since the `Start`,
+// was rejected because the session was modified by another app instance and it couldn't be merged,
+// the session's values are the stored ones, see `Config#OptimisticConcurrency`.
+// A handler can check it at the end of a request, i.e to respond with a 409 Conflict.
+func (s *Session) Conflict() error {
+	s.mu.RLock()
+	err := s.conflict
+	s.mu.RUnlock()
+	return err
+}
@@ -0,0 +1,114 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// versionedDatabase is a shared session database which writes by a compare-and-swap of the versions.
+type versionedDatabase struct {
+	*concurrentDatabase
+}
+
+func (db versionedDatabase) SyncVersion(p SyncPayload) (RemoteStore, error) {
+	db.mu.Lock()
+	stored, ok := db.stores[p.SessionID]
+	db.mu.Unlock()
+
+	if ok && stored.Version != p.Store.Version-1 {
+		return stored, ErrVersionConflict
+	}
+
+	db.Sync(p)
+	return RemoteStore{}, nil
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	db := versionedDatabase{newConcurrentDatabase()}
+
+	start := func(manager *Sessions, cookie *http.Cookie) (*Session, *http.Cookie) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		sess := manager.Start(w, r)
+		if c := findCookie(w, DefaultCookieName); c != nil {
+			cookie = c
+		}
+		return sess, cookie
+	}
+
+	node1 := New(Config{OptimisticConcurrency: true})
+	node1.UseDatabase(db)
+	node2 := New(Config{OptimisticConcurrency: true})
+	node2.UseDatabase(db)
+
+	sess1, cookie := start(node1, nil)
+	sess1.Set("a", 1)
+
+	sess2, _ := start(node2, cookie)
+	if expected, got := sess1.Version(), sess2.Version(); expected != got {
+		t.Fatalf("expected the loaded version to be %d but got %d", expected, got)
+	}
+
+	sess1.Set("b", 2)
+	sess2.Set("c", 3) // stale.
+
+	if err := sess2.Conflict(); err != ErrVersionConflict {
+		t.Fatalf("expected a version conflict but got %v", err)
+	}
+
+	if sess2.Get("b") != 2 || sess2.Get("c") != nil {
+		t.Fatalf("expected the stale changes to be dropped and the stored values to be loaded but got %v", sess2.GetAll())
+	}
+
+	if stored := db.Load(sess1.ID()); stored.Values.Get("c") != nil {
+		t.Fatalf("expected the stale write to be rejected")
+	}
+
+	// merge.
+	node3 := New(Config{
+		OptimisticConcurrency: true,
+		MergeConflict: func(sid string, local, stored RemoteStore) (Store, bool) {
+			merged := stored.Values
+			local.Values.Visit(func(key string, value interface{}) {
+				merged.Set(key, value)
+			})
+			return merged, true
+		},
+	})
+	node3.UseDatabase(db)
+
+	sess3, _ := start(node3, cookie)
+	sess1.Set("d", 4)
+	sess3.Set("e", 5)
+
+	if err := sess3.Conflict(); err != nil {
+		t.Fatalf("expected the conflict to be merged but got %v", err)
+	}
+
+	stored := db.Load(sess1.ID())
+	if stored.Values.Get("d") != 4 || stored.Values.Get("e") != 5 {
+		t.Fatalf("expected the merged values to be stored but got %v", stored.Values)
+	}
+
+	if stored.Version != sess3.Version() {
+		t.Fatalf("expected the stored version to be %d but got %d", sess3.Version(), stored.Version)
+	}
+}
+
+func TestSyncDatabaseVersion(t *testing.T) {
+	db := &countingDatabase{concurrentDatabase: newConcurrentDatabase()}
+
+	p := SyncPayload{SessionID: "sid", Action: ActionInsert, Store: RemoteStore{Version: 5}}
+	if _, err := SyncDatabaseVersion(db, p); err != nil || db.syncs != 1 {
+		t.Fatalf("expected the session to be synced to the unversioned database but got %v after %d syncs", err, db.syncs)
+	}
+
+	versioned := versionedDatabase{db.concurrentDatabase}
+	if _, err := SyncDatabaseVersion(versioned, p); err != ErrVersionConflict {
+		t.Fatalf("expected a version conflict but got %v", err)
+	}
+}
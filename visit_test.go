@@ -0,0 +1,120 @@
+package sessions
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVisit(t *testing.T) {
+	db := newConcurrentDatabase()
+	db.stores["stored"] = RemoteStore{
+		Values:   Store{{Key: "name", ValueRaw: "stored"}},
+		Lifetime: LifeTime{Time: time.Now().Add(time.Hour)},
+	}
+	db.stores["expired"] = RemoteStore{Lifetime: LifeTime{Time: time.Now().Add(-time.Hour)}}
+
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(db)
+	manager.provider.Init("memory", time.Hour).Set("name", "memory")
+
+	if expected, n := 2, manager.Count(); n != expected {
+		t.Fatalf("expected %d active sessions but got %d", expected, n)
+	}
+
+	visited := make(map[string]string)
+	manager.Visit(func(sid string, sess *Session) bool {
+		visited[sid] = sess.GetString("name")
+		return true
+	})
+
+	if visited["memory"] != "memory" || visited["stored"] != "stored" {
+		t.Fatalf("expected the sessions of the memory and the database to be visited but got %v", visited)
+	}
+
+	manager.Visit(func(sid string, sess *Session) bool {
+		if sid == "stored" {
+			manager.DestroyByID(sid)
+			return false
+		}
+		return true
+	})
+
+	if _, found := db.stores["stored"]; found {
+		t.Fatalf("expected the stored session to be revoked from the database")
+	}
+
+	if expected, n := 1, manager.Count(); n != expected {
+		t.Fatalf("expected %d active session after the revocation but got %d", expected, n)
+	}
+}
+
+// countingScanner counts the scanned sessions of its database.
+type countingScanner struct {
+	*concurrentDatabase
+	scanned int
+}
+
+func (db *countingScanner) Scan(visitor func(sid string, store RemoteStore) bool) {
+	db.concurrentDatabase.Scan(func(sid string, store RemoteStore) bool {
+		db.scanned++
+		return visitor(sid, store)
+	})
+}
+
+func TestVisitWith(t *testing.T) {
+	db := &countingScanner{concurrentDatabase: newConcurrentDatabase()}
+	for _, sid := range []string{"user-1", "user-2", "user-3", "admin-1"} {
+		db.stores[sid] = RemoteStore{Values: Store{{Key: "name", ValueRaw: sid}}}
+	}
+
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(db)
+	manager.provider.Init("user-0", time.Hour).Set("name", "user-0")
+
+	var visited []string
+	manager.VisitWith(VisitOptions{Prefix: "user-", Offset: 1, Limit: 2}, func(sid string, sess *Session) bool {
+		visited = append(visited, sess.GetString("name"))
+		return true
+	})
+
+	// the memory session is skipped by the offset, then two of the three stored users are visited.
+	if len(visited) != 2 || visited[0] == "user-0" {
+		t.Fatalf("expected two stored sessions of the prefix after the offset but got %v", visited)
+	}
+	for _, name := range visited {
+		if name == "admin-1" {
+			t.Fatalf("expected the sessions of the prefix only but got %v", visited)
+		}
+	}
+
+	visited = visited[:0]
+	manager.VisitWith(VisitOptions{Predicate: func(sid string, value interface{}) bool {
+		return value.(*Session).GetString("name") == "admin-1"
+	}}, func(sid string, _ *Session) bool {
+		visited = append(visited, sid)
+		return true
+	})
+	if len(visited) != 1 || visited[0] != "admin-1" {
+		t.Fatalf("expected the sessions of the predicate but got %v", visited)
+	}
+}
+
+func TestVisitStream(t *testing.T) {
+	db := &countingScanner{concurrentDatabase: newConcurrentDatabase()}
+	for i := 0; i < 10; i++ {
+		db.stores["stored"+strconv.Itoa(i)] = RemoteStore{Values: Store{{Key: "name", ValueRaw: "stored"}}}
+	}
+
+	manager := New(Config{Expires: time.Hour})
+	manager.UseDatabase(db)
+
+	n := 0
+	manager.Visit(func(string, *Session) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 || db.scanned != 3 {
+		t.Fatalf("expected the scan to stop with the visitor but %d sessions were visited and %d scanned", n, db.scanned)
+	}
+}
@@ -0,0 +1,113 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrNoSession returned by the `ContinueWebSocket` when the handshake request
+// has no session cookie or its session doesn't exist, i.e it expired.
+var ErrNoSession = errors.New("sessions: the request has no valid session")
+
+// ContinueWebSocket continues the session of the WebSocket handshake request, see `Sessions#ContinueWebSocket`.
+func ContinueWebSocket(r *http.Request) (context.Context, context.CancelFunc, error) {
+	return Default.ContinueWebSocket(r)
+}
+
+// ContinueWebSocket validates the session cookie of the WebSocket handshake request "r"
+// and returns the context of the connection, it carries the session, see `FromContext`,
+// and it's cancelled when the session ends, i.e on logout, so the connection should be closed then.
+// It should be called before the upgrade, the handshake should be rejected on `ErrNoSession`.
+//
+// While the connection is open the session's expiration is refreshed periodically,
+// by the `Config#IdleTimeout` or else by the `Config#Expires`, so a long-lived connection
+// doesn't have its session expire mid-stream. The returned cancel func stops the refresh,
+// it should be called when the connection is closed.
+//
+// The session is not held, see `Config#SingleWriter`, so it doesn't block the requests of the same session.
+//
+// Usage:
+// ctx, cancel, err := manager.ContinueWebSocket(r)
+// if err != nil { http.Error(w, err.Error(), http.StatusUnauthorized); return }
+// defer cancel()
+// conn, err := upgrader.Upgrade(w, r, nil)
+// sess := sessions.FromContext(ctx)
+func (s *Sessions) ContinueWebSocket(r *http.Request) (context.Context, context.CancelFunc, error) {
+	return s.continueWebSocket(s.requestValue(r))
+}
+
+// ContinueWebSocketFasthttp continues the session of the WebSocket handshake request, see `Sessions#ContinueWebSocket`.
+func ContinueWebSocketFasthttp(ctx *fasthttp.RequestCtx) (context.Context, context.CancelFunc, error) {
+	return Default.ContinueWebSocketFasthttp(ctx)
+}
+
+// ContinueWebSocketFasthttp continues the session of the WebSocket handshake request, see `Sessions#ContinueWebSocket`.
+// The returned context is not derived from the "ctx", which is reused after the handler returns.
+func (s *Sessions) ContinueWebSocketFasthttp(ctx *fasthttp.RequestCtx) (context.Context, context.CancelFunc, error) {
+	return s.continueWebSocket(s.requestValueFasthttp(ctx))
+}
+
+func (s *Sessions) continueWebSocket(cookieValue string) (context.Context, context.CancelFunc, error) {
+	sid := s.decodeCookieValue(cookieValue)
+	if sid == "" {
+		return nil, nil, ErrNoSession
+	}
+
+	sess, ok := s.provider.Lookup(sid, s.config.Expires)
+	if !ok {
+		return nil, nil, ErrNoSession
+	}
+
+	ctx, cancel := context.WithCancel(NewContext(sess.Context(), sess))
+
+	refresh := s.config.IdleTimeout
+	if refresh <= 0 {
+		refresh = s.config.Expires
+	}
+
+	if refresh > 0 {
+		s.keepAlive(ctx, sess, refresh/2)
+	}
+
+	return ctx, cancel, nil
+}
+
+// keepAlive refreshes the expiration of the "sess" every "interval", by the `Config#Clock`, until the "ctx" is done.
+func (s *Sessions) keepAlive(ctx context.Context, sess *Session, interval time.Duration) {
+	var (
+		mu    sync.Mutex
+		timer Timer
+	)
+
+	refresh := func() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if s.config.IdleTimeout > 0 {
+			s.provider.touch(sess)
+		} else {
+			s.provider.UpdateExpiration(sess.ID(), s.config.Expires)
+		}
+
+		mu.Lock()
+		timer.Reset(interval)
+		mu.Unlock()
+	}
+
+	mu.Lock()
+	timer = s.provider.clock().NewTimer(interval, refresh)
+	mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		mu.Lock()
+		timer.Stop()
+		mu.Unlock()
+	}()
+}
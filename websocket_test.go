@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContinueWebSocket(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := New(Config{IdleTimeout: time.Minute, Clock: clock})
+
+	if _, _, err := manager.ContinueWebSocket(httptest.NewRequest(http.MethodGet, "/ws", nil)); err != ErrNoSession {
+		t.Fatalf("expected the ErrNoSession without a cookie but got %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.AddCookie(&http.Cookie{Name: DefaultCookieName, Value: "unknown"})
+	if _, _, err := manager.ContinueWebSocket(r); err != ErrNoSession {
+		t.Fatalf("expected the ErrNoSession of an unknown session but got %v", err)
+	}
+
+	if n := manager.Count(); n != 0 {
+		t.Fatalf("expected the handshake to not create sessions but got %d", n)
+	}
+
+	w := httptest.NewRecorder()
+	manager.Start(w, httptest.NewRequest(http.MethodGet, "/", nil)).Set("user", "kataras")
+
+	r = httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.AddCookie(findCookie(w, DefaultCookieName))
+	ctx, cancel, err := manager.ContinueWebSocket(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if got := FromContext(ctx).GetString("user"); got != "kataras" {
+		t.Fatalf("expected the session of the handshake but got the user %q", got)
+	}
+
+	// the connection outlives the idle timeout.
+	for i := 0; i < 6; i++ {
+		clock.Advance(30 * time.Second)
+	}
+	if ctx.Err() != nil || manager.Count() != 1 {
+		t.Fatal("expected the session to be refreshed while the connection is open")
+	}
+
+	manager.DestroyByID(FromContext(ctx).ID())
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the connection's context to be cancelled when the session ends")
+	}
+}